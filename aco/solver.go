@@ -0,0 +1,146 @@
+// Package aco solves mastermind by ant colony optimization.  A pheromone
+// trail is laid on every (position, color) assignment.  Each iteration, every
+// ant builds a code by choosing the color of each position with probability
+// proportional to its trail raised to Alpha.  The ants are rated with the
+// genetic solver's fitness function, and the fittest Elite of them lay
+// pheromone on their assignments, in proportion to their rank, while every
+// trail evaporates.  Trails never drop below MinPheromone, as in the MAX-MIN
+// ant system, so no color is ever ruled out for good.  The first consistent
+// code an ant builds is guessed.
+package aco
+
+import (
+	"math"
+	"sort"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/genetic"
+	"github.com/ianmcmahon/mastermind/internal/metaheuristic"
+)
+
+type ant struct {
+	code    mm.Code
+	fitness float64
+}
+
+type Solver struct {
+	metaheuristic.Base
+	// pheromone[position][color]
+	pheromone [][]float64
+
+	// Fitness rates codes; lower is fitter
+	Fitness genetic.FitnessFunction
+	// Ants is the number of codes built each iteration
+	Ants int
+	// Elite is how many of the fittest ants lay pheromone each iteration
+	Elite int
+	// Alpha weighs the pheromone when choosing colors
+	Alpha float64
+	// Evaporation is the fraction of every trail lost each iteration, and
+	// Deposit how much the fittest ant lays
+	Evaporation  float64
+	Deposit      float64
+	MinPheromone float64
+	// Iterations is the most iterations looking for a consistent code each
+	// move.  If none is found, the fittest code built is guessed.
+	Iterations int
+}
+
+func NewSolver(cm mm.Codemaker) *Solver {
+	return &Solver{
+		Base:         metaheuristic.NewBase(cm),
+		Fitness:      genetic.Berghman{A: 2, B: 2},
+		Ants:         50,
+		Elite:        5,
+		Alpha:        1,
+		Evaporation:  0.1,
+		Deposit:      1,
+		MinPheromone: 0.05,
+		Iterations:   1000,
+	}
+}
+
+func (s *Solver) Solve() (mm.Code, error) {
+	return s.Search(s.forage)
+}
+
+// forage sends out the colony until an ant builds a consistent code,
+// returning it, or the fittest code built if the iterations run out.  Each
+// result changes the landscape, so the trails start afresh every move.
+func (s *Solver) forage() mm.Code {
+	s.resetPheromone()
+
+	// each ant builds into the same code every iteration, so the best is
+	// copied out of the colony
+	var best *ant
+	colony := make([]ant, s.Ants)
+	weights := make([]float64, s.Size.Colors)
+	for i := 0; i < s.Iterations; i++ {
+		for a := range colony {
+			if colony[a].code == nil {
+				colony[a].code = make(mm.Code, s.Size.Positions)
+			}
+			s.build(colony[a].code, weights)
+			if s.Consistent(colony[a].code) {
+				return colony[a].code
+			}
+			colony[a].fitness = s.Fitness.Evaluate(colony[a].code, s.History, s.Size)
+		}
+
+		sort.Slice(colony, func(i, j int) bool { return colony[i].fitness < colony[j].fitness })
+		if best == nil || colony[0].fitness < best.fitness {
+			best = &ant{code: append(mm.Code{}, colony[0].code...), fitness: colony[0].fitness}
+		}
+		s.layPheromone(colony)
+	}
+	return best.code
+}
+
+func (s *Solver) resetPheromone() {
+	s.pheromone = make([][]float64, s.Size.Positions)
+	for p := range s.pheromone {
+		s.pheromone[p] = make([]float64, s.Size.Colors)
+		for c := range s.pheromone[p] {
+			s.pheromone[p][c] = 1
+		}
+	}
+}
+
+// build chooses a color for every position of code by roulette over the
+// trails, with weights as scratch space for a weight per color
+func (s *Solver) build(code mm.Code, weights []float64) {
+	for p := range code {
+		total := 0.0
+		for c, tau := range s.pheromone[p] {
+			weights[c] = math.Pow(tau, s.Alpha)
+			total += weights[c]
+		}
+		roll := s.Rand.Float64() * total
+		for c, w := range weights {
+			code[p] = byte(c)
+			if roll -= w; roll < 0 {
+				break
+			}
+		}
+	}
+}
+
+// evaporates every trail and lets the elite of the ranked colony lay more
+func (s *Solver) layPheromone(ranked []ant) {
+	for p := range s.pheromone {
+		for c := range s.pheromone[p] {
+			s.pheromone[p][c] *= 1 - s.Evaporation
+		}
+	}
+	for rank := 0; rank < s.Elite && rank < len(ranked); rank++ {
+		amount := s.Deposit * float64(s.Elite-rank) / float64(s.Elite)
+		for p, c := range ranked[rank].code {
+			s.pheromone[p][c] += amount
+		}
+	}
+	for p := range s.pheromone {
+		for c := range s.pheromone[p] {
+			s.pheromone[p][c] = math.Max(s.pheromone[p][c], s.MinPheromone)
+		}
+	}
+}
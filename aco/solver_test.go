@@ -0,0 +1,52 @@
+package aco
+
+import (
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestPheromone(t *testing.T) {
+	solver := NewSolver(mm.NewCustomGame(4, 6))
+	solver.Seed(1)
+	solver.resetPheromone()
+
+	// an ant which keeps being the fittest draws the colony to its code
+	elite := mm.Code{5, 4, 3, 2}
+	for i := 0; i < 50; i++ {
+		solver.layPheromone([]ant{{code: elite}})
+	}
+	for p, c := range elite {
+		for color, tau := range solver.pheromone[p] {
+			if color != int(c) && tau != solver.MinPheromone {
+				t.Errorf("trail for %d at %d should have evaporated to the minimum, got %.3f", color, p, tau)
+			}
+		}
+	}
+	same := 0
+	code, weights := make(mm.Code, 4), make([]float64, 6)
+	for i := 0; i < 100; i++ {
+		if solver.build(code, weights); code.String() == elite.String() {
+			same++
+		}
+	}
+	if same < 50 {
+		t.Errorf("only %d of 100 ants followed the trail to %v", same, elite)
+	}
+}
+
+// a move's foraging, in which each ant builds into the same code every
+// iteration
+func BenchmarkForage(b *testing.B) {
+	solver := NewSolver(mm.NewCustomGameWithSecret(6, 9, mm.Code{8, 7, 6, 5, 4, 3}))
+	solver.Seed(1)
+	solver.History = mm.History{
+		{Guess: mm.Code{0, 0, 1, 1, 2, 2}, Result: mm.Result{}},
+		{Guess: mm.Code{3, 4, 5, 6, 7, 8}, Result: mm.Result{Correct: 0, HalfCorrect: 6}},
+		{Guess: mm.Code{4, 3, 6, 5, 8, 7}, Result: mm.Result{Correct: 0, HalfCorrect: 6}},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		solver.forage()
+	}
+}
@@ -0,0 +1,227 @@
+package mastermind
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ianmcmahon/mastermind/internal/pool"
+)
+
+// MaxAnnotatedCodes is the most codes a board may have for Annotate, which
+// rates every code as a guess against every code which could still be the
+// secret, each move
+const MaxAnnotatedCodes = 1 << 15
+
+// Quality is how a guess compares to the best there was, as a chess engine
+// annotates moves
+type Quality int
+
+const (
+	// Optimal guesses are as good as any by their worst case or on average
+	Optimal Quality = iota
+	// Good guesses leave no more than GoodLoss more codes than the best
+	Good
+	// Inaccuracy guesses leave no more than InaccuracyLoss more
+	Inaccuracy
+	// Blunder guesses leave more than that
+	Blunder
+)
+
+// GoodLoss and InaccuracyLoss are the most codes a guess may leave, as a
+// fraction more than the best guess leaves, to be Good or an Inaccuracy.
+// A guess is judged by whichever of its worst case and its average is the
+// closer to the best.
+const (
+	GoodLoss       = 0.1
+	InaccuracyLoss = 0.5
+)
+
+func (q Quality) String() string {
+	switch q {
+	case Optimal:
+		return "optimal"
+	case Good:
+		return "good"
+	case Inaccuracy:
+		return "inaccuracy"
+	case Blunder:
+		return "blunder"
+	}
+	return "unknown"
+}
+
+// Annotation is how good a move of a game was
+type Annotation struct {
+	Move
+	Quality Quality
+	// Worst is the most codes the guess could have left possible, and
+	// Expected how many it leaves on average over the codes which were;
+	// a guess which wins leaves none
+	Worst    int
+	Expected float64
+	// Best is the best guess there was, the one leaving the fewest codes at
+	// worst, then on average.  BestWorst and BestExpected are the fewest
+	// codes any guess leaves at worst and on average, which needn't both be
+	// Best's.
+	Best         Code
+	BestWorst    int
+	BestExpected float64
+}
+
+func (a Annotation) String() string {
+	return fmt.Sprintf("%v %v: worst %d (best %d), expected %.1f (best %.1f)",
+		a.Move, a.Quality, a.Worst, a.BestWorst, a.Expected, a.BestExpected)
+}
+
+// Annotate rates each move of a game of size, where the results were
+// reduced to feedback f, against every guess which could have been made
+// instead, by how the guesses split the codes which could be the secret.
+// It fails if the moves leave no code possible.
+func Annotate(size GameSize, f Feedback, h History) ([]Annotation, error) {
+	if size.NumCodes() > MaxAnnotatedCodes {
+		return nil, fmt.Errorf("%v has too many codes to annotate", size)
+	}
+	codes := size.AllCodes()
+	candidates := codes
+	notes := make([]Annotation, 0, len(h))
+	for i, m := range h {
+		if len(m.Guess) != size.Positions {
+			return nil, fmt.Errorf("move %d guesses %v, which isn't size %v", i+1, m.Guess, size)
+		}
+		for _, peg := range m.Guess {
+			if peg >= size.Colors {
+				return nil, fmt.Errorf("move %d guesses %v, which isn't size %v", i+1, m.Guess, size)
+			}
+		}
+
+		a := Annotation{Move: m}
+		a.Worst, a.Expected = partitionOf(m.Guess, candidates, size, f)
+		a.rateBest(codes, candidates, size, f)
+		a.Quality = quality(a)
+		notes = append(notes, a)
+
+		kept := candidates[:0:0]
+		for _, c := range candidates {
+			if r, _ := f.Score(m.Guess, c, size.Colors); r == m.Result {
+				kept = append(kept, c)
+			}
+		}
+		if len(kept) == 0 {
+			return nil, fmt.Errorf("no code scores move %d, %v", i+1, m)
+		}
+		candidates = kept
+	}
+	return notes, nil
+}
+
+// rateBest finds the best of codes as a guess against candidates, each
+// worker rating its own range of them
+func (a *Annotation) rateBest(codes, candidates CodeSlice, size GameSize, f Feedback) {
+	type best struct {
+		guess    Code
+		worst    int
+		expected float64
+		// the fewest codes any guess of the range leaves
+		minWorst    int
+		minExpected float64
+	}
+	workers := pool.Workers(0)
+	shards := make([]best, workers)
+	possible := map[CodeKey]bool{}
+	for _, c := range candidates {
+		possible[c.Key()] = true
+	}
+	// the better of two guesses: fewer codes at worst, then on average, then
+	// the one which could win, then the lesser
+	better := func(g Code, worst int, expected float64, b best) bool {
+		switch {
+		case b.guess == nil || worst != b.worst:
+			return b.guess == nil || worst < b.worst
+		case expected != b.expected:
+			return expected < b.expected
+		case possible[g.Key()] != possible[b.guess.Key()]:
+			return possible[g.Key()]
+		}
+		return g.Compare(b.guess) < 0
+	}
+	pool.Ranges(len(codes), workers, 1, func(i, lo, hi int) {
+		b := best{minWorst: -1}
+		for _, g := range codes[lo:hi] {
+			worst, expected := partitionOf(g, candidates, size, f)
+			if b.minWorst < 0 || worst < b.minWorst {
+				b.minWorst = worst
+			}
+			if b.guess == nil || expected < b.minExpected {
+				b.minExpected = expected
+			}
+			if better(g, worst, expected, b) {
+				b.guess, b.worst, b.expected = g, worst, expected
+			}
+		}
+		shards[i] = b
+	})
+
+	var top best
+	for _, b := range shards {
+		if b.guess == nil {
+			continue
+		}
+		if top.guess == nil || b.minWorst < a.BestWorst {
+			a.BestWorst = b.minWorst
+		}
+		if top.guess == nil || b.minExpected < a.BestExpected {
+			a.BestExpected = b.minExpected
+		}
+		if better(b.guess, b.worst, b.expected, top) {
+			top = b
+		}
+	}
+	a.Best = top.guess
+}
+
+// partitionOf is the most codes of candidates guess could leave possible,
+// and how many it leaves on average; the codes it would win against leave
+// none
+func partitionOf(guess Code, candidates CodeSlice, size GameSize, f Feedback) (int, float64) {
+	results, err := CheckCodes(guess, candidates, size.Colors)
+	if err != nil {
+		panic(err)
+	}
+	var counts [256]int
+	for _, r := range results {
+		counts[f.Reduce(r, size.Positions).Key()]++
+	}
+	win := Result{size.Positions, 0}.Key()
+	worst, squares := 0, 0
+	for k, n := range counts {
+		if ResultKey(k) == win {
+			continue
+		}
+		if n > worst {
+			worst = n
+		}
+		squares += n * n
+	}
+	return worst, float64(squares) / float64(len(candidates))
+}
+
+// quality judges a by whichever of its worst case and average is the
+// closer to the best
+func quality(a Annotation) Quality {
+	loss := math.Min(excess(float64(a.Worst), float64(a.BestWorst)), excess(a.Expected, a.BestExpected))
+	switch {
+	case loss < 1e-9:
+		return Optimal
+	case loss <= GoodLoss:
+		return Good
+	case loss <= InaccuracyLoss:
+		return Inaccuracy
+	}
+	return Blunder
+}
+
+// excess is how much more x is than best, as a fraction of best, or of one
+// code if the best leaves fewer
+func excess(x, best float64) float64 {
+	return (x - best) / math.Max(best, 1)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestRoundTrip(t *testing.T) {
+	messages := []struct{ in, out Message }{
+		{&NewGameRequest{Size: mm.GameSize{Positions: 5, Colors: 8}}, &NewGameRequest{}},
+		{&GameRequest{ID: "abc"}, &GameRequest{}},
+		{&GuessRequest{ID: "abc", Player: "ian", Guess: mm.Code{0, 0, 1, 2}}, &GuessRequest{}},
+		{&GuessResponse{Move: Move{Player: "ian", Guess: mm.Code{3, 0}, Result: mm.Result{Correct: 1, HalfCorrect: 0}}, Solved: true}, &GuessResponse{}},
+		{&Hint{Guess: mm.Code{1, 1, 2, 2}, Remaining: 1296}, &Hint{}},
+		{&Game{
+			ID:     "abc",
+			Size:   mm.GameSize{Positions: 2, Colors: 6},
+			Moves:  []Move{{Guess: mm.Code{0, 1}, Result: mm.Result{Correct: 0, HalfCorrect: 1}}, {Player: "x", Guess: mm.Code{1, 5}, Result: mm.Result{Correct: 2}}},
+			Solved: true,
+			Secret: mm.Code{1, 5},
+		}, &Game{}},
+	}
+	for _, m := range messages {
+		if err := m.out.Unmarshal(m.in.Marshal()); err != nil {
+			t.Errorf("%T: %v", m.in, err)
+		}
+		if !reflect.DeepEqual(m.in, m.out) {
+			t.Errorf("%T: expected %+v, got %+v", m.in, m.in, m.out)
+		}
+	}
+}
+
+func TestWireFormat(t *testing.T) {
+	// field 1 length 4 packed {0 0 1 2}, then field 2 varint 150
+	hint := &Hint{Guess: mm.Code{0, 0, 1, 2}, Remaining: 150}
+	expected := []byte{0x0a, 0x06, 0x0a, 0x04, 0, 0, 1, 2, 0x10, 0x96, 0x01}
+	if b := hint.Marshal(); !bytes.Equal(b, expected) {
+		t.Errorf("expected % x, got % x", expected, b)
+	}
+
+	// unpacked pegs, and fields from the future, are read too
+	code, err := unmarshalCode([]byte{0x08, 3, 0x08, 1, 0x15, 1, 2, 3, 4})
+	if err != nil || code.String() != "31" {
+		t.Errorf("expected 31, got %v %v", code, err)
+	}
+	if err := (&Hint{}).Unmarshal([]byte{0x0a, 0x09}); err == nil {
+		t.Errorf("expected a truncated message to fail")
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"google.golang.org/grpc"
+)
+
+// Client calls the service
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// Dial connects to a server at target.  Transport credentials must be among
+// opts, eg grpc.WithTransportCredentials(insecure.NewCredentials()) for a
+// plaintext connection.
+func Dial(target string, opts ...grpc.DialOption) (*Client, error) {
+	opts = append([]grpc.DialOption{grpc.WithDefaultCallOptions(grpc.ForceCodec(Codec{}))}, opts...)
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) invoke(ctx context.Context, method string, req, resp Message) error {
+	return c.conn.Invoke(ctx, "/"+serviceName+"/"+method, req, resp, grpc.ForceCodec(Codec{}))
+}
+
+// NewGame starts a game with a random secret
+func (c *Client) NewGame(ctx context.Context, size mm.GameSize) (*Game, error) {
+	g := &Game{}
+	return g, c.invoke(ctx, "NewGame", &NewGameRequest{Size: size}, g)
+}
+
+func (c *Client) GetGame(ctx context.Context, id string) (*Game, error) {
+	g := &Game{}
+	return g, c.invoke(ctx, "GetGame", &GameRequest{ID: id}, g)
+}
+
+// Guess scores guess at the game id, on behalf of player
+func (c *Client) Guess(ctx context.Context, id, player string, guess mm.Code) (*GuessResponse, error) {
+	r := &GuessResponse{}
+	return r, c.invoke(ctx, "Guess", &GuessRequest{ID: id, Player: player, Guess: guess}, r)
+}
+
+// Hint asks the solver for the game's next guess
+func (c *Client) Hint(ctx context.Context, id string) (*Hint, error) {
+	h := &Hint{}
+	return h, c.invoke(ctx, "Hint", &GameRequest{ID: id}, h)
+}
@@ -0,0 +1,370 @@
+// Package api is the mastermind gRPC service of mastermind.proto: its
+// messages, the service description for servers, and a typed client.
+//
+// The messages are encoded by hand with protowire rather than generated, so
+// servers and clients must use Codec; Register and Dial see to that.  On the
+// wire they're plain protobuf, so clients in other languages can be
+// generated from mastermind.proto as usual.
+package api
+
+import (
+	"fmt"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Message is implemented by every message of the service
+type Message interface {
+	Marshal() []byte
+	Unmarshal(b []byte) error
+}
+
+type NewGameRequest struct {
+	Size mm.GameSize
+}
+
+type GameRequest struct {
+	ID string
+}
+
+type GuessRequest struct {
+	ID     string
+	Player string
+	Guess  mm.Code
+}
+
+type Move struct {
+	Player string
+	Guess  mm.Code
+	Result mm.Result
+}
+
+type Game struct {
+	ID     string
+	Size   mm.GameSize
+	Moves  []Move
+	Solved bool
+	// Secret is only set once solved
+	Secret mm.Code
+}
+
+type GuessResponse struct {
+	Move   Move
+	Solved bool
+}
+
+type Hint struct {
+	Guess     mm.Code
+	Remaining int
+}
+
+// encoder appends fields, leaving out zero scalars as proto3 does
+type encoder []byte
+
+func (e *encoder) uint(num protowire.Number, v uint64) {
+	if v != 0 {
+		*e = protowire.AppendTag(*e, num, protowire.VarintType)
+		*e = protowire.AppendVarint(*e, v)
+	}
+}
+
+func (e *encoder) bool(num protowire.Number, v bool) {
+	if v {
+		e.uint(num, 1)
+	}
+}
+
+func (e *encoder) string(num protowire.Number, v string) {
+	if v != "" {
+		*e = protowire.AppendTag(*e, num, protowire.BytesType)
+		*e = protowire.AppendString(*e, v)
+	}
+}
+
+func (e *encoder) message(num protowire.Number, v []byte) {
+	*e = protowire.AppendTag(*e, num, protowire.BytesType)
+	*e = protowire.AppendBytes(*e, v)
+}
+
+// field is one field read from the wire; varint or bytes is set by type
+type field struct {
+	num    protowire.Number
+	typ    protowire.Type
+	varint uint64
+	bytes  []byte
+}
+
+// decode calls f with each field of b, skipping types it doesn't handle
+func decode(b []byte, f func(field) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		fl := field{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			fl.varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			fl.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if typ == protowire.VarintType || typ == protowire.BytesType {
+			if err := f(fl); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func marshalSize(s mm.GameSize) []byte {
+	var e encoder
+	e.uint(1, uint64(s.Positions))
+	e.uint(2, uint64(s.Colors))
+	return e
+}
+
+func unmarshalSize(b []byte) (mm.GameSize, error) {
+	var s mm.GameSize
+	err := decode(b, func(f field) error {
+		switch f.num {
+		case 1:
+			s.Positions = int(f.varint)
+		case 2:
+			if f.varint > 255 {
+				return fmt.Errorf("%d colors is too many", f.varint)
+			}
+			s.Colors = byte(f.varint)
+		}
+		return nil
+	})
+	return s, err
+}
+
+// codes are packed, as proto3 does for repeated scalars
+func marshalCode(c mm.Code) []byte {
+	var pegs []byte
+	for _, p := range c {
+		pegs = protowire.AppendVarint(pegs, uint64(p))
+	}
+	var e encoder
+	if len(pegs) > 0 {
+		e.message(1, pegs)
+	}
+	return e
+}
+
+func unmarshalCode(b []byte) (mm.Code, error) {
+	c := mm.Code{}
+	peg := func(v uint64) error {
+		if v > 255 {
+			return fmt.Errorf("color %d is out of range", v)
+		}
+		c = append(c, byte(v))
+		return nil
+	}
+	err := decode(b, func(f field) error {
+		if f.num != 1 {
+			return nil
+		}
+		if f.typ == protowire.VarintType {
+			return peg(f.varint)
+		}
+		for b := f.bytes; len(b) > 0; {
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			if err := peg(v); err != nil {
+				return err
+			}
+			b = b[n:]
+		}
+		return nil
+	})
+	return c, err
+}
+
+func marshalResult(r mm.Result) []byte {
+	var e encoder
+	e.uint(1, uint64(r.Correct))
+	e.uint(2, uint64(r.HalfCorrect))
+	return e
+}
+
+func unmarshalResult(b []byte) (mm.Result, error) {
+	var r mm.Result
+	err := decode(b, func(f field) error {
+		switch f.num {
+		case 1:
+			r.Correct = int(f.varint)
+		case 2:
+			r.HalfCorrect = int(f.varint)
+		}
+		return nil
+	})
+	return r, err
+}
+
+func (m *NewGameRequest) Marshal() []byte {
+	var e encoder
+	e.message(1, marshalSize(m.Size))
+	return e
+}
+
+func (m *NewGameRequest) Unmarshal(b []byte) error {
+	*m = NewGameRequest{}
+	return decode(b, func(f field) (err error) {
+		if f.num == 1 {
+			m.Size, err = unmarshalSize(f.bytes)
+		}
+		return
+	})
+}
+
+func (m *GameRequest) Marshal() []byte {
+	var e encoder
+	e.string(1, m.ID)
+	return e
+}
+
+func (m *GameRequest) Unmarshal(b []byte) error {
+	*m = GameRequest{}
+	return decode(b, func(f field) error {
+		if f.num == 1 {
+			m.ID = string(f.bytes)
+		}
+		return nil
+	})
+}
+
+func (m *GuessRequest) Marshal() []byte {
+	var e encoder
+	e.string(1, m.ID)
+	e.string(2, m.Player)
+	e.message(3, marshalCode(m.Guess))
+	return e
+}
+
+func (m *GuessRequest) Unmarshal(b []byte) error {
+	*m = GuessRequest{}
+	return decode(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			m.ID = string(f.bytes)
+		case 2:
+			m.Player = string(f.bytes)
+		case 3:
+			m.Guess, err = unmarshalCode(f.bytes)
+		}
+		return
+	})
+}
+
+func (m *Move) Marshal() []byte {
+	var e encoder
+	e.string(1, m.Player)
+	e.message(2, marshalCode(m.Guess))
+	e.message(3, marshalResult(m.Result))
+	return e
+}
+
+func (m *Move) Unmarshal(b []byte) error {
+	*m = Move{}
+	return decode(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			m.Player = string(f.bytes)
+		case 2:
+			m.Guess, err = unmarshalCode(f.bytes)
+		case 3:
+			m.Result, err = unmarshalResult(f.bytes)
+		}
+		return
+	})
+}
+
+func (m *Game) Marshal() []byte {
+	var e encoder
+	e.string(1, m.ID)
+	e.message(2, marshalSize(m.Size))
+	for i := range m.Moves {
+		e.message(3, m.Moves[i].Marshal())
+	}
+	e.bool(4, m.Solved)
+	if m.Secret != nil {
+		e.message(5, marshalCode(m.Secret))
+	}
+	return e
+}
+
+func (m *Game) Unmarshal(b []byte) error {
+	*m = Game{}
+	return decode(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			m.ID = string(f.bytes)
+		case 2:
+			m.Size, err = unmarshalSize(f.bytes)
+		case 3:
+			var move Move
+			err = move.Unmarshal(f.bytes)
+			m.Moves = append(m.Moves, move)
+		case 4:
+			m.Solved = f.varint != 0
+		case 5:
+			m.Secret, err = unmarshalCode(f.bytes)
+		}
+		return
+	})
+}
+
+func (m *GuessResponse) Marshal() []byte {
+	var e encoder
+	e.message(1, m.Move.Marshal())
+	e.bool(2, m.Solved)
+	return e
+}
+
+func (m *GuessResponse) Unmarshal(b []byte) error {
+	*m = GuessResponse{}
+	return decode(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			err = m.Move.Unmarshal(f.bytes)
+		case 2:
+			m.Solved = f.varint != 0
+		}
+		return
+	})
+}
+
+func (m *Hint) Marshal() []byte {
+	var e encoder
+	e.message(1, marshalCode(m.Guess))
+	e.uint(2, uint64(m.Remaining))
+	return e
+}
+
+func (m *Hint) Unmarshal(b []byte) error {
+	*m = Hint{}
+	return decode(b, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			m.Guess, err = unmarshalCode(f.bytes)
+		case 2:
+			m.Remaining = int(f.varint)
+		}
+		return
+	})
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+)
+
+const serviceName = "mastermind.Mastermind"
+
+// Codec encodes the service's messages as protobuf.  It's named proto, so
+// it's what other clients expect, but isn't registered, so it doesn't
+// displace the generated-code codec for other services in the process.
+type Codec struct{}
+
+func (Codec) Name() string {
+	return "proto"
+}
+
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(Message)
+	if !ok {
+		return nil, fmt.Errorf("can't marshal %T", v)
+	}
+	return m.Marshal(), nil
+}
+
+func (Codec) Unmarshal(b []byte, v interface{}) error {
+	m, ok := v.(Message)
+	if !ok {
+		return fmt.Errorf("can't unmarshal %T", v)
+	}
+	return m.Unmarshal(b)
+}
+
+// MastermindServer is the service mastermind.proto describes
+type MastermindServer interface {
+	NewGame(context.Context, *NewGameRequest) (*Game, error)
+	GetGame(context.Context, *GameRequest) (*Game, error)
+	Guess(context.Context, *GuessRequest) (*GuessResponse, error)
+	Hint(context.Context, *GameRequest) (*Hint, error)
+}
+
+// ServerOptions are the options a grpc.Server needs to serve the service
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.ForceServerCodec(Codec{})}
+}
+
+// Register serves srv on s, which must have been made with ServerOptions
+func Register(s *grpc.Server, srv MastermindServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+// unary describes a method taking a request made by newReq
+func unary(name string, newReq func() Message, call func(srv MastermindServer, ctx context.Context, req Message) (interface{}, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := newReq()
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return call(srv.(MastermindServer), ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/" + name}
+			return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(srv.(MastermindServer), ctx, req.(Message))
+			})
+		},
+	}
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*MastermindServer)(nil),
+	Methods: []grpc.MethodDesc{
+		unary("NewGame", func() Message { return &NewGameRequest{} }, func(srv MastermindServer, ctx context.Context, req Message) (interface{}, error) {
+			return srv.NewGame(ctx, req.(*NewGameRequest))
+		}),
+		unary("GetGame", func() Message { return &GameRequest{} }, func(srv MastermindServer, ctx context.Context, req Message) (interface{}, error) {
+			return srv.GetGame(ctx, req.(*GameRequest))
+		}),
+		unary("Guess", func() Message { return &GuessRequest{} }, func(srv MastermindServer, ctx context.Context, req Message) (interface{}, error) {
+			return srv.Guess(ctx, req.(*GuessRequest))
+		}),
+		unary("Hint", func() Message { return &GameRequest{} }, func(srv MastermindServer, ctx context.Context, req Message) (interface{}, error) {
+			return srv.Hint(ctx, req.(*GameRequest))
+		}),
+	},
+	Metadata: "mastermind.proto",
+}
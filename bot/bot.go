@@ -0,0 +1,178 @@
+// Package bot plays mastermind in chat: commands such as "/mm new 4x6" and
+// "/mm guess 0123" typed in a channel play that channel's game, which is
+// kept by a server.GameManager, and the replies draw the board in emoji.
+// Handlers adapt it to Slack's slash commands and Discord's interactions.
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/server"
+)
+
+// Pegs are the emoji the colors are drawn with, in order, as the terminal
+// names them: red, green, yellow, blue, magenta, cyan, white, orange, pink
+// and brown
+var Pegs = []string{"🔴", "🟢", "🟡", "🔵", "🟣", "🩵", "⚪", "🟠", "🩷", "🟤"}
+
+const help = "Commands: `new [size]` starts a game, eg `new 4x6`; `guess 0123` guesses, with the colors as digits; " +
+	"`hint` asks the solver; `board` shows the game so far; `giveup` shows the secret and ends the game."
+
+// Bot keeps a game for each channel
+type Bot struct {
+	Games *server.GameManager
+
+	mu       sync.Mutex
+	channels map[string]string // game ids
+}
+
+func New(games *server.GameManager) *Bot {
+	return &Bot{Games: games, channels: map[string]string{}}
+}
+
+// Handle runs a command user typed in channel, returning the reply
+func (b *Bot) Handle(channel, user, text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return help
+	}
+	args := fields[1:]
+	switch strings.ToLower(fields[0]) {
+	case "new", "start":
+		return b.newGame(channel, args)
+	case "guess", "g":
+		if len(args) == 0 {
+			return "Guess what? eg `guess 0123`"
+		}
+		return b.guess(channel, user, strings.Join(args, ""))
+	case "hint":
+		return b.hint(channel)
+	case "board", "show":
+		return b.board(channel)
+	case "giveup", "resign":
+		return b.giveUp(channel)
+	}
+	return help
+}
+
+// game is the channel's game, or nil and the reply saying there's none
+func (b *Bot) game(channel string) (*server.Game, string) {
+	b.mu.Lock()
+	id, ok := b.channels[channel]
+	b.mu.Unlock()
+	if !ok {
+		return nil, "There's no game here.  Start one with `new`."
+	}
+	g, err := b.Games.Get(id)
+	if err != nil {
+		return nil, err.Error()
+	}
+	return g, ""
+}
+
+func (b *Bot) newGame(channel string, args []string) string {
+	size := mm.GameSize{Positions: 4, Colors: 6}
+	if len(args) > 0 {
+		var err error
+		if size, err = mm.ParseGameSize(args[0]); err != nil {
+			return err.Error()
+		}
+	}
+	g, err := b.Games.Create(size)
+	if err != nil {
+		return err.Error()
+	}
+	b.mu.Lock()
+	b.channels[channel] = g.ID
+	b.mu.Unlock()
+
+	legend := []string{}
+	for c := byte(0); c < g.Size.Colors; c++ {
+		legend = append(legend, fmt.Sprintf("%s%d", Pegs[c], c))
+	}
+	return fmt.Sprintf("New %v game: guess the %d pegs with `guess`.  Colors: %s",
+		g.Size, g.Size.Positions, strings.Join(legend, " "))
+}
+
+func (b *Bot) guess(channel, user, guess string) string {
+	g, reply := b.game(channel)
+	if g == nil {
+		return reply
+	}
+	m, solved, err := g.Guess(user, guess)
+	if err != nil {
+		return err.Error()
+	}
+	h := g.History()
+	last := h[len(h)-1]
+	reply = fmt.Sprintf("%s guessed %s  %s", user, pegs(last.Guess), pins(last.Result, g.Size.Positions))
+	if solved {
+		b.forget(channel, g.ID)
+		return fmt.Sprintf("%s\n%s solved it in %d guesses!\n%s", reply, m.Player, len(h), g.Share())
+	}
+	return reply
+}
+
+func (b *Bot) hint(channel string) string {
+	g, reply := b.game(channel)
+	if g == nil {
+		return reply
+	}
+	hint, err := g.Hint()
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("Try %s (%v); %d codes could be the secret.", pegs(hint), hint, g.Candidates())
+}
+
+func (b *Bot) board(channel string) string {
+	g, reply := b.game(channel)
+	if g == nil {
+		return reply
+	}
+	lines := []string{fmt.Sprintf("%v game, %d guesses so far", g.Size, len(g.History()))}
+	for _, m := range g.History() {
+		lines = append(lines, pegs(m.Guess)+"  "+pins(m.Result, g.Size.Positions))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (b *Bot) giveUp(channel string) string {
+	g, reply := b.game(channel)
+	if g == nil {
+		return reply
+	}
+	b.forget(channel, g.ID)
+	secret := g.Secret()
+	return fmt.Sprintf("The secret was %s (%v).", pegs(secret), secret)
+}
+
+// forget ends the channel's game, if it's still id
+func (b *Bot) forget(channel, id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.channels[channel] == id {
+		delete(b.channels, channel)
+	}
+}
+
+func pegs(c mm.Code) string {
+	var s strings.Builder
+	for _, p := range c {
+		if int(p) < len(Pegs) {
+			s.WriteString(Pegs[p])
+		} else {
+			s.WriteString("❔")
+		}
+	}
+	return s.String()
+}
+
+// pins draws a result as mm.ShareGrid does
+func pins(r mm.Result, positions int) string {
+	return strings.Repeat("🟩", r.Correct) + strings.Repeat("🟨", r.HalfCorrect) +
+		strings.Repeat("⬜", positions-r.Correct-r.HalfCorrect)
+}
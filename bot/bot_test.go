@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/server"
+)
+
+func TestBot(t *testing.T) {
+	b := New(server.NewGameManager())
+
+	if reply := b.Handle("c1", "ann", "guess 0123"); !strings.Contains(reply, "no game") {
+		t.Errorf("expected no game yet, got %q", reply)
+	}
+	if reply := b.Handle("c1", "ann", "new 4x6"); !strings.Contains(reply, "New 4x6 game") || !strings.Contains(reply, "🔴0") {
+		t.Fatalf("expected a game, got %q", reply)
+	}
+	if reply := b.Handle("c1", "ann", "hint"); !strings.Contains(reply, "1296 codes") {
+		t.Errorf("expected a hint, got %q", reply)
+	}
+	if reply := b.Handle("c1", "ann", "guess 9999"); strings.Contains(reply, "guessed") {
+		t.Errorf("expected a bad guess to be refused, got %q", reply)
+	}
+
+	g, _ := b.Games.Get(b.channels["c1"])
+	secret := g.Secret()
+	wrong := mm.Code{(secret[0] + 1) % 6, secret[1], secret[2], secret[3]}
+	if reply := b.Handle("c1", "bob", "guess "+wrong.String()); !strings.HasPrefix(reply, "bob guessed "+pegs(wrong)+"  🟩🟩🟩⬜") {
+		t.Errorf("expected three black pins, got %q", reply)
+	}
+
+	// another channel has a game of its own
+	b.Handle("c2", "cat", "new 3x4")
+	if reply := b.Handle("c2", "cat", "board"); !strings.HasPrefix(reply, "3x4 game, 0 guesses") {
+		t.Errorf("expected c2's board, got %q", reply)
+	}
+	if reply := b.Handle("c2", "cat", "giveup"); !strings.HasPrefix(reply, "The secret was") {
+		t.Errorf("expected the secret, got %q", reply)
+	}
+	if reply := b.Handle("c2", "cat", "board"); !strings.Contains(reply, "no game") {
+		t.Errorf("expected the game over, got %q", reply)
+	}
+
+	reply := b.Handle("c1", "ann", "g "+strings.Join(strings.Split(secret.String(), ""), " "))
+	if !strings.Contains(reply, "ann solved it in 2 guesses!") || !strings.Contains(reply, "Mastermind 4x6 2/10") {
+		t.Errorf("expected a win, got %q", reply)
+	}
+	if reply := b.Handle("c1", "ann", "frobnicate"); reply != help {
+		t.Errorf("expected help, got %q", reply)
+	}
+}
@@ -0,0 +1,113 @@
+package bot
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// Discord answers the interactions Discord posts for the bot's slash
+// command.  Every request must be signed with the key whose public half is
+// PublicKey, the application's public key.
+//
+// The command's options are read as the words of a command in order, so it
+// may be registered with a single string option, eg /mm command:guess 0123,
+// or with subcommands, eg /mm guess code:0123.
+type Discord struct {
+	Bot       *Bot
+	PublicKey ed25519.PublicKey
+}
+
+// the interaction and response types used
+const (
+	discordPing        = 1
+	discordCommand     = 2
+	discordPong        = 1
+	discordChannelText = 4
+)
+
+type discordOption struct {
+	Name    string          `json:"name"`
+	Value   interface{}     `json:"value"`
+	Options []discordOption `json:"options"`
+}
+
+type discordUser struct {
+	Username string `json:"username"`
+}
+
+type interaction struct {
+	Type      int    `json:"type"`
+	ChannelID string `json:"channel_id"`
+	Data      struct {
+		Name    string          `json:"name"`
+		Options []discordOption `json:"options"`
+	} `json:"data"`
+	// Member is set in a server, and User in a direct message
+	Member *struct {
+		User discordUser `json:"user"`
+	} `json:"member"`
+	User *discordUser `json:"user"`
+}
+
+func (d *Discord) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 1<<16))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sig, err := hex.DecodeString(r.Header.Get("X-Signature-Ed25519"))
+	if err != nil || len(d.PublicKey) != ed25519.PublicKeySize ||
+		!ed25519.Verify(d.PublicKey, append([]byte(r.Header.Get("X-Signature-Timestamp")), body...), sig) {
+		http.Error(w, "bad signature", http.StatusUnauthorized)
+		return
+	}
+	var in interaction
+	if err := json.Unmarshal(body, &in); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch in.Type {
+	case discordPing:
+		json.NewEncoder(w).Encode(map[string]int{"type": discordPong})
+	case discordCommand:
+		user := ""
+		if in.Member != nil {
+			user = in.Member.User.Username
+		} else if in.User != nil {
+			user = in.User.Username
+		}
+		reply := d.Bot.Handle(in.ChannelID, user, strings.Join(words(in.Data.Options), " "))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"type": discordChannelText,
+			"data": map[string]string{"content": reply},
+		})
+	default:
+		http.Error(w, fmt.Sprintf("unsupported interaction type %d", in.Type), http.StatusBadRequest)
+	}
+}
+
+// words reads a command's options as a command: a subcommand's name
+// followed by its options, or an option's value
+func words(options []discordOption) []string {
+	w := []string{}
+	for _, o := range options {
+		if o.Value != nil {
+			w = append(w, fmt.Sprint(o.Value))
+			continue
+		}
+		w = append(w, o.Name)
+		w = append(w, words(o.Options)...)
+	}
+	return w
+}
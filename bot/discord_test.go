@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ianmcmahon/mastermind/server"
+)
+
+func TestDiscord(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &Discord{Bot: New(server.NewGameManager()), PublicKey: pub}
+
+	post := func(body string, key ed25519.PrivateKey) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/discord", strings.NewReader(body))
+		r.Header.Set("X-Signature-Timestamp", "1700000000")
+		r.Header.Set("X-Signature-Ed25519", hex.EncodeToString(ed25519.Sign(key, []byte("1700000000"+body))))
+		w := httptest.NewRecorder()
+		d.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := post(`{"type":1}`, priv); w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"type":1}` {
+		t.Errorf("expected a pong, got %d %s", w.Code, w.Body.String())
+	}
+
+	// a subcommand with an option
+	w := post(`{"type":2,"channel_id":"9","member":{"user":{"username":"ann"}},
+		"data":{"name":"mm","options":[{"name":"new","options":[{"name":"size","value":"3x4"}]}]}}`, priv)
+	var reply struct {
+		Type int
+		Data struct{ Content string }
+	}
+	json.NewDecoder(w.Body).Decode(&reply)
+	if reply.Type != 4 || !strings.HasPrefix(reply.Data.Content, "New 3x4 game") {
+		t.Errorf("expected a game, got %d %+v", w.Code, reply)
+	}
+	// a single string option, in a direct message
+	w = post(`{"type":2,"channel_id":"9","user":{"username":"bob"},
+		"data":{"name":"mm","options":[{"name":"command","value":"board"}]}}`, priv)
+	json.NewDecoder(w.Body).Decode(&reply)
+	if !strings.HasPrefix(reply.Data.Content, "3x4 game, 0 guesses") {
+		t.Errorf("expected the board, got %+v", reply)
+	}
+
+	_, other, _ := ed25519.GenerateKey(nil)
+	if w := post(`{"type":1}`, other); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected a bad signature to be refused, got %d", w.Code)
+	}
+}
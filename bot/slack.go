@@ -0,0 +1,68 @@
+package bot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Slack answers the slash command, eg /mm, that Slack posts to it.  Every
+// request must be signed with SigningSecret, the app's signing secret.
+type Slack struct {
+	Bot           *Bot
+	SigningSecret string
+}
+
+// requests older than this are taken to be replays
+const maxSkew = 5 * time.Minute
+
+func (s *Slack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 1<<16))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.verify(r.Header, body, time.Now()) {
+		http.Error(w, "bad signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reply := s.Bot.Handle(form.Get("channel_id"), form.Get("user_name"), form.Get("text"))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"response_type": "in_channel", "text": reply})
+}
+
+// verify checks Slack's signature of a request, made at now
+func (s *Slack) verify(h http.Header, body []byte, now time.Time) bool {
+	ts := h.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(sec, 0)); skew > maxSkew || skew < -maxSkew {
+		return false
+	}
+	return hmac.Equal([]byte(h.Get("X-Slack-Signature")), []byte(slackSignature(s.SigningSecret, ts, body)))
+}
+
+func slackSignature(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
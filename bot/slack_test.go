@@ -0,0 +1,46 @@
+package bot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ianmcmahon/mastermind/server"
+)
+
+func TestSlack(t *testing.T) {
+	s := &Slack{Bot: New(server.NewGameManager()), SigningSecret: "shh"}
+	form := url.Values{"channel_id": {"C1"}, "user_name": {"ann"}, "command": {"/mm"}, "text": {"new 3x4"}}.Encode()
+
+	post := func(ts time.Time, secret string) *httptest.ResponseRecorder {
+		stamp := strconv.FormatInt(ts.Unix(), 10)
+		r := httptest.NewRequest(http.MethodPost, "/slack", strings.NewReader(form))
+		r.Header.Set("X-Slack-Request-Timestamp", stamp)
+		r.Header.Set("X-Slack-Signature", slackSignature(secret, stamp, []byte(form)))
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		return w
+	}
+
+	w := post(time.Now(), "shh")
+	var reply struct {
+		ResponseType string `json:"response_type"`
+		Text         string
+	}
+	json.NewDecoder(w.Body).Decode(&reply)
+	if w.Code != http.StatusOK || reply.ResponseType != "in_channel" || !strings.HasPrefix(reply.Text, "New 3x4 game") {
+		t.Errorf("expected a game, got %d %+v", w.Code, reply)
+	}
+
+	if w := post(time.Now(), "wrong"); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected a bad signature to be refused, got %d", w.Code)
+	}
+	if w := post(time.Now().Add(-time.Hour), "shh"); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected an old request to be refused, got %d", w.Code)
+	}
+}
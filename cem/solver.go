@@ -0,0 +1,142 @@
+// Package cem solves mastermind with the cross-entropy method.  The solver
+// keeps an independent distribution over the colors of each position.  Each
+// iteration it samples codes from the distributions, rates them with the
+// genetic solver's fitness function, and moves the distributions towards the
+// color frequencies of the elite fraction, smoothed so they don't collapse
+// onto one code too soon.  The first consistent code sampled is guessed.  It
+// has next to no knobs and scales to giant boards, which makes it a strong
+// baseline.
+package cem
+
+import (
+	"sort"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/genetic"
+	"github.com/ianmcmahon/mastermind/internal/metaheuristic"
+)
+
+type sample struct {
+	code    mm.Code
+	fitness float64
+}
+
+type Solver struct {
+	metaheuristic.Base
+	// dist[position][color] is the probability of color at position
+	dist [][]float64
+
+	// Fitness rates codes; lower is fitter
+	Fitness genetic.FitnessFunction
+	// Samples is the number of codes drawn each iteration
+	Samples int
+	// EliteFraction is the fraction of the fittest samples the
+	// distributions move towards
+	EliteFraction float64
+	// Smoothing is how far the distributions move each iteration, from 0
+	// (not at all) to 1 (all the way to the elite's frequencies)
+	Smoothing float64
+	// MinProbability is the least probability any color keeps, so none is
+	// ever ruled out for good
+	MinProbability float64
+	// Iterations is the most iterations looking for a consistent code each
+	// move.  If none is found, the fittest code sampled is guessed.
+	Iterations int
+}
+
+func NewSolver(cm mm.Codemaker) *Solver {
+	return &Solver{
+		Base:           metaheuristic.NewBase(cm),
+		Fitness:        genetic.Berghman{A: 2, B: 2},
+		Samples:        200,
+		EliteFraction:  0.1,
+		Smoothing:      0.7,
+		MinProbability: 0.01,
+		Iterations:     500,
+	}
+}
+
+func (s *Solver) Solve() (mm.Code, error) {
+	return s.Search(s.search)
+}
+
+// search samples until a consistent code turns up, returning it, or the
+// fittest code sampled if the iterations run out.  The distributions start
+// uniform every move.
+func (s *Solver) search() mm.Code {
+	s.resetDist()
+
+	elite := int(float64(s.Samples) * s.EliteFraction)
+	if elite < 1 {
+		elite = 1
+	}
+
+	var best *sample
+	samples := make([]sample, s.Samples)
+	for i := 0; i < s.Iterations; i++ {
+		for j := range samples {
+			samples[j].code = s.draw()
+			if s.Consistent(samples[j].code) {
+				return samples[j].code
+			}
+			samples[j].fitness = s.Fitness.Evaluate(samples[j].code, s.History, s.Size)
+		}
+
+		sort.Slice(samples, func(i, j int) bool { return samples[i].fitness < samples[j].fitness })
+		if best == nil || samples[0].fitness < best.fitness {
+			best = &sample{code: samples[0].code, fitness: samples[0].fitness}
+		}
+		s.update(samples[:elite])
+	}
+	return best.code
+}
+
+func (s *Solver) resetDist() {
+	s.dist = make([][]float64, s.Size.Positions)
+	for p := range s.dist {
+		s.dist[p] = make([]float64, s.Size.Colors)
+		for c := range s.dist[p] {
+			s.dist[p][c] = 1 / float64(s.Size.Colors)
+		}
+	}
+}
+
+// draws a code from the distributions
+func (s *Solver) draw() mm.Code {
+	code := make(mm.Code, s.Size.Positions)
+	for p := range code {
+		roll := s.Rand.Float64()
+		code[p] = byte(s.Size.Colors - 1)
+		for c, prob := range s.dist[p] {
+			if roll -= prob; roll < 0 {
+				code[p] = byte(c)
+				break
+			}
+		}
+	}
+	return code
+}
+
+// moves the distributions Smoothing of the way to the color frequencies of
+// elite, then floors them at MinProbability
+func (s *Solver) update(elite []sample) {
+	for p := range s.dist {
+		freq := make([]float64, s.Size.Colors)
+		for _, e := range elite {
+			freq[e.code[p]] += 1 / float64(len(elite))
+		}
+
+		total := 0.0
+		for c := range s.dist[p] {
+			prob := (1-s.Smoothing)*s.dist[p][c] + s.Smoothing*freq[c]
+			if prob < s.MinProbability {
+				prob = s.MinProbability
+			}
+			s.dist[p][c] = prob
+			total += prob
+		}
+		for c := range s.dist[p] {
+			s.dist[p][c] /= total
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package cem
+
+import (
+	"math"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestUpdate(t *testing.T) {
+	solver := NewSolver(mm.NewCustomGame(4, 6))
+	solver.resetDist()
+
+	elite := []sample{{code: mm.Code{5, 4, 3, 2}}, {code: mm.Code{5, 4, 3, 1}}}
+	for i := 0; i < 20; i++ {
+		solver.update(elite)
+	}
+
+	for p, dist := range solver.dist {
+		total := 0.0
+		for c, prob := range dist {
+			total += prob
+			if prob < solver.MinProbability*0.9 {
+				t.Errorf("probability of %d at %d fell to %.4f", c, p, prob)
+			}
+		}
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("distribution at %d sums to %.4f", p, total)
+		}
+	}
+	if solver.dist[0][5] < 0.9 {
+		t.Errorf("every elite code starts with 5, but its probability is only %.2f", solver.dist[0][5])
+	}
+	if math.Abs(solver.dist[3][2]-solver.dist[3][1]) > 1e-9 {
+		t.Errorf("half the elite ends in 1 and half in 2, but they're at %.2f and %.2f", solver.dist[3][1], solver.dist[3][2])
+	}
+}
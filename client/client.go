@@ -0,0 +1,393 @@
+// Code generated by openapigen from the server's OpenAPI document. DO NOT EDIT.
+
+// Package client is a typed client of the REST API of package server.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the API at BaseURL, eg http://localhost:8080, in the
+// session with token Session, if it's set, with the API key APIKey, if
+// the server takes them
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+	Session string
+	APIKey  string
+}
+
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), HTTP: http.DefaultClient}
+}
+
+// Error is a failure the API reported
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, http.StatusText(e.Status), e.Message)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return err
+		}
+	}
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, &body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Session != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Session)
+	}
+	if c.APIKey != "" {
+		req.Header.Set("X-API-Key", c.APIKey)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return &Error{Status: resp.StatusCode, Message: e.Error}
+	}
+	// bodies which aren't JSON are returned as they are
+	if b, ok := out.(*[]byte); ok {
+		*b, err = ioutil.ReadAll(resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// websocketURL is the ws: or wss: URL of path
+func (c *Client) websocketURL(path string, query url.Values) string {
+	if c.Session != "" || c.APIKey != "" {
+		if query == nil {
+			query = url.Values{}
+		}
+	}
+	if c.Session != "" {
+		query.Set("session", c.Session)
+	}
+	if c.APIKey != "" {
+		query.Set("api_key", c.APIKey)
+	}
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	if strings.HasPrefix(u, "https:") {
+		return "wss:" + strings.TrimPrefix(u, "https:")
+	}
+	return "ws:" + strings.TrimPrefix(u, "http:")
+}
+
+type CreateGameRequest struct {
+	Size string `json:"size,omitempty"`
+}
+
+type GameList struct {
+	Games []State `json:"games"`
+}
+
+type GuessRequest struct {
+	Guess  string `json:"guess"`
+	Player string `json:"player,omitempty"`
+}
+
+type GuessResponse struct {
+	Player string `json:"player,omitempty"`
+	Guess  string `json:"guess"`
+	Black  int    `json:"black"`
+	White  int    `json:"white"`
+	Solved bool   `json:"solved"`
+}
+
+type HintRequest struct {
+	Size  string `json:"size,omitempty"`
+	Moves []Move `json:"moves"`
+}
+
+type HintResponse struct {
+	Hint      string `json:"hint"`
+	Remaining int    `json:"remaining"`
+}
+
+type JoinRequest struct {
+	Size   string `json:"size,omitempty"`
+	Player string `json:"player"`
+}
+
+type JoinResponse struct {
+	Match MatchState `json:"match"`
+	Seat  int        `json:"seat"`
+	Token string     `json:"token"`
+}
+
+type MatchGuessRequest struct {
+	Token string `json:"token"`
+	Guess string `json:"guess"`
+}
+
+type MatchState struct {
+	ID      string       `json:"id"`
+	Size    string       `json:"size"`
+	Players [2]string    `json:"players"`
+	Scores  [2]int       `json:"scores"`
+	Phase   string       `json:"phase"`
+	Round   int          `json:"round"`
+	Rounds  int          `json:"rounds"`
+	Guesses int          `json:"guesses"`
+	Maker   int          `json:"maker"`
+	Current RoundState   `json:"current"`
+	Played  []RoundState `json:"played"`
+}
+
+type Move struct {
+	Player string `json:"player,omitempty"`
+	Guess  string `json:"guess"`
+	Black  int    `json:"black"`
+	White  int    `json:"white"`
+}
+
+type Rating struct {
+	Name   string  `json:"name"`
+	Rating float64 `json:"rating"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+	Losses int     `json:"losses"`
+	Draws  int     `json:"draws"`
+}
+
+type RoundState struct {
+	Maker  int    `json:"maker"`
+	Moves  []Move `json:"moves"`
+	Solved bool   `json:"solved"`
+	Secret string `json:"secret,omitempty"`
+}
+
+type SecretRequest struct {
+	Token  string `json:"token"`
+	Secret string `json:"secret"`
+}
+
+type SessionResponse struct {
+	Token string `json:"token"`
+}
+
+type ShareResponse struct {
+	Text string `json:"text"`
+}
+
+type State struct {
+	ID     string `json:"id"`
+	Size   string `json:"size"`
+	Moves  []Move `json:"moves"`
+	Solved bool   `json:"solved"`
+	Secret string `json:"secret,omitempty"`
+	Daily  int    `json:"daily,omitempty"`
+}
+
+// AgentURL is the websocket URL of GET /agents: play matches as a program, with the agent protocol over a websocket
+func (c *Client) AgentURL() string {
+	return c.websocketURL("/agents", nil)
+}
+
+// CreateDaily is POST /daily: start a game of today's puzzle, which has the same secret for everyone
+func (c *Client) CreateDaily(ctx context.Context, req CreateGameRequest) (*State, error) {
+	var out State
+	if err := c.do(ctx, "POST", "/daily", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListGames is GET /games: the session's unsolved games
+func (c *Client) ListGames(ctx context.Context) (*GameList, error) {
+	var out GameList
+	if err := c.do(ctx, "GET", "/games", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateGame is POST /games: start a game with a random secret
+func (c *Client) CreateGame(ctx context.Context, req CreateGameRequest) (*State, error) {
+	var out State
+	if err := c.do(ctx, "POST", "/games", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetGame is GET /games/{id}: a game's state; the secret is shown once it's solved
+func (c *Client) GetGame(ctx context.Context, id string) (*State, error) {
+	var out State
+	if err := c.do(ctx, "GET", "/games/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BoardPNG is GET /games/{id}/board.png: a picture of the board, with the secret below once it's solved
+func (c *Client) BoardPNG(ctx context.Context, id string) ([]byte, error) {
+	var out []byte
+	if err := c.do(ctx, "GET", "/games/"+url.PathEscape(id)+"/board.png", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BoardSVG is GET /games/{id}/board.svg: a picture of the board, with the secret below once it's solved
+func (c *Client) BoardSVG(ctx context.Context, id string) ([]byte, error) {
+	var out []byte
+	if err := c.do(ctx, "GET", "/games/"+url.PathEscape(id)+"/board.svg", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Guess is POST /games/{id}/guesses: score a guess
+func (c *Client) Guess(ctx context.Context, id string, req GuessRequest) (*GuessResponse, error) {
+	var out GuessResponse
+	if err := c.do(ctx, "POST", "/games/"+url.PathEscape(id)+"/guesses", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Hint is GET /games/{id}/hint: the solver's choice of next guess
+func (c *Client) Hint(ctx context.Context, id string) (*HintResponse, error) {
+	var out HintResponse
+	if err := c.do(ctx, "GET", "/games/"+url.PathEscape(id)+"/hint", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// LiveGameURL is the websocket URL of GET /games/{id}/live: play a game over a websocket, named by the player query parameter
+func (c *Client) LiveGameURL(id string, player string) string {
+	query := url.Values{}
+	if player != "" {
+		query.Set("player", player)
+	}
+	return c.websocketURL("/games/"+url.PathEscape(id)+"/live", query)
+}
+
+// Share is GET /games/{id}/share: the game written up for sharing, with a row of emoji pins for each move
+func (c *Client) Share(ctx context.Context, id string) (*ShareResponse, error) {
+	var out ShareResponse
+	if err := c.do(ctx, "GET", "/games/"+url.PathEscape(id)+"/share", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StatelessHint is POST /hint: the solver's choice of next guess for a game played anywhere, given its moves so far
+func (c *Client) StatelessHint(ctx context.Context, req HintRequest) (*HintResponse, error) {
+	var out HintResponse
+	if err := c.do(ctx, "POST", "/hint", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// JoinMatch is POST /matches: join the lobby, starting a match or taking the second seat in one
+func (c *Client) JoinMatch(ctx context.Context, req JoinRequest) (*JoinResponse, error) {
+	var out JoinResponse
+	if err := c.do(ctx, "POST", "/matches", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMatch is GET /matches/{id}: a match's state
+func (c *Client) GetMatch(ctx context.Context, id string) (*MatchState, error) {
+	var out MatchState
+	if err := c.do(ctx, "GET", "/matches/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MatchGuess is POST /matches/{id}/guesses: score a guess, as this round's codebreaker
+func (c *Client) MatchGuess(ctx context.Context, id string, req MatchGuessRequest) (*Move, error) {
+	var out Move
+	if err := c.do(ctx, "POST", "/matches/"+url.PathEscape(id)+"/guesses", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// LiveMatchURL is the websocket URL of GET /matches/{id}/live: follow and play a match over a websocket, with the player's token
+func (c *Client) LiveMatchURL(id string, token string) string {
+	query := url.Values{}
+	if token != "" {
+		query.Set("token", token)
+	}
+	return c.websocketURL("/matches/"+url.PathEscape(id)+"/live", query)
+}
+
+// SetSecret is POST /matches/{id}/secret: set the secret, as this round's codemaker
+func (c *Client) SetSecret(ctx context.Context, id string, req SecretRequest) (*MatchState, error) {
+	var out MatchState
+	if err := c.do(ctx, "POST", "/matches/"+url.PathEscape(id)+"/secret", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Leaderboard is GET /ratings: the highest rated players, best first
+func (c *Client) Leaderboard(ctx context.Context, limit int) ([]Rating, error) {
+	var out []Rating
+	query := url.Values{}
+	if limit != 0 {
+		query.Set("limit", fmt.Sprint(limit))
+	}
+	if err := c.do(ctx, "GET", "/ratings", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetRating is GET /ratings/{name}: a player's rating
+func (c *Client) GetRating(ctx context.Context, name string) (*Rating, error) {
+	var out Rating
+	if err := c.do(ctx, "GET", "/ratings/"+url.PathEscape(name), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateSession is POST /sessions: start a session, whose games only it may play
+func (c *Client) CreateSession(ctx context.Context) (*SessionResponse, error) {
+	var out SessionResponse
+	if err := c.do(ctx, "POST", "/sessions", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
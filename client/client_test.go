@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ianmcmahon/mastermind/server"
+)
+
+func TestClient(t *testing.T) {
+	srv := httptest.NewServer(server.NewServer())
+	defer srv.Close()
+	c := New(srv.URL + "/")
+	ctx := context.Background()
+
+	st, err := c.CreateGame(ctx, CreateGameRequest{Size: "3x4"})
+	if err != nil || st.Size != "3x4" {
+		t.Fatalf("expected a 3x4 game, got %+v %v", st, err)
+	}
+	hint, err := c.Hint(ctx, st.ID)
+	if err != nil || hint.Remaining != 64 {
+		t.Fatalf("expected an opening hint, got %+v %v", hint, err)
+	}
+	m, err := c.Guess(ctx, st.ID, GuessRequest{Guess: hint.Hint, Player: "ann"})
+	if err != nil || m.Guess != hint.Hint || m.Player != "ann" {
+		t.Fatalf("expected the guess scored, got %+v %v", m, err)
+	}
+	if st, err = c.GetGame(ctx, st.ID); err != nil || len(st.Moves) != 1 {
+		t.Errorf("expected a move, got %+v %v", st, err)
+	}
+
+	_, err = c.GetGame(ctx, "nope")
+	if e, ok := err.(*Error); !ok || e.Status != http.StatusNotFound {
+		t.Errorf("expected a 404, got %v", err)
+	}
+	if _, err = c.Guess(ctx, st.ID, GuessRequest{Guess: "99"}); err == nil {
+		t.Error("expected a bad guess to fail")
+	}
+
+	j, err := c.JoinMatch(ctx, JoinRequest{Player: "ann"})
+	if err != nil || j.Token == "" || j.Match.Phase != "waiting" {
+		t.Fatalf("expected a waiting match, got %+v %v", j, err)
+	}
+	if ms, err := c.GetMatch(ctx, j.Match.ID); err != nil || ms.Players[0] != "ann" {
+		t.Errorf("expected ann's match, got %+v %v", ms, err)
+	}
+	if board, err := c.Leaderboard(ctx, 5); err != nil || len(board) != 0 {
+		t.Errorf("expected no ratings yet, got %+v %v", board, err)
+	}
+	if r, err := c.GetRating(ctx, "ann"); err != nil || r.Rating != 1500 {
+		t.Errorf("expected an initial rating, got %+v %v", r, err)
+	}
+
+	if u := c.LiveGameURL(st.ID, "ann"); !strings.HasPrefix(u, "ws://") || !strings.HasSuffix(u, "/live?player=ann") {
+		t.Errorf("bad websocket URL %s", u)
+	}
+}
@@ -0,0 +1,150 @@
+// Command gabench runs the genetic solver across a matrix of board sizes
+// and configurations, writing average moves, failures and wall time for
+// each as CSV.
+//
+//	gabench -sizes 4x6,5x8 -configs default,islands -games 20 > ga.csv
+//
+// Given values for any of -populations, -generations, -mutations,
+// -permutations and -inversions, it sweeps every combination of them
+// instead, or -random of them drawn at random, leaving the parameters
+// without values at their defaults.  Every configuration plays the same
+// secrets, and they're listed best first on each board: by failures,
+// then average moves, then wall time.
+//
+//	gabench -sizes 5x8 -populations 50,150,300 -generations 50,100 -mutations 0.01,0.03,0.1 > sweep.csv
+//
+// -cpuprofile and -memprofile write profiles of the run for go tool pprof.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/genetic"
+	"github.com/ianmcmahon/mastermind/internal/profile"
+)
+
+func main() {
+	var names []string
+	for _, c := range genetic.BenchConfigs {
+		names = append(names, c.Name)
+	}
+	var sizeNames []string
+	for _, s := range genetic.BenchSizes {
+		sizeNames = append(sizeNames, s.String())
+	}
+
+	sizesFlag := flag.String("sizes", strings.Join(sizeNames, ","), "comma separated board sizes")
+	configsFlag := flag.String("configs", strings.Join(names, ","), "comma separated configurations")
+	games := flag.Int("games", 10, "games per size and configuration")
+	seed := flag.Int64("seed", 1, "seed for the secrets and solvers")
+	populations := flag.String("populations", "", "comma separated population sizes to sweep")
+	generations := flag.String("generations", "", "comma separated generation caps to sweep")
+	mutations := flag.String("mutations", "", "comma separated mutation rates to sweep")
+	permutations := flag.String("permutations", "", "comma separated permutation rates to sweep")
+	inversions := flag.String("inversions", "", "comma separated inversion rates to sweep")
+	random := flag.Int("random", 0, "configurations of the sweep to draw at random, rather than trying them all")
+	var prof profile.Profile
+	prof.AddFlags(flag.CommandLine)
+	flag.Parse()
+
+	sizes := []mm.GameSize{}
+	for _, s := range strings.Split(*sizesFlag, ",") {
+		size, err := mm.ParseGameSize(s)
+		if err != nil {
+			fail(err)
+		}
+		sizes = append(sizes, size)
+	}
+
+	var grid genetic.Grid
+	var err error
+	if grid.PopulationSizes, err = ints(*populations); err != nil {
+		fail(err)
+	}
+	if grid.MaxGenerations, err = ints(*generations); err != nil {
+		fail(err)
+	}
+	if grid.MutationRates, err = floats(*mutations); err != nil {
+		fail(err)
+	}
+	if grid.PermutationRates, err = floats(*permutations); err != nil {
+		fail(err)
+	}
+	if grid.InversionRates, err = floats(*inversions); err != nil {
+		fail(err)
+	}
+	sweep := *populations != "" || *generations != "" || *mutations != "" || *permutations != "" || *inversions != ""
+
+	configs := []genetic.BenchConfig{}
+	switch {
+	case sweep && *random > 0:
+		configs = grid.Sample(*random, *seed)
+	case sweep:
+		configs = grid.Configs()
+	}
+	for _, name := range strings.Split(*configsFlag, ",") {
+		if sweep {
+			break
+		}
+		found := false
+		for _, c := range genetic.BenchConfigs {
+			if c.Name == name {
+				configs = append(configs, c)
+				found = true
+			}
+		}
+		if !found {
+			fail(fmt.Errorf("unknown configuration %q, expected one of %s", name, strings.Join(names, ", ")))
+		}
+	}
+
+	if err := prof.Start(); err != nil {
+		fail(err)
+	}
+	results := genetic.Benchmark(sizes, configs, *games, *seed)
+	if err := prof.Stop(); err != nil {
+		fail(err)
+	}
+	if sweep {
+		results = genetic.RankResults(results)
+	}
+	if err := genetic.WriteCSV(os.Stdout, results); err != nil {
+		fail(err)
+	}
+}
+
+// ints reads a comma separated list of ints, none if s is empty
+func ints(s string) ([]int, error) {
+	var vs []int
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' }) {
+		v, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %q", f)
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+// floats reads a comma separated list of floats, none if s is empty
+func floats(s string) ([]float64, error) {
+	var vs []float64
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' }) {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %q", f)
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "gabench:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/websocket"
+	"github.com/ianmcmahon/mastermind/server"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+func agentCommand(args []string) error {
+	fs := flag.NewFlagSet("agent", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:7070", "server's agent address, host:port for TCP or a ws:// URL")
+	name := fs.String("name", "knuth", "name to play and be rated under")
+	size := fs.String("size", "4x6", "board size")
+	matches := fs.Int("matches", 1, "matches to play, one after another")
+	key := fs.String("key", "", "API key to play with, if the server takes keys")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	s, err := mm.ParseGameSize(*size)
+	if err != nil {
+		return err
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < *matches; i++ {
+		conn, err := dialAgent(*addr, *key)
+		if err != nil {
+			return err
+		}
+		st, seat, err := playAgent(conn, *name, *key, s, rng)
+		conn.Close()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("match %s against %s: %d to %d\n", st.ID, st.Players[1-seat], st.Scores[seat], st.Scores[1-seat])
+	}
+	return nil
+}
+
+type agentConn interface {
+	server.AgentConn
+	Close() error
+}
+
+// tcpConn is a connection of a JSON object per line
+type tcpConn struct {
+	net.Conn
+	dec *json.Decoder
+	enc *json.Encoder
+}
+
+func (c *tcpConn) ReadJSON(v interface{}) error  { return c.dec.Decode(v) }
+func (c *tcpConn) WriteJSON(v interface{}) error { return c.enc.Encode(v) }
+
+// dialAgent connects to the server's agents at addr.  Over a websocket key
+// is sent with the request, as the API takes it; over TCP it's the hello's.
+func dialAgent(addr, key string) (agentConn, error) {
+	if strings.HasPrefix(addr, "ws://") || strings.HasPrefix(addr, "wss://") {
+		if key != "" {
+			u, err := url.Parse(addr)
+			if err != nil {
+				return nil, err
+			}
+			q := u.Query()
+			q.Set("api_key", key)
+			u.RawQuery = q.Encode()
+			addr = u.String()
+		}
+		return websocket.Dial(addr)
+	}
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &tcpConn{Conn: conn, dec: json.NewDecoder(conn), enc: json.NewEncoder(conn)}, nil
+}
+
+// playAgent plays a match with the agent protocol as the built in solver,
+// making random secrets, and returns the finished match and its seat
+func playAgent(conn server.AgentConn, name, key string, size mm.GameSize, rng *rand.Rand) (server.MatchState, int, error) {
+	if err := conn.WriteJSON(server.AgentMessage{Type: "hello", Name: name, Size: size.String(), Key: key}); err != nil {
+		return server.MatchState{}, 0, err
+	}
+	game := &solver.Solver{Game: mm.NewCustomGame(size.Positions, size.Colors)}
+	for {
+		var msg server.AgentMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return server.MatchState{}, 0, err
+		}
+		var reply server.AgentMessage
+		switch msg.Type {
+		case "make":
+			secret := size.CodeAt(rng.Intn(size.NumCodes()))
+			reply = server.AgentMessage{Type: "secret", Secret: secret.String()}
+		case "break":
+			history, err := agentHistory(msg.Match.Current.Moves, size)
+			if err != nil {
+				return server.MatchState{}, 0, err
+			}
+			guess, err := game.Step(history)
+			if err != nil {
+				return server.MatchState{}, 0, err
+			}
+			reply = server.AgentMessage{Type: "guess", Guess: guess.String()}
+		case "over":
+			return *msg.Match, msg.Seat, nil
+		case "error":
+			return server.MatchState{}, 0, fmt.Errorf("server: %s", msg.Message)
+		default:
+			continue
+		}
+		if err := conn.WriteJSON(reply); err != nil {
+			return server.MatchState{}, 0, err
+		}
+	}
+}
+
+// agentHistory reads the moves of a round as the solver's history
+func agentHistory(moves []server.Move, size mm.GameSize) (mm.History, error) {
+	var h mm.History
+	for _, m := range moves {
+		guess, err := parseCode(m.Guess, size)
+		if err != nil {
+			return nil, err
+		}
+		h = append(h, mm.Move{Guess: guess, Result: mm.Result{Correct: m.Black, HalfCorrect: m.White}})
+	}
+	return h, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"math/rand"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/server"
+)
+
+func TestPlayAgent(t *testing.T) {
+	s := server.NewServer()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	go s.ServeAgents(lis)
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	type result struct {
+		st   server.MatchState
+		seat int
+		err  error
+	}
+	results := make(chan result, 2)
+	// one over TCP, the other over a websocket
+	for i, addr := range []string{lis.Addr().String(), "ws" + strings.TrimPrefix(srv.URL, "http") + "/agents"} {
+		conn, err := dialAgent(addr, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		go func(name string, seed int64) {
+			st, seat, err := playAgent(conn, name, "", mm.GameSize{Positions: 4, Colors: 6}, rand.New(rand.NewSource(seed)))
+			results <- result{st, seat, err}
+		}([]string{"tcp", "ws"}[i], int64(i))
+	}
+
+	for i := 0; i < 2; i++ {
+		r := <-results
+		if r.err != nil {
+			t.Fatal(r.err)
+		}
+		if r.st.Phase != server.Finished || len(r.st.Played) != 2 {
+			t.Fatalf("expected a finished match, got %+v", r.st)
+		}
+		for _, round := range r.st.Played {
+			if !round.Solved || len(round.Moves) > 5 {
+				t.Errorf("expected the solver to break every secret in 5, got %+v", round)
+			}
+		}
+	}
+}
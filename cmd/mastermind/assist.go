@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+func assistCommand(args []string) error {
+	fs := flag.NewFlagSet("assist", flag.ContinueOnError)
+	size := fs.String("size", "4x6", "board size of the physical game")
+	plain := fs.Bool("plain", false, "don't draw in color")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	s, err := mm.ParseGameSize(*size)
+	if err != nil {
+		return err
+	}
+
+	a := newAssistant(s, bufio.NewScanner(os.Stdin), os.Stdout, drawer{plain: *plain})
+	return ignoreEOF(a.run())
+}
+
+// assistant suggests guesses for a game played on a physical board, with
+// the codemaker's answers typed in
+type assistant struct {
+	in     *bufio.Scanner
+	out    io.Writer
+	draw   drawer
+	size   mm.GameSize
+	solver *solver.Solver
+}
+
+func newAssistant(size mm.GameSize, in *bufio.Scanner, out io.Writer, draw drawer) *assistant {
+	return &assistant{
+		in:     in,
+		out:    out,
+		draw:   draw,
+		size:   size,
+		solver: solver.NewSolver(mm.NewCustomGame(size.Positions, size.Colors)),
+	}
+}
+
+// next suggests the guess to make after history
+func (a *assistant) next(history mm.History) (mm.Code, error) {
+	if len(history) == 0 {
+		return a.solver.Opener(), nil
+	}
+	return a.solver.Step(history)
+}
+
+func (a *assistant) run() error {
+	fmt.Fprintf(a.out, "Assisting a %v game.  Colors: %s\n", a.size, a.draw.legend(a.size.Colors))
+	fmt.Fprintln(a.out, "After each guess, enter the black and white pegs the codemaker gives, eg 2 1.")
+
+	history := mm.History{}
+	for {
+		guess, err := a.next(history)
+		if err != nil {
+			// the answers can't all be right
+			if err := a.correct(history); err != nil {
+				return err
+			}
+			continue
+		}
+
+		left := len(a.solver.Possible(history))
+		fmt.Fprintf(a.out, "\nMove %d, %d codes possible.  Guess %s  (%s)\n", len(history)+1, left, a.draw.code(guess), guess)
+
+		result, err := a.readResult()
+		if err != nil {
+			return err
+		}
+		if result.Correct == a.size.Positions {
+			fmt.Fprintf(a.out, "\nSolved in %d moves.\n", len(history)+1)
+			return nil
+		}
+		history = append(history, mm.Move{Guess: guess, Result: result})
+	}
+}
+
+// correct has the codemaker fix the answers in history, which no code
+// gives.  If one answer explains it, it's the one asked for again; if it
+// could be any of a few, the codemaker is asked which; and if more than one
+// must be wrong, every answer is checked.
+func (a *assistant) correct(history mm.History) error {
+	fmt.Fprint(a.out, "\nNo code gives all those answers, so ")
+	suspects := a.solver.Suspects(history)
+	if len(suspects) == 0 {
+		fmt.Fprintln(a.out, "more than one was wrong.  Check each of them, or press enter to keep it.")
+		for i := range history {
+			fmt.Fprintf(a.out, "Move %d, %s  (%s), was given %s.  ", i+1, a.draw.code(history[i].Guess), history[i].Guess, history[i].Result)
+			r, err := a.readResultOr(&history[i].Result)
+			if err != nil {
+				return err
+			}
+			history[i].Result = r
+		}
+		return nil
+	}
+
+	s := suspects[0]
+	if len(suspects) == 1 {
+		fmt.Fprintln(a.out, "one was wrong, and it must be this one:")
+		a.showSuspect(s, history)
+	} else {
+		fmt.Fprintln(a.out, "one was wrong, and it's one of these:")
+		for _, s := range suspects {
+			a.showSuspect(s, history)
+		}
+		n, err := a.readMove(suspects)
+		if err != nil {
+			return err
+		}
+		for _, s = range suspects {
+			if s.Move == n {
+				break
+			}
+		}
+	}
+	fmt.Fprintf(a.out, "What should move %d have been given?  ", s.Move+1)
+	r, err := a.readResultOr(nil)
+	if err != nil {
+		return err
+	}
+	history[s.Move].Result = r
+	return nil
+}
+
+func (a *assistant) showSuspect(s solver.Suspect, history mm.History) {
+	m := history[s.Move]
+	var could []string
+	for _, r := range s.Results {
+		could = append(could, r.String())
+	}
+	fmt.Fprintf(a.out, "  move %d, %s  (%s), was given %s but could only have been %s\n",
+		s.Move+1, a.draw.code(m.Guess), m.Guess, m.Result, strings.Join(could, " or "))
+}
+
+// readMove reads the number of one of the suspects' moves, until it is one,
+// returning its index
+func (a *assistant) readMove(suspects []solver.Suspect) (int, error) {
+	for {
+		line, err := a.readLine("Which move was it? ")
+		if err != nil {
+			return 0, err
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(line))
+		for _, s := range suspects {
+			if err == nil && s.Move == n-1 {
+				return s.Move, nil
+			}
+		}
+		fmt.Fprintln(a.out, "enter the number of one of the moves above")
+	}
+}
+
+// readResult reads black and white peg counts, until they're valid
+func (a *assistant) readResult() (mm.Result, error) {
+	return a.readResultOr(nil)
+}
+
+// readResultOr is readResult, with keep the answer to an empty line if
+// it's given
+func (a *assistant) readResultOr(keep *mm.Result) (mm.Result, error) {
+	for {
+		line, err := a.readLine("Black and white: ")
+		if err != nil {
+			return mm.Result{}, err
+		}
+		if keep != nil && strings.TrimSpace(line) == "" {
+			return *keep, nil
+		}
+		r, err := parseResult(line, a.size.Positions)
+		if err == nil {
+			return r, nil
+		}
+		fmt.Fprintln(a.out, err)
+	}
+}
+
+func (a *assistant) readLine(prompt string) (string, error) {
+	fmt.Fprint(a.out, prompt)
+	if !a.in.Scan() {
+		if err := a.in.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return a.in.Text(), nil
+}
+
+// parseResult reads peg counts as two numbers, eg "2 1", "2,1" or "2-1"
+func parseResult(s string, positions int) (mm.Result, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' || r == '-' })
+	if len(fields) != 2 {
+		return mm.Result{}, fmt.Errorf("enter the black and white pegs as two numbers, eg 2 1")
+	}
+	black, err1 := strconv.Atoi(fields[0])
+	white, err2 := strconv.Atoi(fields[1])
+	if err1 != nil || err2 != nil || black < 0 || white < 0 {
+		return mm.Result{}, fmt.Errorf("enter the black and white pegs as two numbers, eg 2 1")
+	}
+	if black+white > positions || black == positions-1 && white == 1 {
+		return mm.Result{}, fmt.Errorf("%d black and %d white can't happen with %d positions", black, white, positions)
+	}
+	return mm.Result{Correct: black, HalfCorrect: white}, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestAssist(t *testing.T) {
+	size := mm.GameSize{Positions: 4, Colors: 6}
+	secret := mm.Code{3, 1, 5, 2}
+	a := newAssistant(size, nil, nil, drawer{plain: true})
+
+	// answer honestly, with a typo along the way
+	answers := []string{"two and one"}
+	history := mm.History{}
+	for {
+		guess, err := a.next(history)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, _ := mm.CheckCode(guess, secret, size.Colors)
+		answers = append(answers, r.String())
+		if r.Correct == size.Positions {
+			break
+		}
+		history = append(history, mm.Move{Guess: guess, Result: r})
+	}
+
+	out := &bytes.Buffer{}
+	a = newAssistant(size, bufio.NewScanner(strings.NewReader(strings.Join(answers, "\n"))), out, drawer{plain: true})
+	if err := a.run(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Solved in") || len(answers)-1 > 5 {
+		t.Errorf("expected a solve in at most 5 moves:\n%s", out)
+	}
+	if !strings.Contains(out.String(), "enter the black and white pegs") {
+		t.Errorf("expected the typo to be rejected:\n%s", out)
+	}
+}
+
+func TestAssistInconsistent(t *testing.T) {
+	out := &bytes.Buffer{}
+	// no code shares nothing with every guess
+	in := strings.Repeat("0 0\n", 6)
+	a := newAssistant(mm.GameSize{Positions: 4, Colors: 6}, bufio.NewScanner(strings.NewReader(in)), out, drawer{plain: true})
+	if err := ignoreEOF(a.run()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No code gives all those answers") {
+		t.Errorf("expected the inconsistency to be caught:\n%s", out)
+	}
+}
+
+func TestAssistLie(t *testing.T) {
+	size := mm.GameSize{Positions: 4, Colors: 6}
+	secret := mm.Code{3, 1, 5, 2}
+	a := newAssistant(size, nil, nil, drawer{plain: true})
+
+	// the second answer is wrong, until the assistant finds it out
+	var answers []string
+	history := mm.History{}
+	lied, caught := false, false
+	for {
+		guess, err := a.next(history)
+		if err != nil {
+			suspects := a.solver.Suspects(history)
+			if len(suspects) > 1 {
+				answers = append(answers, "2")
+			}
+			r, _ := mm.CheckCode(history[1].Guess, secret, size.Colors)
+			answers = append(answers, r.String())
+			history[1].Result = r
+			caught = true
+			continue
+		}
+		r, _ := mm.CheckCode(guess, secret, size.Colors)
+		if len(history) == 1 && !lied {
+			if r.HalfCorrect > 0 {
+				r.HalfCorrect--
+			} else {
+				r.HalfCorrect++
+			}
+			lied = true
+		}
+		answers = append(answers, r.String())
+		if r.Correct == size.Positions {
+			break
+		}
+		history = append(history, mm.Move{Guess: guess, Result: r})
+	}
+	if !caught {
+		t.Fatal("expected the wrong answer to be caught")
+	}
+
+	out := &bytes.Buffer{}
+	a = newAssistant(size, bufio.NewScanner(strings.NewReader(strings.Join(answers, "\n"))), out, drawer{plain: true})
+	if err := a.run(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"No code gives all those answers", "move 2, ", "What should move 2 have been given?", "Solved in"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, out)
+		}
+	}
+}
+
+func TestParseResult(t *testing.T) {
+	for in, expected := range map[string]mm.Result{"2 1": {2, 1}, "0,0": {0, 0}, "1-3": {1, 3}, " 4 0 ": {4, 0}} {
+		if r, err := parseResult(in, 4); err != nil || r != expected {
+			t.Errorf("%q parsed as %v (%v), expected %v", in, r, err, expected)
+		}
+	}
+	for _, in := range []string{"", "2", "2 1 1", "a b", "3 2", "3 1", "1 x"} {
+		if _, err := parseResult(in, 4); err == nil {
+			t.Errorf("parsed invalid result %q", in)
+		}
+	}
+}
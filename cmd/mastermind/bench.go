@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/genetic"
+	"github.com/ianmcmahon/mastermind/human"
+	"github.com/ianmcmahon/mastermind/internal/profile"
+	"github.com/ianmcmahon/mastermind/solver"
+	"github.com/ianmcmahon/mastermind/storage"
+)
+
+// benchSolvers makes each solver the bench command knows, seeded with seed
+// and rating guesses with h if it's one which does
+var benchSolvers = map[string]func(seed int64, h solver.Heuristic) mm.SolverFunc{
+	"knuth": func(seed int64, h solver.Heuristic) mm.SolverFunc {
+		return func(cm mm.Codemaker) mm.Solver {
+			s := solver.NewCodemakerSolver(cm)
+			s.Heuristic = h
+			return s
+		}
+	},
+	"genetic": func(seed int64, h solver.Heuristic) mm.SolverFunc {
+		return func(cm mm.Codemaker) mm.Solver {
+			return genetic.NewCodemakerSolver(cm, genetic.WithSeed(seed))
+		}
+	},
+	"human-same":        humanSolver(human.Same),
+	"human-elimination": humanSolver(human.Elimination),
+	"human-random":      humanSolver(human.Random),
+}
+
+// humanSolver makes solvers which play as people do, by strategy
+func humanSolver(strategy human.Strategy) func(seed int64, h solver.Heuristic) mm.SolverFunc {
+	return func(seed int64, h solver.Heuristic) mm.SolverFunc {
+		return func(cm mm.Codemaker) mm.Solver {
+			s := human.NewSolver(cm, strategy)
+			s.Seed(seed)
+			return s
+		}
+	}
+}
+
+// benchHeuristics are the solvers which take a heuristic
+var benchHeuristics = map[string]bool{"knuth": true}
+
+// benchGame is the outcome of one secret
+type benchGame struct {
+	Secret  string  `json:"secret"`
+	Moves   int     `json:"moves"`
+	Seconds float64 `json:"seconds"`
+	Solved  bool    `json:"solved"`
+	Error   string  `json:"error,omitempty"`
+	// Guesses are the moves made, in order
+	Guesses []string `json:"guesses"`
+	// Stats are the work the solver did on each move, for solvers which
+	// count it
+	Stats []benchMove `json:"stats,omitempty"`
+}
+
+// benchMove is the work a solver did on a move, as solver.MoveStats
+type benchMove struct {
+	Checks     int64 `json:"checks"`
+	Partitions int64 `json:"partitions"`
+	Possible   int   `json:"possible"`
+	Pruned     int   `json:"pruned"`
+	Peak       int   `json:"peak"`
+}
+
+// instrumented is a solver which counts the work of each move
+type instrumented interface {
+	Stats() solver.Stats
+}
+
+// benchReport is a bench run, with the summary over the games solved
+type benchReport struct {
+	Solver      string  `json:"solver"`
+	Heuristic   string  `json:"heuristic,omitempty"`
+	Size        string  `json:"size"`
+	Games       int     `json:"games"`
+	Failures    int     `json:"failures"`
+	MeanMoves   float64 `json:"mean_moves"`
+	MaxMoves    int     `json:"max_moves"`
+	WorstSecret string  `json:"worst_secret,omitempty"`
+	Seconds     float64 `json:"seconds"`
+	// Checks and Partitions are the work of every game, and PeakCodes the
+	// most codes in play on any move, for solvers which count them
+	Checks     int64       `json:"checks,omitempty"`
+	Partitions int64       `json:"partitions,omitempty"`
+	PeakCodes  int         `json:"peak_codes,omitempty"`
+	Results    []benchGame `json:"results"`
+
+	size    mm.GameSize
+	results []mm.SecretResult
+}
+
+func benchCommand(args []string) (err error) {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	name := fs.String("solver", "knuth", "solver to run: knuth, genetic, or human-same, human-elimination or human-random to play as people do")
+	heuristic := fs.String("heuristic", "minmax", "how knuth rates guesses: minmax, expected or entropy")
+	size := fs.String("size", "4x6", "board size")
+	all := fs.Bool("all-secrets", false, "play every secret on the board")
+	games := fs.Int("games", 100, "random secrets to play, without -all-secrets")
+	seed := fs.Int64("seed", 1, "seed for the secrets and the solver")
+	format := fs.String("format", "json", "output format: json or csv")
+	output := fs.String("o", "", "file to write to, instead of stdout")
+	db := fs.String("db", "", "database to record the runs in too: a SQLite file, redis://host or memory")
+	var prof profile.Profile
+	prof.AddFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := prof.Start(); err != nil {
+		return err
+	}
+	defer func() {
+		if stopErr := prof.Stop(); err == nil {
+			err = stopErr
+		}
+	}()
+	newSolver, ok := benchSolvers[*name]
+	if !ok {
+		return fmt.Errorf("unknown solver %q", *name)
+	}
+	h, err := parseHeuristic(*heuristic)
+	if err != nil {
+		return err
+	}
+	if !benchHeuristics[*name] {
+		*heuristic = ""
+	}
+	if *format != "json" && *format != "csv" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	s, err := mm.ParseGameSize(*size)
+	if err != nil {
+		return err
+	}
+
+	var secrets mm.CodeSlice
+	if *all {
+		secrets = s.AllCodes()
+	} else {
+		rng := rand.New(rand.NewSource(*seed))
+		for i := 0; i < *games; i++ {
+			secrets = append(secrets, s.CodeAt(rng.Intn(s.NumCodes())))
+		}
+	}
+
+	report := bench(*name, *heuristic, func(seed int64) mm.SolverFunc { return newSolver(seed, h) }, s, secrets, *seed)
+	if *db != "" {
+		if err := report.save(*db); err != nil {
+			return err
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if *format == "csv" {
+		return report.writeCSV(w)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// bench plays every secret with the solvers newSolver makes, each seeded
+// differently
+func bench(name, heuristic string, newSolver func(seed int64) mm.SolverFunc, size mm.GameSize, secrets mm.CodeSlice, seed int64) *benchReport {
+	report := &benchReport{Solver: name, Heuristic: heuristic, Size: size.String(), Games: len(secrets), Results: []benchGame{}, size: size}
+	moves := 0
+	start := time.Now()
+	for i, secret := range secrets {
+		// the solver is kept to ask for its stats after
+		var played mm.Solver
+		newPlayed := newSolver(seed + int64(i) + 1)
+		res := mm.Play(size, secret, func(cm mm.Codemaker) mm.Solver {
+			played = newPlayed(cm)
+			return played
+		})
+		report.results = append(report.results, res)
+		turns := len(res.Moves)
+		r := benchGame{Secret: secret.String(), Moves: turns, Seconds: res.Duration.Seconds(), Solved: res.Solved, Guesses: []string{}}
+		if res.Err != nil {
+			r.Error = res.Err.Error()
+		}
+		for _, m := range res.Moves {
+			r.Guesses = append(r.Guesses, m.Guess.String())
+		}
+		if s, ok := played.(instrumented); ok {
+			for _, m := range s.Stats().Moves {
+				r.Stats = append(r.Stats, benchMove{m.Checks, m.Partitions, m.Possible, m.Pruned, m.Peak})
+				report.Checks += m.Checks
+				report.Partitions += m.Partitions
+				if m.Peak > report.PeakCodes {
+					report.PeakCodes = m.Peak
+				}
+			}
+		}
+		report.Results = append(report.Results, r)
+
+		if !r.Solved {
+			report.Failures++
+			continue
+		}
+		moves += turns
+		if turns > report.MaxMoves {
+			report.MaxMoves = turns
+			report.WorstSecret = r.Secret
+		}
+	}
+	report.Seconds = time.Since(start).Seconds()
+	if won := report.Games - report.Failures; won > 0 {
+		report.MeanMoves = float64(moves) / float64(won)
+	}
+	return report
+}
+
+// save records every game as a solver run
+func (r *benchReport) save(path string) error {
+	store, err := storage.Open(path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	at := time.Now()
+	for _, g := range r.results {
+		run := &storage.Run{
+			Solver:   r.Solver,
+			Config:   r.Heuristic,
+			Size:     r.size,
+			Secret:   g.Secret,
+			Moves:    len(g.Moves),
+			Duration: g.Duration,
+			Solved:   g.Solved,
+			At:       at,
+		}
+		if err := store.SaveRun(run); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes a row for each game, with a header row, as
+// mm.WriteResultsCSV does
+func (r *benchReport) writeCSV(w io.Writer) error {
+	return mm.WriteResultsCSV(w, r.Solver, r.Heuristic, r.size, r.results)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+func TestBench(t *testing.T) {
+	size := mm.GameSize{Positions: 4, Colors: 6}
+	secrets := size.AllCodes()[:20]
+	for name, newSolver := range benchSolvers {
+		play := func(seed int64) mm.SolverFunc { return newSolver(seed, solver.MinMax) }
+		report := bench(name, "minmax", play, size, secrets, 1)
+		if report.Games != 20 || len(report.Results) != 20 || report.Failures != 0 {
+			t.Errorf("%s: expected 20 games solved, got %+v", name, report)
+		}
+		if report.MaxMoves < 1 || report.MeanMoves < 1 || report.WorstSecret == "" {
+			t.Errorf("%s: expected a summary, got %+v", name, report)
+		}
+		if g := report.Results[0]; len(g.Guesses) != g.Moves || g.Guesses[g.Moves-1] != g.Secret {
+			t.Errorf("%s: expected the guesses ending in the secret, got %+v", name, g)
+		}
+		if g := report.Results[0]; name == "knuth" && (len(g.Stats) != g.Moves || report.Partitions == 0 || report.PeakCodes < 1296) {
+			t.Errorf("%s: expected the work of each move, got %+v", name, report)
+		}
+
+		buf := &bytes.Buffer{}
+		if err := report.writeCSV(buf); err != nil {
+			t.Fatal(err)
+		}
+		rows, err := csv.NewReader(buf).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 21 || rows[1][3] != secrets[0].String() {
+			t.Errorf("%s: expected a header and 20 rows, got %v", name, rows[:2])
+		}
+		if n := len(rows[0]); rows[0][n-1] != fmt.Sprintf("guess_%d", report.MaxMoves) || rows[1][1] != "minmax" {
+			t.Errorf("%s: expected a column for each guess, got %v", name, rows[:2])
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/experiments"
+	"github.com/ianmcmahon/mastermind/internal/profile"
+)
+
+func experimentCommand(args []string) (err error) {
+	fs := flag.NewFlagSet("experiment", flag.ContinueOnError)
+	solvers := fs.String("solvers", "knuth,genetic", "solvers to play")
+	heuristic := fs.String("heuristic", "minmax", "how knuth rates guesses: minmax, expected or entropy")
+	sizes := fs.String("sizes", "4x6", "board sizes to play on")
+	seeds := fs.String("seeds", "1", "seeds to play with, each drawing its own secrets")
+	games := fs.Int("games", 20, "random secrets each seed draws; 0 plays every secret")
+	format := fs.String("format", "table", "output format: table or csv")
+	output := fs.String("o", "", "file to write to, instead of stdout")
+	heatmap := fs.String("heatmap", "", "file to write the moves every solver took on every secret to, as CSV")
+	moves := fs.String("moves", "", "file to write every move of every game to, as CSV with its schema")
+	hardest := fs.Int("hardest", 0, "secrets to list after the table which each solver took the most moves on")
+	paired := fs.Bool("paired", false, "compare every two solvers secret by secret after the table")
+	var prof profile.Profile
+	prof.AddFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "table" && *format != "csv" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	h, err := parseHeuristic(*heuristic)
+	if err != nil {
+		return err
+	}
+
+	m := experiments.Matrix{Games: *games}
+	for _, name := range strings.Split(*solvers, ",") {
+		newSolver, ok := benchSolvers[name]
+		if !ok {
+			return fmt.Errorf("unknown solver %q", name)
+		}
+		s := experiments.Solver{
+			Name: name,
+			New:  func(seed int64) mm.SolverFunc { return newSolver(seed, h) },
+		}
+		if benchHeuristics[name] {
+			s.Config = h.String()
+		}
+		m.Solvers = append(m.Solvers, s)
+	}
+	for _, s := range strings.Split(*sizes, ",") {
+		size, err := mm.ParseGameSize(s)
+		if err != nil {
+			return err
+		}
+		m.Sizes = append(m.Sizes, size)
+	}
+	for _, s := range strings.Split(*seeds, ",") {
+		seed, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("bad seed %q", s)
+		}
+		m.Seeds = append(m.Seeds, seed)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := prof.Start(); err != nil {
+		return err
+	}
+	played := m.Run()
+	if err := prof.Stop(); err != nil {
+		return err
+	}
+	summaries := experiments.Summarize(played)
+	maps := experiments.Heatmaps(played)
+	if *heatmap != "" {
+		if err := writeHeatmap(*heatmap, maps); err != nil {
+			return err
+		}
+	}
+	if *moves != "" {
+		if err := writeMoves(*moves, played); err != nil {
+			return err
+		}
+	}
+	if *format == "csv" {
+		return experiments.WriteCSV(w, summaries)
+	}
+	if err := experiments.WriteTable(w, summaries); err != nil {
+		return err
+	}
+	if *hardest > 0 {
+		if err := printHardest(w, maps, *hardest); err != nil {
+			return err
+		}
+	}
+	if *paired {
+		return printComparisons(w, m.Solvers, played)
+	}
+	return nil
+}
+
+// printComparisons compares every two solvers on the secrets both played
+func printComparisons(w io.Writer, solvers []experiments.Solver, played []experiments.Game) error {
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	for i, a := range solvers {
+		for _, b := range solvers[i+1:] {
+			if err := experiments.WriteComparisons(w, experiments.Compare(played, a.Name, b.Name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeMoves(path string, played []experiments.Game) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := experiments.WriteMovesCSV(f, played); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeHeatmap(path string, maps []*experiments.Heatmap) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := experiments.WriteHeatmapCSV(f, maps); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// printHardest lists the n secrets each solver took the most moves on, on
+// each size
+func printHardest(w io.Writer, maps []*experiments.Heatmap, n int) error {
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	for _, h := range maps {
+		for _, solver := range h.Solvers {
+			var secrets []string
+			for _, s := range h.Hardest(solver, n) {
+				moves := "failed"
+				if !math.IsNaN(s.Moves) {
+					moves = strconv.FormatFloat(s.Moves, 'f', -1, 64)
+				}
+				secrets = append(secrets, fmt.Sprintf("%v (%s)", s.Secret, moves))
+			}
+			if _, err := fmt.Fprintf(w, "hardest for %s on %v: %s\n", solver, h.Size, strings.Join(secrets, ", ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
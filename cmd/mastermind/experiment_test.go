@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExperiment(t *testing.T) {
+	dir := t.TempDir()
+	out, heatmap := filepath.Join(dir, "experiment.csv"), filepath.Join(dir, "heatmap.csv")
+	args := []string{"-solvers", "knuth,genetic", "-sizes", "4x6,4x5", "-seeds", "1,2", "-games", "3", "-format", "csv", "-o", out, "-heatmap", heatmap}
+	if err := experimentCommand(args); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[1], "knuth,4x6,6,0,") || !strings.HasPrefix(lines[4], "genetic,4x5,6,") {
+		t.Errorf("expected a row for each solver and size, of 6 games each, got\n%s", b)
+	}
+	if b, err = os.ReadFile(heatmap); err != nil {
+		t.Fatal(err)
+	}
+	// no more than 6 secrets a size, fewer if seeds drew the same one
+	if lines := strings.Split(strings.TrimSpace(string(b)), "\n"); lines[0] != "size,secret,knuth,genetic" || len(lines) < 3 || len(lines) > 13 {
+		t.Errorf("expected a heatmap of knuth and genetic, got\n%s", b)
+	}
+
+	table, moves := filepath.Join(dir, "experiment.txt"), filepath.Join(dir, "moves.csv")
+	if err := experimentCommand([]string{"-solvers", "knuth,human-random", "-games", "5", "-paired", "-hardest", "1", "-o", table, "-moves", moves}); err != nil {
+		t.Fatal(err)
+	}
+	if b, err = os.ReadFile(table); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "hardest for human-random on 4x6: ") || !strings.Contains(string(b), "knuth vs human-random on 4x6: 5 secrets, ") {
+		t.Errorf("expected the hardest secrets and a comparison after the table, got\n%s", b)
+	}
+	if b, err = os.ReadFile(moves); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), "# mastermind moves v1\n") || !strings.Contains(string(b), "\nknuth,minmax,4x6,1,0,") || !strings.Contains(string(b), "\nhuman-random,,4x6,1,4,") {
+		t.Errorf("expected every move of knuth's and human-random's games, got\n%s", b)
+	}
+
+	for _, args := range [][]string{{"-solvers", "nobody"}, {"-sizes", "4"}, {"-seeds", "x"}, {"-format", "xml"}} {
+		if err := experimentCommand(args); err == nil {
+			t.Errorf("expected %v refused", args)
+		}
+	}
+}
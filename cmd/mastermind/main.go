@@ -0,0 +1,178 @@
+// Command mastermind plays Mastermind in the terminal.
+//
+//	mastermind [play] [-size 4x6] [-guesses 10] [-plain] [-daily] [-record game.mmr] [-results file]
+//	mastermind tui [-size 4x6] [-guesses 10]
+//	mastermind replay [-speed 1] [-plain] [-analyze | -annotate] game.mmr
+//	mastermind assist [-size 4x6] [-plain]
+//	mastermind bench [-solver knuth|genetic|human-same|human-elimination|human-random] [-heuristic minmax|expected|entropy] [-size 4x6] [-all-secrets | -games 100] [-seed 1] [-format json|csv] [-o file] [-db file] [-cpuprofile file] [-memprofile file]
+//	mastermind tournament [-solvers knuth,genetic] [-size 4x6] [-games 20] [-seed 1] [-db file] [-cpuprofile file] [-memprofile file]
+//	mastermind experiment [-solvers knuth,genetic] [-heuristic minmax|expected|entropy] [-sizes 4x6,...] [-seeds 1,...] [-games 20] [-format table|csv] [-o file] [-heatmap file] [-moves file] [-hardest n] [-paired] [-cpuprofile file] [-memprofile file]
+//	mastermind serve [-addr :8080] [-grpc :9090] [-agents :7070] [-db file] [-slack-secret s] [-discord-key hex] [-trees 4x6.mmst,...] [-idle 24h] [-keys file] [-anon-limit 60/m] [-memory-limit MB] [-daily-salt s]
+//	mastermind agent [-addr localhost:7070 | ws://host/agents] [-name knuth] [-size 4x6] [-matches 1] [-key k]
+//	mastermind stats [-db mastermind.db] [-size 4x6]
+//	mastermind render [-db mastermind.db] -game id [-format svg|png] [-rows 10] [-o file]
+//	mastermind partition [-size 4x6] [-moves 0011:1-1,...] [-guess 0123] [-format table|html|dot] [-examples 5]
+//	mastermind openings [-size 4x6] [-heuristic minmax|expected|entropy] [-exact]
+//	mastermind tree [-size 4x6] [-o 4x6.mmst] | -check 4x6.mmst [-cpuprofile file] [-memprofile file]
+//	mastermind prove [-size 4x6] -bound 5 [-strategy minmax|expected|entropy] [-workers n] [-cpuprofile file] [-memprofile file]
+//
+// play is a game against the computer.  Guesses are entered as digits, eg
+// 0123, or as color names, eg "red green blue yellow".  With -daily it's
+// one game of today's puzzle, which everyone gets the same secret for, and
+// a grid of the result to share.  With -record it's one game, recorded to a
+// .mmr replay file.  Results are kept in a file, by default results.json
+// in the user's config directory, and the streak, average guesses and
+// distribution of guesses are shown after each game: for daily puzzles, and
+// for practice games of each size.
+//
+// tui is the same game on a full-screen board, with guesses entered with
+// the arrow keys or digits, and a key for hints.
+//
+// replay steps through a recorded game, a move each time enter is pressed,
+// or at the pace it was played, and checks the secret revealed at the end
+// against the one committed to at the start.  With -analyze it writes, for
+// each move, how many codes could be the secret before and after it and
+// the bits of information it gained, as CSV for plotting.  With -annotate
+// it labels each guess optimal, good, an inaccuracy or a blunder, as a chess
+// engine would, by the codes it could leave against the best guess there
+// was, at worst and on average.
+//
+// assist suggests guesses for a game on a physical board, with the black and
+// white pegs the codemaker gives typed in after each one.  When no code
+// gives all the answers, it works out which answer must have been wrong, or
+// which few could have been, and has the codemaker correct it.
+//
+// bench runs a solver against every secret, or a random sample of them, and
+// writes the guesses and time taken for each as JSON or CSV, and optionally
+// to a database.  Besides knuth and genetic, the human- solvers play as
+// people typically do, as package human describes, for a baseline; bench,
+// tournament and experiment all take them.  The JSON has the work knuth
+// did on each move too: the codes it scored against each other, the guesses
+// it split the codes left by, how many codes the result ruled out, and the
+// most codes it held at once.
+//
+// tournament plays solvers against each other on the same secrets, and
+// rates them, keeping the ratings in a database if given one.
+//
+// experiment plays solvers on every size given, with every seed, and sums
+// up the moves and time each solver took on each size: mean, standard
+// deviation and worst.  Each seed draws the secrets and seeds the solvers,
+// so every solver plays the same games, and running the experiment again
+// plays them again.  -hardest lists the secrets each solver took the most
+// moves on, and -heatmap writes the moves every solver took on every
+// secret as CSV, a row a secret and a column a solver.  -paired compares
+// every two solvers secret by secret: the mean difference in moves, the
+// secrets each won, and the sign test's p-value of the difference.  -moves
+// writes every move of every game, a row each with the game's solver,
+// configuration, seed and secret, as CSV headed by comment lines
+// describing each column, as experiments.MoveColumns does.
+//
+// serve runs the HTTP API of package server, and optionally its gRPC service.
+// With a database, games survive restarts and match ratings are kept; -db
+// takes a SQLite file, memory, or a redis:// URL which several servers can
+// share.
+// Games made in a session, started with POST /sessions, are only that
+// session's to play, even once they're got again from the database; games
+// and sessions left unused for -idle, a day unless set, are forgotten.
+// With a Slack signing secret or Discord public key, it answers that chat's
+// slash command too, as package bot.  Programs in any language can play in
+// its lobby with the agent protocol, described in package server, over TCP
+// or a websocket.  Hints for games played elsewhere are
+// answered from strategy trees, worked out when first needed or loaded with
+// -trees.  With -keys, requests to the API need one of the keys in the
+// file, and each key's are limited to its own rate, whether they're made
+// over HTTP, gRPC, as x-api-key metadata, or the agent protocol, in the
+// hello; -anon-limit lets
+// requests without a key in too, limited by the address they come from.
+// -memory-limit refuses boards too big to hint in that many MB, by
+// solver.EstimateMemory, 1024 unless set; boards of more than a million
+// codes are refused whatever it is.  Daily puzzles' secrets are salted with
+// -daily-salt, so they can't be worked out from the date as play's can;
+// unless it's set the salt is random, and the day's puzzle changes when the
+// server restarts.
+//
+// agent plays lobby matches over the agent protocol as the built in solver,
+// for a program to play against, or as an example of the protocol.  -key is
+// the API key it plays with, on a server started with -keys.
+//
+// stats sums up the games and solver runs kept in a database.
+//
+// render draws a game kept in a database as an SVG or PNG picture of the
+// board.
+//
+// partition shows how a guess, or the solver's choice of one, splits the
+// codes which could still be the secret by the result each would score, as
+// a table, an HTML table or a Graphviz graph.
+//
+// openings rates every first guess on a board by a heuristic, best first,
+// one of each family of guesses alike but for the order of their pegs and
+// which colors they are, eg 0012 for 0012, 1020 and 3455.  With -exact, on
+// boards small enough, it plays out the strategy after each opening over
+// every secret, and ranks them by the moves that takes.
+//
+// tree works out the solver's whole strategy for a board size ahead of time,
+// and writes it in a compact binary form, or checks a tree written before
+// wins every game, showing the branch which loses if it doesn't.  serve
+// checks the trees it loads the same way.  Either way it shows how many moves the tree takes
+// more than the published optimum, for sizes where that's known.
+//
+// prove checks that the solver wins every game on a board within a number
+// of moves, searching all the games at once in parallel, and shows a game
+// which takes longer if there's one.
+//
+// bench, tournament, experiment, tree and prove write profiles of their run for go tool
+// pprof with -cpuprofile and -memprofile.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"play", "play a game against the computer", playCommand},
+	{"tui", "play a game on a full-screen board", tuiCommand},
+	{"replay", "step through a recorded game", replayCommand},
+	{"assist", "suggest guesses for a game on a physical board", assistCommand},
+	{"bench", "measure a solver over many secrets", benchCommand},
+	{"tournament", "rate solvers against each other", tournamentCommand},
+	{"experiment", "sum up solvers over sizes and seeds", experimentCommand},
+	{"serve", "serve games over HTTP and websockets", serveCommand},
+	{"agent", "play matches on a server as the solver", agentCommand},
+	{"stats", "sum up the games and runs in a database", statsCommand},
+	{"render", "draw a game in a database as an image", renderCommand},
+	{"partition", "show how a guess splits the codes left", partitionCommand},
+	{"openings", "rate every first guess on a board", openingsCommand},
+	{"tree", "work out the solver's strategy for a size", treeCommand},
+	{"prove", "check the solver always wins within a bound", proveCommand},
+}
+
+func main() {
+	args := os.Args[1:]
+	name := "play"
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		name, args = args[0], args[1:]
+	}
+
+	for _, c := range commands {
+		if c.name == name {
+			if err := c.run(args); err != nil {
+				fmt.Fprintln(os.Stderr, "mastermind:", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "mastermind: unknown command %q\n\nCommands:\n", name)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.usage)
+	}
+	os.Exit(2)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+func openingsCommand(args []string) error {
+	fs := flag.NewFlagSet("openings", flag.ContinueOnError)
+	size := fs.String("size", "4x6", "board size")
+	heuristic := fs.String("heuristic", "minmax", "how to rate guesses: minmax, expected or entropy")
+	exact := fs.Bool("exact", false, "play out the strategy after each opening over every secret, for boards small enough")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	s, err := mm.ParseGameSize(*size)
+	if err != nil {
+		return err
+	}
+	h, err := parseHeuristic(*heuristic)
+	if err != nil {
+		return err
+	}
+	game := &solver.Solver{Game: mm.NewCustomGame(s.Positions, s.Colors), Heuristic: h}
+	openings, err := game.Openings(*exact)
+	if err != nil {
+		return err
+	}
+	return writeOpenings(os.Stdout, openings)
+}
+
+// writeOpenings writes the openings as a table, best first
+func writeOpenings(w io.Writer, openings []solver.Opening) error {
+	if _, err := fmt.Fprintf(w, "%-12s %8s %12s %8s %8s %10s %6s\n",
+		"guess", "codes", "score", "largest", "results", "expected", "worst"); err != nil {
+		return err
+	}
+	for _, o := range openings {
+		expected, worst := "", ""
+		if o.Counts != nil {
+			expected, worst = fmt.Sprintf("%.4f", o.Counts.Expected()), fmt.Sprint(o.Counts.Worst())
+		}
+		if _, err := fmt.Fprintf(w, "%-12v %8d %12.4f %8d %8d %10s %6s\n",
+			o.Guess, o.Codes, o.Score, o.Largest, o.Results, expected, worst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+func TestWriteOpenings(t *testing.T) {
+	game := &solver.Solver{Game: mm.NewCustomGame(4, 6)}
+	openings, err := game.Openings(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := writeOpenings(&out, openings); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 6 || !strings.HasPrefix(lines[0], "guess") || !strings.HasPrefix(lines[1], "0011 ") || !strings.Contains(lines[1], " 256.0000 ") {
+		t.Errorf("expected a header and the five openings, 0011 first, got\n%s", out.String())
+	}
+}
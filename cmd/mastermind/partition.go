@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/render"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+func partitionCommand(args []string) error {
+	fs := flag.NewFlagSet("partition", flag.ContinueOnError)
+	size := fs.String("size", "4x6", "board size")
+	moves := fs.String("moves", "", "the game so far, as guess:black-white separated by commas, eg 0011:1-1,1234:0-2")
+	guess := fs.String("guess", "", "the guess to split the codes with; the solver's choice if not given")
+	format := fs.String("format", "table", "table, html or dot")
+	n := fs.Int("examples", 5, "codes to show from each bucket")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	s, err := mm.ParseGameSize(*size)
+	if err != nil {
+		return err
+	}
+	history, err := parseMoves(*moves, s)
+	if err != nil {
+		return err
+	}
+
+	game := &solver.Solver{Game: mm.NewCustomGame(s.Positions, s.Colors)}
+	var code mm.Code
+	if *guess != "" {
+		if code, err = parseCode(*guess, s); err != nil {
+			return err
+		}
+	} else if code, err = game.Step(history); err != nil {
+		return err
+	}
+	return writePartition(os.Stdout, game, history, code, *format, *n)
+}
+
+// parseMoves reads a game written as guess:result pairs separated by
+// commas
+func parseMoves(s string, size mm.GameSize) (mm.History, error) {
+	var history mm.History
+	for _, move := range strings.Split(s, ",") {
+		move = strings.TrimSpace(move)
+		if move == "" {
+			continue
+		}
+		parts := strings.Split(move, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("move %q isn't of the form 0011:1-1", move)
+		}
+		guess, err := parseCode(parts[0], size)
+		if err != nil {
+			return nil, err
+		}
+		result, err := parseResult(parts[1], size.Positions)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, mm.Move{Guess: guess, Result: result})
+	}
+	return history, nil
+}
+
+func writePartition(w io.Writer, game *solver.Solver, history mm.History, guess mm.Code, format string, n int) error {
+	p, err := game.Partition(history, guess)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "table":
+		return render.PartitionTable(w, p, n)
+	case "html":
+		return render.PartitionHTML(w, p, n)
+	case "dot":
+		return render.PartitionDOT(w, p, n)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+func TestParseMoves(t *testing.T) {
+	size := mm.GameSize{Positions: 4, Colors: 6}
+	h, err := parseMoves("0011:1-1, 1234:0 2,", size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 2 || h[1].Guess.String() != "1234" || h[1].Result != (mm.Result{HalfCorrect: 2}) {
+		t.Errorf("unexpected moves %v", h)
+	}
+	for _, bad := range []string{"0011", "0011:1-1:2", "001:1-1", "0011:5-0"} {
+		if _, err := parseMoves(bad, size); err == nil {
+			t.Errorf("parsed bad moves %q", bad)
+		}
+	}
+}
+
+func TestWritePartition(t *testing.T) {
+	game := &solver.Solver{Game: mm.NewCustomGame(4, 6)}
+	h := mm.History{{Guess: mm.Code{0, 0, 1, 1}, Result: mm.Result{Correct: 1, HalfCorrect: 1}}}
+	for format, expected := range map[string]string{"table": "guess 0123 splits 208 codes", "html": "<table", "dot": "digraph"} {
+		var out bytes.Buffer
+		if err := writePartition(&out, game, h, mm.Code{0, 1, 2, 3}, format, 3); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(out.String(), expected) {
+			t.Errorf("%s: expected %q, got\n%s", format, expected, out.String())
+		}
+	}
+	if err := writePartition(&bytes.Buffer{}, game, h, mm.Code{0, 1, 2, 3}, "pdf", 3); err == nil {
+		t.Error("expected an unknown format to fail")
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// the names of the colors, in order, and the ANSI codes they're drawn in
+var colorNames = []string{"red", "green", "yellow", "blue", "magenta", "cyan", "white", "orange", "pink", "brown", "gray", "purple"}
+var colorCodes = []string{"31", "32", "33", "34", "35", "36", "37", "38;5;208", "38;5;213", "38;5;130", "90", "38;5;93"}
+
+// colorName is the name of color c, or its number past the named ones
+func colorName(c byte) string {
+	if int(c) < len(colorNames) {
+		return colorNames[c]
+	}
+	return strconv.Itoa(int(c))
+}
+
+// parseCode reads a code of the given size, either as digits, eg 0123, or
+// as color names or their first letters separated by spaces or commas
+func parseCode(s string, size mm.GameSize) (mm.Code, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) == 1 && size.Positions > 1 {
+		// digits run together
+		fields = strings.Split(fields[0], "")
+	}
+	if len(fields) != size.Positions {
+		return nil, fmt.Errorf("a code has %d pegs", size.Positions)
+	}
+
+	code := make(mm.Code, size.Positions)
+	for i, f := range fields {
+		c, err := parseColor(f, size.Colors)
+		if err != nil {
+			return nil, err
+		}
+		code[i] = c
+	}
+	return code, nil
+}
+
+func parseColor(s string, colors byte) (byte, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 || n >= int(colors) {
+			return 0, fmt.Errorf("colors go from 0 to %d", colors-1)
+		}
+		return byte(n), nil
+	}
+
+	match := -1
+	for c := 0; c < int(colors) && c < len(colorNames); c++ {
+		if colorNames[c] == s {
+			return byte(c), nil
+		}
+		if strings.HasPrefix(colorNames[c], s) {
+			if match >= 0 {
+				return 0, fmt.Errorf("%q could be %s or %s", s, colorNames[match], colorNames[c])
+			}
+			match = c
+		}
+	}
+	if match < 0 {
+		return 0, fmt.Errorf("%q isn't one of the colors", s)
+	}
+	return byte(match), nil
+}
+
+// drawer renders codes and results, in color unless plain
+type drawer struct {
+	plain bool
+}
+
+func (d drawer) paint(s, ansi string) string {
+	if d.plain {
+		return s
+	}
+	return "\x1b[" + ansi + "m" + s + "\x1b[0m"
+}
+
+func (d drawer) code(c mm.Code) string {
+	if d.plain {
+		return c.String()
+	}
+	pegs := make([]string, len(c))
+	for i, v := range c {
+		pegs[i] = d.peg(v)
+	}
+	return strings.Join(pegs, " ")
+}
+
+// a single peg of color c
+func (d drawer) peg(c byte) string {
+	if d.plain {
+		return mm.Code{c}.String()
+	}
+	ansi := "1"
+	if int(c) < len(colorCodes) {
+		ansi = colorCodes[c]
+	}
+	return d.paint("●", ansi)
+}
+
+// a black peg for each correct position, a white one for each correct color
+func (d drawer) result(r mm.Result, positions int) string {
+	if d.plain {
+		return fmt.Sprintf("%d black, %d white", r.Correct, r.HalfCorrect)
+	}
+	return fmt.Sprintf("%s  (%d black, %d white)", d.pins(r, positions), r.Correct, r.HalfCorrect)
+}
+
+// the pegs of result r alone, with a dot for each position scoring nothing
+func (d drawer) pins(r mm.Result, positions int) string {
+	black, white := "●", "○"
+	if d.plain {
+		black, white = "b", "w"
+	} else {
+		black, white = d.paint(black, "1"), d.paint(white, "1")
+	}
+	return strings.Repeat(black, r.Correct) + strings.Repeat(white, r.HalfCorrect) +
+		strings.Repeat("·", positions-r.Correct-r.HalfCorrect)
+}
+
+// the legend of colors for a board with colors colors
+func (d drawer) legend(colors byte) string {
+	parts := make([]string, colors)
+	for c := byte(0); c < colors; c++ {
+		parts[c] = fmt.Sprintf("%d=%s", c, colorName(c))
+		if !d.plain && int(c) < len(colorCodes) {
+			parts[c] = d.paint(parts[c], colorCodes[c])
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func playCommand(args []string) error {
+	fs := flag.NewFlagSet("play", flag.ContinueOnError)
+	size := fs.String("size", "", "board size, eg 4x6; asked for if not given")
+	guesses := fs.Int("guesses", 10, "guesses allowed before the game is lost")
+	plain := fs.Bool("plain", false, "don't draw in color")
+	daily := fs.Bool("daily", false, "play today's puzzle, the same for everyone, and write up the result for sharing")
+	record := fs.String("record", "", "play one game, recording it to this .mmr replay file")
+	keep := fs.String("results", defaultResultsPath(), "file to keep your streaks and statistics in; none if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	p := &player{
+		in:      bufio.NewScanner(os.Stdin),
+		out:     os.Stdout,
+		draw:    drawer{plain: *plain},
+		guesses: *guesses,
+		record:  *record,
+		results: *keep,
+	}
+	if *daily {
+		today := time.Now()
+		p.daily = mm.DailyNumber(today)
+		p.newGame = func(size mm.GameSize) *mm.Game {
+			return mm.NewDailyGame(size, today)
+		}
+	}
+	if *size != "" {
+		s, err := mm.ParseGameSize(*size)
+		if err != nil {
+			return err
+		}
+		p.size = &s
+	}
+	return p.run()
+}
+
+type player struct {
+	in      *bufio.Scanner
+	out     io.Writer
+	draw    drawer
+	size    *mm.GameSize
+	guesses int
+	// newGame makes each game; a random secret unless set
+	newGame func(size mm.GameSize) *mm.Game
+	// daily is the number of the daily puzzle being played, if it is one.
+	// There's only one game of it, and a grid to share after.
+	daily int
+	// record is a file to record the one game played to.  The secret is
+	// only revealed in it if the game is finished.
+	record string
+	// results is a file to keep the player's results in, shown after each
+	// game
+	results string
+}
+
+// prompt asks a question, returning the answer, or io.EOF once the input
+// runs out
+func (p *player) prompt(format string, args ...interface{}) (string, error) {
+	fmt.Fprintf(p.out, format, args...)
+	if !p.in.Scan() {
+		if err := p.in.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return strings.TrimSpace(p.in.Text()), nil
+}
+
+// run plays games until the player declines a rematch or the input ends
+func (p *player) run() error {
+	for {
+		size, err := p.chooseSize()
+		if err != nil {
+			return ignoreEOF(err)
+		}
+		if err := p.game(size); err != nil {
+			return ignoreEOF(err)
+		}
+		if p.daily > 0 || p.record != "" {
+			return nil
+		}
+
+		again, err := p.prompt("Play again? [Y/n] ")
+		if err != nil {
+			return ignoreEOF(err)
+		}
+		if strings.HasPrefix(strings.ToLower(again), "n") {
+			return nil
+		}
+	}
+}
+
+func ignoreEOF(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+func (p *player) chooseSize() (mm.GameSize, error) {
+	if p.size != nil {
+		return *p.size, nil
+	}
+	for {
+		answer, err := p.prompt("Board size, positions x colors [4x6]: ")
+		if err != nil {
+			return mm.GameSize{}, err
+		}
+		if answer == "" {
+			return mm.GameSize{Positions: 4, Colors: 6}, nil
+		}
+		size, err := mm.ParseGameSize(answer)
+		if err == nil {
+			return size, nil
+		}
+		fmt.Fprintln(p.out, err)
+	}
+}
+
+// game plays one game of the given size
+func (p *player) game(size mm.GameSize) error {
+	var game *mm.Game
+	if p.newGame != nil {
+		game = p.newGame(size)
+	} else {
+		game = mm.NewCustomGame(size.Positions, size.Colors)
+	}
+	game.Quiet = true
+	size = game.GameSize()
+
+	title := "A new"
+	if p.daily > 0 {
+		title = fmt.Sprintf("Daily puzzle #%d, a", p.daily)
+	}
+	fmt.Fprintf(p.out, "\n%s %v game: guess the %d pegs in %d tries.\nColors: %s\n\n",
+		title, size, size.Positions, p.guesses, p.draw.legend(size.Colors))
+
+	var history mm.History
+	defer func() {
+		if p.daily > 0 && len(history) > 0 {
+			fmt.Fprintf(p.out, "\n%s", mm.ShareGrid(size, p.daily, history, p.guesses))
+		}
+	}()
+	replay := mm.NewReplay(size, game.Secret(), newSalt(), time.Now())
+	over := false
+	if p.record != "" {
+		defer func() {
+			if err := writeReplay(p.record, replay, over); err != nil {
+				fmt.Fprintln(p.out, "recording the game:", err)
+			}
+		}()
+	}
+
+	for turn := 1; turn <= p.guesses; {
+		answer, err := p.prompt("Guess %d: ", turn)
+		if err != nil {
+			return err
+		}
+		guess, err := parseCode(answer, size)
+		if err != nil {
+			fmt.Fprintln(p.out, err)
+			continue
+		}
+
+		result, err := game.ScoredGuess(guess)
+		if err != nil {
+			return err
+		}
+		history = append(history, mm.Move{Guess: guess, Result: result})
+		replay.Add(history[len(history)-1], time.Now())
+		fmt.Fprintf(p.out, "  %s   %s\n", p.draw.code(guess), p.draw.result(result, size.Positions))
+		if game.IsWin(result) {
+			over = true
+			fmt.Fprintf(p.out, "\nYou won in %d guesses!\n", turn)
+			p.keepResult(size, true, turn)
+			return nil
+		}
+		turn++
+	}
+
+	over = true
+	fmt.Fprintf(p.out, "\nOut of guesses.  The secret was %s  (%s)\n", p.draw.code(game.Secret()), game.Secret())
+	p.keepResult(size, false, p.guesses)
+	return nil
+}
+
+// keepResult adds a finished game to the player's results and shows them.
+// A daily puzzle only counts the first time it's played.  Trouble with the
+// file is reported, but doesn't end the game.
+func (p *player) keepResult(size mm.GameSize, won bool, guesses int) {
+	if p.results == "" {
+		return
+	}
+	r, err := loadResults(p.results)
+	if err != nil {
+		fmt.Fprintln(p.out, "reading your results:", err)
+		return
+	}
+	var t *tally
+	name := fmt.Sprintf("%v games", size)
+	if p.daily > 0 {
+		t, name = &r.Daily, "Daily puzzles"
+		if !t.addDaily(p.daily, won, guesses) {
+			fmt.Fprintf(p.out, "\nYou've played puzzle #%d already, so this one doesn't count.\n", p.daily)
+		}
+	} else {
+		if t = r.Practice[size.String()]; t == nil {
+			t = &tally{}
+			r.Practice[size.String()] = t
+		}
+		t.add(won, guesses)
+	}
+	if err := r.save(p.results); err != nil {
+		fmt.Fprintln(p.out, "saving your results:", err)
+	}
+	t.show(p.out, name)
+}
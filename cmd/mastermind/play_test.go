@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestParseCode(t *testing.T) {
+	size := mm.GameSize{Positions: 4, Colors: 6}
+	valid := map[string]string{
+		"0123":                   "0123",
+		"0 1 2 3":                "0123",
+		"red green blue yellow":  "0132",
+		"r,g,b,y":                "0132",
+		"  Magenta CYAN red red": "4500",
+	}
+	for in, expected := range valid {
+		code, err := parseCode(in, size)
+		if err != nil {
+			t.Errorf("%q: %v", in, err)
+			continue
+		}
+		if code.String() != expected {
+			t.Errorf("%q parsed as %v, expected %s", in, code, expected)
+		}
+	}
+
+	// too short, out of range, a color not on the board, unknown
+	for _, in := range []string{"012", "0126", "red red red white", "red red red mauve"} {
+		if _, err := parseCode(in, size); err == nil {
+			t.Errorf("parsed invalid code %q", in)
+		}
+	}
+}
+
+func TestPlay(t *testing.T) {
+	secret := mm.Code{5, 4, 3, 2}
+	input := strings.Join([]string{
+		"4x6",
+		"0123",              // wrong
+		"bad",               // rejected, doesn't count
+		"5432",              // won
+		"y",                 // rematch
+		"",                  // default size
+		"0", "0000", "1111", // out of guesses, after one rejected guess
+		"n",
+	}, "\n")
+	out := &bytes.Buffer{}
+	p := &player{
+		in:      bufio.NewScanner(strings.NewReader(input)),
+		out:     out,
+		draw:    drawer{plain: true},
+		guesses: 2,
+		newGame: func(size mm.GameSize) *mm.Game {
+			return mm.NewCustomGameWithSecret(size.Positions, size.Colors, secret)
+		},
+	}
+	if err := p.run(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"0123   0 black, 2 white",
+		"a code has 4 pegs",
+		"You won in 2 guesses!",
+		"Out of guesses.  The secret was 5432",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in the output:\n%s", expected, out)
+		}
+	}
+	if n := strings.Count(out.String(), "A new 4x6 game"); n != 2 {
+		t.Errorf("expected 2 games, got %d", n)
+	}
+}
+
+func TestPlayDaily(t *testing.T) {
+	day := time.Date(2026, time.March, 8, 12, 0, 0, 0, time.UTC)
+	secret := mm.DailySecret(mm.GameSize{Positions: 4, Colors: 6}, day)
+	out := &bytes.Buffer{}
+	p := &player{
+		// no rematch is offered
+		in:      bufio.NewScanner(strings.NewReader("4x6\n" + secret.String() + "\ny\n")),
+		out:     out,
+		draw:    drawer{plain: true},
+		guesses: 10,
+		daily:   mm.DailyNumber(day),
+		newGame: func(size mm.GameSize) *mm.Game {
+			return mm.NewDailyGame(size, day)
+		},
+	}
+	if err := p.run(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"Daily puzzle #798, a 4x6 game",
+		"You won in 1 guesses!",
+		"Mastermind #798 4x6 1/10\n🟩🟩🟩🟩\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in the output:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out.String(), "Play again") {
+		t.Error("expected one game of the daily puzzle")
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/profile"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+func proveCommand(args []string) (err error) {
+	fs := flag.NewFlagSet("prove", flag.ContinueOnError)
+	size := fs.String("size", "4x6", "board size")
+	bound := fs.Int("bound", 0, "the most moves any game may take")
+	strategy := fs.String("strategy", "minmax", "how the solver rates guesses: minmax, expected or entropy")
+	workers := fs.Int("workers", runtime.NumCPU(), "games to search at once")
+	var prof profile.Profile
+	prof.AddFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := prof.Start(); err != nil {
+		return err
+	}
+	defer func() {
+		if stopErr := prof.Stop(); err == nil {
+			err = stopErr
+		}
+	}()
+	if *bound < 1 {
+		return fmt.Errorf("prove needs a -bound of at least 1")
+	}
+	s, err := mm.ParseGameSize(*size)
+	if err != nil {
+		return err
+	}
+	game := &solver.Solver{Game: mm.NewCustomGame(s.Positions, s.Colors)}
+	if game.Heuristic, err = parseHeuristic(*strategy); err != nil {
+		return err
+	}
+	return prove(os.Stdout, game, *bound, *workers)
+}
+
+func parseHeuristic(name string) (solver.Heuristic, error) {
+	for _, h := range []solver.Heuristic{solver.MinMax, solver.ExpectedSize, solver.Entropy} {
+		if h.String() == name {
+			return h, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown strategy %q", name)
+}
+
+// prove checks the solver wins every game within bound moves, and shows
+// the game it doesn't if there's one, playing it out to the end
+func prove(w io.Writer, game *solver.Solver, bound, workers int) error {
+	start := time.Now()
+	c, err := game.Prove(bound, workers)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		fmt.Fprintf(w, "%v wins every %v game within %d moves (checked in %v)\n",
+			game.Heuristic, game.GameSize(), bound, time.Since(start).Round(time.Millisecond))
+		return nil
+	}
+
+	fmt.Fprintf(w, "counterexample: secret %v\n", c.Secret)
+	history := c.Moves
+	for {
+		guess, err := game.Step(history)
+		if err != nil {
+			return err
+		}
+		r, err := game.Feedback.Score(guess, c.Secret, game.Colors())
+		if err != nil {
+			return err
+		}
+		history = append(history, mm.Move{Guess: guess, Result: r})
+		if guess.String() == c.Secret.String() {
+			break
+		}
+	}
+	for i, m := range history {
+		fmt.Fprintf(w, "%3d  %v  %v\n", i+1, m.Guess, m.Result)
+	}
+	return fmt.Errorf("%v takes %d moves, more than %d", c.Secret, len(history), bound)
+}
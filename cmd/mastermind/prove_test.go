@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+func TestProve(t *testing.T) {
+	game := &solver.Solver{Game: mm.NewCustomGame(4, 6)}
+	var out bytes.Buffer
+	if err := prove(&out, game, 5, 4); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "minmax wins every 4x6 game within 5 moves") {
+		t.Errorf("unexpected proof %q", out.String())
+	}
+
+	out.Reset()
+	err := prove(&out, game, 4, 4)
+	if err == nil || !strings.Contains(err.Error(), "takes 5 moves, more than 4") {
+		t.Fatalf("expected a counterexample, got %v", err)
+	}
+	// the game is shown played out, ending in the win
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 6 || !strings.HasPrefix(lines[0], "counterexample: secret ") || !strings.HasSuffix(lines[5], "4-0") {
+		t.Errorf("unexpected counterexample\n%s", out.String())
+	}
+}
+
+func TestParseHeuristic(t *testing.T) {
+	if h, err := parseHeuristic("entropy"); err != nil || h != solver.Entropy {
+		t.Errorf("expected entropy, got %v %v", h, err)
+	}
+	if _, err := parseHeuristic("magic"); err == nil {
+		t.Error("expected an unknown strategy to fail")
+	}
+}
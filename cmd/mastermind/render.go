@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/render"
+	"github.com/ianmcmahon/mastermind/storage"
+)
+
+func renderCommand(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	db := fs.String("db", "mastermind.db", "database of games: a SQLite file or redis://host")
+	id := fs.String("game", "", "the game to draw")
+	format := fs.String("format", "", "svg or png; taken from the output file's name if not given, else svg")
+	output := fs.String("o", "", "file to write to, instead of stdout")
+	rows := fs.Int("rows", 0, "rows on the board, if more than the guesses made")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id == "" {
+		return fmt.Errorf("which game? give its id with -game")
+	}
+	if *format == "" {
+		*format = "svg"
+		if filepath.Ext(*output) == ".png" {
+			*format = "png"
+		}
+	}
+	if *format != "svg" && *format != "png" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if _, err := os.Stat(*db); err != nil {
+		return err
+	}
+
+	store, err := storage.Open(*db)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	rec, err := store.Game(*id)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return renderGame(w, rec, *format, *rows)
+}
+
+// renderGame draws a stored game, with its secret if it was solved
+func renderGame(w io.Writer, rec *storage.Game, format string, rows int) error {
+	b := render.Board{Size: rec.Size, Rows: rows}
+	for _, m := range rec.Moves {
+		b.Moves = append(b.Moves, mm.Move{Guess: m.Guess, Result: m.Result})
+	}
+	if rec.Solved {
+		b.Secret = rec.Secret
+	}
+	if format == "png" {
+		return render.PNG(w, b)
+	}
+	return render.SVG(w, b)
+}
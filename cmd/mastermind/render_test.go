@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/storage"
+)
+
+func TestRenderGame(t *testing.T) {
+	rec := &storage.Game{
+		ID:     "abc",
+		Size:   mm.GameSize{Positions: 4, Colors: 6},
+		Secret: mm.Code{0, 1, 2, 3},
+		Solved: true,
+		Moves: []storage.Move{
+			{Guess: mm.Code{0, 0, 1, 1}, Result: mm.Result{Correct: 1, HalfCorrect: 1}},
+			{Guess: mm.Code{0, 1, 2, 3}, Result: mm.Result{Correct: 4}},
+		},
+	}
+
+	var svg bytes.Buffer
+	if err := renderGame(&svg, rec, "svg", 10); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(svg.String(), "<svg") || !strings.Contains(svg.String(), `fill="#d62728"`) {
+		t.Errorf("expected an SVG with a red peg:\n%s", svg.String())
+	}
+
+	var buf bytes.Buffer
+	if err := renderGame(&buf, rec, "png", 10); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ten rows and the secret's
+	if h := img.Bounds().Dy(); h != 2*12+11*32+4 {
+		t.Errorf("expected room for 11 rows, got a height of %d", h)
+	}
+}
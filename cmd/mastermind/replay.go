@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func replayCommand(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	speed := fs.Float64("speed", 0, "play at this multiple of the recorded pace, instead of a move each time enter is pressed")
+	plain := fs.Bool("plain", false, "don't draw in color")
+	analyze := fs.Bool("analyze", false, "write the codes left and bits learned at each move as CSV, instead of showing the game")
+	annotate := fs.Bool("annotate", false, "label each guess optimal, good, an inaccuracy or a blunder, instead of showing the game")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("replay takes one .mmr file")
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := mm.ReadReplay(f)
+	if err != nil {
+		return err
+	}
+	if *analyze {
+		steps, err := mm.Analyze(r.Size, mm.FullFeedback, r.History())
+		if err != nil {
+			return err
+		}
+		return mm.WriteStepsCSV(os.Stdout, steps)
+	}
+	if *annotate {
+		notes, err := mm.Annotate(r.Size, mm.FullFeedback, r.History())
+		if err != nil {
+			return err
+		}
+		return printAnnotations(os.Stdout, notes)
+	}
+
+	v := &viewer{out: os.Stdout, draw: drawer{plain: *plain}, sleep: time.Sleep}
+	if *speed > 0 {
+		v.speed = *speed
+	} else {
+		v.in = bufio.NewScanner(os.Stdin)
+	}
+	return v.show(r)
+}
+
+// printAnnotations writes a line for each move, with the best guess there
+// was for those which weren't optimal
+func printAnnotations(w io.Writer, notes []mm.Annotation) error {
+	for i, a := range notes {
+		line := fmt.Sprintf("%3d  %v  %v  %-10v  worst %d (best %d), expected %.1f (best %.1f)",
+			i+1, a.Guess, a.Result, a.Quality, a.Worst, a.BestWorst, a.Expected, a.BestExpected)
+		if a.Quality != mm.Optimal {
+			line += fmt.Sprintf(", %v was better", a.Best)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newSalt is a random salt for committing to a secret
+func newSalt() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeReplay(path string, r *mm.Replay, reveal bool) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.Write(f, reveal); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// viewer steps through a replay, a move each time enter is pressed on in,
+// or at speed times the recorded pace if there's no in
+type viewer struct {
+	in    *bufio.Scanner
+	out   io.Writer
+	draw  drawer
+	speed float64
+	sleep func(time.Duration)
+}
+
+func (v *viewer) show(r *mm.Replay) error {
+	fmt.Fprintf(v.out, "A %v game played %s, with the secret committed to as %.12s…\n",
+		r.Size, r.Started.Local().Format("2 Jan 2006 15:04"), r.Commitment)
+	if v.in != nil {
+		fmt.Fprintln(v.out, "Press enter for each move.")
+	}
+	fmt.Fprintln(v.out)
+
+	last := time.Duration(0)
+	for i, m := range r.Moves {
+		if v.in != nil {
+			if !v.in.Scan() {
+				return v.in.Err()
+			}
+		} else {
+			v.sleep(time.Duration(float64(m.At-last) / v.speed))
+		}
+		fmt.Fprintf(v.out, "%3d  %s   %s   %6.1fs\n",
+			i+1, v.draw.code(m.Guess), v.draw.result(m.Result, r.Size.Positions), (m.At - last).Seconds())
+		last = m.At
+	}
+
+	fmt.Fprintln(v.out)
+	switch {
+	case r.Solved():
+		fmt.Fprintf(v.out, "Solved in %d guesses, %.1fs.\n", len(r.Moves), last.Seconds())
+	default:
+		fmt.Fprintf(v.out, "Not solved after %d guesses.\n", len(r.Moves))
+	}
+	if r.Secret == nil {
+		fmt.Fprintln(v.out, "The secret isn't revealed.")
+	} else if err := r.Verify(); err != nil {
+		fmt.Fprintf(v.out, "The replay doesn't check out: %v\n", err)
+	} else {
+		fmt.Fprintf(v.out, "The secret %s (%v) matches the commitment and every result.\n", v.draw.code(r.Secret), r.Secret)
+	}
+	return nil
+}
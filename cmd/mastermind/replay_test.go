@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "game.mmr")
+
+	secret := mm.Code{5, 4, 3, 2}
+	p := &player{
+		// no rematch is offered
+		in:      bufio.NewScanner(strings.NewReader("4x6\n0123\n5432\ny\n")),
+		out:     &bytes.Buffer{},
+		draw:    drawer{plain: true},
+		guesses: 10,
+		record:  path,
+		newGame: func(size mm.GameSize) *mm.Game {
+			return mm.NewCustomGameWithSecret(size.Positions, size.Colors, secret)
+		},
+	}
+	if err := p.run(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(p.out.(*bytes.Buffer).String(), "Play again") {
+		t.Error("expected one game to be recorded")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := mm.ReadReplay(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Moves) != 2 || r.Secret.String() != "5432" || r.Verify() != nil {
+		t.Fatalf("unexpected replay %+v", r)
+	}
+
+	out := &bytes.Buffer{}
+	v := &viewer{in: bufio.NewScanner(strings.NewReader("\n\n")), out: out, draw: drawer{plain: true}}
+	if err := v.show(r); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"A 4x6 game played", "  1  0123   0 black, 2 white", "  2  5432   4 black", "Solved in 2 guesses", "matches the commitment"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in the output:\n%s", expected, out)
+		}
+	}
+
+	notes, err := mm.Annotate(r.Size, mm.FullFeedback, r.History())
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := printAnnotations(out, notes); err != nil {
+		t.Fatal(err)
+	}
+	// 0123 leaves 312 codes at worst, to 0011's 256, but 188.2 on average,
+	// within a tenth of the best 185.3
+	for _, expected := range []string{"  1  0123  0-2  good        worst 312 (best 256), expected 188.2 (best 185.3), 0011 was better", "  2  5432  4-0  good"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in the annotations:\n%s", expected, out)
+		}
+	}
+}
+
+func TestReplayPace(t *testing.T) {
+	r := mm.NewReplay(mm.GameSize{Positions: 2, Colors: 3}, mm.Code{0, 1}, "salt", time.Now())
+	r.Add(mm.Move{Guess: mm.Code{1, 1}, Result: mm.Result{Correct: 1}}, r.Started.Add(2*time.Second))
+	r.Add(mm.Move{Guess: mm.Code{0, 1}, Result: mm.Result{Correct: 2}}, r.Started.Add(6*time.Second))
+	r.Secret = mm.Code{1, 0}
+
+	var slept []time.Duration
+	out := &bytes.Buffer{}
+	v := &viewer{out: out, draw: drawer{plain: true}, speed: 2, sleep: func(d time.Duration) { slept = append(slept, d) }}
+	if err := v.show(r); err != nil {
+		t.Fatal(err)
+	}
+	if len(slept) != 2 || slept[0] != time.Second || slept[1] != 2*time.Second {
+		t.Errorf("expected the moves at twice the pace, slept %v", slept)
+	}
+	if !strings.Contains(out.String(), "doesn't check out") {
+		t.Errorf("expected a false secret to be caught:\n%s", out)
+	}
+}
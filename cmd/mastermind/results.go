@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// results are the player's results over every game played, kept in a file
+// between runs: the daily puzzles, and practice games by size
+type results struct {
+	Daily    tally
+	Practice map[string]*tally
+}
+
+// tally sums up a run of games
+type tally struct {
+	Played int
+	Won    int
+	// Guesses is the total taken to win the games won
+	Guesses int
+	// Distribution counts the games won by the guesses they took
+	Distribution map[int]int
+	// Streak is the games won in a row up to the last one, and Best the
+	// longest streak ever
+	Streak int
+	Best   int
+	// Last is the number of the last daily puzzle played, whose streak is
+	// broken by missing a day
+	Last int `json:",omitempty"`
+}
+
+// defaultResultsPath is where results are kept unless -results says
+// otherwise, or nowhere if there's no config directory
+func defaultResultsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "mastermind", "results.json")
+}
+
+// loadResults reads the results in path, which are empty if it isn't there
+func loadResults(path string) (*results, error) {
+	r := &results{}
+	buf, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(buf, r); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	if r.Practice == nil {
+		r.Practice = map[string]*tally{}
+	}
+	return r, nil
+}
+
+// save writes the results to path, replacing what's there only once
+// they're all written
+func (r *results) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// add counts a game, won in guesses or lost
+func (t *tally) add(won bool, guesses int) {
+	t.Played++
+	if !won {
+		t.Streak = 0
+		return
+	}
+	t.Won++
+	t.Guesses += guesses
+	if t.Distribution == nil {
+		t.Distribution = map[int]int{}
+	}
+	t.Distribution[guesses]++
+	t.Streak++
+	if t.Streak > t.Best {
+		t.Best = t.Streak
+	}
+}
+
+// addDaily counts a game of daily puzzle number, unless it's been played
+// already; a puzzle missed since the last one breaks the streak
+func (t *tally) addDaily(number int, won bool, guesses int) bool {
+	if number <= t.Last {
+		return false
+	}
+	if number != t.Last+1 {
+		t.Streak = 0
+	}
+	t.Last = number
+	t.add(won, guesses)
+	return true
+}
+
+// show writes the tally up, with a bar for each number of guesses up to
+// the most any game was won in
+func (t *tally) show(w io.Writer, name string) {
+	fmt.Fprintf(w, "\n%s: %d played, %d%% won, streak %d (best %d)", name, t.Played, t.Won*100/t.Played, t.Streak, t.Best)
+	if t.Won == 0 {
+		fmt.Fprintln(w)
+		return
+	}
+	fmt.Fprintf(w, ", %.2f guesses on average\n", float64(t.Guesses)/float64(t.Won))
+	most, longest := 0, 0
+	for guesses, n := range t.Distribution {
+		if n > most {
+			most = n
+		}
+		if guesses > longest {
+			longest = guesses
+		}
+	}
+	for guesses := 1; guesses <= longest; guesses++ {
+		n := t.Distribution[guesses]
+		fmt.Fprintf(w, "  %2d %s %d\n", guesses, strings.Repeat("#", (n*20+most-1)/most), n)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestTally(t *testing.T) {
+	var tl tally
+	tl.add(true, 4)
+	tl.add(true, 5)
+	tl.add(false, 10)
+	tl.add(true, 4)
+	expected := tally{Played: 4, Won: 3, Guesses: 13, Distribution: map[int]int{4: 2, 5: 1}, Streak: 1, Best: 2}
+	if !reflect.DeepEqual(tl, expected) {
+		t.Errorf("expected %+v, got %+v", expected, tl)
+	}
+
+	out := &bytes.Buffer{}
+	tl.show(out, "4x6 games")
+	for _, line := range []string{
+		"4x6 games: 4 played, 75% won, streak 1 (best 2), 4.33 guesses on average",
+		"   1  0",
+		"   4 #################### 2",
+		"   5 ########## 1",
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("expected %q in:\n%s", line, out)
+		}
+	}
+
+	// a puzzle counts once, and missing a day breaks the streak
+	var daily tally
+	for _, n := range []int{10, 11, 11, 13} {
+		daily.addDaily(n, true, 3)
+	}
+	if daily.Played != 3 || daily.Streak != 1 || daily.Best != 2 || daily.Last != 13 {
+		t.Errorf("expected 3 played with a streak of 1, got %+v", daily)
+	}
+}
+
+func TestResults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "results")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "mastermind", "results.json")
+
+	day := time.Date(2026, time.March, 8, 12, 0, 0, 0, time.UTC)
+	secret := mm.DailySecret(mm.GameSize{Positions: 4, Colors: 6}, day)
+	play := func(input string) string {
+		out := &bytes.Buffer{}
+		p := &player{
+			in:      bufio.NewScanner(strings.NewReader(input)),
+			out:     out,
+			draw:    drawer{plain: true},
+			guesses: 10,
+			daily:   mm.DailyNumber(day),
+			newGame: func(size mm.GameSize) *mm.Game {
+				return mm.NewDailyGame(size, day)
+			},
+			results: path,
+		}
+		if err := p.run(); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	out := play("4x6\n0000\n" + secret.String() + "\n")
+	if !strings.Contains(out, "Daily puzzles: 1 played, 100% won, streak 1 (best 1), 2.00 guesses on average") {
+		t.Errorf("expected the daily results in:\n%s", out)
+	}
+	// played again, it's kept in the file but not counted twice
+	out = play("4x6\n" + secret.String() + "\n")
+	if !strings.Contains(out, "played puzzle #798 already") || !strings.Contains(out, "Daily puzzles: 1 played") {
+		t.Errorf("expected the replay not counted in:\n%s", out)
+	}
+
+	r, err := loadResults(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Daily.Last != 798 || r.Daily.Distribution[2] != 1 || len(r.Practice) != 0 {
+		t.Errorf("expected the one daily game kept, got %+v", r)
+	}
+}
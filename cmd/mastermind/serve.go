@@ -0,0 +1,155 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ianmcmahon/mastermind/bot"
+	"github.com/ianmcmahon/mastermind/ratings"
+	"github.com/ianmcmahon/mastermind/server"
+	"github.com/ianmcmahon/mastermind/solver"
+	"github.com/ianmcmahon/mastermind/storage"
+	"google.golang.org/grpc"
+)
+
+func serveCommand(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	grpcAddr := fs.String("grpc", "", "address to serve gRPC on too, sharing the games")
+	db := fs.String("db", "", "database to keep games in: a SQLite file, redis://host or memory")
+	agentAddr := fs.String("agents", "", "address to take agents' TCP connections on, for their matches")
+	slackSecret := fs.String("slack-secret", "", "Slack app signing secret, to answer its slash command on /slack")
+	discordKey := fs.String("discord-key", "", "Discord application public key, in hex, to answer its interactions on /discord")
+	idle := fs.Duration("idle", server.DefaultIdleTimeout, "forget games and sessions unused for this long, or never if 0; games kept in the database can still be got again")
+	trees := fs.String("trees", "", "strategy tree files written by the tree command, separated by commas, to answer hints from")
+	keys := fs.String("keys", "", "file of API keys to take, a line each of a name, the key and its limit, eg \"ian 9f8e7d6c 10/s\"")
+	memoryLimit := fs.Uint64("memory-limit", server.DefaultMemoryLimit>>20, "refuse boards whose hints would take more than this many MB; 0 to refuse only those of more than a million codes")
+	dailySalt := fs.String("daily-salt", "", "secret mixed into the daily puzzles, so their answers can't be worked out from the date; random, and so changing on restart, unless set")
+	anonLimit := fs.String("anon-limit", "", "limit on the requests from each address without an API key, eg 60/m; none are taken without one if -keys is given")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	s := server.NewServer()
+	s.Games.MemoryLimit = *memoryLimit << 20
+	if *dailySalt != "" {
+		s.Games.DailySalt = *dailySalt
+	}
+	if *db != "" {
+		store, err := storage.Open(*db)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		s.Games.Store = store
+		s.Lobby.Ratings = ratings.New(store)
+	}
+	s.Games.IdleTimeout = *idle
+	if *idle > 0 {
+		go func() {
+			for now := range time.Tick(time.Minute) {
+				s.Games.Expire(now)
+			}
+		}()
+	}
+	if *trees != "" {
+		if err := loadTrees(s.Trees, strings.Split(*trees, ",")); err != nil {
+			return err
+		}
+	}
+
+	if *keys != "" || *anonLimit != "" {
+		auth, err := loadAuth(*keys, *anonLimit)
+		if err != nil {
+			return err
+		}
+		s.Auth = auth
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", s)
+	chat := bot.New(s.Games)
+	if *slackSecret != "" {
+		mux.Handle("/slack", &bot.Slack{Bot: chat, SigningSecret: *slackSecret})
+	}
+	if *discordKey != "" {
+		key, err := hex.DecodeString(*discordKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return fmt.Errorf("bad Discord public key %q", *discordKey)
+		}
+		mux.Handle("/discord", &bot.Discord{Bot: chat, PublicKey: key})
+	}
+
+	errs := make(chan error, 3)
+	if *agentAddr != "" {
+		lis, err := net.Listen("tcp", *agentAddr)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("taking agents on %s\n", *agentAddr)
+		go func() { errs <- s.ServeAgents(lis) }()
+	}
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("serving gRPC on %s\n", *grpcAddr)
+		srv := server.NewGRPCServer(s.Games, grpc.ChainUnaryInterceptor(s.Auth.UnaryInterceptor(s.Metrics)))
+		go func() { errs <- srv.Serve(lis) }()
+	}
+	fmt.Printf("serving games on %s\n", *addr)
+	go func() { errs <- http.ListenAndServe(*addr, mux) }()
+	return <-errs
+}
+
+// loadAuth sets up the API keys in the file at path, if there is one, and
+// the limit on requests without one, if that's given
+func loadAuth(path, anonLimit string) (*server.Auth, error) {
+	var keys []server.APIKey
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if keys, err = server.ReadKeys(f); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	var anon server.Limit
+	if anonLimit != "" {
+		var err error
+		if anon, err = server.ParseLimit(anonLimit); err != nil {
+			return nil, err
+		}
+	}
+	return server.NewAuth(keys, anon), nil
+}
+
+func loadTrees(trees *server.Trees, paths []string) error {
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		tree, err := solver.ReadTree(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if err := tree.Validate(); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if err := trees.Add(tree); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/storage"
+)
+
+func statsCommand(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	db := fs.String("db", "mastermind.db", "database of games and runs: a SQLite file or redis://host")
+	size := fs.String("size", "4x6", "board size")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	s, err := mm.ParseGameSize(*size)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(*db); err != nil {
+		return err
+	}
+
+	store, err := storage.Open(*db)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	st, err := store.Stats(s)
+	if err != nil {
+		return err
+	}
+	printStats(os.Stdout, st)
+	return nil
+}
+
+func printStats(w io.Writer, st *storage.Stats) {
+	fmt.Fprintf(w, "%v: %d games, %d solved", st.Size, st.Games, st.Solved)
+	if st.Solved > 0 {
+		fmt.Fprintf(w, " in %.2f guesses on average", st.MeanGuesses)
+	}
+	fmt.Fprintln(w)
+
+	guesses := []int{}
+	for n := range st.Distribution {
+		guesses = append(guesses, n)
+	}
+	sort.Ints(guesses)
+	for _, n := range guesses {
+		fmt.Fprintf(w, "  %2d guesses: %d\n", n, st.Distribution[n])
+	}
+
+	for _, ss := range st.Solvers {
+		fmt.Fprintf(w, "%s: %d runs, %d failed, %.3f mean moves, %d worst, %v mean time\n",
+			ss.Solver, ss.Runs, ss.Failures, ss.MeanMoves, ss.MaxMoves, ss.MeanDuration)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/profile"
+	"github.com/ianmcmahon/mastermind/ratings"
+	"github.com/ianmcmahon/mastermind/solver"
+	"github.com/ianmcmahon/mastermind/storage"
+)
+
+func tournamentCommand(args []string) (err error) {
+	fs := flag.NewFlagSet("tournament", flag.ContinueOnError)
+	solvers := fs.String("solvers", "knuth,genetic", "solvers to play against each other")
+	size := fs.String("size", "4x6", "board size")
+	games := fs.Int("games", 20, "secrets each pair of solvers plays")
+	seed := fs.Int64("seed", 1, "seed for the secrets and the solvers")
+	db := fs.String("db", "", "database to keep the ratings in: a SQLite file, redis://host or memory")
+	var prof profile.Profile
+	prof.AddFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := prof.Start(); err != nil {
+		return err
+	}
+	defer func() {
+		if stopErr := prof.Stop(); err == nil {
+			err = stopErr
+		}
+	}()
+	s, err := mm.ParseGameSize(*size)
+	if err != nil {
+		return err
+	}
+	names := strings.Split(*solvers, ",")
+	for _, name := range names {
+		if _, ok := benchSolvers[name]; !ok {
+			return fmt.Errorf("unknown solver %q", name)
+		}
+	}
+
+	r := ratings.New(nil)
+	if *db != "" {
+		store, err := storage.Open(*db)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		r = ratings.New(store)
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	secrets := make(mm.CodeSlice, *games)
+	for i := range secrets {
+		secrets[i] = s.CodeAt(rng.Intn(s.NumCodes()))
+	}
+	if err := tournament(r, names, s, secrets, *seed); err != nil {
+		return err
+	}
+	return printLeaderboard(os.Stdout, r, len(names))
+}
+
+// tournament has every solver break each secret, and rates each pair on
+// each secret by who took fewer moves.  Failing to solve loses to solving.
+func tournament(r *ratings.Ratings, names []string, size mm.GameSize, secrets mm.CodeSlice, seed int64) error {
+	for i, secret := range secrets {
+		moves := make([]int, len(names))
+		for j, name := range names {
+			res := mm.Play(size, secret, benchSolvers[name](seed+int64(i)+1, solver.MinMax))
+			moves[j] = len(res.Moves)
+			if !res.Solved {
+				moves[j] = math.MaxInt32
+			}
+		}
+
+		for a := range names {
+			for b := a + 1; b < len(names); b++ {
+				score := 0.5
+				switch {
+				case moves[a] < moves[b]:
+					score = 1
+				case moves[a] > moves[b]:
+					score = 0
+				}
+				if _, _, err := r.Record(names[a], names[b], score); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func printLeaderboard(w io.Writer, r *ratings.Ratings, limit int) error {
+	board, err := r.Leaderboard(limit)
+	if err != nil {
+		return err
+	}
+	for i, rating := range board {
+		fmt.Fprintf(w, "%2d. %-12s %6.0f  %d-%d-%d\n", i+1, rating.Name, rating.Rating, rating.Wins, rating.Losses, rating.Draws)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/ratings"
+)
+
+func TestTournament(t *testing.T) {
+	size := mm.GameSize{Positions: 4, Colors: 6}
+	r := ratings.New(nil)
+	if err := tournament(r, []string{"knuth", "genetic"}, size, size.AllCodes()[100:110], 1); err != nil {
+		t.Fatal(err)
+	}
+	k, _ := r.Get("knuth")
+	g, _ := r.Get("genetic")
+	if k.Games != 10 || g.Games != 10 || k.Wins != g.Losses || math.Abs(k.Rating+g.Rating-2*ratings.Initial) > 1e-9 {
+		t.Errorf("expected ten rated games, got %+v %+v", k, g)
+	}
+
+	out := &bytes.Buffer{}
+	printLeaderboard(out, r, 2)
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[0], "1.") {
+		t.Errorf("expected a leaderboard of two, got\n%s", out)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/profile"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+func treeCommand(args []string) (err error) {
+	fs := flag.NewFlagSet("tree", flag.ContinueOnError)
+	size := fs.String("size", "4x6", "board size")
+	out := fs.String("o", "", "file to write the tree to")
+	check := fs.String("check", "", "read a tree from this file and check it, instead of working one out")
+	var prof profile.Profile
+	prof.AddFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := prof.Start(); err != nil {
+		return err
+	}
+	defer func() {
+		if stopErr := prof.Stop(); err == nil {
+			err = stopErr
+		}
+	}()
+
+	var tree *solver.Tree
+	if *check != "" {
+		f, err := os.Open(*check)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if tree, err = solver.ReadTree(f); err != nil {
+			return err
+		}
+	} else {
+		s, err := mm.ParseGameSize(*size)
+		if err != nil {
+			return err
+		}
+		game := &solver.Solver{Game: mm.NewCustomGame(s.Positions, s.Colors)}
+		if tree, err = game.Tree(); err != nil {
+			return err
+		}
+	}
+
+	if err := summarizeTree(os.Stdout, tree); err != nil {
+		return err
+	}
+	if *out == "" {
+		return nil
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := tree.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// summarizeTree plays the tree against every secret, failing with the
+// branch which loses if it can't win one, and compares it to the optimum
+// if that's known
+func summarizeTree(w io.Writer, tree *solver.Tree) error {
+	if err := tree.Validate(); err != nil {
+		return err
+	}
+	counts, err := tree.MoveCounts()
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(w, "%v tree opening %v: %d nodes, wins all %d secrets in at most %d moves, %.3f on average\n",
+		tree.Size, tree.Root.Guess, tree.Nodes(), counts.Secrets, counts.Worst(), counts.Expected()); err != nil {
+		return err
+	}
+	if tree.Feedback != mm.FullFeedback {
+		return nil
+	}
+	deviations, err := solver.Deviations("", counts)
+	if err != nil {
+		return err
+	}
+	for _, d := range deviations {
+		if _, err := fmt.Fprintf(w, "  %v\n", d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+func TestSummarizeTree(t *testing.T) {
+	game := &solver.Solver{Game: mm.NewCustomGame(3, 3)}
+	tree, err := game.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := summarizeTree(&out, tree); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "wins all 27 secrets") {
+		t.Errorf("unexpected summary %q", out.String())
+	}
+
+	// a tree missing a branch can't win every game
+	for r := range tree.Root.Next {
+		delete(tree.Root.Next, r)
+		break
+	}
+	if err := summarizeTree(&out, tree); err == nil || !strings.Contains(err.Error(), "no branch for") {
+		t.Errorf("expected a broken tree to fail with the branch missing, got %v", err)
+	}
+}
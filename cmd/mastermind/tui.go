@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+	"golang.org/x/term"
+)
+
+func tuiCommand(args []string) error {
+	fs := flag.NewFlagSet("tui", flag.ContinueOnError)
+	size := fs.String("size", "4x6", "board size")
+	guesses := fs.Int("guesses", 10, "guesses allowed before the game is lost")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	s, err := mm.ParseGameSize(*size)
+	if err != nil {
+		return err
+	}
+	if s.Colors > 10 {
+		return fmt.Errorf("the board can only be drawn with up to 10 colors")
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("tui needs a terminal; try mastermind play")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+	// hide the cursor, and put it back with the screen cleared on the way out
+	fmt.Print("\x1b[?25l")
+	defer fmt.Print("\x1b[H\x1b[2J\x1b[?25h")
+
+	b := newBoard(s, *guesses, nil)
+	in := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print(b.render(drawer{}))
+		k, err := readKey(in)
+		if err != nil {
+			return err
+		}
+		if b.key(k) {
+			return nil
+		}
+	}
+}
+
+// key is a key pressed: the character typed, or one of the special keys
+type key rune
+
+const (
+	keyUp key = -1 - iota
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyQuit
+)
+
+// readKey reads a key from a terminal in raw mode
+func readKey(r *bufio.Reader) (key, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch c {
+	case 3, 4:
+		return keyQuit, nil
+	case '\r', '\n':
+		return keyEnter, nil
+	case 0x1b:
+		// arrows are ESC [ or ESC O, then A to D
+		if r.Buffered() < 2 {
+			return key(c), nil
+		}
+		if b, _ := r.Peek(1); b[0] != '[' && b[0] != 'O' {
+			return key(c), nil
+		}
+		r.ReadByte()
+		d, _ := r.ReadByte()
+		switch d {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		case 'C':
+			return keyRight, nil
+		case 'D':
+			return keyLeft, nil
+		}
+		return key(d), nil
+	}
+	return key(c), nil
+}
+
+// board is the state of the tui: the game, and the guess being entered
+type board struct {
+	size    mm.GameSize
+	guesses int
+	// newGame makes each game; a random secret unless set
+	newGame func(size mm.GameSize) *mm.Game
+
+	game    *mm.Game
+	history mm.History
+	entry   mm.Code
+	cursor  int
+	message string
+	over    bool
+}
+
+func newBoard(size mm.GameSize, guesses int, newGame func(mm.GameSize) *mm.Game) *board {
+	if newGame == nil {
+		newGame = func(size mm.GameSize) *mm.Game {
+			return mm.NewCustomGame(size.Positions, size.Colors)
+		}
+	}
+	b := &board{size: size, guesses: guesses, newGame: newGame}
+	b.reset()
+	return b
+}
+
+func (b *board) reset() {
+	b.game = b.newGame(b.size)
+	b.game.Quiet = true
+	b.history = nil
+	b.entry = make(mm.Code, b.size.Positions)
+	b.cursor = 0
+	b.message = ""
+	b.over = false
+}
+
+// key acts on a key, reporting whether it's time to quit
+func (b *board) key(k key) bool {
+	if k == keyQuit || k == 'q' {
+		return true
+	}
+	if b.over {
+		if k == 'n' {
+			b.reset()
+		}
+		return false
+	}
+
+	b.message = ""
+	P, C := b.size.Positions, b.size.Colors
+	switch {
+	case k == keyLeft:
+		b.cursor = (b.cursor + P - 1) % P
+	case k == keyRight:
+		b.cursor = (b.cursor + 1) % P
+	case k == keyUp:
+		b.entry[b.cursor] = (b.entry[b.cursor] + 1) % C
+	case k == keyDown:
+		b.entry[b.cursor] = (b.entry[b.cursor] + C - 1) % C
+	case k >= '0' && k <= '9' && byte(k-'0') < C:
+		b.entry[b.cursor] = byte(k - '0')
+		if b.cursor < P-1 {
+			b.cursor++
+		}
+	case k == keyEnter:
+		b.submit()
+	case k == 'h':
+		b.hint()
+	}
+	return false
+}
+
+func (b *board) submit() {
+	guess := append(mm.Code{}, b.entry...)
+	r, err := b.game.ScoredGuess(guess)
+	if err != nil {
+		b.message = err.Error()
+		return
+	}
+	b.history = append(b.history, mm.Move{Guess: guess, Result: r})
+	b.cursor = 0
+
+	switch {
+	case b.game.IsWin(r):
+		b.over = true
+		b.message = fmt.Sprintf("Solved in %d guesses!  n for a new game, q to quit", len(b.history))
+	case len(b.history) >= b.guesses:
+		b.over = true
+		b.message = fmt.Sprintf("Out of guesses; the secret was %s.  n for a new game, q to quit", b.game.Secret())
+	}
+}
+
+// hint puts the solver's next guess into the entry
+func (b *board) hint() {
+	s := &solver.Solver{Game: mm.NewCustomGame(b.size.Positions, b.size.Colors)}
+	guess, err := s.Step(b.history)
+	if err != nil {
+		b.message = err.Error()
+		return
+	}
+	copy(b.entry, guess)
+	b.message = fmt.Sprintf("Hint: %s, with %d codes still possible", guess, len(s.Possible(b.history)))
+}
+
+// render draws the whole screen: a row per guess with its result pins
+// beside it, the entry row under the guesses so far, and the help
+func (b *board) render(d drawer) string {
+	var sb strings.Builder
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(&sb, format, args...)
+		sb.WriteString("\x1b[K\r\n")
+	}
+	sb.WriteString("\x1b[H")
+	line("  MASTERMIND %v", b.size)
+	line("")
+
+	for i := 0; i < b.guesses; i++ {
+		row := make([]string, b.size.Positions)
+		pins, marker := "", " "
+		switch {
+		case i < len(b.history):
+			for j, c := range b.history[i].Guess {
+				row[j] = " " + d.peg(c) + " "
+			}
+			pins = d.pins(b.history[i].Result, b.size.Positions)
+		case i == len(b.history) && !b.over:
+			marker = ">"
+			for j, c := range b.entry {
+				row[j] = " " + d.peg(c) + " "
+				if j == b.cursor {
+					row[j] = "[" + d.peg(c) + "]"
+				}
+			}
+		default:
+			for j := range row {
+				row[j] = " · "
+			}
+		}
+		line(" %s %2d %s  %s", marker, i+1, strings.Join(row, ""), pins)
+	}
+
+	line("")
+	line("  %s", d.legend(b.size.Colors))
+	if b.over {
+		line("  n new game   q quit")
+	} else {
+		line("  ←→ move   ↑↓ color   0-%d set   enter guess   h hint   q quit", b.size.Colors-1)
+	}
+	line("")
+	line("  %s", b.message)
+	sb.WriteString("\x1b[J")
+	return sb.String()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestReadKey(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("\x1b[A\x1b[B\x1b[C\x1bOD\r3h\x03\x1b"))
+	expected := []key{keyUp, keyDown, keyRight, keyLeft, keyEnter, '3', 'h', keyQuit, 0x1b}
+	for _, e := range expected {
+		if k, err := readKey(r); err != nil || k != e {
+			t.Errorf("expected key %d, got %d (%v)", e, k, err)
+		}
+	}
+}
+
+func TestBoard(t *testing.T) {
+	secret := mm.Code{3, 1, 5, 2}
+	b := newBoard(mm.GameSize{Positions: 4, Colors: 6}, 10, func(size mm.GameSize) *mm.Game {
+		return mm.NewCustomGameWithSecret(size.Positions, size.Colors, secret)
+	})
+	press := func(keys ...key) {
+		for _, k := range keys {
+			if b.key(k) {
+				t.Fatalf("quit on key %d", k)
+			}
+		}
+	}
+
+	// digits advance the cursor; arrows move it and change colors
+	press('1', '2', keyLeft, keyUp, keyUp, keyRight, '5', keyDown, keyDown, keyEnter)
+	if len(b.history) != 1 || b.history[0].Guess.String() != "1454" {
+		t.Fatalf("expected 1454 guessed, got %v", b.history)
+	}
+	if !strings.Contains(b.render(drawer{plain: true}), "1  4  5  4   bw··") {
+		t.Errorf("expected the guess and its pins drawn:\n%s", b.render(drawer{plain: true}))
+	}
+
+	// the hint is loaded, ready to enter
+	press('h')
+	if !strings.Contains(b.message, "Hint: "+b.entry.String()) {
+		t.Errorf("expected a consistent hint, got %v: %s", b.entry, b.message)
+	}
+
+	for _, c := range secret {
+		press(key('0' + c))
+	}
+	press(keyEnter)
+	if !b.over || !strings.Contains(b.message, "Solved in 2") {
+		t.Errorf("expected a win, got %q", b.message)
+	}
+	press('n')
+	if b.over || len(b.history) != 0 {
+		t.Errorf("expected a new game")
+	}
+	if !b.key('q') {
+		t.Errorf("expected q to quit")
+	}
+}
+
+func TestBoardLost(t *testing.T) {
+	b := newBoard(mm.GameSize{Positions: 2, Colors: 3}, 2, func(size mm.GameSize) *mm.Game {
+		return mm.NewCustomGameWithSecret(size.Positions, size.Colors, mm.Code{2, 2})
+	})
+	b.key(keyEnter)
+	b.key(keyEnter)
+	if !b.over || !strings.Contains(b.message, "the secret was 22") {
+		t.Errorf("expected a loss, got %q", b.message)
+	}
+	if strings.Contains(b.render(drawer{plain: true}), ">") {
+		t.Errorf("expected no entry row once the game is over")
+	}
+}
@@ -0,0 +1,37 @@
+//go:build js && wasm
+
+// Command mmwasm runs package engine in a browser.  Built with
+//
+//	GOOS=js GOARCH=wasm go build -o mastermind.wasm ./cmd/mmwasm
+//
+// and loaded with Go's wasm_exec.js, it sets a global mastermind object
+// with score, candidates and hint functions, each taking a request as a
+// JSON string and returning the response as one:
+//
+//	mastermind.hint('{"size": "4x6", "moves": [{"guess": "0011", "black": 1, "white": 1}]}')
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/ianmcmahon/mastermind/engine"
+)
+
+func wrap(f func(string) string) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if len(args) != 1 || args[0].Type() != js.TypeString {
+			return `{"error":"expected a JSON string"}`
+		}
+		return f(args[0].String())
+	})
+}
+
+func main() {
+	js.Global().Set("mastermind", js.ValueOf(map[string]interface{}{
+		"score":      wrap(engine.Score),
+		"candidates": wrap(engine.Candidates),
+		"hint":       wrap(engine.Hint),
+	}))
+	// the functions are called from JavaScript for as long as the page lives
+	select {}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/ianmcmahon/mastermind/server"
+)
+
+const clientHeader = `// Code generated by openapigen from the server's OpenAPI document. DO NOT EDIT.
+
+// Package client is a typed client of the REST API of package server.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client calls the API at BaseURL, eg http://localhost:8080, in the
+// session with token Session, if it's set, with the API key APIKey, if
+// the server takes them
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+	Session string
+	APIKey  string
+}
+
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), HTTP: http.DefaultClient}
+}
+
+// Error is a failure the API reported
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, http.StatusText(e.Status), e.Message)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return err
+		}
+	}
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, &body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Session != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Session)
+	}
+	if c.APIKey != "" {
+		req.Header.Set("X-API-Key", c.APIKey)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error string ` + "`json:\"error\"`" + `
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return &Error{Status: resp.StatusCode, Message: e.Error}
+	}
+	// bodies which aren't JSON are returned as they are
+	if b, ok := out.(*[]byte); ok {
+		*b, err = ioutil.ReadAll(resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// websocketURL is the ws: or wss: URL of path
+func (c *Client) websocketURL(path string, query url.Values) string {
+	if c.Session != "" || c.APIKey != "" {
+		if query == nil {
+			query = url.Values{}
+		}
+	}
+	if c.Session != "" {
+		query.Set("session", c.Session)
+	}
+	if c.APIKey != "" {
+		query.Set("api_key", c.APIKey)
+	}
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	if strings.HasPrefix(u, "https:") {
+		return "wss:" + strings.TrimPrefix(u, "https:")
+	}
+	return "ws:" + strings.TrimPrefix(u, "http:")
+}
+`
+
+// generateClient writes package client from spec: a type for each schema
+// and a method for each operation
+func generateClient(spec *server.Spec) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(clientHeader)
+
+	names := []string{}
+	for name := range spec.Components.Schemas {
+		// failures are returned as *Error
+		if name != "Error" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeType(&buf, name, spec.Components.Schemas[name])
+	}
+
+	paths := []string{}
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for _, method := range []string{"get", "post", "put", "patch", "delete"} {
+			if op, ok := spec.Paths[path][method]; ok {
+				if err := writeMethod(&buf, strings.ToUpper(method), path, op); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting client: %v", err)
+	}
+	return src, nil
+}
+
+func writeType(buf *bytes.Buffer, name string, s *server.Schema) {
+	required := map[string]bool{}
+	for _, r := range s.Required {
+		required[r] = true
+	}
+	fmt.Fprintf(buf, "\ntype %s struct {\n", name)
+	for _, p := range s.Properties {
+		tag := p.Name
+		if !required[p.Name] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(buf, "\t%s %s `json:%q`\n", goName(p.Name), goType(p.Schema), tag)
+	}
+	buf.WriteString("}\n")
+}
+
+// goName is the exported Go name of a JSON field or parameter
+func goName(name string) string {
+	if name == "id" {
+		return "ID"
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func goType(s *server.Schema) string {
+	switch {
+	case s.Ref != "":
+		return s.ComponentName()
+	case s.Type == "array" && s.MinItems != nil && s.MaxItems != nil && *s.MinItems == *s.MaxItems:
+		return fmt.Sprintf("[%d]%s", *s.MinItems, goType(s.Items))
+	case s.Type == "array":
+		return "[]" + goType(s.Items)
+	case s.Type == "integer":
+		return "int"
+	case s.Type == "number":
+		return "float64"
+	case s.Type == "boolean":
+		return "bool"
+	case s.Type == "string":
+		return "string"
+	}
+	return "interface{}"
+}
+
+func writeMethod(buf *bytes.Buffer, method, path string, op *server.Operation) error {
+	args := []string{}
+	if !op.Websocket {
+		args = append(args, "ctx context.Context")
+	}
+	query := []server.Parameter{}
+	for _, p := range op.Parameters {
+		args = append(args, p.Name+" "+goType(p.Schema))
+		if p.In == "query" {
+			query = append(query, p)
+		}
+	}
+	if op.RequestBody != nil {
+		args = append(args, "req "+goType(op.RequestBody.Content["application/json"].Schema))
+	}
+
+	// the path as a Go expression, with its parameters escaped
+	expr := []string{}
+	for _, part := range strings.SplitAfter(path, "/") {
+		if strings.HasPrefix(part, "{") {
+			expr = append(expr, fmt.Sprintf("url.PathEscape(%s)", strings.Trim(part, "{}/")))
+			if strings.HasSuffix(part, "/") {
+				expr = append(expr, `"/"`)
+			}
+		} else {
+			expr = append(expr, fmt.Sprintf("%q", part))
+		}
+	}
+	pathExpr := strings.Replace(strings.Join(expr, " + "), `" + "`, "", -1)
+
+	summary := strings.ToLower(op.Summary[:1]) + op.Summary[1:]
+	fmt.Fprintf(buf, "\n// %s", op.OperationID)
+	var result string
+	if op.Websocket {
+		fmt.Fprintf(buf, "URL is the websocket URL of %s %s: %s\n", method, path, summary)
+		fmt.Fprintf(buf, "func (c *Client) %sURL(%s) string {\n", op.OperationID, strings.Join(args, ", "))
+	} else {
+		var resp *server.Response
+		for code, r := range op.Responses {
+			if code != "default" {
+				resp = r
+			}
+		}
+		if resp == nil {
+			return fmt.Errorf("%s %s has no response", method, path)
+		}
+		if content, ok := resp.Content["application/json"]; ok {
+			result = goType(content.Schema)
+			if content.Schema.Ref != "" {
+				result = "*" + result
+			}
+		} else {
+			result = "[]byte"
+		}
+		fmt.Fprintf(buf, " is %s %s: %s\n", method, path, summary)
+		fmt.Fprintf(buf, "func (c *Client) %s(%s) (%s, error) {\n", op.OperationID, strings.Join(args, ", "), result)
+		fmt.Fprintf(buf, "var out %s\n", strings.TrimPrefix(result, "*"))
+	}
+
+	queryArg := "nil"
+	if len(query) > 0 {
+		queryArg = "query"
+		buf.WriteString("query := url.Values{}\n")
+		for _, p := range query {
+			if p.Schema.Type == "integer" {
+				fmt.Fprintf(buf, "if %[1]s != 0 {\nquery.Set(%[1]q, fmt.Sprint(%[1]s))\n}\n", p.Name)
+			} else {
+				fmt.Fprintf(buf, "if %[1]s != \"\" {\nquery.Set(%[1]q, %[1]s)\n}\n", p.Name)
+			}
+		}
+	}
+
+	if op.Websocket {
+		fmt.Fprintf(buf, "return c.websocketURL(%s, %s)\n}\n", pathExpr, queryArg)
+		return nil
+	}
+	body := "nil"
+	if op.RequestBody != nil {
+		body = "req"
+	}
+	fmt.Fprintf(buf, "if err := c.do(ctx, %q, %s, %s, %s, &out); err != nil {\n", method, pathExpr, queryArg, body)
+	if strings.HasPrefix(result, "*") {
+		buf.WriteString("return nil, err\n}\nreturn &out, nil\n}\n")
+	} else {
+		buf.WriteString("return nil, err\n}\nreturn out, nil\n}\n")
+	}
+	return nil
+}
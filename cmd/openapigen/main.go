@@ -0,0 +1,52 @@
+// Command openapigen writes the OpenAPI document of package server's REST
+// API, and a typed Go client of it.
+//
+//	openapigen -spec server/openapi.json -client client/client.go
+//
+// It's run by go generate in package server.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/ianmcmahon/mastermind/server"
+)
+
+func main() {
+	specPath := flag.String("spec", "openapi.json", "where to write the OpenAPI document")
+	clientPath := flag.String("client", "client.go", "where to write the client")
+	flag.Parse()
+
+	spec := server.OpenAPI()
+	doc, err := marshalSpec(spec)
+	if err != nil {
+		fail(err)
+	}
+	src, err := generateClient(spec)
+	if err != nil {
+		fail(err)
+	}
+	if err := ioutil.WriteFile(*specPath, doc, 0644); err != nil {
+		fail(err)
+	}
+	if err := ioutil.WriteFile(*clientPath, src, 0644); err != nil {
+		fail(err)
+	}
+}
+
+func marshalSpec(spec *server.Spec) ([]byte, error) {
+	doc, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(doc, '\n'), nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "openapigen:", err)
+	os.Exit(1)
+}
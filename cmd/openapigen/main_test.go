@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ianmcmahon/mastermind/server"
+)
+
+// the committed document and client must be what the routes generate now
+func TestUpToDate(t *testing.T) {
+	spec := server.OpenAPI()
+	doc, err := marshalSpec(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := generateClient(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string][]byte{
+		"../../server/openapi.json": doc,
+		"../../client/client.go":    src,
+	} {
+		got, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is out of date; run go generate ./server", path)
+		}
+	}
+}
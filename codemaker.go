@@ -0,0 +1,18 @@
+package mastermind
+
+// Codemaker scores guesses against a secret.  *Game is the usual codemaker,
+// but anything which can answer guesses will do: a person entering results
+// by hand, a remote game, or an adversary.
+type Codemaker interface {
+	GameSize() GameSize
+	ScoredGuess(code Code) (Result, error)
+}
+
+// Solver is implemented by every codebreaker.  Solve plays until the secret
+// is found, returning it.
+type Solver interface {
+	Solve() (Code, error)
+}
+
+// SolverFunc makes a solver which plays against cm
+type SolverFunc func(cm Codemaker) Solver
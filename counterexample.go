@@ -0,0 +1,54 @@
+package mastermind
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Counterexample searches for the secret which forces the solvers made by
+// newSolver to take the most moves, returning it and its move count.
+//
+// The search starts by playing the solver against an EvilCodemaker, whose
+// final answer is usually the worst case or close to it.  Every secret is
+// then played for real, in order of how long the evil codemaker kept it
+// consistent, so the hardest looking secrets come first.  If limit is
+// positive the search stops as soon as a secret taking at least limit moves
+// is found, which makes disproving a claimed bound quick; otherwise every
+// secret is played and the result is the solver's true worst case.
+func Counterexample(size GameSize, newSolver SolverFunc, limit int) (Code, int, error) {
+	evil := NewEvilCodemaker(size)
+	if _, err := newSolver(evil).Solve(); err != nil {
+		return nil, 0, fmt.Errorf("playing the evil codemaker: %v", err)
+	}
+
+	secrets := size.AllCodes()
+	sort.SliceStable(secrets, func(i, j int) bool {
+		ei, ej := evil.Eliminated(secrets[i]), evil.Eliminated(secrets[j])
+		// still consistent is as late as it gets
+		if ei == 0 {
+			return ej != 0
+		}
+		return ej != 0 && ei > ej
+	})
+
+	var worst Code
+	worstMoves := 0
+	for _, secret := range secrets {
+		game := NewCustomGameWithSecret(size.Positions, size.Colors, secret)
+		winner, err := newSolver(game).Solve()
+		if err != nil {
+			return secret, game.TurnsTaken, fmt.Errorf("solving %s: %v", secret, err)
+		}
+		if !game.IsWinner(winner) {
+			return secret, game.TurnsTaken, fmt.Errorf("solving %s: got wrong answer %s", secret, winner)
+		}
+		if game.TurnsTaken > worstMoves {
+			worst, worstMoves = secret, game.TurnsTaken
+		}
+		if limit > 0 && worstMoves >= limit {
+			break
+		}
+	}
+
+	return worst, worstMoves, nil
+}
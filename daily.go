@@ -0,0 +1,98 @@
+package mastermind
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// DailyEpoch is the date of daily puzzle #1
+var DailyEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// date is t's calendar date, at midnight UTC, so days can be counted
+// without daylight saving getting in the way
+func date(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// DailyNumber is the number of the puzzle on day's date, in day's location
+func DailyNumber(day time.Time) int {
+	return int(date(day).Sub(DailyEpoch).Hours()/24) + 1
+}
+
+// DailySecret is the secret of the puzzle on day's date, in day's location.
+// Everyone playing a size on the same date gets the same secret, and anyone
+// with this source can work it out; SaltedDailySecret is one they can't.
+func DailySecret(size GameSize, day time.Time) Code {
+	return SaltedDailySecret(size, day, "")
+}
+
+// SaltedDailySecret is DailySecret kept secret by salt: without the salt,
+// knowing the date and size is no help guessing it.  An empty salt is
+// DailySecret's.
+func SaltedDailySecret(size GameSize, day time.Time, salt string) Code {
+	puzzle := fmt.Sprintf("%s %v", date(day).Format("2006-01-02"), size)
+	var seed uint64
+	if salt == "" {
+		h := fnv.New64a()
+		h.Write([]byte(puzzle))
+		seed = h.Sum64()
+	} else {
+		h := hmac.New(sha256.New, []byte(salt))
+		h.Write([]byte(puzzle))
+		seed = binary.BigEndian.Uint64(h.Sum(nil))
+	}
+	r := rand.New(rand.NewSource(int64(seed)))
+
+	code := make(Code, size.Positions)
+	for i := range code {
+		code[i] = byte(r.Intn(int(size.Colors)))
+	}
+	return code
+}
+
+// NewDailyGame is a game of day's puzzle
+func NewDailyGame(size GameSize, day time.Time) *Game {
+	return NewSaltedDailyGame(size, day, "")
+}
+
+// NewSaltedDailyGame is a game of day's puzzle, salted with salt
+func NewSaltedDailyGame(size GameSize, day time.Time, salt string) *Game {
+	return NewCustomGameWithSecret(size.Positions, size.Colors, SaltedDailySecret(size, day, salt))
+}
+
+// ShareGrid writes up a game for sharing without giving the secret away, a
+// row of pins for each move: 🟩 for a black pin, 🟨 for a white and ⬜ for
+// none.  The header gives the puzzle number, if it's a daily one, and the
+// moves taken out of the guesses allowed, or X if it wasn't solved.
+//
+//	Mastermind #42 4x6 3/10
+//	🟩🟨⬜⬜
+//	🟩🟩🟨🟨
+//	🟩🟩🟩🟩
+func ShareGrid(size GameSize, number int, h History, guesses int) string {
+	var b strings.Builder
+	b.WriteString("Mastermind")
+	if number > 0 {
+		fmt.Fprintf(&b, " #%d", number)
+	}
+	score := "X"
+	if len(h) > 0 && h[len(h)-1].Result.Correct == size.Positions {
+		score = fmt.Sprint(len(h))
+	}
+	fmt.Fprintf(&b, " %v %s/%d\n", size, score, guesses)
+
+	for _, m := range h {
+		b.WriteString(strings.Repeat("🟩", m.Result.Correct))
+		b.WriteString(strings.Repeat("🟨", m.Result.HalfCorrect))
+		b.WriteString(strings.Repeat("⬜", size.Positions-m.Result.Correct-m.Result.HalfCorrect))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,224 @@
+// Package engine is the scoring and hint logic with JSON in and out, for
+// callers outside Go, chiefly browsers running it as WebAssembly through
+// cmd/mmwasm.  It does everything on the calling goroutine and never
+// writes to stdout, so it needs nothing a browser doesn't have.
+//
+// Each function takes a Request as JSON and returns its response as JSON,
+// with an error field set if the request was no good.
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// MaxCodes is the most codes a board may have to list candidates or give
+// hints on; bigger boards take too long to enumerate
+const MaxCodes = 1 << 20
+
+// maxWork caps the codes scored to choose a hint.  Past it, the guess is
+// chosen among the candidates rather than every code, and past that the
+// first candidate is taken.
+const maxWork = 4000000
+
+type Request struct {
+	// Size is the board, eg 4x6
+	Size   string `json:"size"`
+	Guess  string `json:"guess,omitempty"`
+	Secret string `json:"secret,omitempty"`
+	// Moves are the game so far
+	Moves []Move `json:"moves,omitempty"`
+	// Limit caps the codes Candidates lists; 100 if left out
+	Limit int `json:"limit,omitempty"`
+}
+
+type Move struct {
+	Guess string `json:"guess"`
+	Black int    `json:"black"`
+	White int    `json:"white"`
+}
+
+type ScoreResponse struct {
+	Black int    `json:"black"`
+	White int    `json:"white"`
+	Won   bool   `json:"won"`
+	Error string `json:"error,omitempty"`
+}
+
+type CandidatesResponse struct {
+	// Remaining is how many codes could be the secret, and Codes the first
+	// of them, in order
+	Remaining int      `json:"remaining"`
+	Codes     []string `json:"codes"`
+	Error     string   `json:"error,omitempty"`
+}
+
+type HintResponse struct {
+	Hint      string `json:"hint"`
+	Remaining int    `json:"remaining"`
+	Error     string `json:"error,omitempty"`
+}
+
+// respond is resp as JSON, with err in it if there's one
+func respond(resp interface{}, err error) string {
+	if err != nil {
+		resp = struct {
+			Error string `json:"error"`
+		}{err.Error()}
+	}
+	b, _ := json.Marshal(resp)
+	return string(b)
+}
+
+// parse reads a request, with its board size and moves
+func parse(req string) (*Request, mm.GameSize, mm.History, error) {
+	var r Request
+	if err := json.Unmarshal([]byte(req), &r); err != nil {
+		return nil, mm.GameSize{}, nil, fmt.Errorf("bad request: %v", err)
+	}
+	size, err := mm.ParseGameSize(r.Size)
+	if err != nil {
+		return nil, mm.GameSize{}, nil, err
+	}
+	var h mm.History
+	for _, m := range r.Moves {
+		guess, err := code(m.Guess, size)
+		if err != nil {
+			return nil, size, nil, err
+		}
+		h = append(h, mm.Move{Guess: guess, Result: mm.Result{Correct: m.Black, HalfCorrect: m.White}})
+	}
+	return &r, size, h, nil
+}
+
+// code reads a code written as digits, as mm.Code.String writes them
+func code(s string, size mm.GameSize) (mm.Code, error) {
+	if len(s) != size.Positions {
+		return nil, fmt.Errorf("code %q isn't %d pegs", s, size.Positions)
+	}
+	c := make(mm.Code, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || int(s[i]-'0') >= int(size.Colors) {
+			return nil, fmt.Errorf("code %q has a color out of range", s)
+		}
+		c[i] = s[i] - '0'
+	}
+	return c, nil
+}
+
+// Score scores the request's guess against its secret
+func Score(req string) string {
+	r, size, _, err := parse(req)
+	if err != nil {
+		return respond(nil, err)
+	}
+	guess, err := code(r.Guess, size)
+	if err != nil {
+		return respond(nil, err)
+	}
+	secret, err := code(r.Secret, size)
+	if err != nil {
+		return respond(nil, err)
+	}
+	result, err := mm.CheckCode(guess, secret, size.Colors)
+	if err != nil {
+		return respond(nil, err)
+	}
+	return respond(ScoreResponse{Black: result.Correct, White: result.HalfCorrect, Won: result.Correct == size.Positions}, nil)
+}
+
+// possible is the codes consistent with h, in order
+func possible(size mm.GameSize, h mm.History) (mm.CodeSlice, error) {
+	if size.NumCodes() > MaxCodes {
+		return nil, fmt.Errorf("a %v board has too many codes", size)
+	}
+	S := mm.CodeSlice{}
+	for i := 0; i < size.NumCodes(); i++ {
+		if c := size.CodeAt(i); h.Consistent(c, size.Colors) {
+			S = append(S, c)
+		}
+	}
+	if len(S) == 0 {
+		return nil, fmt.Errorf("no code gives all those results")
+	}
+	return S, nil
+}
+
+// Candidates lists the codes which could still be the secret after the
+// request's moves
+func Candidates(req string) string {
+	r, size, h, err := parse(req)
+	if err != nil {
+		return respond(nil, err)
+	}
+	S, err := possible(size, h)
+	if err != nil {
+		return respond(nil, err)
+	}
+	limit := r.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	resp := CandidatesResponse{Remaining: len(S), Codes: []string{}}
+	for i := 0; i < len(S) && i < limit; i++ {
+		resp.Codes = append(resp.Codes, S[i].String())
+	}
+	return respond(resp, nil)
+}
+
+// Hint chooses the next guess after the request's moves, the one whose
+// worst result leaves fewest codes, as Knuth's solver does
+func Hint(req string) string {
+	_, size, h, err := parse(req)
+	if err != nil {
+		return respond(nil, err)
+	}
+	S, err := possible(size, h)
+	if err != nil {
+		return respond(nil, err)
+	}
+	return respond(HintResponse{Hint: hint(size, S).String(), Remaining: len(S)}, nil)
+}
+
+func hint(size mm.GameSize, S mm.CodeSlice) mm.Code {
+	if len(S) <= 2 {
+		return S[0]
+	}
+	P := S
+	if size.NumCodes()*len(S) <= maxWork {
+		P = size.Codes(0, size.NumCodes(), 1)
+	} else if len(S)*len(S) > maxWork {
+		return S[0]
+	}
+	inS := map[mm.CodeKey]bool{}
+	for _, c := range S {
+		inS[c.Key()] = true
+	}
+
+	// results index a table by black*(positions+1)+white
+	counts := make([]int, (size.Positions+1)*(size.Positions+1))
+	var best mm.Code
+	bestWorst, bestInS := len(S)+1, false
+	for _, guess := range P {
+		for i := range counts {
+			counts[i] = 0
+		}
+		worst := 0
+		for _, s := range S {
+			r := mm.Score(guess, s, size.Colors)
+			i := r.Correct*(size.Positions+1) + r.HalfCorrect
+			counts[i]++
+			if counts[i] > worst {
+				worst = counts[i]
+			}
+		}
+		// ties go to a code which could win, then the first
+		in := inS[guess.Key()]
+		if worst < bestWorst || worst == bestWorst && in && !bestInS {
+			best, bestWorst, bestInS = guess, worst, in
+		}
+	}
+	return best
+}
@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+func TestScore(t *testing.T) {
+	var resp ScoreResponse
+	json.Unmarshal([]byte(Score(`{"size":"4x6","guess":"0011","secret":"0123"}`)), &resp)
+	if resp != (ScoreResponse{Black: 1, White: 1}) {
+		t.Errorf("expected 1-1, got %+v", resp)
+	}
+	json.Unmarshal([]byte(Score(`{"size":"4x6","guess":"0123","secret":"0123"}`)), &resp)
+	if !resp.Won {
+		t.Errorf("expected a win, got %+v", resp)
+	}
+
+	for _, bad := range []string{`{`, `{"size":"4"}`, `{"size":"4x6","guess":"0016","secret":"0123"}`, `{"size":"4x6","guess":"0011"}`} {
+		if out := Score(bad); !strings.HasPrefix(out, `{"error":`) {
+			t.Errorf("%s: expected an error, got %s", bad, out)
+		}
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	var resp CandidatesResponse
+	json.Unmarshal([]byte(Candidates(`{"size":"4x6","moves":[{"guess":"0011","black":2,"white":2}],"limit":3}`)), &resp)
+	if resp.Remaining != 4 || strings.Join(resp.Codes, ",") != "0101,0110,1001" {
+		t.Errorf("unexpected candidates %+v", resp)
+	}
+	if out := Candidates(`{"size":"4x6","moves":[{"guess":"0011","black":3,"white":1}]}`); !strings.Contains(out, "no code") {
+		t.Errorf("expected impossible moves to fail, got %s", out)
+	}
+	if out := Candidates(`{"size":"10x10"}`); !strings.Contains(out, "too many codes") {
+		t.Errorf("expected a huge board to be refused, got %s", out)
+	}
+}
+
+func TestHint(t *testing.T) {
+	var resp HintResponse
+	json.Unmarshal([]byte(Hint(`{"size":"4x6"}`)), &resp)
+	if resp.Hint != "0011" || resp.Remaining != 1296 {
+		t.Errorf("expected Knuth's opener, got %+v", resp)
+	}
+
+	// the solver's choice is as good as the hint's
+	moves := []Move{{Guess: "0011", Black: 1, White: 1}}
+	req, _ := json.Marshal(Request{Size: "4x6", Moves: moves})
+	json.Unmarshal([]byte(Hint(string(req))), &resp)
+	h := mm.History{{Guess: mm.Code{0, 0, 1, 1}, Result: mm.Result{Correct: 1, HalfCorrect: 1}}}
+	game := &solver.Solver{Game: mm.NewCustomGame(4, 6)}
+	expected, _ := game.Step(h)
+	hint, _ := code(resp.Hint, mm.GameSize{Positions: 4, Colors: 6})
+	p1, _ := game.Partition(h, expected)
+	p2, _ := game.Partition(h, hint)
+	if resp.Remaining != 208 || p1.Largest() != p2.Largest() {
+		t.Errorf("expected a hint as good as %v, got %+v", expected, resp)
+	}
+}
@@ -0,0 +1,90 @@
+package mastermind
+
+import "fmt"
+
+// EvilCodemaker never commits to a secret.  Each guess is answered with the
+// result which keeps the most codes consistent with every answer so far, so
+// a solver facing it takes as many moves as the hardest secret it could have
+// been given.
+type EvilCodemaker struct {
+	Size       GameSize
+	TurnsTaken int
+
+	candidates CodeSlice
+	// the turn each code stopped being a candidate, indexed by Code.Index
+	eliminated []int
+}
+
+func NewEvilCodemaker(size GameSize) *EvilCodemaker {
+	return &EvilCodemaker{
+		Size:       size,
+		candidates: size.AllCodes(),
+		eliminated: make([]int, size.NumCodes()),
+	}
+}
+
+func (e *EvilCodemaker) GameSize() GameSize {
+	return e.Size
+}
+
+func (e *EvilCodemaker) ScoredGuess(guess Code) (Result, error) {
+	if len(guess) != e.Size.Positions {
+		return Result{}, fmt.Errorf("code must have %d positions", e.Size.Positions)
+	}
+	e.TurnsTaken++
+
+	partitions := map[Result]CodeSlice{}
+	for _, c := range e.candidates {
+		r := Score(guess, c, e.Size.Colors)
+		partitions[r] = append(partitions[r], c)
+	}
+
+	win := Result{Correct: e.Size.Positions}
+	var best Result
+	first := true
+	for r, codes := range partitions {
+		if first || e.worse(r, codes, best, partitions[best], win) {
+			best = r
+			first = false
+		}
+	}
+
+	for r, codes := range partitions {
+		if r == best {
+			continue
+		}
+		for _, c := range codes {
+			e.eliminated[c.Index(e.Size.Colors)] = e.TurnsTaken
+		}
+	}
+	e.candidates = partitions[best]
+
+	return best, nil
+}
+
+// is answering r (leaving codes) worse for the solver than answering best?
+// more codes is worse; on a tie, not winning is worse, then fewer pegs, so
+// the answer is deterministic
+func (e *EvilCodemaker) worse(r Result, codes CodeSlice, best Result, bestCodes CodeSlice, win Result) bool {
+	if len(codes) != len(bestCodes) {
+		return len(codes) > len(bestCodes)
+	}
+	if (r == win) != (best == win) {
+		return best == win
+	}
+	if r.Correct != best.Correct {
+		return r.Correct < best.Correct
+	}
+	return r.HalfCorrect < best.HalfCorrect
+}
+
+// Candidates are the codes still consistent with every answer given
+func (e *EvilCodemaker) Candidates() CodeSlice {
+	return e.candidates
+}
+
+// Eliminated returns the turn on which c stopped being consistent with the
+// answers given, or 0 if it still is
+func (e *EvilCodemaker) Eliminated(c Code) int {
+	return e.eliminated[c.Index(e.Size.Colors)]
+}
@@ -0,0 +1,128 @@
+package experiments
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Comparison is how two solvers did against each other on the same
+// secrets of a size, secret by secret
+type Comparison struct {
+	A, B string
+	Size mm.GameSize
+	// Pairs is how many secrets both played
+	Pairs int
+	// Wins, Losses and Ties are the secrets A took fewer moves on than B,
+	// more, and as many.  Failing a secret loses to solving it, and ties
+	// with failing it.
+	Wins, Losses, Ties int
+	// MeanDiff and StddevDiff are of A's moves less B's, over the secrets
+	// both solved
+	MeanDiff, StddevDiff float64
+	// P is the two-sided sign test's p-value, the chance of A and B winning
+	// as unevenly as this if they're as good as each other, with ties left
+	// out
+	P float64
+}
+
+// Compare pairs up the games solvers a and b played on the same secrets,
+// the same round of the same seed, and compares them on each size, in
+// the order the sizes were first played
+func Compare(games []Game, a, b string) []Comparison {
+	type key struct {
+		size  mm.GameSize
+		seed  int64
+		round int
+	}
+	var sizes []mm.GameSize
+	bySize := map[mm.GameSize]*Comparison{}
+	diffs := map[mm.GameSize][]float64{}
+	played := map[key]Game{}
+	for _, g := range games {
+		if g.Solver == a {
+			played[key{g.Size, g.Seed, g.Round}] = g
+		}
+	}
+	for _, gb := range games {
+		ga, ok := played[key{gb.Size, gb.Seed, gb.Round}]
+		if gb.Solver != b || !ok {
+			continue
+		}
+		c := bySize[gb.Size]
+		if c == nil {
+			c = &Comparison{A: a, B: b, Size: gb.Size}
+			bySize[gb.Size] = c
+			sizes = append(sizes, gb.Size)
+		}
+		c.Pairs++
+		d := len(ga.Moves) - len(gb.Moves)
+		switch {
+		case ga.Solved && gb.Solved:
+			diffs[gb.Size] = append(diffs[gb.Size], float64(d))
+		case ga.Solved:
+			d = -1
+		case gb.Solved:
+			d = 1
+		default:
+			d = 0
+		}
+		switch {
+		case d < 0:
+			c.Wins++
+		case d > 0:
+			c.Losses++
+		default:
+			c.Ties++
+		}
+	}
+
+	comparisons := make([]Comparison, len(sizes))
+	for i, size := range sizes {
+		c := bySize[size]
+		c.MeanDiff, c.StddevDiff = meanStddev(diffs[size])
+		c.P = signTest(c.Wins, c.Losses)
+		comparisons[i] = *c
+	}
+	return comparisons
+}
+
+// signTest is the two-sided p-value of wins against losses, under even
+// odds of each
+func signTest(wins, losses int) float64 {
+	n := wins + losses
+	k := wins
+	if losses < k {
+		k = losses
+	}
+	if n == 0 {
+		return 1
+	}
+	// twice the chance of k or fewer of n fair coins landing heads, summed
+	// in logs so big n doesn't overflow
+	lgN, _ := math.Lgamma(float64(n + 1))
+	p := 0.0
+	for i := 0; i <= k; i++ {
+		lgI, _ := math.Lgamma(float64(i + 1))
+		lgRest, _ := math.Lgamma(float64(n - i + 1))
+		p += math.Exp(lgN - lgI - lgRest - float64(n)*math.Ln2)
+	}
+	return math.Min(1, 2*p)
+}
+
+func (c Comparison) String() string {
+	return fmt.Sprintf("%s vs %s on %v: %d secrets, %d-%d-%d, %+.3f moves (±%.3f), p = %.4g",
+		c.A, c.B, c.Size, c.Pairs, c.Wins, c.Losses, c.Ties, c.MeanDiff, c.StddevDiff, c.P)
+}
+
+// WriteComparisons writes comparisons for reading, a line each
+func WriteComparisons(w io.Writer, comparisons []Comparison) error {
+	for _, c := range comparisons {
+		if _, err := fmt.Fprintln(w, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
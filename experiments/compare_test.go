@@ -0,0 +1,57 @@
+package experiments
+
+import (
+	"math"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestCompare(t *testing.T) {
+	size := mm.GameSize{4, 6}
+	game := func(solver string, round, moves int, solved bool) Game {
+		return Game{Solver: solver, Size: size, Round: round, SecretResult: mm.SecretResult{Moves: make(mm.History, moves), Solved: solved}}
+	}
+	games := []Game{
+		game("a", 0, 4, true), game("a", 1, 5, true), game("a", 2, 4, true), game("a", 3, 6, false), game("a", 4, 3, true),
+		game("b", 0, 5, true), game("b", 1, 5, true), game("b", 2, 6, true), game("b", 3, 6, true), game("b", 4, 2, false),
+		// only a pair is compared
+		game("b", 5, 1, true),
+	}
+	comparisons := Compare(games, "a", "b")
+	if len(comparisons) != 1 {
+		t.Fatalf("expected a comparison on 4x6, got %v", comparisons)
+	}
+	c := comparisons[0]
+	// a wins 0, 2 and 4, which b fails, loses 3, which it fails, and ties 1
+	if c.Pairs != 5 || c.Wins != 3 || c.Losses != 1 || c.Ties != 1 {
+		t.Errorf("expected 5 pairs, 3-1-1, got %v", c)
+	}
+	// of 0, 1 and 2, solved by both, a took 1, 0 and 2 fewer moves
+	if c.MeanDiff != -1 || math.Abs(c.StddevDiff-math.Sqrt(2.0/3)) > 1e-9 {
+		t.Errorf("expected -1±0.816 moves, got %v", c)
+	}
+	// 3 or more of 4 fair coins landing the same way: 10 in 16
+	if math.Abs(c.P-0.625) > 1e-9 {
+		t.Errorf("expected p = 0.625, got %v", c.P)
+	}
+}
+
+func TestSignTest(t *testing.T) {
+	for _, c := range []struct {
+		wins, losses int
+		p            float64
+	}{
+		{0, 0, 1},
+		{5, 5, 1},
+		{10, 0, 2.0 / 1024},
+		{0, 10, 2.0 / 1024},
+		{8, 2, 2 * 56.0 / 1024},
+		// big enough to overflow the binomial coefficients
+		{1100, 900, 8.457089535503927e-06},
+	} {
+		if p := signTest(c.wins, c.losses); math.Abs(p-c.p) > 1e-9*c.p {
+			t.Errorf("%d-%d: expected p = %.4g, got %.4g", c.wins, c.losses, c.p, p)
+		}
+	}
+}
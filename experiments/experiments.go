@@ -0,0 +1,211 @@
+// Package experiments plays solvers over a matrix of board sizes and seeds,
+// with every random choice made from the seeds, and sums up how many moves
+// and how long each took.
+package experiments
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Solver is a solver to experiment with: its name in the tables, and how
+// to make one seeded with seed
+type Solver struct {
+	Name string
+	// Config is how the solver is configured, eg knuth's heuristic, for the
+	// exported games; empty if there's nothing to say
+	Config string
+	New    func(seed int64) mm.SolverFunc
+}
+
+// Matrix is an experiment: every solver plays on every size, with every
+// seed
+type Matrix struct {
+	Solvers []Solver
+	Sizes   []mm.GameSize
+	Seeds   []int64
+	// Games is how many secrets each seed draws at random on a size; every
+	// secret of the size, in order, if it's 0
+	Games int
+}
+
+// Game is one game of a matrix
+type Game struct {
+	Solver string
+	Config string
+	Size   mm.GameSize
+	Seed   int64
+	// Round is which of the seed's secrets was played, from 0; every
+	// solver plays the same secret in the same round
+	Round int
+	mm.SecretResult
+}
+
+// Run plays every game of the matrix, by size, then seed, then solver.  A
+// seed draws the same secrets on a size for every solver, and the same
+// seeds for the solvers which play them, so every solver plays the same
+// games, and running a matrix again plays them all again just the same
+// but for the time they take.  Solvers which take randomness from anything
+// but their seed aren't pinned down, of course.
+func (m Matrix) Run() []Game {
+	var games []Game
+	for _, size := range m.Sizes {
+		for _, seed := range m.Seeds {
+			secrets, seeds := m.draw(size, seed)
+			for _, s := range m.Solvers {
+				for i, secret := range secrets {
+					res := mm.Play(size, secret, s.New(seeds[i]))
+					games = append(games, Game{Solver: s.Name, Config: s.Config, Size: size, Seed: seed, Round: i, SecretResult: res})
+				}
+			}
+		}
+	}
+	return games
+}
+
+// draw is the secrets seed plays on size, and the seed of the solver which
+// plays each
+func (m Matrix) draw(size mm.GameSize, seed int64) (mm.CodeSlice, []int64) {
+	rng := rand.New(rand.NewSource(seed))
+	var secrets mm.CodeSlice
+	if m.Games == 0 {
+		secrets = size.AllCodes()
+	} else {
+		secrets = make(mm.CodeSlice, m.Games)
+		for i := range secrets {
+			secrets[i] = size.CodeAt(rng.Intn(size.NumCodes()))
+		}
+	}
+	seeds := make([]int64, len(secrets))
+	for i := range seeds {
+		seeds[i] = rng.Int63()
+	}
+	return secrets, seeds
+}
+
+// Summary sums up the games a solver played on a size, over every seed
+type Summary struct {
+	Solver   string
+	Size     mm.GameSize
+	Games    int
+	Failures int
+	// MeanMoves, StddevMoves and WorstMoves are over the games won
+	MeanMoves   float64
+	StddevMoves float64
+	WorstMoves  int
+	// MeanTime and WorstTime are over every game
+	MeanTime  time.Duration
+	WorstTime time.Duration
+}
+
+// Summarize sums up games by solver and size, in the order each solver
+// first played on each size
+func Summarize(games []Game) []Summary {
+	type key struct {
+		solver string
+		size   mm.GameSize
+	}
+	var order []key
+	byKey := map[key][]Game{}
+	for _, g := range games {
+		k := key{g.Solver, g.Size}
+		if _, ok := byKey[k]; !ok {
+			order = append(order, k)
+		}
+		byKey[k] = append(byKey[k], g)
+	}
+
+	summaries := make([]Summary, len(order))
+	for i, k := range order {
+		summaries[i] = summarize(k.solver, k.size, byKey[k])
+	}
+	return summaries
+}
+
+func summarize(solver string, size mm.GameSize, games []Game) Summary {
+	s := Summary{Solver: solver, Size: size, Games: len(games)}
+	var moves []float64
+	var total time.Duration
+	for _, g := range games {
+		total += g.Duration
+		if g.Duration > s.WorstTime {
+			s.WorstTime = g.Duration
+		}
+		if !g.Solved {
+			s.Failures++
+			continue
+		}
+		moves = append(moves, float64(len(g.Moves)))
+		if len(g.Moves) > s.WorstMoves {
+			s.WorstMoves = len(g.Moves)
+		}
+	}
+	if len(games) > 0 {
+		s.MeanTime = total / time.Duration(len(games))
+	}
+	s.MeanMoves, s.StddevMoves = meanStddev(moves)
+	return s
+}
+
+// meanStddev is the mean of xs and their standard deviation as a
+// population, both 0 if there are none
+func meanStddev(xs []float64) (float64, float64) {
+	if len(xs) == 0 {
+		return 0, 0
+	}
+	mean := 0.0
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	variance := 0.0
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(xs)))
+}
+
+// WriteTable writes summaries as a table for reading, a row each
+func WriteTable(w io.Writer, summaries []Summary) error {
+	if _, err := fmt.Fprintf(w, "%-18s %-6s %6s %6s %8s %8s %6s %12s %12s\n",
+		"solver", "size", "games", "failed", "mean", "stddev", "worst", "mean time", "worst time"); err != nil {
+		return err
+	}
+	for _, s := range summaries {
+		if _, err := fmt.Fprintf(w, "%-18s %-6s %6d %6d %8.3f %8.3f %6d %12v %12v\n",
+			s.Solver, s.Size, s.Games, s.Failures, s.MeanMoves, s.StddevMoves, s.WorstMoves,
+			s.MeanTime.Round(time.Microsecond), s.WorstTime.Round(time.Microsecond)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCSV writes summaries as CSV, with a header row, for analysis in eg
+// pandas or R
+func WriteCSV(w io.Writer, summaries []Summary) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"solver", "size", "games", "failures", "mean_moves", "stddev_moves", "worst_moves", "mean_seconds", "worst_seconds"})
+	for _, s := range summaries {
+		cw.Write([]string{
+			s.Solver,
+			s.Size.String(),
+			strconv.Itoa(s.Games),
+			strconv.Itoa(s.Failures),
+			fmt.Sprintf("%.3f", s.MeanMoves),
+			fmt.Sprintf("%.3f", s.StddevMoves),
+			strconv.Itoa(s.WorstMoves),
+			fmt.Sprintf("%.6f", s.MeanTime.Seconds()),
+			fmt.Sprintf("%.6f", s.WorstTime.Seconds()),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,93 @@
+package experiments
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/genetic"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+var testSolvers = []Solver{
+	{Name: "knuth", Config: "minmax", New: func(seed int64) mm.SolverFunc {
+		return func(cm mm.Codemaker) mm.Solver { return solver.NewCodemakerSolver(cm) }
+	}},
+	{Name: "genetic", New: func(seed int64) mm.SolverFunc {
+		return func(cm mm.Codemaker) mm.Solver { return genetic.NewCodemakerSolver(cm, genetic.WithSeed(seed)) }
+	}},
+}
+
+func TestRun(t *testing.T) {
+	// the genetic solver's population takes more codes than smaller
+	// boards have
+	m := Matrix{Solvers: testSolvers, Sizes: []mm.GameSize{{4, 6}, {4, 5}}, Seeds: []int64{1, 2}, Games: 5}
+	games := m.Run()
+	if len(games) != 2*2*2*5 {
+		t.Fatalf("expected 40 games, got %d", len(games))
+	}
+	for i, g := range games {
+		if !g.Solved {
+			t.Errorf("expected %s to solve %v, got %v", g.Solver, g.Secret, g.Err)
+		}
+		// each solver faces the same secrets as the one before
+		if g.Solver == "genetic" && g.Secret.String() != games[i-5].Secret.String() {
+			t.Errorf("expected genetic to play %v as knuth did, got %v", games[i-5].Secret, g.Secret)
+		}
+	}
+
+	// everything but the times comes out the same again
+	for i, g := range m.Run() {
+		if g.Secret.String() != games[i].Secret.String() || len(g.Moves) != len(games[i].Moves) {
+			t.Fatalf("expected game %d played as before, got %v in %d moves", i, g.Secret, len(g.Moves))
+		}
+	}
+
+	all := Matrix{Solvers: testSolvers[:1], Sizes: []mm.GameSize{{2, 3}}, Seeds: []int64{1}}.Run()
+	if len(all) != 9 || all[8].Secret.String() != "22" {
+		t.Errorf("expected every 2x3 secret in order, got %d games", len(all))
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	size := mm.GameSize{4, 6}
+	game := func(solver string, moves int, solved bool, d time.Duration) Game {
+		return Game{Solver: solver, Size: size, SecretResult: mm.SecretResult{Moves: make(mm.History, moves), Solved: solved, Duration: d}}
+	}
+	summaries := Summarize([]Game{
+		game("b", 4, true, time.Second),
+		game("a", 2, true, time.Second),
+		game("b", 6, true, 3*time.Second),
+		game("b", 10, false, 2*time.Second),
+	})
+	if len(summaries) != 2 || summaries[0].Solver != "b" || summaries[1].Solver != "a" {
+		t.Fatalf("expected b then a summed up, got %+v", summaries)
+	}
+	b := summaries[0]
+	if b.Games != 3 || b.Failures != 1 || b.MeanMoves != 5 || math.Abs(b.StddevMoves-1) > 1e-9 || b.WorstMoves != 6 {
+		t.Errorf("expected 3 games, 1 failed, 5±1 moves, 6 worst, got %+v", b)
+	}
+	if b.MeanTime != 2*time.Second || b.WorstTime != 3*time.Second {
+		t.Errorf("expected 2s mean and 3s worst, got %v and %v", b.MeanTime, b.WorstTime)
+	}
+
+	table := &bytes.Buffer{}
+	if err := WriteTable(table, summaries); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "b ") || !strings.Contains(lines[1], "5.000") {
+		t.Errorf("expected a header and two rows, got\n%s", table)
+	}
+
+	out := &bytes.Buffer{}
+	if err := WriteCSV(out, summaries); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 3 || lines[2] != "a,4x6,1,0,2.000,0.000,2,1.000000,1.000000" {
+		t.Errorf("expected a header and two rows, got\n%s", out)
+	}
+}
@@ -0,0 +1,102 @@
+package experiments
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Column is a column of an exported table
+type Column struct {
+	Name string
+	// Type is what the column holds: string, int, float or bool
+	Type string
+	Doc  string
+}
+
+// MovesVersion is the version of MoveColumns, bumped whenever they change
+// other than by adding columns at the end
+const MovesVersion = 1
+
+// MoveColumns are the columns WriteMovesCSV writes, a row for each move of
+// each game.  The columns up to move are the game's, the same on each of
+// its rows.
+var MoveColumns = []Column{
+	{"solver", "string", "the solver's name"},
+	{"config", "string", "how the solver was configured, eg knuth's heuristic; empty if it takes nothing"},
+	{"size", "string", "the board, as positions x colors, eg 4x6"},
+	{"seed", "int", "the seed which drew the secret and seeded the solver"},
+	{"round", "int", "which of the seed's secrets, from 0; every solver plays the same secret in the same round"},
+	{"secret", "string", "the secret, a digit for each peg's color"},
+	{"solved", "bool", "whether the solver found the secret"},
+	{"error", "string", "why the secret wasn't found, if it wasn't"},
+	{"moves", "int", "how many guesses the game took"},
+	{"seconds", "float", "how long the game took"},
+	{"move", "int", "which guess of the game the row is, from 1; 0 for a game without any"},
+	{"guess", "string", "the guess, a digit for each peg's color"},
+	{"black", "int", "how many pegs of the guess were the right color in the right place"},
+	{"white", "int", "how many more were the right color in the wrong place"},
+}
+
+// WriteMovesCSV writes games a row a move, as MoveColumns describes, for
+// analysis in eg pandas or R.  The schema comes first, as lines starting
+// with #: the format and version, then a line for each column with its
+// name, type and meaning.  Then come a header row of the column names and
+// the rows, as CSV, so the file reads with eg
+// pandas.read_csv(path, comment="#").
+func WriteMovesCSV(w io.Writer, games []Game) error {
+	if err := writeSchema(w, "moves", MovesVersion, MoveColumns); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	header := make([]string, len(MoveColumns))
+	for i, c := range MoveColumns {
+		header[i] = c.Name
+	}
+	cw.Write(header)
+	for _, g := range games {
+		errText := ""
+		if g.Err != nil {
+			errText = g.Err.Error()
+		}
+		game := []string{
+			g.Solver,
+			g.Config,
+			g.Size.String(),
+			strconv.FormatInt(g.Seed, 10),
+			strconv.Itoa(g.Round),
+			g.Secret.String(),
+			strconv.FormatBool(g.Solved),
+			errText,
+			strconv.Itoa(len(g.Moves)),
+			fmt.Sprintf("%.6f", g.Duration.Seconds()),
+		}
+		if len(g.Moves) == 0 {
+			cw.Write(append(game, "0", "", "", ""))
+		}
+		for i, m := range g.Moves {
+			cw.Write(append(game[:len(game):len(game)],
+				strconv.Itoa(i+1),
+				m.Guess.String(),
+				strconv.Itoa(m.Result.Correct),
+				strconv.Itoa(m.Result.HalfCorrect),
+			))
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeSchema writes the schema of a table as comment lines
+func writeSchema(w io.Writer, table string, version int, columns []Column) error {
+	if _, err := fmt.Fprintf(w, "# mastermind %s v%d\n", table, version); err != nil {
+		return err
+	}
+	for _, c := range columns {
+		if _, err := fmt.Fprintf(w, "# %s %s: %s\n", c.Name, c.Type, c.Doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package experiments
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestWriteMovesCSV(t *testing.T) {
+	m := Matrix{Solvers: testSolvers, Sizes: []mm.GameSize{{4, 6}}, Seeds: []int64{1}, Games: 2}
+	games := append(m.Run(), Game{Solver: "broken", Size: mm.GameSize{4, 6}, Seed: 1, Round: 0,
+		SecretResult: mm.SecretResult{Secret: mm.Code{0, 1, 2, 3}, Err: errors.New("gave up")}})
+
+	var buf bytes.Buffer
+	if err := WriteMovesCSV(&buf, games); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if lines[0] != "# mastermind moves v1" || lines[1] != "# solver string: the solver's name" {
+		t.Errorf("expected the schema first, got\n%s", strings.Join(lines[:3], "\n"))
+	}
+
+	r := csv.NewReader(&buf)
+	r.Comment = '#'
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows[0]) != len(MoveColumns) || rows[0][0] != "solver" || rows[0][len(rows[0])-1] != "white" {
+		t.Errorf("expected a header of the columns, got %v", rows[0])
+	}
+	moves := 0
+	for _, g := range games[:4] {
+		moves += len(g.Moves)
+	}
+	// a row a move, and one for the game without any
+	if len(rows) != 1+moves+1 {
+		t.Fatalf("expected %d rows, got %d", 1+moves+1, len(rows))
+	}
+	first := games[0]
+	if row := strings.Join(rows[1], ","); !strings.HasPrefix(row, "knuth,minmax,4x6,1,0,"+first.Secret.String()+",true,,") ||
+		!strings.HasSuffix(row, ",1,"+first.Moves[0].Guess.String()+","+strings.Replace(first.Moves[0].Result.String(), "-", ",", 1)) {
+		t.Errorf("unexpected first row %s", row)
+	}
+	if row := strings.Join(rows[len(rows)-1], ","); !strings.HasPrefix(row, "broken,,4x6,1,0,0123,false,gave up,0,") || !strings.HasSuffix(row, ",0,,,") {
+		t.Errorf("unexpected row for a game without moves %s", row)
+	}
+}
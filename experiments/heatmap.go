@@ -0,0 +1,150 @@
+package experiments
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Heatmap is how many moves every solver took on every secret played on a
+// size, to show which secrets are hard for which solvers
+type Heatmap struct {
+	Size    mm.GameSize
+	Solvers []string
+	// Secrets are in order
+	Secrets mm.CodeSlice
+	// Moves[i][j] is the mean moves Solvers[j] took on Secrets[i] over
+	// every seed; NaN if it failed in any game of it, or never played it
+	Moves [][]float64
+}
+
+// Heatmaps is a heatmap for each size games were played on, in the order
+// they were first played
+func Heatmaps(games []Game) []*Heatmap {
+	var maps []*Heatmap
+	bySize := map[mm.GameSize]*Heatmap{}
+	type cell struct {
+		moves, games int
+		failed       bool
+	}
+	cells := map[*Heatmap]map[string]map[int]*cell{}
+	for _, g := range games {
+		h := bySize[g.Size]
+		if h == nil {
+			h = &Heatmap{Size: g.Size}
+			bySize[g.Size] = h
+			maps = append(maps, h)
+			cells[h] = map[string]map[int]*cell{}
+		}
+		bySecret := cells[h][g.Solver]
+		if bySecret == nil {
+			h.Solvers = append(h.Solvers, g.Solver)
+			bySecret = map[int]*cell{}
+			cells[h][g.Solver] = bySecret
+		}
+		i := g.Secret.Index(g.Size.Colors)
+		c := bySecret[i]
+		if c == nil {
+			c = &cell{}
+			bySecret[i] = c
+		}
+		c.games++
+		c.moves += len(g.Moves)
+		c.failed = c.failed || !g.Solved
+	}
+
+	for _, h := range maps {
+		var indexes []int
+		seen := map[int]bool{}
+		for _, bySecret := range cells[h] {
+			for i := range bySecret {
+				if !seen[i] {
+					seen[i] = true
+					indexes = append(indexes, i)
+				}
+			}
+		}
+		sort.Ints(indexes)
+		for _, i := range indexes {
+			h.Secrets = append(h.Secrets, h.Size.CodeAt(i))
+			row := make([]float64, len(h.Solvers))
+			for j, solver := range h.Solvers {
+				row[j] = math.NaN()
+				if c := cells[h][solver][i]; c != nil && !c.failed {
+					row[j] = float64(c.moves) / float64(c.games)
+				}
+			}
+			h.Moves = append(h.Moves, row)
+		}
+	}
+	return maps
+}
+
+// HardSecret is a secret and the mean moves a solver took on it, NaN if
+// it wasn't always solved
+type HardSecret struct {
+	Secret mm.Code
+	Moves  float64
+}
+
+// Hardest is the n secrets solver took the most moves on, those it failed
+// on first, and ties in order of the secrets
+func (h *Heatmap) Hardest(solver string, n int) []HardSecret {
+	j := -1
+	for k, s := range h.Solvers {
+		if s == solver {
+			j = k
+		}
+	}
+	if j < 0 {
+		return nil
+	}
+	hardest := make([]HardSecret, len(h.Secrets))
+	for i, secret := range h.Secrets {
+		hardest[i] = HardSecret{secret, h.Moves[i][j]}
+	}
+	sort.SliceStable(hardest, func(a, b int) bool {
+		x, y := hardest[a].Moves, hardest[b].Moves
+		return math.IsNaN(x) && !math.IsNaN(y) || x > y
+	})
+	if n < len(hardest) {
+		hardest = hardest[:n]
+	}
+	return hardest
+}
+
+// WriteHeatmapCSV writes heatmaps as CSV, a row for each secret of each,
+// and a column for each solver of any, with a header row.  Cells of
+// secrets a solver failed on or didn't play are left empty.
+func WriteHeatmapCSV(w io.Writer, maps []*Heatmap) error {
+	var solvers []string
+	column := map[string]int{}
+	for _, h := range maps {
+		for _, s := range h.Solvers {
+			if _, ok := column[s]; !ok {
+				column[s] = len(solvers)
+				solvers = append(solvers, s)
+			}
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{"size", "secret"}, solvers...))
+	for _, h := range maps {
+		for i, secret := range h.Secrets {
+			cells := make([]string, len(solvers))
+			for j, moves := range h.Moves[i] {
+				if !math.IsNaN(moves) {
+					cells[column[h.Solvers[j]]] = strconv.FormatFloat(moves, 'f', -1, 64)
+				}
+			}
+			cw.Write(append([]string{h.Size.String(), secret.String()}, cells...))
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
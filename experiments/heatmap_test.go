@@ -0,0 +1,58 @@
+package experiments
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestHeatmap(t *testing.T) {
+	small, big := mm.GameSize{3, 4}, mm.GameSize{4, 6}
+	game := func(solver string, size mm.GameSize, secret string, moves int, solved bool) Game {
+		code := make(mm.Code, len(secret))
+		for i := range secret {
+			code[i] = secret[i] - '0'
+		}
+		return Game{Solver: solver, Size: size, SecretResult: mm.SecretResult{Secret: code, Moves: make(mm.History, moves), Solved: solved}}
+	}
+	maps := Heatmaps([]Game{
+		game("a", small, "012", 3, true),
+		game("a", small, "000", 5, true),
+		game("b", small, "000", 2, true),
+		game("b", small, "012", 4, false),
+		game("a", small, "000", 4, true),
+		game("c", big, "0123", 4, true),
+	})
+	if len(maps) != 2 || maps[0].Size != small || maps[1].Size != big {
+		t.Fatalf("expected heatmaps of 3x4 and 4x6, got %d", len(maps))
+	}
+	h := maps[0]
+	if strings.Join(h.Solvers, ",") != "a,b" || len(h.Secrets) != 2 || h.Secrets[0].String() != "000" {
+		t.Fatalf("expected a and b on 000 and 012, got %v on %v", h.Solvers, h.Secrets)
+	}
+	if h.Moves[0][0] != 4.5 || h.Moves[0][1] != 2 || h.Moves[1][0] != 3 || !math.IsNaN(h.Moves[1][1]) {
+		t.Errorf("expected 4.5 and 2 moves on 000, 3 and none on 012, got %v", h.Moves)
+	}
+
+	if hardest := h.Hardest("a", 1); len(hardest) != 1 || hardest[0].Secret.String() != "000" || hardest[0].Moves != 4.5 {
+		t.Errorf("expected 000 hardest for a, got %v", hardest)
+	}
+	if hardest := h.Hardest("b", 5); len(hardest) != 2 || hardest[0].Secret.String() != "012" {
+		t.Errorf("expected 012, which b failed on, hardest for it, got %v", hardest)
+	}
+	if hardest := h.Hardest("c", 5); hardest != nil {
+		t.Errorf("expected nothing for a solver which didn't play, got %v", hardest)
+	}
+
+	out := &bytes.Buffer{}
+	if err := WriteHeatmapCSV(out, maps); err != nil {
+		t.Fatal(err)
+	}
+	expected := "size,secret,a,b,c\n3x4,000,4.5,2,\n3x4,012,3,,\n4x6,0123,,,4\n"
+	if out.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out)
+	}
+}
@@ -0,0 +1,100 @@
+package mastermind
+
+// The default 4x6 game is by far the most played, so Score and CheckCodes
+// hand it to the functions here, which know its size when compiled: the
+// pegs are compared without a loop, and each code's colors are counted
+// into a word, a nibble a color, rather than into arrays which need
+// clearing.
+
+// colorBits4x6[c] is what a peg of color c adds to a code's color counts:
+// one in its color's nibble, or nothing for a color off the board, which
+// can't be half correct
+var colorBits4x6 = func() (bits [256]uint32) {
+	for c := 0; c < defaultColors; c++ {
+		bits[c] = 1 << (4 * c)
+	}
+	return bits
+}()
+
+// score4x6 is Score for two codes of 4 pegs and 6 colors
+func score4x6(guess, actual Code) Result {
+	g, a := guess[:defaultPositions], actual[:defaultPositions]
+	correct := 0
+	var inGuess, inActual uint32
+	if g[0] == a[0] {
+		correct++
+	} else {
+		inGuess += colorBits4x6[g[0]]
+		inActual += colorBits4x6[a[0]]
+	}
+	if g[1] == a[1] {
+		correct++
+	} else {
+		inGuess += colorBits4x6[g[1]]
+		inActual += colorBits4x6[a[1]]
+	}
+	if g[2] == a[2] {
+		correct++
+	} else {
+		inGuess += colorBits4x6[g[2]]
+		inActual += colorBits4x6[a[2]]
+	}
+	if g[3] == a[3] {
+		correct++
+	} else {
+		inGuess += colorBits4x6[g[3]]
+		inActual += colorBits4x6[a[3]]
+	}
+	return Result{correct, common4x6(inGuess, inActual)}
+}
+
+// common4x6 is how many pegs two codes' color counts have in common: the
+// lesser count of each color, summed.  No count is over 4, so the nibbles
+// are compared all at once: x-y borrows nothing with 8 added to each of
+// x's, and leaves a nibble's top bit set where x's count is the greater.
+func common4x6(x, y uint32) int {
+	greater := (((x | 0x888888) - y) >> 3 & 0x111111) * 0xf
+	return pegs4x6(y&greater | x&^greater)
+}
+
+// pegs4x6 is how many pegs color counts add up to.  Nor is that over 4, so
+// multiplying adds the counts up into the top nibble without carrying out
+// of any.
+func pegs4x6(counts uint32) int {
+	return int((counts * 0x111111) >> 20 & 0xf)
+}
+
+// checkCodes4x6 is CheckCodes for a guess of 4 pegs and 6 colors, against
+// secrets known to be as long.  The guess's colors are counted once, for
+// every secret.
+func checkCodes4x6(guess Code, secrets []Code) []Result {
+	g := guess[:defaultPositions]
+	inGuess := colorBits4x6[g[0]] + colorBits4x6[g[1]] + colorBits4x6[g[2]] + colorBits4x6[g[3]]
+
+	results := make([]Result, len(secrets))
+	for i, secret := range secrets {
+		s := secret[:defaultPositions]
+		inSecret := colorBits4x6[s[0]] + colorBits4x6[s[1]] + colorBits4x6[s[2]] + colorBits4x6[s[3]]
+		// the pegs in the right place, their colors counted too so that
+		// only those on the board are taken from the common ones
+		correct, inPlace := 0, uint32(0)
+		if g[0] == s[0] {
+			correct++
+			inPlace += colorBits4x6[g[0]]
+		}
+		if g[1] == s[1] {
+			correct++
+			inPlace += colorBits4x6[g[1]]
+		}
+		if g[2] == s[2] {
+			correct++
+			inPlace += colorBits4x6[g[2]]
+		}
+		if g[3] == s[3] {
+			correct++
+			inPlace += colorBits4x6[g[3]]
+		}
+		results[i] = Result{correct, common4x6(inGuess, inSecret) - pegs4x6(inPlace)}
+	}
+	return results
+}
@@ -0,0 +1,51 @@
+package mastermind
+
+// Feedback is how much a codemaker reveals about each guess
+type Feedback int
+
+const (
+	// FullFeedback reveals correct and half-correct pegs separately
+	FullFeedback Feedback = iota
+	// TotalFeedback reveals only the total of correct and half-correct
+	// pegs, as Result{0, total}
+	TotalFeedback
+	// BlackFeedback reveals only the correct pegs, as Result{correct, 0}
+	BlackFeedback
+)
+
+func (f Feedback) String() string {
+	switch f {
+	case FullFeedback:
+		return "full"
+	case TotalFeedback:
+		return "total"
+	case BlackFeedback:
+		return "black"
+	}
+	return "unknown"
+}
+
+// Reduce hides what f doesn't reveal of r, a full result for a code of the
+// given number of positions.  A win is always reported in full, so it can't
+// be confused with a reduced result.
+func (f Feedback) Reduce(r Result, positions int) Result {
+	if r.Correct == positions {
+		return r
+	}
+	switch f {
+	case TotalFeedback:
+		return Result{0, r.Correct + r.HalfCorrect}
+	case BlackFeedback:
+		return Result{r.Correct, 0}
+	}
+	return r
+}
+
+// Score is CheckCode, reduced to what f reveals
+func (f Feedback) Score(guess, actual Code, colors byte) (Result, error) {
+	r, err := CheckCode(guess, actual, colors)
+	if err != nil {
+		return r, err
+	}
+	return f.Reduce(r, len(actual)), nil
+}
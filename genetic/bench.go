@@ -0,0 +1,114 @@
+package genetic
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// BenchSizes are the boards benchmarked by default, from the classic game
+// up to where exhaustive solvers give out
+var BenchSizes = []mm.GameSize{
+	{Positions: 4, Colors: 6},
+	{Positions: 5, Colors: 8},
+	{Positions: 6, Colors: 9},
+	{Positions: 7, Colors: 10},
+	{Positions: 8, Colors: 12},
+}
+
+// BenchConfig is a named configuration of the solver to benchmark
+type BenchConfig struct {
+	Name    string
+	Options []Option
+}
+
+// BenchConfigs are the configurations benchmarked by default
+var BenchConfigs = []BenchConfig{
+	{Name: "default"},
+	{Name: "tournament", Options: []Option{WithSelector(Tournament{Size: 3})}},
+	{Name: "adaptive", Options: []Option{WithAdaptiveRates(0.5)}},
+	{Name: "islands", Options: []Option{WithIslands(4, 10, 5)}},
+	{Name: "endgame", Options: []Option{WithEndgame(30)}},
+}
+
+// BenchResult sums up the games played by one configuration on one board
+type BenchResult struct {
+	Size     mm.GameSize
+	Config   string
+	Games    int
+	Failures int
+	// MeanMoves and MaxMoves are over the games won
+	MeanMoves float64
+	MaxMoves  int
+	WallTime  time.Duration
+}
+
+// Benchmark plays games games with every configuration on every board.
+// The secrets come from seed, so every configuration faces the same ones.
+func Benchmark(sizes []mm.GameSize, configs []BenchConfig, games int, seed int64) []BenchResult {
+	results := []BenchResult{}
+	for _, size := range sizes {
+		rng := rand.New(rand.NewSource(seed))
+		secrets := make(mm.CodeSlice, games)
+		for i := range secrets {
+			secrets[i] = size.CodeAt(rng.Intn(size.NumCodes()))
+		}
+
+		for _, config := range configs {
+			r := BenchResult{Size: size, Config: config.Name, Games: games}
+			moves := 0
+			start := time.Now()
+			for i, secret := range secrets {
+				game := mm.NewCustomGameWithSecret(size.Positions, size.Colors, secret)
+				game.Quiet = true
+				opts := append([]Option{WithSeed(seed + int64(i) + 1)}, config.Options...)
+				solver := NewSolver(game, opts...)
+
+				winner, err := solver.Solve()
+				if err != nil || winner.String() != secret.String() {
+					r.Failures++
+					continue
+				}
+				moves += solver.TurnsTaken
+				if solver.TurnsTaken > r.MaxMoves {
+					r.MaxMoves = solver.TurnsTaken
+				}
+			}
+			r.WallTime = time.Since(start)
+			if won := games - r.Failures; won > 0 {
+				r.MeanMoves = float64(moves) / float64(won)
+			}
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// WriteCSV writes results as CSV, with a header row
+func WriteCSV(w io.Writer, results []BenchResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"size", "config", "games", "failures", "mean_moves", "max_moves", "wall_seconds", "seconds_per_game"})
+	for _, r := range results {
+		perGame := 0.0
+		if r.Games > 0 {
+			perGame = r.WallTime.Seconds() / float64(r.Games)
+		}
+		cw.Write([]string{
+			r.Size.String(),
+			r.Config,
+			strconv.Itoa(r.Games),
+			strconv.Itoa(r.Failures),
+			fmt.Sprintf("%.3f", r.MeanMoves),
+			strconv.Itoa(r.MaxMoves),
+			fmt.Sprintf("%.3f", r.WallTime.Seconds()),
+			fmt.Sprintf("%.3f", perGame),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,50 @@
+package genetic
+
+import "sync"
+
+// fitnessCache remembers the fitness of every code scored this move, so no
+// code is scored twice in a move however many generations it survives.
+// Each move's result changes every fitness, so a new move empties it.  The
+// islands of the island model share one.
+type fitnessCache struct {
+	mu     sync.Mutex
+	move   int
+	scores map[citizenKey]float64
+	// misses counts the codes actually scored
+	misses int
+}
+
+func newFitnessCache() *fitnessCache {
+	return &fitnessCache{scores: map[citizenKey]float64{}}
+}
+
+// get returns the fitness of the code keyed by key at move, from score if
+// it isn't cached
+func (fc *fitnessCache) get(move int, key citizenKey, score func() float64) float64 {
+	fc.mu.Lock()
+	if move != fc.move {
+		fc.move = move
+		fc.scores = map[citizenKey]float64{}
+	}
+	f, ok := fc.scores[key]
+	fc.mu.Unlock()
+	if ok {
+		return f
+	}
+
+	f = score()
+	fc.mu.Lock()
+	if move == fc.move {
+		fc.scores[key] = f
+	}
+	fc.misses++
+	fc.mu.Unlock()
+	return f
+}
+
+// reset forgets every fitness, eg when the history is replaced outright
+func (fc *fitnessCache) reset() {
+	fc.mu.Lock()
+	fc.scores = map[citizenKey]float64{}
+	fc.mu.Unlock()
+}
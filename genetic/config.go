@@ -0,0 +1,200 @@
+package genetic
+
+import (
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+// Config holds the tuning parameters of the genetic algorithm.  The defaults
+// follow Berghman, Goossens & Leus, "Efficient solutions for Mastermind
+// using genetic algorithms".
+type Config struct {
+	// Seed seeds the solver's random source; zero seeds it from the clock
+	Seed int64
+	// MaxMoves is how many moves Solve makes before giving up.  Zero means
+	// positions^2, which is plenty for any board.
+	MaxMoves int
+	// PopulationSize is the size of the population initialized each move
+	PopulationSize int
+	// MaxGenerations is how many generations are evolved each move at most
+	MaxGenerations int
+	// MaxEligible stops evolution once this many eligible codes are found
+	MaxEligible int
+	// Elitism is the number of the fittest citizens copied unchanged into
+	// each new generation
+	Elitism int
+	// SpawnRate is the fraction of the population, fittest first, which breeds
+	SpawnRate float64
+	// MutationRate is the chance a child has one position recolored
+	MutationRate float64
+	// PermutationRate is the chance a child has two positions swapped
+	PermutationRate float64
+	// InversionRate is the chance a child has a run of positions reversed
+	InversionRate float64
+	// AdaptiveRates raises the mutation, permutation and inversion rates
+	// while the population's diversity is below MinDiversity, to escape
+	// premature convergence, and relaxes them back once it recovers.
+	// Diversity is the number of unique codes over PopulationSize.
+	AdaptiveRates bool
+	MinDiversity  float64
+	// Fitness rates citizens; if nil, Berghman with weights FitnessA and
+	// FitnessB is used.  FitnessA weighs the correct peg differences, and
+	// FitnessB the penalty proportional to positions and turns taken.
+	Fitness  FitnessFunction
+	FitnessA float64
+	FitnessB float64
+	// Selector chooses which citizens breed; if nil, Greedy does
+	Selector Selector
+	// Islands is the number of subpopulations evolved in parallel, each of
+	// PopulationSize.  Every MigrationInterval generations the best Migrants
+	// of each island move to the next one; a MigrationInterval of zero
+	// never migrates.
+	Islands           int
+	MigrationInterval int
+	Migrants          int
+	// EndgameThreshold hands the move to the solver package's exact minimax
+	// step once fewer than this many eligible codes are found; zero, the
+	// default, never does, so the whole game is the GA's.  The GA picks any
+	// eligible code, which wastes moves once only a few are left, where a
+	// guess that splits them evenly wins sooner.
+	EndgameThreshold int
+	// StateFile, if set, is where the solver's State is saved after every
+	// move, to be restored if the solve is interrupted
+	StateFile string
+	// Logger receives the solver's messages; by default they're discarded
+	Logger Logger
+	// Workers is how many goroutines score each generation's fitness at
+	// once; zero means one per CPU.  Unless it's 1, Fitness must be safe
+	// to call from several goroutines, as it already is with Islands.
+	Workers int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PopulationSize:    150,
+		MaxGenerations:    100,
+		MaxEligible:       60,
+		Elitism:           2,
+		SpawnRate:         0.5,
+		MutationRate:      0.03,
+		PermutationRate:   0.03,
+		InversionRate:     0.02,
+		AdaptiveRates:     false,
+		MinDiversity:      0.5,
+		FitnessA:          2.0,
+		FitnessB:          2.0,
+		Selector:          Greedy{},
+		Islands:           1,
+		MigrationInterval: 10,
+		Migrants:          5,
+		Logger:            silent{},
+	}
+}
+
+// EstimateMemory is roughly the most memory, in bytes, a solve of a game of
+// size takes with c, as solver.EstimateMemory reckons the other solvers'
+func (c Config) EstimateMemory(size mm.GameSize) uint64 {
+	return solver.GeneticMemory(size, c.PopulationSize, c.MaxGenerations, c.MaxEligible, c.Islands)
+}
+
+// Option changes the configuration of a new Solver
+type Option func(*Config)
+
+// WithConfig replaces the whole configuration
+func WithConfig(c Config) Option {
+	return func(cfg *Config) { *cfg = c }
+}
+
+func WithPopulationSize(n int) Option {
+	return func(cfg *Config) { cfg.PopulationSize = n }
+}
+
+func WithMaxGenerations(n int) Option {
+	return func(cfg *Config) { cfg.MaxGenerations = n }
+}
+
+func WithMaxEligible(n int) Option {
+	return func(cfg *Config) { cfg.MaxEligible = n }
+}
+
+func WithSpawnRate(r float64) Option {
+	return func(cfg *Config) { cfg.SpawnRate = r }
+}
+
+// WithRates sets the mutation, permutation and inversion rates
+func WithRates(mutation, permutation, inversion float64) Option {
+	return func(cfg *Config) {
+		cfg.MutationRate = mutation
+		cfg.PermutationRate = permutation
+		cfg.InversionRate = inversion
+	}
+}
+
+// WithFitnessWeights sets the weights a and b of the fitness function
+func WithFitnessWeights(a, b float64) Option {
+	return func(cfg *Config) {
+		cfg.FitnessA = a
+		cfg.FitnessB = b
+	}
+}
+
+func WithSelector(sel Selector) Option {
+	return func(cfg *Config) { cfg.Selector = sel }
+}
+
+func WithElitism(n int) Option {
+	return func(cfg *Config) { cfg.Elitism = n }
+}
+
+// WithAdaptiveRates turns on rate adaptation below the given diversity
+func WithAdaptiveRates(minDiversity float64) Option {
+	return func(cfg *Config) {
+		cfg.AdaptiveRates = true
+		cfg.MinDiversity = minDiversity
+	}
+}
+
+func WithMaxMoves(n int) Option {
+	return func(cfg *Config) { cfg.MaxMoves = n }
+}
+
+// WithSeed makes the solver reproducible
+func WithSeed(seed int64) Option {
+	return func(cfg *Config) { cfg.Seed = seed }
+}
+
+// WithFitness replaces the fitness function
+func WithFitness(f FitnessFunction) Option {
+	return func(cfg *Config) { cfg.Fitness = f }
+}
+
+// WithIslands evolves n subpopulations, migrating the best migrants of each
+// to the next every interval generations
+func WithIslands(n, interval, migrants int) Option {
+	return func(cfg *Config) {
+		cfg.Islands = n
+		cfg.MigrationInterval = interval
+		cfg.Migrants = migrants
+	}
+}
+
+// WithEndgame has the exact solver take over once fewer than threshold
+// eligible codes are found, eg 30; zero plays the whole game with the GA
+func WithEndgame(threshold int) Option {
+	return func(cfg *Config) { cfg.EndgameThreshold = threshold }
+}
+
+// WithLogger sends the solver's messages to l
+func WithLogger(l Logger) Option {
+	return func(cfg *Config) { cfg.Logger = l }
+}
+
+// WithWorkers scores fitness on n goroutines at once
+func WithWorkers(n int) Option {
+	return func(cfg *Config) { cfg.Workers = n }
+}
+
+// WithStateFile saves the solver's state to path after every move
+func WithStateFile(path string) Option {
+	return func(cfg *Config) { cfg.StateFile = path }
+}
@@ -0,0 +1,29 @@
+package genetic
+
+import (
+	"sort"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+// boards with more codes than this are too big to enumerate each move, so
+// the endgame picks among the eligible codes instead
+const maxEnumerated = 1 << 20
+
+// endgame returns the exact minimax guess of the solver package.  On boards
+// small enough to enumerate, that's over the true consistent set; otherwise
+// Ei stands in for it, which is only as good as the search that found it.
+func (s *Solver) endgame(Ei Population) (mm.Code, error) {
+	exact := &solver.Solver{Game: s.Game}
+	if s.Size.NumCodes() <= maxEnumerated {
+		return exact.Step(s.history())
+	}
+
+	candidates := make(mm.CodeSlice, 0, len(Ei))
+	for _, c := range Ei {
+		candidates = append(candidates, c.Code)
+	}
+	sort.Sort(candidates)
+	return exact.StepAmong(candidates)
+}
@@ -0,0 +1,81 @@
+package genetic
+
+import (
+	"math"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// FitnessFunction rates a code by how close it comes to being eligible,
+// given the moves played so far.  Lower is fitter.  Whatever the function,
+// a code is only ever guessed once it's eligible, ie consistent with every
+// result; fitness just steers the search towards such codes.
+type FitnessFunction interface {
+	Evaluate(code mm.Code, history mm.History, size mm.GameSize) float64
+}
+
+// In order to compute the fitness value of a chromosome c, we compare it with
+// every previous guess gq by determining the number of black pins Xq′ (c) and the
+// number of white pins Yq′(c) that the code c would score if the previous guess gq
+// were the secret code. The difference between Xq′ and Xq and between Yq′ and Yq
+// is an indication of the quality of the code c; if these differences are zero for
+// each previous guess gq then the code is eligible.
+//
+// {X'q(c), Y'q(c)} is the result produced for guess gq if c were the secret
+// {Xq, Yq} is the actual result produced for the guess at move q
+//
+// f(c;i) = a(sum[q=1-i](|X'q(c) - Xq|) + sum[q=1-i](|Y'q(c) - Yq|) + bP(i-1)
+//
+// P is the number of positions in the game
+// a and b are weights allowing us to balance the weight of black pins (corrects)
+// against a constant proportional to P and the number of turns taken.
+// by default, a = 2, b = 2
+type Berghman struct {
+	A, B float64
+}
+
+func (f Berghman) Evaluate(code mm.Code, history mm.History, size mm.GameSize) float64 {
+	P := float64(size.Positions)
+	sumX, sumY := Differences(code, history, size.Colors)
+	return (f.A * sumX) + sumY + (f.B * P * float64(len(history)-1))
+}
+
+// Differences returns sum(|X'q(c) - Xq|) and sum(|Y'q(c) - Yq|) over every
+// move in history
+func Differences(code mm.Code, history mm.History, colors byte) (float64, float64) {
+	sumX := 0.0
+	sumY := 0.0
+
+	for _, m := range history {
+		// resQ = {Xq,Yq}
+		// resP = {X'q(c), Y'q(c)
+		resQ := m.Result
+		resP := mm.Score(code, m.Guess, colors)
+
+		sumX += absi(resP.Correct - resQ.Correct)
+		sumY += absi(resP.HalfCorrect - resQ.HalfCorrect)
+	}
+
+	return sumX, sumY
+}
+
+func absi(v int) float64 {
+	return math.Abs(float64(v))
+}
+
+// PenalizeGuessed adds Penalty to the fitness of codes which have already
+// been guessed, since they can't be the secret
+type PenalizeGuessed struct {
+	FitnessFunction
+	Penalty float64
+}
+
+func (f PenalizeGuessed) Evaluate(code mm.Code, history mm.History, size mm.GameSize) float64 {
+	fitness := f.FitnessFunction.Evaluate(code, history, size)
+	for _, m := range history {
+		if m.Guess.String() == code.String() {
+			return fitness + f.Penalty
+		}
+	}
+	return fitness
+}
@@ -0,0 +1,135 @@
+package genetic
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/ianmcmahon/mastermind/internal/pool"
+)
+
+// island is one subpopulation of the island model.  It evolves on its own
+// copy of the solver, so it has its own random source and adaptive rates.
+type island struct {
+	*Solver
+	population Population
+	Ei         Population
+}
+
+// newIslands makes n islands off s, each seeded from s's random source so a
+// seeded solver stays reproducible
+func (s *Solver) newIslands(n int) []*island {
+	islands := make([]*island, n)
+	for i := range islands {
+		is := *s
+		is.island = i
+		is.rand = rand.New(rand.NewSource(s.rand.Int63()))
+		is.resetRates()
+		islands[i] = &island{
+			Solver:     &is,
+			population: is.InitializePopulation(s.config.PopulationSize),
+			Ei:         make(Population, 0),
+		}
+	}
+	return islands
+}
+
+// searchIslands evolves Islands subpopulations in parallel.  Every
+// MigrationInterval generations the islands stop, their eligible codes are
+// pooled, and each island's best Migrants move on to the next island in the
+// ring.
+func (s *Solver) searchIslands() Population {
+	islands := s.newIslands(s.config.Islands)
+
+	// OnGeneration is called from every island, one at a time
+	if s.OnGeneration != nil {
+		var mu sync.Mutex
+		onGeneration := s.OnGeneration
+		for _, is := range islands {
+			is.OnGeneration = func(stats GenerationStats) {
+				mu.Lock()
+				defer mu.Unlock()
+				onGeneration(stats)
+			}
+		}
+	}
+
+	interval := s.config.MigrationInterval
+	if interval < 1 {
+		interval = s.config.MaxGenerations
+	}
+
+	Ei := make(Population, 0)
+	for h := 0; h < s.config.MaxGenerations; h += interval {
+		epoch := interval
+		if h+epoch > s.config.MaxGenerations {
+			epoch = s.config.MaxGenerations - h
+		}
+
+		limiter := pool.New(len(islands))
+		for _, is := range islands {
+			is := is
+			limiter.Go(func() error {
+				for g := h; g < h+epoch; g++ {
+					is.population = is.evolve(g, is.population, is.Ei)
+					if len(is.Ei) >= s.config.MaxEligible {
+						break
+					}
+				}
+				return nil
+			})
+		}
+		limiter.Wait()
+
+		for _, is := range islands {
+			for k, v := range is.Ei {
+				Ei[k] = v
+			}
+		}
+		s.logf(LevelDebug, "move %d: generation %d: islands found %d eligible", s.move, h+epoch, len(Ei))
+		if len(Ei) >= s.config.MaxEligible {
+			break
+		}
+
+		s.migrate(islands)
+	}
+
+	s.population = make(Population)
+	for _, is := range islands {
+		for k, v := range is.population {
+			s.population[k] = v
+		}
+	}
+	return Ei
+}
+
+// migrate copies the best Migrants of each island into the next island in
+// the ring, replacing its worst citizens so the population size holds
+func (s *Solver) migrate(islands []*island) {
+	if s.config.Migrants < 1 || len(islands) < 2 {
+		return
+	}
+
+	emigrants := make([]fitnessList, len(islands))
+	for i, is := range islands {
+		ranked := is.Fitness(is.population)
+		n := s.config.Migrants
+		if n > len(ranked) {
+			n = len(ranked)
+		}
+		emigrants[i] = ranked[:n]
+	}
+
+	for i, is := range islands {
+		from := emigrants[(i+len(islands)-1)%len(islands)]
+		ranked := is.Fitness(is.population)
+		for j, c := range from {
+			if _, ok := is.population[c.Key()]; ok {
+				continue
+			}
+			if worst := len(ranked) - 1 - j; worst >= 0 {
+				delete(is.population, ranked[worst].Key())
+			}
+			is.population[c.Key()] = c
+		}
+	}
+}
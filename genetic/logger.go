@@ -0,0 +1,66 @@
+package genetic
+
+import (
+	"fmt"
+	"io"
+	"log"
+)
+
+// Level is how verbose a log message is
+type Level int
+
+const (
+	// LevelWarn is for things gone wrong that the solver works around
+	LevelWarn Level = iota
+	// LevelInfo is each guess made
+	LevelInfo
+	// LevelDebug is the progress of each move's search
+	LevelDebug
+	// LevelTrace is every parent pairing and population dump
+	LevelTrace
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelWarn:
+		return "WARN"
+	case LevelInfo:
+		return "INFO"
+	case LevelDebug:
+		return "DEBUG"
+	case LevelTrace:
+		return "TRACE"
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// Logger receives the solver's messages.  The default logger discards them.
+type Logger interface {
+	Logf(level Level, format string, args ...interface{})
+}
+
+type silent struct{}
+
+func (silent) Logf(Level, string, ...interface{}) {}
+
+// LevelLogger writes the messages up to Level to a standard logger
+type LevelLogger struct {
+	*log.Logger
+	Level Level
+}
+
+// NewLogger logs messages up to level to w
+func NewLogger(w io.Writer, level Level) *LevelLogger {
+	return &LevelLogger{Logger: log.New(w, "", log.LstdFlags), Level: level}
+}
+
+func (l *LevelLogger) Logf(level Level, format string, args ...interface{}) {
+	if level > l.Level {
+		return
+	}
+	l.Printf("%s: %s", level, fmt.Sprintf(format, args...))
+}
+
+func (s *Solver) logf(level Level, format string, args ...interface{}) {
+	s.config.Logger.Logf(level, format, args...)
+}
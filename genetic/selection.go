@@ -0,0 +1,104 @@
+package genetic
+
+import (
+	"math/rand"
+)
+
+// Selector chooses the parents of the next generation.  ranked is the
+// population sorted fittest first, and n is the number of pairs wanted.
+// Lower fitness is better.  Any randomness should come from rng, so that
+// seeded solvers are reproducible.
+type Selector interface {
+	Pairs(ranked []Citizen, n int, rng *rand.Rand) [][2]Citizen
+}
+
+// Greedy pairs off the fittest citizens in order: the first with the
+// second, the third with the fourth, and so on.  It's the strongest
+// selection pressure, and the default.
+type Greedy struct{}
+
+func (Greedy) Pairs(ranked []Citizen, n int, rng *rand.Rand) [][2]Citizen {
+	pairs := [][2]Citizen{}
+	for i := 0; i < n && 2*i+1 < len(ranked); i++ {
+		pairs = append(pairs, [2]Citizen{ranked[2*i], ranked[2*i+1]})
+	}
+	return pairs
+}
+
+// Tournament picks each parent as the fittest of Size citizens drawn at
+// random.  Larger tournaments mean stronger selection pressure.
+type Tournament struct {
+	Size int
+}
+
+func (t Tournament) Pairs(ranked []Citizen, n int, rng *rand.Rand) [][2]Citizen {
+	return pairsOf(ranked, n, func() Citizen {
+		// ranked is sorted, so the fittest drawn is the lowest index
+		best := rng.Intn(len(ranked))
+		for i := 1; i < t.Size; i++ {
+			if j := rng.Intn(len(ranked)); j < best {
+				best = j
+			}
+		}
+		return ranked[best]
+	})
+}
+
+// Roulette picks each parent with probability proportional to 1/(1+f),
+// where f is its fitness.
+type Roulette struct{}
+
+func (Roulette) Pairs(ranked []Citizen, n int, rng *rand.Rand) [][2]Citizen {
+	weights := make([]float64, len(ranked))
+	for i, c := range ranked {
+		weights[i] = 1 / (1 + c.fitness)
+	}
+	return pairsOf(ranked, n, weightedPicker(ranked, weights, rng))
+}
+
+// Rank picks each parent with probability proportional to its rank, the
+// fittest of n citizens weighing n and the least fit weighing 1.  Unlike
+// Roulette, the pressure doesn't depend on how spread out the fitnesses are.
+type Rank struct{}
+
+func (Rank) Pairs(ranked []Citizen, n int, rng *rand.Rand) [][2]Citizen {
+	weights := make([]float64, len(ranked))
+	for i := range ranked {
+		weights[i] = float64(len(ranked) - i)
+	}
+	return pairsOf(ranked, n, weightedPicker(ranked, weights, rng))
+}
+
+// picks n pairs with pick, avoiding pairing a citizen with itself where possible
+func pairsOf(ranked []Citizen, n int, pick func() Citizen) [][2]Citizen {
+	pairs := [][2]Citizen{}
+	if len(ranked) < 2 {
+		return pairs
+	}
+	for i := 0; i < n; i++ {
+		x := pick()
+		y := pick()
+		for tries := 0; y.Key() == x.Key() && tries < 10; tries++ {
+			y = pick()
+		}
+		pairs = append(pairs, [2]Citizen{x, y})
+	}
+	return pairs
+}
+
+func weightedPicker(ranked []Citizen, weights []float64, rng *rand.Rand) func() Citizen {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	return func() Citizen {
+		roll := rng.Float64() * total
+		for i, w := range weights {
+			roll -= w
+			if roll < 0 {
+				return ranked[i]
+			}
+		}
+		return ranked[len(ranked)-1]
+	}
+}
@@ -4,50 +4,161 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
-	"rn/parallel"
 	"sort"
+	"time"
 
 	mm "github.com/ianmcmahon/mastermind"
-)
-
-const (
-	initialPopulationSize int     = 150
-	maxGenerations        int     = 100
-	maxSamplePopulation   int     = 60
-	fitnessThreshold      float64 = 0.0
-	spawnRate             float64 = 0.5
+	"github.com/ianmcmahon/mastermind/internal/pool"
 )
 
 type Solver struct {
 	*mm.Game
-	move    int
-	guesses []mm.Code
-	results []mm.Result
+	codemaker mm.Codemaker
+	config    Config
+	move      int
+	guesses   []mm.Code
+	results   []mm.Result
+
+	// current rates, which differ from the configured ones when adapting
+	mutationRate    float64
+	permutationRate float64
+	inversionRate   float64
+	// Diversity of the latest generation
+	Diversity float64
+
+	rand      *rand.Rand
+	fitnessFn FitnessFunction
+	cache     *fitnessCache
+	// the population and eligible set the latest search ended with, and the
+	// codes the next search starts from
+	population Population
+	ei         Population
+	seed       mm.CodeSlice
+	// the code children are spawned in, before they're kept
+	child mm.Code
+	// which island this is, in the island model
+	island int
+
+	// OnGeneration, if set, is called with the stats of every generation
+	OnGeneration func(GenerationStats)
+}
+
+// GenerationStats describe one generation of the search for a move's guess
+type GenerationStats struct {
+	Move       int
+	Generation int
+	// Island is the subpopulation the generation belongs to, always zero
+	// unless Config.Islands is more than one
+	Island int
+	// BestFitness and MeanFitness are over the generation's population
+	BestFitness float64
+	MeanFitness float64
+	// Diversity is unique codes over the configured population size
+	Diversity float64
+	// Eligible is the size of the eligible set Ei after this generation,
+	// and NewEligible how many of those this generation found
+	Eligible    int
+	NewEligible int
+	// Elapsed is the time spent on this generation
+	Elapsed time.Duration
+}
+
+func (s *Solver) generationStats(h int, pop, Ei Population, eligibleBefore int, elapsed time.Duration) GenerationStats {
+	stats := GenerationStats{
+		Move:        s.move,
+		Generation:  h,
+		Island:      s.island,
+		Diversity:   s.Diversity,
+		Eligible:    len(Ei),
+		NewEligible: len(Ei) - eligibleBefore,
+		Elapsed:     elapsed,
+	}
+	first := true
+	for _, c := range pop {
+		if first || c.fitness < stats.BestFitness {
+			stats.BestFitness = c.fitness
+			first = false
+		}
+		stats.MeanFitness += c.fitness
+	}
+	if len(pop) > 0 {
+		stats.MeanFitness /= float64(len(pop))
+	}
+	return stats
+}
+
+// the most any rate is raised to when adapting
+const maxAdaptiveRate = 0.5
+
+func NewSolver(g *mm.Game, opts ...Option) *Solver {
+	return NewCodemakerSolver(g, opts...)
 }
 
-func NewSolver(g *mm.Game) *Solver {
+// NewCodemakerSolver returns a solver which plays against any codemaker.
+// Unless cm is itself a *mm.Game, the embedded game only keeps score.
+func NewCodemakerSolver(cm mm.Codemaker, opts ...Option) *Solver {
+	g, ok := cm.(*mm.Game)
+	if !ok {
+		g = mm.NewCustomGame(cm.GameSize().Positions, cm.GameSize().Colors)
+	}
 	s := &Solver{
-		Game: g,
-		move: 0,
+		Game:      g,
+		codemaker: cm,
+		config:    DefaultConfig(),
+		move:      0,
+	}
+	for _, opt := range opts {
+		opt(&s.config)
+	}
+	seed := s.config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
 	}
+	s.rand = rand.New(rand.NewSource(seed))
+	s.fitnessFn = s.config.Fitness
+	if s.config.Logger == nil {
+		s.config.Logger = silent{}
+	}
+	if s.config.Selector == nil {
+		s.config.Selector = Greedy{}
+	}
+	if s.fitnessFn == nil {
+		s.fitnessFn = Berghman{A: s.config.FitnessA, B: s.config.FitnessB}
+	}
+	s.cache = newFitnessCache()
+	s.resetRates()
+	// moves are numbered from 1
 	maxGuesses := s.maxGuesses()
-	s.results = make([]mm.Result, maxGuesses)
-	s.guesses = make([]mm.Code, maxGuesses)
+	s.results = make([]mm.Result, maxGuesses+1)
+	s.guesses = make([]mm.Code, maxGuesses+1)
 	return s
 }
 
+// Seed makes the solver reproducible, as WithSeed does, if it's called
+// before Solve
+func (s *Solver) Seed(seed int64) {
+	s.config.Seed = seed
+	s.rand = rand.New(rand.NewSource(seed))
+}
+
 func (s *Solver) Solve() (mm.Code, error) {
 	var err error
 
 	guess := s.InitialGuess()
+	if s.move > 0 {
+		// resuming a restored game
+		if guess, err = s.choose(s.ei); err != nil {
+			return nil, err
+		}
+	}
 
 	for {
-		if s.move >= 9 {
+		if s.move >= s.maxGuesses() {
 			return nil, fmt.Errorf("didn't find solution in %d moves", s.move)
 		}
 		s.move++
 		s.guesses[s.move] = guess
-		fmt.Printf("GUESS: %v\n", guess)
+		s.logf(LevelInfo, "move %d: guess %v", s.move, guess)
 		s.results[s.move], err = s.ScoredGuess(guess)
 		if err != nil {
 			return nil, err
@@ -57,141 +168,194 @@ func (s *Solver) Solve() (mm.Code, error) {
 			return guess, nil
 		}
 
-		Ei := make(Population, 0)
-		population := s.InitializePopulation(initialPopulationSize)
+		if s.config.Islands > 1 {
+			s.ei = s.searchIslands()
+		} else {
+			s.ei = s.search()
+		}
+		s.seed = nil
+		s.logf(LevelDebug, "move %d: Ei %d: %v", s.move, len(s.ei), s.ei)
 
-		fmt.Printf("move %d: initial %d\n", s.move, len(population))
+		if s.config.StateFile != "" {
+			if err := s.State().Save(s.config.StateFile); err != nil {
+				return nil, err
+			}
+		}
 
-		for h := 0; h < maxGenerations; h++ {
-			fmt.Printf("move %d generation %d\n", s.move, h)
+		if guess, err = s.choose(s.ei); err != nil {
+			return nil, err
+		}
+	}
+}
 
-			// add last move's Ei to this move's population
-			for k, v := range Ei {
-				population[k] = v
-			}
+// choose picks the next guess from the eligible codes
+func (s *Solver) choose(Ei Population) (mm.Code, error) {
+	// with few codes left, guessing well beats guessing consistently
+	if len(Ei) < s.config.EndgameThreshold {
+		return s.endgame(Ei)
+	}
+	return s.BestCandidate(Ei).Code, nil
+}
 
-			// Generate new population using crossover, mutation, inversion and permutation;
-			population = s.Generate(population)
-
-			for _, c := range population {
-				f := s.fitness(c)
-				if s.move > 1 {
-					//fmt.Printf("move %d: second cull: %v - %.2f\n", s.move, c, f)
-				}
-				if f <= fitnessThreshold {
-					Ei[c.Key()] = c
-				}
-			}
-			if len(Ei) >= maxSamplePopulation {
-				break
-			}
+// ScoredGuess scores code with the solver's codemaker
+func (s *Solver) ScoredGuess(code mm.Code) (mm.Result, error) {
+	if s.codemaker == mm.Codemaker(s.Game) {
+		return s.Game.ScoredGuess(code)
+	}
+	s.TurnsTaken++
+	result, err := s.codemaker.ScoredGuess(code)
+	if err == nil && s.IsWin(result) {
+		s.SolveTime = s.Elapsed()
+	}
+	return result, err
+}
+
+// evolves a population until enough eligible codes are found or the
+// generations run out, returning the eligible codes
+func (s *Solver) search() Population {
+	Ei := make(Population, 0)
+	population := s.InitializePopulation(s.config.PopulationSize)
+	s.resetRates()
+
+	s.logf(LevelDebug, "move %d: initial %d", s.move, len(population))
+
+	for h := 0; h < s.config.MaxGenerations; h++ {
+		population = s.evolve(h, population, Ei)
+		if len(Ei) >= s.config.MaxEligible {
+			break
 		}
-		fmt.Printf("move %d: population %d\n", s.move, len(population))
-		fmt.Printf("move %d: Ei %d: %v\n", s.move, len(Ei), Ei)
+	}
+	s.logf(LevelDebug, "move %d: population %d", s.move, len(population))
+
+	s.population = population
+	return Ei
+}
+
+// evolves population by one generation, adding the eligible codes found to Ei
+func (s *Solver) evolve(h int, population, Ei Population) Population {
+	s.logf(LevelDebug, "move %d generation %d", s.move, h)
+	start := time.Now()
+	eligible := len(Ei)
 
-		guess = s.BestCandidate(Ei).Code
+	// add last move's Ei to this move's population
+	for k, v := range Ei {
+		population[k] = v
 	}
+
+	// Generate new population using crossover, mutation, inversion and permutation;
+	population = s.Generate(population)
+	s.adapt(population)
+
+	for _, c := range population {
+		if s.eligible(c) {
+			Ei[c.Key()] = c
+		}
+	}
+	if s.OnGeneration != nil {
+		s.OnGeneration(s.generationStats(h, population, Ei, eligible, time.Since(start)))
+	}
+	return population
 }
 
 // theoretically this algorithm should be able to complete in O(n log log n)
 // n^2 should be plenty big enough; maybe revisit and calculate a tighter
-// set once the algorithm is optimal
+// set once the algorithm is optimal.  Config.MaxMoves overrides it.
 func (s *Solver) maxGuesses() int {
+	if s.config.MaxMoves > 0 {
+		return s.config.MaxMoves
+	}
 	return int(math.Ceil(math.Pow(float64(s.Positions()), 2.0)))
 }
 
+// The opener is made of blocks of colors, as even as possible with the
+// bigger blocks first, using about half as many colors as positions:
+// 0012 for 4 positions, 00123 for 5, 001123 for 6, 00112234 for 8.
 func (s *Solver) InitialGuess() mm.Code {
 	size := s.GameSize()
-	switch size.Positions {
-	case 4:
-		return mm.Code{0, 0, 1, 2}
-	case 5:
-		return mm.Code{0, 0, 1, 2, 3}
-	case 6:
-		return mm.Code{0, 0, 1, 1, 2, 3}
+	colors := (size.Positions + 3) / 2
+	if colors > int(size.Colors) {
+		colors = int(size.Colors)
 	}
-	return mm.Code{}
+
+	guess := make(mm.Code, 0, size.Positions)
+	for color := 0; color < colors; color++ {
+		block := size.Positions / colors
+		if color < size.Positions%colors {
+			block++
+		}
+		for i := 0; i < block; i++ {
+			guess = append(guess, byte(color))
+		}
+	}
+	return guess
 }
 
 // Initialize population;
 // A population of size 150 is used, which is initialized randomly,
 // taking into account that every code in the population should be distinct.
+// Codes the solver was warm started with come first.
 func (s *Solver) InitializePopulation(size int) Population {
 	set := make(Population, size)
-	for i := 0; i < size; {
-		code := s.RandomCode()
-		if _, ok := set[code.String()]; !ok {
-			set[code.String()] = Citizen{Code: code}
+	for _, code := range s.seed {
+		if len(set) == size {
+			break
+		}
+		set[keyOf(code)] = Citizen{Code: code}
+	}
+	// a code already drawn is drawn again in place, so only the codes
+	// kept are allocated
+	var code mm.Code
+	for i := len(set); i < size; {
+		if code == nil {
+			code = make(mm.Code, s.Positions())
+		}
+		s.fillRandom(code)
+		if _, ok := set[keyOf(code)]; !ok {
+			set[keyOf(code)] = Citizen{Code: code}
+			code = nil
 			i++
 		}
 	}
 	return set
 }
 
-//  In order to compute the fitness value of a chromosome c, we compare it with
-// every previous guess gq by determining the number of black pins Xq′ (c) and the
-// number of white pins Yq′(c) that the code c would score if the previous guess gq
-// were the secret code. The difference between Xq′ and Xq and between Yq′ and Yq
-// is an indication of the quality of the code c; if these differences are zero for
-// each previous guess gq then the code is eligible.
-//
-// {X'q(c), Y'q(c)} is the result produced for guess gq if c were the secret
-// {Xq, Yq} is the actual result produced for the guess at move q
-//
-// f(c;i) = a(sum[q=1-i](|X'q(c) - Xq|) + sum[q=1-i](|Y'q(c) - Yq|) + bP(i-1)
-//
-// P is the number of positions in the game
-// a and b are weights allowing us to balance the weight of black pins (corrects)
-// against a constant proportional to P and the number of turns taken.
-// initially, a = 2, b = 2
+// fitness of c by the solver's fitness function, Berghman by default,
+// scored at most once a move
 func (s *Solver) fitness(c Citizen) float64 {
-	a := 2.0
-	b := 2.0
-	P := float64(s.Size.Positions)
+	return s.cache.get(s.move, c.Key(), func() float64 {
+		return s.fitnessFn.Evaluate(c.Code, s.history(), s.Size)
+	})
+}
 
-	sumX := 0.0
-	sumY := 0.0
+// a code is eligible when it would have produced the same result for every
+// previous guess, ie when the differences in the fitness function are zero
+func (s *Solver) eligible(c Citizen) bool {
+	sumX, sumY := Differences(c.Code, s.history(), s.Size.Colors)
+	return sumX == 0 && sumY == 0
+}
 
+// the moves made so far
+func (s *Solver) history() mm.History {
+	h := make(mm.History, 0, s.move)
 	for q := 1; q <= s.move; q++ {
-		gq := s.guesses[q]
-		// resQ = {Xq,Yq}
-		// resP = {X'q(c), Y'q(c)
-		resQ := s.results[q]
-		resP, _ := mm.CheckCode(c.Code, gq, s.Size.Colors)
-
-		sumX += absi(resP.Correct - resQ.Correct)
-		sumY += absi(resP.HalfCorrect - resQ.HalfCorrect)
+		h = append(h, mm.Move{Guess: s.guesses[q], Result: s.results[q]})
 	}
-
-	fitness := (a * sumX) + sumY + (b * P * float64((s.move - 1)))
-
-	return fitness
-}
-
-func absi(v int) float64 {
-	return math.Abs(float64(v))
+	return h
 }
 
 func (s *Solver) Fitness(pop Population) fitnessList {
-	citizens := fitnessList{}
-
-	limiter := parallel.NewLimiter(1)
-
-	for _, citizen := range pop {
-		c := citizen
-
-		limiter.Go(func() error {
-			f := s.fitness(c)
-			c.fitness = f
-			limiter.Locked(func() error {
-				citizens = append(citizens, c)
-				return nil
-			})
-			return nil
-		})
+	citizens := make(fitnessList, 0, len(pop))
+	for _, c := range pop {
+		citizens = append(citizens, c)
 	}
 
-	limiter.Wait()
+	// each worker scores its own share of the citizens; on one worker
+	// that's a plain loop, without a goroutine
+	pool.Ranges(len(citizens), pool.Workers(s.config.Workers), 1, func(_, lo, hi int) {
+		for i := lo; i < hi; i++ {
+			citizens[i].fitness = s.fitness(citizens[i])
+		}
+	})
 
 	// sort elders by fitness
 	sort.Sort(citizens)
@@ -204,52 +368,149 @@ func (s *Solver) Generate(pop Population) Population {
 	nextGen := make(Population, len(pop))
 
 	elders := s.Fitness(pop)
-	fmt.Printf("move %d: %d: %v\n", s.move, len(elders), elders)
+	s.logf(LevelTrace, "move %d: %d: %v", s.move, len(elders), elders)
 
-	// take the first half of elders
-	elders = elders[0 : len(elders)/2]
+	// the fittest elders carry over unchanged
+	for i := 0; i < s.config.Elitism && i < len(elders); i++ {
+		nextGen.add(elders[i])
+	}
 
-	// pair off top two elders and spawn until list is consumed
-	for {
-		if len(elders) < 2 {
-			break
-		}
-		x, y := elders[0], elders[1]
-		elders = elders[2:]
+	// SpawnRate of the elders breed, in pairs chosen by the selector
+	breeders := int(float64(len(elders)) * s.config.SpawnRate)
+	pairs := s.config.Selector.Pairs(elders, breeders/2, s.rand)
+	// children are spawned in the scratch code, and copied out to the
+	// generation's block of codes only once they're kept
+	if len(s.child) != s.Positions() {
+		s.child = make(mm.Code, s.Positions())
+	}
+	block := make(mm.Code, 0, 2*len(pairs)*s.Positions())
+	for _, pair := range pairs {
+		x, y := pair[0], pair[1]
 
 		// eligible parents go in next generation
-		nextGen[x.Key()] = x
-		nextGen[y.Key()] = y
+		nextGen.add(x)
+		nextGen.add(y)
+
+		// spawn two inverse children, replacing any that already exist;
+		// both go in next generation
+		a := s.breed(x, y, pop, nextGen, &block)
+		b := s.breed(y, x, pop, nextGen, &block)
 
-		// spawn two inverse children
-		a := s.Spawn(x, y)
-		b := s.Spawn(y, x)
+		s.logf(LevelTrace, "eligible parents %v and %v produced children %v and %v", x, y, a, b)
+	}
 
-		a.fitness = s.fitness(a)
-		b.fitness = s.fitness(b)
+	s.logf(LevelTrace, "initial population %d, next generation %d", len(pop), len(nextGen))
 
-		// both go in next generation
-		nextGen[a.Key()] = a
-		nextGen[b.Key()] = b
+	return s.resize(nextGen)
+}
 
-		fmt.Printf("eligible parents %v and %v produced children %v and %v\n", x, y, a, b)
+// breed spawns a child of x and y in the scratch code, made unique among pop
+// and nextGen, and keeps it in nextGen with its code copied to the end of
+// block.  Until then, nothing is allocated.
+func (s *Solver) breed(x, y Citizen, pop, nextGen Population, block *mm.Code) Citizen {
+	s.spawn(s.child, x, y)
+	c := s.unique(Citizen{Code: s.child}, pop, nextGen)
+	c.fitness = s.fitness(c)
+
+	start := len(*block)
+	*block = append(*block, c.Code...)
+	c.Code = (*block)[start:len(*block):len(*block)]
+	nextGen[c.Key()] = c
+	return c
+}
+
+// resize keeps pop at the configured size.  Parents picked for more than one
+// pair, and children identical to a parent, take up a single place, so a
+// new generation falls short; it's topped up with fresh random codes.  One
+// grown by last move's eligible codes is cut back to its fittest.
+func (s *Solver) resize(pop Population) Population {
+	size := s.config.PopulationSize
+	var code mm.Code
+	for tries := 0; len(pop) < size && tries < 100*size; tries++ {
+		if code == nil {
+			code = make(mm.Code, s.Positions())
+		}
+		s.fillRandom(code)
+		c := Citizen{Code: code}
+		if _, ok := pop[c.Key()]; !ok {
+			c.fitness = s.fitness(c)
+			pop[c.Key()] = c
+			code = nil
+		}
 	}
 
-	fmt.Printf("initial population %d, next generation %d\n", len(pop), len(nextGen))
+	if len(pop) > size {
+		ranked := make(fitnessList, 0, len(pop))
+		for _, c := range pop {
+			ranked = append(ranked, c)
+		}
+		sort.Sort(ranked)
+		for _, c := range ranked[size:] {
+			delete(pop, c.Key())
+		}
+	}
+	return pop
+}
 
-	return nextGen
+// if c is already in either population, returns a random code which isn't
+// instead, to keep the population diverse.  If none is found in a reasonable
+// number of tries, c is returned anyway.  c is a new child, which nothing
+// else holds, so the random codes are drawn into it.
+func (s *Solver) unique(c Citizen, pop, nextGen Population) Citizen {
+	for tries := 0; tries < 100; tries++ {
+		key := c.Key()
+		_, inPop := pop[key]
+		_, inNext := nextGen[key]
+		if !inPop && !inNext {
+			break
+		}
+		s.fillRandom(c.Code)
+	}
+	return c
+}
+
+func (s *Solver) resetRates() {
+	s.mutationRate = s.config.MutationRate
+	s.permutationRate = s.config.PermutationRate
+	s.inversionRate = s.config.InversionRate
+}
+
+// measures the diversity of pop, and if adapting, doubles the rates while
+// it's below the minimum or halves their excess over the configured rates
+// once it isn't
+func (s *Solver) adapt(pop Population) {
+	s.Diversity = float64(len(pop)) / float64(s.config.PopulationSize)
+	if !s.config.AdaptiveRates {
+		return
+	}
+
+	adjust := func(rate, configured float64) float64 {
+		if s.Diversity < s.config.MinDiversity {
+			return math.Min(math.Max(2*rate, 0.01), maxAdaptiveRate)
+		}
+		return configured + (rate-configured)/2
+	}
+	s.mutationRate = adjust(s.mutationRate, s.config.MutationRate)
+	s.permutationRate = adjust(s.permutationRate, s.config.PermutationRate)
+	s.inversionRate = adjust(s.inversionRate, s.config.InversionRate)
 }
 
 func (s *Solver) BestCandidate(p Population) Citizen {
 	// naive way: take random one.
-	for _, c := range p {
-		return c
+	// (in code order, so a seeded solver picks the same one every run)
+	if len(p) > 0 {
+		codes := make(mm.CodeSlice, 0, len(p))
+		for _, c := range p {
+			codes = append(codes, c.Code)
+		}
+		sort.Sort(codes)
+		return p[keyOf(codes[s.rand.Intn(len(codes))])]
 	}
 
 	// whitepaper way:
 	// algorithmically determine the code most like other codes
-	fmt.Printf("WARN: Best Candidate didn't find a match, returning random code!\n")
-	return Citizen{Code: s.RandomCode()}
+	s.logf(LevelWarn, "Best Candidate didn't find a match, returning random code!")
+	return Citizen{Code: s.randomCode()}
 }
 
 // Subsequent generations of the population are created through 1-point or 2-point crossover
@@ -261,47 +522,57 @@ func (s *Solver) BestCandidate(p Population) Citizen {
 // When these procedures lead to a code that is already present in the population, it is replaced
 // by a randomly composed code, in order to improve the diversity of the population.
 func (s *Solver) Spawn(x, y Citizen) Citizen {
-	child := s.crossover(x, y)
-	s.mutate(child)
-	s.permute(child)
-	s.invert(child)
-	return child
+	child := make(mm.Code, s.Positions())
+	s.spawn(child, x, y)
+	return Citizen{Code: child}
+}
+
+// spawn is Spawn into child, which is overwritten
+func (s *Solver) spawn(child mm.Code, x, y Citizen) {
+	s.crossover(child, x, y)
+	c := Citizen{Code: child}
+	s.mutate(c)
+	s.permute(c)
+	s.invert(c)
 }
 
 // 1-point crossover with probability 0.5
 // 2-point crossover with probability 0.5
-// attempts to divide the chromosome into as equal parts as possible
-// currently always uses the same combinations; maybe the inverse should be possible?
-func (s *Solver) crossover(x, y Citizen) Citizen {
-	roll := rand.Float64()
+// cut points are chosen uniformly at random each spawn, and the child, written
+// into child, takes the genes of y between them and those of x elsewhere
+func (s *Solver) crossover(child mm.Code, x, y Citizen) {
+	roll := s.rand.Float64()
 
-	child := make(mm.Code, s.Positions())
 	copy(child, x.Code)
 
-	cp1, cp2 := 0, 0
-	if roll < 0.5 {
-		cp2 = int(s.Size.Positions / 2)
-	} else {
-		cp1 = int(s.Size.Positions / 3)
-		cp2 = s.Size.Positions - cp1
+	// cuts fall between positions, so there are Positions-1 of them
+	cuts := s.Positions() - 1
+	if cuts < 1 {
+		return
 	}
 
-	for i := cp1; i < cp2; i++ {
-		child[i] = y.Code[i]
+	cp1, cp2 := 0, 1+s.rand.Intn(cuts)
+	if roll >= 0.5 && cuts >= 2 {
+		cp1, cp2 = 1+s.rand.Intn(cuts), 1+s.rand.Intn(cuts-1)
+		if cp2 >= cp1 {
+			cp2++
+		} else {
+			cp1, cp2 = cp2, cp1
+		}
 	}
 
-	return Citizen{Code: child}
+	copy(child[cp1:cp2], y.Code[cp1:cp2])
 }
 
-//  With a probability of 0.03, a mutation replaces the color
+// With a probability of MutationRate (0.03), a mutation replaces the color
 // of one randomly chosen position by a random other color.
 func (s *Solver) mutate(c Citizen) bool {
-	roll := rand.Float64()
+	roll := s.rand.Float64()
 
-	if roll < 0.03 {
-		pos := rand.Intn(s.Positions())
+	if roll < s.mutationRate {
+		pos := s.rand.Intn(s.Positions())
 		for {
-			col := byte(rand.Intn(int(s.Colors())))
+			col := byte(s.rand.Intn(int(s.Colors())))
 			if c.Code[pos] != col {
 				c.Code[pos] = col
 				return true
@@ -312,16 +583,16 @@ func (s *Solver) mutate(c Citizen) bool {
 	return false
 }
 
-// 0.03 chance of permutation, where the colors of two random positions are switched.
+// PermutationRate (0.03) chance of permutation, where the colors of two random positions are switched.
 func (s *Solver) permute(c Citizen) bool {
-	roll := rand.Float64()
+	roll := s.rand.Float64()
 
-	if roll < 0.03 {
-		p1, p2 := rand.Intn(s.Positions()), 0
+	if roll < s.permutationRate {
+		p1, p2 := s.rand.Intn(s.Positions()), 0
 		i := 0
 		for {
 			i++
-			p2 = rand.Intn(s.Positions())
+			p2 = s.rand.Intn(s.Positions())
 			if p1 == p2 {
 				continue
 			}
@@ -339,30 +610,41 @@ func (s *Solver) permute(c Citizen) bool {
 	return false
 }
 
-// 0.02 chance of inversion, in which case two positions are randomly picked,
+// InversionRate (0.02) chance of inversion, in which case two positions are randomly picked,
 // and the sequence of colors between these positions is inverted.
 func (s *Solver) invert(c Citizen) bool {
-	roll := rand.Float64()
+	roll := s.rand.Float64()
 
-	if roll < 0.02 {
-		p1, p2 := rand.Intn(s.Positions()), 0
+	if roll < s.inversionRate {
+		p1, p2 := s.rand.Intn(s.Positions()), 0
 		for {
-			p2 = rand.Intn(s.Positions())
+			p2 = s.rand.Intn(s.Positions())
 			if p1 != p2 {
 				break
 			}
 		}
-		p1, p2 = order(p1, p2)
-		s := make([]byte, 1+p2-p1)
-		copy(s, c.Code[p1:p2+1])
-		for i, v := range s {
-			c.Code[p2-i] = v
+		for p1, p2 = order(p1, p2); p1 < p2; p1, p2 = p1+1, p2-1 {
+			c.Code[p1], c.Code[p2] = c.Code[p2], c.Code[p1]
 		}
 		return true
 	}
 	return false
 }
 
+// a random code drawn from the solver's own source
+func (s *Solver) randomCode() mm.Code {
+	code := make(mm.Code, s.Positions())
+	s.fillRandom(code)
+	return code
+}
+
+// fillRandom draws a random code into code, reusing it
+func (s *Solver) fillRandom(code mm.Code) {
+	for i := range code {
+		code[i] = byte(s.rand.Intn(int(s.Colors())))
+	}
+}
+
 func order(x, y int) (int, int) {
 	if y < x {
 		return y, x
@@ -370,15 +652,42 @@ func order(x, y int) (int, int) {
 	return x, y
 }
 
-type Population map[string]Citizen
+type Population map[citizenKey]Citizen
+
+// add puts c in p, unless it's there already
+func (p Population) add(c Citizen) {
+	if _, ok := p[c.Key()]; !ok {
+		p[c.Key()] = c
+	}
+}
+
+// citizenKey keys a code in a Population or the fitness cache: by its
+// CodeKey, or, for a code too long to pack, which only boards of more than
+// 14 positions have, by its pegs as a string
+type citizenKey struct {
+	packed mm.CodeKey
+	long   string
+}
+
+func keyOf(c mm.Code) citizenKey {
+	if k, ok := c.TryKey(); ok {
+		return citizenKey{packed: k}
+	}
+	return citizenKey{long: string(c)}
+}
 
 type Citizen struct {
 	mm.Code
 	fitness float64
 }
 
-func (c Citizen) Key() string {
-	return c.Code.String()
+// Fitness is the citizen's fitness as last evaluated; lower is better
+func (c Citizen) Fitness() float64 {
+	return c.fitness
+}
+
+func (c Citizen) Key() citizenKey {
+	return keyOf(c.Code)
 }
 
 func (c Citizen) String() string {
@@ -387,8 +696,12 @@ func (c Citizen) String() string {
 
 type fitnessList []Citizen
 
+// ties are broken by code, so the order doesn't depend on map iteration
 func (s fitnessList) Less(i, j int) bool {
-	return s[i].fitness < s[j].fitness
+	if s[i].fitness != s[j].fitness {
+		return s[i].fitness < s[j].fitness
+	}
+	return s[i].Code.Compare(s[j].Code) < 0
 }
 
 func (s fitnessList) Swap(i, j int) {
@@ -1,17 +1,41 @@
 package genetic
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
 )
 
-// benchmark against large games, 6x9
+// benchmark against large games, 6x9.  A population of b.N codes would never
+// fill once b.N passed the 531441 codes of the board, so each iteration
+// makes one of the usual size instead.
 func BenchmarkInitializePopulation(b *testing.B) {
-	solver := NewSolver(mm.NewCustomGame(6, 9))
-	solver.InitializePopulation(b.N)
+	solver := NewSolver(mm.NewCustomGame(6, 9), WithSeed(1))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		solver.InitializePopulation(150)
+	}
+}
+
+// a generation of a large game, which draws random codes in place of the
+// children already in the population
+func BenchmarkGenerate(b *testing.B) {
+	solver := NewSolver(mm.NewCustomGame(6, 9), WithSeed(1))
+	pop := solver.InitializePopulation(150)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pop = solver.Generate(pop)
+	}
 }
 
 func TestGeneticAlgorithm(t *testing.T) {
@@ -48,3 +72,618 @@ func TestGeneticAlgorithm(t *testing.T) {
 		t.Error(fmt.Errorf("Worst case took %d moves to solve, should be no more than 5", worstCaseMoves))
 	}
 }
+
+func TestOptions(t *testing.T) {
+	solver := NewSolver(mm.NewGame(), WithPopulationSize(40), WithRates(0.1, 0.2, 0.3), WithFitnessWeights(1, 0))
+	c := solver.config
+	if c.PopulationSize != 40 || c.MutationRate != 0.1 || c.PermutationRate != 0.2 || c.InversionRate != 0.3 {
+		t.Errorf("options not applied: %+v", c)
+	}
+	if c.FitnessA != 1 || c.FitnessB != 0 || c.MaxGenerations != DefaultConfig().MaxGenerations {
+		t.Errorf("options not applied: %+v", c)
+	}
+
+	cfg := DefaultConfig()
+	cfg.MaxEligible = 5
+	if NewSolver(mm.NewGame(), WithConfig(cfg)).config != cfg {
+		t.Error("WithConfig not applied")
+	}
+
+	// a config without a selector or logger plays with the defaults
+	cfg.Selector, cfg.Logger, cfg.MaxEligible = nil, nil, 60
+	game := mm.NewCustomGame(4, 5)
+	solver = NewSolver(game, WithConfig(cfg), WithSeed(1))
+	if solver.config.Selector != (Greedy{}) {
+		t.Errorf("expected a nil Selector to be Greedy, got %T", solver.config.Selector)
+	}
+	if winner, err := solver.Solve(); err != nil || !game.IsWinner(winner) {
+		t.Errorf("expected a win without a selector, got %v %v", winner, err)
+	}
+}
+
+func TestSelectors(t *testing.T) {
+	solver := NewSolver(mm.NewGame())
+	ranked := []Citizen{}
+	for i := 0; i < 20; i++ {
+		ranked = append(ranked, Citizen{Code: solver.Size.CodeAt(i), fitness: float64(i)})
+	}
+
+	pairs := Greedy{}.Pairs(ranked, 3, solver.rand)
+	if len(pairs) != 3 || pairs[0][0].Key() != ranked[0].Key() || pairs[2][1].Key() != ranked[5].Key() {
+		t.Errorf("greedy should pair off the fittest in order, got %v", pairs)
+	}
+	if len(Greedy{}.Pairs(ranked, 50, solver.rand)) != 10 {
+		t.Error("greedy can't make more pairs than there are citizens")
+	}
+
+	for _, sel := range []Selector{Tournament{3}, Roulette{}, Rank{}} {
+		pairs := sel.Pairs(ranked, 200, solver.rand)
+		if len(pairs) != 200 {
+			t.Errorf("%T made %d pairs, expected 200", sel, len(pairs))
+		}
+		// selection pressure: the fitter half should be picked more often
+		fitter := 0
+		for _, p := range pairs {
+			for _, c := range p {
+				if c.Fitness() < 10 {
+					fitter++
+				}
+			}
+		}
+		if fitter <= 200 {
+			t.Errorf("%T picked the fitter half only %d of 400 times", sel, fitter)
+		}
+	}
+}
+
+func TestGenerateKeepsChildren(t *testing.T) {
+	solver := NewSolver(mm.NewGame(), WithRates(0, 0, 0))
+	pop := solver.InitializePopulation(150)
+
+	// every pair of parents and their two children survive, even when
+	// crossover reproduces a parent, and the population keeps its size
+	for generation := 0; generation < 20; generation++ {
+		next := solver.Generate(pop)
+		if len(next) != 150 {
+			t.Fatalf("generation %d has %d citizens, expected 150", generation, len(next))
+		}
+		pop = next
+	}
+}
+
+func TestPopulationSize(t *testing.T) {
+	// selectors which pick the same parent for several pairs, and a move's
+	// eligible codes added to the population, mustn't change its size
+	for _, sel := range []Selector{Greedy{}, Tournament{Size: 3}, Roulette{}, Rank{}} {
+		solver := NewSolver(mm.NewCustomGameWithSecret(5, 8, mm.Code{1, 4, 0, 5, 3}), WithSeed(1), WithSelector(sel))
+		solver.move = 1
+		solver.guesses[1] = mm.Code{0, 0, 1, 1, 2}
+		solver.results[1], _ = solver.Game.ScoredGuess(solver.guesses[1])
+
+		Ei := make(Population)
+		pop := solver.InitializePopulation(150)
+		for h := 0; h < 20; h++ {
+			pop = solver.evolve(h, pop, Ei)
+			if len(pop) != 150 {
+				t.Errorf("%T: generation %d has %d citizens, expected 150", sel, h, len(pop))
+			}
+		}
+	}
+}
+
+func TestEligible(t *testing.T) {
+	game := mm.NewCustomGameWithSecret(4, 6, mm.Code{5, 4, 3, 2})
+	solver := NewSolver(game)
+	solver.move = 1
+	solver.guesses[1] = mm.Code{1, 2, 3, 4}
+	solver.results[1], _ = game.ScoredGuess(solver.guesses[1])
+
+	if !solver.eligible(Citizen{Code: mm.Code{5, 4, 3, 2}}) {
+		t.Error("the secret must be eligible")
+	}
+	for _, c := range solver.Size.AllCodes() {
+		r, _ := mm.CheckCode(solver.guesses[1], c, 6)
+		if solver.eligible(Citizen{Code: c}) != (r == solver.results[1]) {
+			t.Errorf("%s should be eligible only if it would score %s for %s", c, solver.results[1], solver.guesses[1])
+		}
+	}
+}
+
+func TestAdaptiveRates(t *testing.T) {
+	solver := NewSolver(mm.NewGame(), WithPopulationSize(100), WithAdaptiveRates(0.5))
+	rate := solver.mutationRate
+
+	// a collapsed population raises the rates
+	solver.adapt(solver.InitializePopulation(10))
+	if solver.Diversity != 0.1 {
+		t.Errorf("diversity should be 0.1, got %.2f", solver.Diversity)
+	}
+	if solver.mutationRate <= rate {
+		t.Errorf("mutation rate should rise above %.3f, got %.3f", rate, solver.mutationRate)
+	}
+	for i := 0; i < 20; i++ {
+		solver.adapt(solver.InitializePopulation(10))
+	}
+	if solver.mutationRate > maxAdaptiveRate {
+		t.Errorf("mutation rate %.3f exceeds the cap of %.3f", solver.mutationRate, maxAdaptiveRate)
+	}
+
+	// and a recovered one relaxes them
+	for i := 0; i < 20; i++ {
+		solver.adapt(solver.InitializePopulation(100))
+	}
+	if solver.mutationRate-rate > 0.001 {
+		t.Errorf("mutation rate should relax to %.3f, got %.3f", rate, solver.mutationRate)
+	}
+}
+
+func TestMaxMoves(t *testing.T) {
+	if n := NewSolver(mm.NewCustomGame(8, 10)).maxGuesses(); n != 64 {
+		t.Errorf("8x10 should allow 64 moves, got %d", n)
+	}
+
+	solver := NewSolver(mm.NewCustomGameWithSecret(4, 6, mm.Code{5, 4, 3, 2}), WithMaxMoves(1))
+	if _, err := solver.Solve(); err == nil {
+		t.Error("solving in one move shouldn't succeed")
+	}
+	if solver.TurnsTaken != 1 {
+		t.Errorf("expected to give up after 1 move, took %d", solver.TurnsTaken)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	secret := mm.Code{5, 4, 3, 2}
+	play := func(seed int64, workers int) []mm.Code {
+		solver := NewSolver(mm.NewCustomGameWithSecret(4, 6, secret), WithSeed(seed), WithWorkers(workers))
+		if _, err := solver.Solve(); err != nil {
+			t.Fatal(err)
+		}
+		return solver.guesses[1 : solver.move+1]
+	}
+
+	a, b := play(42, 1), play(42, 1)
+	if fmt.Sprint(a) != fmt.Sprint(b) {
+		t.Errorf("solvers with the same seed guessed differently: %v and %v", a, b)
+	}
+	// however many goroutines score fitness
+	if c := play(42, 4); fmt.Sprint(a) != fmt.Sprint(c) {
+		t.Errorf("solvers with 1 and 4 workers guessed differently: %v and %v", a, c)
+	}
+	// or seeded after they're made
+	solver := NewSolver(mm.NewCustomGameWithSecret(4, 6, secret))
+	solver.Seed(42)
+	if _, err := solver.Solve(); err != nil {
+		t.Fatal(err)
+	}
+	if d := solver.guesses[1 : solver.move+1]; fmt.Sprint(a) != fmt.Sprint(d) {
+		t.Errorf("solvers seeded with and after WithSeed guessed differently: %v and %v", a, d)
+	}
+}
+
+func TestInitialGuess(t *testing.T) {
+	expected := map[mm.GameSize]string{
+		{4, 6}:  "0012",
+		{5, 8}:  "00123",
+		{6, 9}:  "001123",
+		{8, 10}: "00112234",
+		{4, 2}:  "0011",
+		{3, 1}:  "000",
+	}
+	for size, guess := range expected {
+		solver := NewSolver(mm.NewCustomGame(size.Positions, size.Colors))
+		if g := solver.InitialGuess(); g.String() != guess {
+			t.Errorf("initial guess for %v should be %s, got %s", size, guess, g)
+		}
+	}
+}
+
+func TestFitnessFunctions(t *testing.T) {
+	size := mm.GameSize{4, 6}
+	history := mm.History{
+		{Guess: mm.Code{1, 2, 3, 4}, Result: mm.Result{1, 2}},
+		{Guess: mm.Code{5, 4, 3, 1}, Result: mm.Result{3, 0}},
+	}
+
+	b := Berghman{A: 2, B: 2}
+	// 5432 is consistent, so only the turn penalty remains: 2 * 4 * (2-1)
+	if f := b.Evaluate(mm.Code{5, 4, 3, 2}, history, size); f != 8 {
+		t.Errorf("fitness of 5432 should be 8, got %.2f", f)
+	}
+	// 5431 would score 4-0 against itself instead of 3-0, costing a
+	if f := b.Evaluate(mm.Code{5, 4, 3, 1}, history, size); f != 10 {
+		t.Errorf("fitness of 5431 should be 10, got %.2f", f)
+	}
+
+	p := PenalizeGuessed{b, 100}
+	if f := p.Evaluate(mm.Code{5, 4, 3, 1}, history, size); f != 110 {
+		t.Errorf("penalized fitness of 5431 should be 110, got %.2f", f)
+	}
+
+	solver := NewSolver(mm.NewGame(), WithFitness(p))
+	if solver.fitnessFn != FitnessFunction(p) {
+		t.Error("WithFitness not applied")
+	}
+}
+
+func TestGenerationStats(t *testing.T) {
+	solver := NewSolver(mm.NewCustomGameWithSecret(4, 6, mm.Code{5, 4, 3, 2}), WithSeed(1))
+	stats := []GenerationStats{}
+	solver.OnGeneration = func(s GenerationStats) {
+		stats = append(stats, s)
+	}
+	if _, err := solver.Solve(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stats) == 0 {
+		t.Fatal("no generation stats reported")
+	}
+	eligible := 0
+	for i, s := range stats {
+		if s.BestFitness > s.MeanFitness {
+			t.Errorf("generation %d: best fitness %.2f worse than mean %.2f", i, s.BestFitness, s.MeanFitness)
+		}
+		if s.Diversity <= 0 {
+			t.Errorf("generation %d: diversity should be positive, got %.2f", i, s.Diversity)
+		}
+		if s.Generation == 0 {
+			eligible = 0
+		}
+		if s.Eligible != eligible+s.NewEligible {
+			t.Errorf("generation %d: eligible went from %d to %d, but %d were new", i, eligible, s.Eligible, s.NewEligible)
+		}
+		eligible = s.Eligible
+	}
+}
+
+func TestIslands(t *testing.T) {
+	secret := mm.Code{1, 4, 0, 5, 3}
+	solver := NewSolver(mm.NewCustomGameWithSecret(5, 8, secret), WithSeed(3), WithIslands(4, 5, 3))
+	islands := map[int]bool{}
+	solver.OnGeneration = func(s GenerationStats) {
+		islands[s.Island] = true
+	}
+	guess, err := solver.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if guess.String() != secret.String() {
+		t.Errorf("solved %v, secret was %v", guess, secret)
+	}
+	if len(islands) != 4 {
+		t.Errorf("expected stats from 4 islands, got %v", islands)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	solver := NewSolver(mm.NewCustomGameWithSecret(4, 6, mm.Code{5, 4, 3, 2}), WithSeed(1), WithIslands(2, 1, 2))
+	solver.move = 1
+	solver.guesses[1] = mm.Code{0, 0, 1, 2}
+	solver.results[1] = mm.Result{0, 1}
+
+	islands := solver.newIslands(2)
+	best := islands[0].Fitness(islands[0].population)[:2]
+	solver.migrate(islands)
+
+	if len(islands[1].population) != solver.config.PopulationSize {
+		t.Errorf("migration changed the population size to %d", len(islands[1].population))
+	}
+	for _, c := range best {
+		if _, ok := islands[1].population[c.Key()]; !ok {
+			t.Errorf("%v didn't migrate", c)
+		}
+	}
+}
+
+func TestEndgame(t *testing.T) {
+	// Knuth's bound for 4x6, which the GA alone doesn't always meet
+	for i, secret := range []mm.Code{{5, 4, 3, 2}, {0, 0, 0, 0}, {1, 3, 5, 1}, {2, 2, 4, 0}} {
+		solver := NewSolver(mm.NewCustomGameWithSecret(4, 6, secret), WithSeed(int64(i+1)), WithEndgame(60))
+		if _, err := solver.Solve(); err != nil {
+			t.Fatal(err)
+		}
+		if solver.TurnsTaken > 5 {
+			t.Errorf("%v took %d moves, expected at most 5", secret, solver.TurnsTaken)
+		}
+	}
+}
+
+func TestCrossover(t *testing.T) {
+	solver := NewSolver(mm.NewCustomGame(6, 8), WithSeed(7))
+	x, y := Citizen{Code: mm.Code{0, 0, 0, 0, 0, 0}}, Citizen{Code: mm.Code{1, 1, 1, 1, 1, 1}}
+
+	children := map[citizenKey]bool{}
+	for i := 0; i < 1000; i++ {
+		child := Citizen{Code: make(mm.Code, 6)}
+		solver.crossover(child.Code, x, y)
+		children[child.Key()] = true
+
+		// y's genes form one unbroken run inside x's
+		runs := 0
+		for p := range child.Code {
+			if child.Code[p] == 1 && (p == 0 || child.Code[p-1] == 0) {
+				runs++
+			}
+		}
+		if runs != 1 || child.Code[len(child.Code)-1] == 1 && child.Code[0] == 1 {
+			t.Fatalf("%v isn't a crossover of %v and %v", child, x, y)
+		}
+	}
+	// 5 one-point children and 10 two-point ones, all of them seen
+	if len(children) != 15 {
+		t.Errorf("expected 15 distinct children, got %d: %v", len(children), children)
+	}
+}
+
+func TestSpawnAllocs(t *testing.T) {
+	solver := NewSolver(mm.NewCustomGame(6, 9), WithSeed(1))
+	pop := solver.InitializePopulation(150)
+	elders := solver.Fitness(pop)
+	x, y := elders[0], elders[1]
+
+	// spawning a child, making it unique and looking up a fitness already
+	// scored allocate nothing
+	child := make(mm.Code, 6)
+	allocs := testing.AllocsPerRun(100, func() {
+		solver.spawn(child, x, y)
+		solver.unique(Citizen{Code: child}, pop, pop)
+		solver.fitness(x)
+	})
+	if allocs != 0 {
+		t.Errorf("expected spawning to allocate nothing, got %v allocations", allocs)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	solver := NewSolver(mm.NewCustomGameWithSecret(4, 6, mm.Code{5, 4, 3, 2}), WithSeed(1), WithLogger(NewLogger(buf, LevelInfo)))
+	if _, err := solver.Solve(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != solver.TurnsTaken {
+		t.Errorf("expected a line per guess, got %d for %d guesses:\n%s", len(lines), solver.TurnsTaken, buf)
+	}
+	for _, l := range lines {
+		if !strings.Contains(l, "INFO: ") {
+			t.Errorf("logged above LevelInfo: %s", l)
+		}
+	}
+}
+
+func TestCodemakerSolver(t *testing.T) {
+	size := mm.GameSize{Positions: 4, Colors: 6}
+	evil := mm.NewEvilCodemaker(size)
+	solver := NewCodemakerSolver(evil, WithSeed(1))
+
+	winner, err := solver.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if candidates := evil.Candidates(); len(candidates) != 1 || candidates[0].String() != winner.String() {
+		t.Errorf("solved %v, but the evil codemaker still had %v", winner, candidates)
+	}
+	if solver.TurnsTaken != evil.TurnsTaken {
+		t.Errorf("solver counted %d moves, codemaker %d", solver.TurnsTaken, evil.TurnsTaken)
+	}
+}
+
+// a codemaker which goes away after a few guesses
+type flaky struct {
+	*mm.Game
+	guesses int
+}
+
+func (f *flaky) ScoredGuess(code mm.Code) (mm.Result, error) {
+	if f.guesses == 0 {
+		return mm.Result{}, errors.New("connection lost")
+	}
+	f.guesses--
+	return f.Game.ScoredGuess(code)
+}
+
+func TestStateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genetic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+
+	secret := mm.Code{1, 4, 0, 5, 3}
+	interrupted := NewCodemakerSolver(&flaky{mm.NewCustomGameWithSecret(5, 8, secret), 2}, WithSeed(1), WithStateFile(path))
+	if _, err := interrupted.Solve(); err == nil {
+		t.Fatal("expected the interrupted solve to fail")
+	}
+
+	st, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.History) != 2 || len(st.Population) == 0 {
+		t.Fatalf("expected the state after 2 moves with a population, got %d moves and %d codes", len(st.History), len(st.Population))
+	}
+	for _, c := range st.Eligible {
+		if !st.History.Consistent(c, 8) {
+			t.Errorf("saved eligible code %v isn't consistent with %v", c, st.History)
+		}
+	}
+
+	resumed := NewSolver(mm.NewCustomGameWithSecret(5, 8, secret), WithSeed(1))
+	if err := resumed.Restore(st); err != nil {
+		t.Fatal(err)
+	}
+	winner, err := resumed.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if winner.String() != secret.String() {
+		t.Errorf("resumed solve found %v, secret was %v", winner, secret)
+	}
+	if h := resumed.history(); h[0].Guess.String() != st.History[0].Guess.String() || h[1].Guess.String() != st.History[1].Guess.String() {
+		t.Errorf("resumed game lost its history: %v", h)
+	}
+
+	if err := NewSolver(mm.NewGame()).Restore(st); err == nil {
+		t.Error("expected an error restoring a 5x8 state on a 4x6 game")
+	}
+}
+
+func TestWarmStart(t *testing.T) {
+	solver := NewSolver(mm.NewGame(), WithSeed(1))
+	seed := mm.CodeSlice{{0, 1, 2, 3}, {5, 5, 5, 5}, {1, 1, 2, 2}}
+	solver.WarmStart(seed)
+
+	pop := solver.InitializePopulation(10)
+	if len(pop) != 10 {
+		t.Errorf("population has %d codes, expected 10", len(pop))
+	}
+	for _, c := range seed {
+		if _, ok := pop[keyOf(c)]; !ok {
+			t.Errorf("warm start code %v missing from the population", c)
+		}
+	}
+}
+
+// counts how often each code is scored after each number of moves
+type countingFitness struct {
+	FitnessFunction
+	counts map[string]int
+}
+
+func (f countingFitness) Evaluate(code mm.Code, history mm.History, size mm.GameSize) float64 {
+	f.counts[fmt.Sprintf("%d:%s", len(history), code)]++
+	return f.FitnessFunction.Evaluate(code, history, size)
+}
+
+func TestFitnessCache(t *testing.T) {
+	counting := countingFitness{Berghman{A: 2, B: 2}, map[string]int{}}
+	// counting's map isn't safe to score on several goroutines
+	solver := NewSolver(mm.NewCustomGameWithSecret(5, 8, mm.Code{1, 4, 0, 5, 3}), WithSeed(1), WithFitness(counting), WithWorkers(1))
+	if _, err := solver.Solve(); err != nil {
+		t.Fatal(err)
+	}
+
+	for k, n := range counting.counts {
+		if n > 1 {
+			t.Errorf("%s scored %d times", k, n)
+		}
+	}
+	if solver.cache.misses != len(counting.counts) {
+		t.Errorf("cache missed %d times for %d codes", solver.cache.misses, len(counting.counts))
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	configs := []BenchConfig{{Name: "default"}, {Name: "small", Options: []Option{WithPopulationSize(60)}}}
+	results := Benchmark([]mm.GameSize{{Positions: 4, Colors: 6}}, configs, 3, 1)
+	if len(results) != 2 {
+		t.Fatalf("expected a result per configuration, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.Games != 3 || r.Failures != 0 || r.MeanMoves < 1 || r.MaxMoves < 1 {
+			t.Errorf("unexpected result %+v", r)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteCSV(buf, results); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "size,config,") || !strings.HasPrefix(lines[2], "4x6,small,3,0,") {
+		t.Errorf("unexpected CSV:\n%s", buf)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	g := Grid{PopulationSizes: []int{60, 150}, MutationRates: []float64{0.01, 0.03, 0.1}}
+	configs := g.Configs()
+	if len(configs) != 6 {
+		t.Fatalf("expected 6 configurations, got %d", len(configs))
+	}
+	d := DefaultConfig()
+	for _, c := range configs {
+		config := DefaultConfig()
+		for _, o := range c.Options {
+			o(&config)
+		}
+		if config.MaxGenerations != d.MaxGenerations || config.InversionRate != d.InversionRate {
+			t.Errorf("%s doesn't keep the defaults left out of the grid", c.Name)
+		}
+		if !strings.Contains(c.Name, fmt.Sprintf("population=%d ", config.PopulationSize)) ||
+			!strings.Contains(c.Name, fmt.Sprintf("mutation=%g ", config.MutationRate)) {
+			t.Errorf("%s isn't named for its parameters", c.Name)
+		}
+	}
+
+	sample := g.Sample(4, 1)
+	again := g.Sample(4, 1)
+	seen := map[string]bool{}
+	for i, c := range sample {
+		if seen[c.Name] {
+			t.Errorf("%s sampled twice", c.Name)
+		}
+		seen[c.Name] = true
+		if again[i].Name != c.Name {
+			t.Errorf("sampling again with the same seed drew %s, not %s", again[i].Name, c.Name)
+		}
+	}
+	if len(sample) != 4 || len(g.Sample(10, 1)) != 6 {
+		t.Errorf("expected 4 configurations sampled, and all 6 when asking for more")
+	}
+
+	small, big := mm.GameSize{Positions: 4, Colors: 6}, mm.GameSize{Positions: 5, Colors: 8}
+	ranked := RankResults([]BenchResult{
+		{Size: big, Config: "slow", MeanMoves: 5, WallTime: 2},
+		{Size: small, Config: "failing", Failures: 1, MeanMoves: 4},
+		{Size: big, Config: "fast", MeanMoves: 5, WallTime: 1},
+		{Size: small, Config: "best", MeanMoves: 4.5},
+		{Size: big, Config: "fewest", MeanMoves: 4.8, WallTime: 3},
+	})
+	var order []string
+	for _, r := range ranked {
+		order = append(order, r.Config)
+	}
+	if got := strings.Join(order, ","); got != "fewest,fast,slow,best,failing" {
+		t.Errorf("expected fewest,fast,slow,best,failing, got %s", got)
+	}
+}
+
+func TestCitizenKey(t *testing.T) {
+	short, long := mm.Code{0, 1, 2, 3}, make(mm.Code, 15)
+	long[0] = 9
+	if k := keyOf(short); k.long != "" || k.packed != short.Key() {
+		t.Errorf("expected %v keyed by its CodeKey, got %+v", short, k)
+	}
+	if k := keyOf(long); k.long == "" {
+		t.Errorf("expected %v, too long to pack, keyed by its pegs, got %+v", long, k)
+	}
+	other := append(mm.Code{}, long...)
+	other[14] = 1
+	if keyOf(long) == keyOf(other) || keyOf(long) != keyOf(append(mm.Code{}, long...)) {
+		t.Error("expected long codes keyed apart, and alike when they're the same")
+	}
+}
+
+func TestEstimateMemory(t *testing.T) {
+	size := mm.GameSize{Positions: 8, Colors: 10}
+	cfg := DefaultConfig()
+	base := cfg.EstimateMemory(size)
+	if exact := solver.EstimateMemory(size, "knuth"); base*100 > exact {
+		t.Errorf("expected 8x10 to take far less than knuth, got %d and %d bytes", base, exact)
+	}
+	for _, change := range []func(*Config){
+		func(c *Config) { c.PopulationSize *= 2 },
+		func(c *Config) { c.MaxGenerations *= 2 },
+		func(c *Config) { c.MaxEligible *= 2 },
+		func(c *Config) { c.Islands = 4 },
+	} {
+		bigger := cfg
+		change(&bigger)
+		if n := bigger.EstimateMemory(size); n <= base {
+			t.Errorf("expected %+v to take more than the default's %d bytes, got %d", bigger, base, n)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package genetic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// State is a snapshot of a solver after a move: the game so far, and the
+// population and eligible set its search ended with.  Restoring it resumes
+// the game, eg after the process was killed midway through a long solve of
+// a large board; warm starting from it seeds a new game's first population.
+type State struct {
+	Size       mm.GameSize
+	History    mm.History
+	Population mm.CodeSlice
+	Eligible   mm.CodeSlice
+}
+
+// LoadState reads a state saved by Save
+func LoadState(path string) (*State, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	st := &State{}
+	if err := json.Unmarshal(buf, st); err != nil {
+		return nil, fmt.Errorf("reading %s: %v", path, err)
+	}
+	return st, nil
+}
+
+// Save writes the state as JSON, to a temporary file first, so a kill
+// mid-write can't lose the last state saved
+func (st *State) Save(path string) error {
+	buf, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path+".tmp", buf, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func codes(p Population) mm.CodeSlice {
+	out := make(mm.CodeSlice, 0, len(p))
+	for _, c := range p {
+		out = append(out, c.Code)
+	}
+	sort.Sort(out)
+	return out
+}
+
+// State returns a snapshot of the solver after its latest move
+func (s *Solver) State() *State {
+	return &State{
+		Size:       s.Size,
+		History:    s.history(),
+		Population: codes(s.population),
+		Eligible:   codes(s.ei),
+	}
+}
+
+// Restore resumes the game in st.  The next call to Solve guesses from the
+// restored eligible set rather than opening a new game.
+func (s *Solver) Restore(st *State) error {
+	if st.Size != s.Size {
+		return fmt.Errorf("state is for %v, not %v", st.Size, s.Size)
+	}
+	if len(st.History) >= s.maxGuesses() {
+		return fmt.Errorf("state has %d moves, more than the %d allowed", len(st.History), s.maxGuesses())
+	}
+	s.move = len(st.History)
+	s.cache.reset()
+	for i, m := range st.History {
+		s.guesses[i+1] = m.Guess
+		s.results[i+1] = m.Result
+	}
+	s.ei = make(Population, len(st.Eligible))
+	for _, c := range st.Eligible {
+		s.ei[keyOf(c)] = Citizen{Code: c}
+	}
+	s.WarmStart(st.Population)
+	return nil
+}
+
+// WarmStart seeds the population of the next search with codes, eg the
+// population a previous game of the same size ended with
+func (s *Solver) WarmStart(codes mm.CodeSlice) {
+	s.seed = codes
+}
@@ -0,0 +1,115 @@
+package genetic
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Grid is the values of each hyperparameter a sweep tries.  A parameter
+// left without values keeps its default.
+type Grid struct {
+	PopulationSizes  []int
+	MaxGenerations   []int
+	MutationRates    []float64
+	PermutationRates []float64
+	InversionRates   []float64
+}
+
+// point is a value of each of the grid's parameters
+type point struct {
+	population, generations          int
+	mutation, permutation, inversion float64
+}
+
+func (p point) config() BenchConfig {
+	return BenchConfig{
+		Name: fmt.Sprintf("population=%d generations=%d mutation=%g permutation=%g inversion=%g",
+			p.population, p.generations, p.mutation, p.permutation, p.inversion),
+		Options: []Option{
+			WithPopulationSize(p.population),
+			WithMaxGenerations(p.generations),
+			WithRates(p.mutation, p.permutation, p.inversion),
+		},
+	}
+}
+
+// axes is the grid's values, defaults filled in for those left out
+func (g Grid) axes() ([]int, []int, []float64, []float64, []float64) {
+	d := DefaultConfig()
+	ints := func(vs []int, def int) []int {
+		if len(vs) == 0 {
+			return []int{def}
+		}
+		return vs
+	}
+	floats := func(vs []float64, def float64) []float64 {
+		if len(vs) == 0 {
+			return []float64{def}
+		}
+		return vs
+	}
+	return ints(g.PopulationSizes, d.PopulationSize), ints(g.MaxGenerations, d.MaxGenerations),
+		floats(g.MutationRates, d.MutationRate), floats(g.PermutationRates, d.PermutationRate),
+		floats(g.InversionRates, d.InversionRate)
+}
+
+// Configs is every combination of the grid's values, each named for them
+func (g Grid) Configs() []BenchConfig {
+	populations, generations, mutations, permutations, inversions := g.axes()
+	var configs []BenchConfig
+	for _, pop := range populations {
+		for _, gen := range generations {
+			for _, mut := range mutations {
+				for _, perm := range permutations {
+					for _, inv := range inversions {
+						configs = append(configs, point{pop, gen, mut, perm, inv}.config())
+					}
+				}
+			}
+		}
+	}
+	return configs
+}
+
+// Sample is n distinct combinations of the grid's values, drawn at random
+// from seed, for a random search of a grid too big to sweep whole; every
+// combination if there are no more than n
+func (g Grid) Sample(n int, seed int64) []BenchConfig {
+	all := g.Configs()
+	if n >= len(all) {
+		return all
+	}
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(all), func(i, j int) { all[i], all[j] = all[j], all[i] })
+	return all[:n]
+}
+
+// RankResults orders the results of each board best first: the fewest failures,
+// then the fewest moves on average, then the least wall time.  The boards
+// stay in the order they were benchmarked.
+func RankResults(results []BenchResult) []BenchResult {
+	order := map[mm.GameSize]int{}
+	for _, r := range results {
+		if _, ok := order[r.Size]; !ok {
+			order[r.Size] = len(order)
+		}
+	}
+	ranked := append([]BenchResult{}, results...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Size != b.Size {
+			return order[a.Size] < order[b.Size]
+		}
+		if a.Failures != b.Failures {
+			return a.Failures < b.Failures
+		}
+		if a.MeanMoves != b.MeanMoves {
+			return a.MeanMoves < b.MeanMoves
+		}
+		return a.WallTime < b.WallTime
+	})
+	return ranked
+}
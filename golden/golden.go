@@ -0,0 +1,276 @@
+// Package golden records the guesses solvers make on a sample of secrets,
+// and checks solvers still make them, so a change to a strategy can't slip
+// in unnoticed.  The games are experiments.Matrix games, so every random
+// choice is made from their seeds; a solver with random choices of its own
+// must take a seed for its goldens to hold, which Deterministic arranges.
+//
+// A golden file has a line for each game, eg
+//
+//	knuth 4x6 1 0 3105: 0011 1203 1340 2104 3105
+//
+// for knuth's game with seed 1, of its first secret, 3105: the solver,
+// size, seed, round and secret, then the guesses, the last of which is the
+// secret if it was solved.  Lines starting with # are comments.
+package golden
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/experiments"
+)
+
+// Seeder is a solver whose random choices can be pinned down by a seed
+type Seeder interface {
+	Seed(seed int64)
+}
+
+// Deterministic is a solver for a matrix named name, made by newSolver and
+// seeded with each game's seed if it's a Seeder.  A solver which isn't must
+// make no random choices.
+func Deterministic(name string, newSolver mm.SolverFunc) experiments.Solver {
+	return experiments.Solver{
+		Name: name,
+		New: func(seed int64) mm.SolverFunc {
+			return func(cm mm.Codemaker) mm.Solver {
+				s := newSolver(cm)
+				if seeder, ok := s.(Seeder); ok {
+					seeder.Seed(seed)
+				}
+				return s
+			}
+		},
+	}
+}
+
+// Game is the guesses a solver made in a game of a matrix
+type Game struct {
+	Solver  string
+	Size    mm.GameSize
+	Seed    int64
+	Round   int
+	Secret  mm.Code
+	Guesses mm.CodeSlice
+}
+
+func (g Game) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %v %d %d %v:", g.Solver, g.Size, g.Seed, g.Round, g.Secret)
+	for _, c := range g.Guesses {
+		fmt.Fprintf(&b, " %v", c)
+	}
+	return b.String()
+}
+
+// key is what picks a game out of a matrix
+type key struct {
+	solver string
+	size   mm.GameSize
+	seed   int64
+	round  int
+}
+
+func (g Game) key() key {
+	return key{g.Solver, g.Size, g.Seed, g.Round}
+}
+
+// Record plays every game of m, for goldens
+func Record(m experiments.Matrix) []Game {
+	var games []Game
+	for _, g := range m.Run() {
+		game := Game{Solver: g.Solver, Size: g.Size, Seed: g.Seed, Round: g.Round, Secret: g.Secret}
+		for _, move := range g.Moves {
+			game.Guesses = append(game.Guesses, move.Guess)
+		}
+		games = append(games, game)
+	}
+	return games
+}
+
+// Drift is a game which isn't played as its golden was
+type Drift struct {
+	// Golden is the game as recorded, and Got as it's played now; either is
+	// nil if the game is only in the other
+	Golden *Game
+	Got    *Game
+	// Move is the first move played differently, from 1, or 0 if the game
+	// is missing from either
+	Move int
+}
+
+func (d Drift) String() string {
+	switch {
+	case d.Golden == nil:
+		return fmt.Sprintf("%v has no golden", d.Got)
+	case d.Got == nil:
+		return fmt.Sprintf("%v wasn't played", d.Golden)
+	}
+	return fmt.Sprintf("%s %v %d %d %v: move %d drifted\n\tgolden %v\n\tgot    %v",
+		d.Got.Solver, d.Got.Size, d.Got.Seed, d.Got.Round, d.Got.Secret, d.Move, d.Golden.Guesses, d.Got.Guesses)
+}
+
+// Compare lists the games of got which differ from goldens, in the order
+// they were played, then those of goldens which weren't played
+func Compare(goldens, got []Game) []Drift {
+	byKey := map[key]*Game{}
+	for i := range goldens {
+		byKey[goldens[i].key()] = &goldens[i]
+	}
+	var drifts []Drift
+	played := map[key]bool{}
+	for i := range got {
+		g := &got[i]
+		played[g.key()] = true
+		want, ok := byKey[g.key()]
+		if !ok {
+			drifts = append(drifts, Drift{Got: g})
+			continue
+		}
+		if move := firstDifference(want, g); move > 0 {
+			drifts = append(drifts, Drift{Golden: want, Got: g, Move: move})
+		}
+	}
+	for i := range goldens {
+		if !played[goldens[i].key()] {
+			drifts = append(drifts, Drift{Golden: &goldens[i]})
+		}
+	}
+	return drifts
+}
+
+// firstDifference is the first move of b which isn't a's, from 1, or 0 if
+// they're the same game.  A different secret differs from the first move.
+func firstDifference(a, b *Game) int {
+	if a.Secret.Compare(b.Secret) != 0 {
+		return 1
+	}
+	for i := 0; i < len(a.Guesses) || i < len(b.Guesses); i++ {
+		if i >= len(a.Guesses) || i >= len(b.Guesses) || a.Guesses[i].Compare(b.Guesses[i]) != 0 {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Write writes games as a golden file.  The solvers' names can't have
+// spaces or colons.
+func Write(w io.Writer, games []Game) error {
+	if _, err := fmt.Fprintln(w, "# solver size seed round secret: guesses"); err != nil {
+		return err
+	}
+	for _, g := range games {
+		if g.Solver == "" || strings.ContainsAny(g.Solver, " \t:") {
+			return fmt.Errorf("solver %q can't be named in a golden file", g.Solver)
+		}
+		if _, err := fmt.Fprintln(w, g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read reads a golden file
+func Read(r io.Reader) ([]Game, error) {
+	var games []Game
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		g, err := parseGame(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		games = append(games, g)
+	}
+	return games, scanner.Err()
+}
+
+func parseGame(line string) (Game, error) {
+	var g Game
+	head, guesses, ok := strings.Cut(line, ":")
+	fields := strings.Fields(head)
+	if !ok || len(fields) != 5 {
+		return g, fmt.Errorf("%q isn't solver size seed round secret: guesses", line)
+	}
+	g.Solver = fields[0]
+	var err error
+	if g.Size, err = mm.ParseGameSize(fields[1]); err != nil {
+		return g, err
+	}
+	if g.Seed, err = strconv.ParseInt(fields[2], 10, 64); err != nil {
+		return g, fmt.Errorf("bad seed %q", fields[2])
+	}
+	if g.Round, err = strconv.Atoi(fields[3]); err != nil {
+		return g, fmt.Errorf("bad round %q", fields[3])
+	}
+	if g.Secret, err = parseCode(fields[4], g.Size); err != nil {
+		return g, err
+	}
+	for _, f := range strings.Fields(guesses) {
+		c, err := parseCode(f, g.Size)
+		if err != nil {
+			return g, err
+		}
+		g.Guesses = append(g.Guesses, c)
+	}
+	return g, nil
+}
+
+// parseCode reads a code spelled as Code.String spells it
+func parseCode(s string, size mm.GameSize) (mm.Code, error) {
+	var c mm.Code
+	for _, r := range s {
+		peg := r - '0'
+		if peg < 0 || peg >= rune(size.Colors) {
+			return nil, fmt.Errorf("code %q has colors past %d", s, size.Colors-1)
+		}
+		c = append(c, byte(peg))
+	}
+	if len(c) != size.Positions {
+		return nil, fmt.Errorf("code %q isn't %d pegs", s, size.Positions)
+	}
+	return c, nil
+}
+
+// Test plays m and reports each game which drifts from the goldens in the
+// file at path as an error of t.  With update, it writes the games played
+// to path instead, as the new goldens.
+func Test(t testing.TB, path string, m experiments.Matrix, update bool) {
+	t.Helper()
+	got := Record(m)
+	if update {
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Write(f, got); err != nil {
+			f.Close()
+			t.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("%v; record goldens with update", err)
+	}
+	defer f.Close()
+	goldens, err := Read(f)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	for _, d := range Compare(goldens, got) {
+		t.Errorf("%s: %v", path, d)
+	}
+}
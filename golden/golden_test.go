@@ -0,0 +1,113 @@
+package golden
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/experiments"
+	"github.com/ianmcmahon/mastermind/genetic"
+	"github.com/ianmcmahon/mastermind/human"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the games played now")
+
+// TestSolvers checks the solvers still play the games they were recorded
+// playing.  A change to a strategy which means to change them is recorded
+// with go test -run TestSolvers -update.
+func TestSolvers(t *testing.T) {
+	knuth := func(h solver.Heuristic, budget uint64) mm.SolverFunc {
+		return func(cm mm.Codemaker) mm.Solver {
+			s := solver.NewCodemakerSolver(cm)
+			s.Heuristic, s.MemoryBudget = h, budget
+			return s
+		}
+	}
+	m := experiments.Matrix{
+		Solvers: []experiments.Solver{
+			Deterministic("knuth", knuth(solver.MinMax, 0)),
+			Deterministic("knuth-entropy", knuth(solver.Entropy, 0)),
+			// scores a sample of 100 codes each move, drawn from the seed
+			Deterministic("knuth-sampled", knuth(solver.ExpectedSize, 2800)),
+			Deterministic("genetic", func(cm mm.Codemaker) mm.Solver { return genetic.NewCodemakerSolver(cm) }),
+			Deterministic("human-random", func(cm mm.Codemaker) mm.Solver { return human.NewSolver(cm, human.Random) }),
+		},
+		Sizes: []mm.GameSize{{Positions: 4, Colors: 6}},
+		Seeds: []int64{1, 2},
+		Games: 5,
+	}
+	Test(t, "testdata/solvers.golden", m, *update)
+}
+
+func TestCompare(t *testing.T) {
+	size := mm.GameSize{Positions: 2, Colors: 3}
+	game := func(round int, guesses ...mm.Code) Game {
+		return Game{Solver: "s", Size: size, Seed: 1, Round: round, Secret: mm.Code{2, 1}, Guesses: guesses}
+	}
+	goldens := []Game{
+		game(0, mm.Code{0, 0}, mm.Code{2, 1}),
+		game(1, mm.Code{0, 0}, mm.Code{1, 1}, mm.Code{2, 1}),
+		game(2, mm.Code{0, 1}, mm.Code{2, 1}),
+		game(3, mm.Code{2, 1}),
+	}
+	got := []Game{
+		game(0, mm.Code{0, 0}, mm.Code{2, 1}),
+		game(1, mm.Code{0, 0}, mm.Code{2, 1}),
+		game(2, mm.Code{0, 1}, mm.Code{2, 1}, mm.Code{2, 1}),
+		game(4, mm.Code{2, 1}),
+	}
+	drifts := Compare(goldens, got)
+	var summary []string
+	for _, d := range drifts {
+		switch {
+		case d.Golden == nil:
+			summary = append(summary, "new")
+		case d.Got == nil:
+			summary = append(summary, "missing")
+		default:
+			summary = append(summary, strings.Repeat("+", d.Move))
+		}
+	}
+	// round 1 drifts on its second move, round 2 plays on past its third,
+	// round 4 is new and round 3 isn't played
+	if s := strings.Join(summary, ","); s != "++,+++,new,missing" {
+		t.Errorf("expected ++,+++,new,missing, got %s: %v", s, drifts)
+	}
+	if s := drifts[0].String(); !strings.Contains(s, "s 2x3 1 1 21: move 2 drifted") {
+		t.Errorf("unexpected drift %q", s)
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	games := []Game{
+		{Solver: "knuth", Size: mm.GameSize{Positions: 4, Colors: 6}, Seed: 3, Round: 1, Secret: mm.Code{3, 1, 0, 5},
+			Guesses: mm.CodeSlice{{0, 0, 1, 1}, {3, 1, 0, 5}}},
+		{Solver: "failing", Size: mm.GameSize{Positions: 4, Colors: 6}, Seed: 3, Round: 0, Secret: mm.Code{1, 1, 1, 1}},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, games); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\nknuth 4x6 3 1 3105: 0011 3105\n") {
+		t.Errorf("unexpected golden file\n%s", buf.String())
+	}
+	read, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 || len(Compare(games, read)) != 0 {
+		t.Errorf("expected the games read back, got %v", read)
+	}
+
+	for _, bad := range []string{"knuth 4x6 3 1 3105 0011", "knuth 4x6 x 1 3105:", "knuth 4x6 3 1 3106:", "knuth 4x6 3 1 3105: 001"} {
+		if _, err := Read(strings.NewReader(bad)); err == nil {
+			t.Errorf("read bad line %q", bad)
+		}
+	}
+	if err := Write(&buf, []Game{{Solver: "two words"}}); err == nil {
+		t.Error("expected a solver named with a space refused")
+	}
+}
@@ -0,0 +1,100 @@
+// Package hillclimb solves mastermind by greedy local search, the weakest
+// baseline in comparisons.  Each move it starts from a random code and takes
+// the fittest of its neighbors, every code one recolor or one swap away,
+// while that's an improvement, rating codes with the genetic solver's
+// fitness function.  At a local optimum it restarts from another random
+// code.  The first consistent code reached is guessed.
+package hillclimb
+
+import (
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/genetic"
+	"github.com/ianmcmahon/mastermind/internal/metaheuristic"
+)
+
+type Solver struct {
+	metaheuristic.Base
+
+	// Fitness rates codes; lower is fitter
+	Fitness genetic.FitnessFunction
+	// Restarts is the most climbs made looking for a consistent code each
+	// move.  If none is found, the fittest code reached is guessed.
+	Restarts int
+}
+
+func NewSolver(cm mm.Codemaker) *Solver {
+	return &Solver{
+		Base:     metaheuristic.NewBase(cm),
+		Fitness:  genetic.Berghman{A: 2, B: 2},
+		Restarts: 1000,
+	}
+}
+
+func (s *Solver) Solve() (mm.Code, error) {
+	return s.Search(s.search)
+}
+
+// search climbs from random codes until one reaches a consistent code,
+// returning it, or the fittest code reached if the restarts run out
+func (s *Solver) search() mm.Code {
+	var best mm.Code
+	bestFitness := 0.0
+	for i := 0; i < s.Restarts; i++ {
+		code, fitness := s.climb(s.RandomCode())
+		if s.Consistent(code) {
+			return code
+		}
+		if best == nil || fitness < bestFitness {
+			best, bestFitness = code, fitness
+		}
+	}
+	return best
+}
+
+// climb moves from code to its fittest neighbor until none is fitter,
+// returning the local optimum and its fitness
+func (s *Solver) climb(code mm.Code) (mm.Code, float64) {
+	fitness := s.Fitness.Evaluate(code, s.History, s.Size)
+	for !s.Consistent(code) {
+		next, f := s.bestNeighbor(code)
+		if f >= fitness {
+			break
+		}
+		code, fitness = next, f
+	}
+	return code, fitness
+}
+
+// the fittest code one recolor or one swap from code; ties go to the first
+// found, from a random starting position so climbs don't all lean one way
+func (s *Solver) bestNeighbor(code mm.Code) (mm.Code, float64) {
+	var best mm.Code
+	bestFitness := 0.0
+	try := func(n mm.Code) {
+		if f := s.Fitness.Evaluate(n, s.History, s.Size); best == nil || f < bestFitness {
+			best, bestFitness = append(mm.Code{}, n...), f
+		}
+	}
+
+	n := append(mm.Code{}, code...)
+	offset := s.Rand.Intn(len(code))
+	for i := range code {
+		p := (i + offset) % len(code)
+		for c := byte(0); c < s.Size.Colors; c++ {
+			if c != code[p] {
+				n[p] = c
+				try(n)
+			}
+		}
+		n[p] = code[p]
+
+		for q := p + 1; q < len(code); q++ {
+			if code[p] != code[q] {
+				n[p], n[q] = code[q], code[p]
+				try(n)
+				n[p], n[q] = code[p], code[q]
+			}
+		}
+	}
+	return best, bestFitness
+}
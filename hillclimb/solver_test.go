@@ -0,0 +1,30 @@
+package hillclimb
+
+import (
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestClimbNeverGetsWorse(t *testing.T) {
+	game := mm.NewCustomGameWithSecret(5, 8, mm.Code{1, 4, 0, 5, 3})
+	solver := NewSolver(game)
+	solver.Seed(1)
+	for _, guess := range []mm.Code{{0, 0, 1, 1, 2}, {3, 4, 5, 6, 7}} {
+		r, _ := game.ScoredGuess(guess)
+		solver.History = append(solver.History, mm.Move{Guess: guess, Result: r})
+	}
+
+	for i := 0; i < 20; i++ {
+		start := solver.RandomCode()
+		code, fitness := solver.climb(start)
+		if f := solver.Fitness.Evaluate(start, solver.History, solver.Size); fitness > f {
+			t.Errorf("climbed from %v (%.1f) down to %v (%.1f)", start, f, code, fitness)
+		}
+		if !solver.Consistent(code) {
+			if _, f := solver.bestNeighbor(code); f < fitness {
+				t.Errorf("stopped at %v with a fitter neighbor", code)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package mastermind
+
+// Move is a guess and the result it scored
+type Move struct {
+	Guess  Code
+	Result Result
+}
+
+func (m Move) String() string {
+	return m.Guess.String() + " " + m.Result.String()
+}
+
+// History is the sequence of moves made in a game, oldest first
+type History []Move
+
+// Consistent reports whether code could be the secret, ie whether it would
+// have produced every result in h
+func (h History) Consistent(code Code, colors byte) bool {
+	return h.ConsistentWith(code, colors, FullFeedback)
+}
+
+// ConsistentWith is Consistent for a game where the results in h were
+// reduced to feedback f
+func (h History) ConsistentWith(code Code, colors byte, f Feedback) bool {
+	for _, m := range h {
+		r, err := f.Score(m.Guess, code, colors)
+		if err != nil || r != m.Result {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+// Package human plays mastermind the ways people typically do, as a
+// measure of how much better the algorithmic solvers are.  None of its
+// strategies looks ahead: each guesses a code which could be the secret,
+// picked at random as a person would pick one that fits, though each
+// opens differently.
+package human
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// MaxCodes is the most codes a board may have to be played; the solver
+// keeps every code which could still be the secret
+const MaxCodes = 1 << 20
+
+// Strategy is a way a person plays
+type Strategy int
+
+const (
+	// Same opens with a code of a single color, then guesses any code
+	// which fits the results so far
+	Same Strategy = iota
+	// Elimination guesses a code of each color in turn, to learn how many
+	// pegs of each the secret has, stopping once every peg is accounted
+	// for, then arranges the colors found into codes which fit
+	Elimination
+	// Random guesses any code which fits the results so far, from the
+	// first guess on
+	Random
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case Same:
+		return "same"
+	case Elimination:
+		return "elimination"
+	case Random:
+		return "random"
+	}
+	return "unknown"
+}
+
+// ParseStrategy is the strategy named by String
+func ParseStrategy(name string) (Strategy, error) {
+	for _, s := range []Strategy{Same, Elimination, Random} {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown strategy %q", name)
+}
+
+type Solver struct {
+	codemaker mm.Codemaker
+	size      mm.GameSize
+	rand      *rand.Rand
+
+	Strategy Strategy
+	// MaxMoves is how many moves to make before giving up
+	MaxMoves int
+
+	History    mm.History
+	TurnsTaken int
+	SolveTime  time.Duration
+}
+
+func NewSolver(cm mm.Codemaker, strategy Strategy) *Solver {
+	size := cm.GameSize()
+	return &Solver{
+		codemaker: cm,
+		size:      size,
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		Strategy:  strategy,
+		MaxMoves:  size.Positions * int(size.Colors),
+	}
+}
+
+// Seed makes the solver reproducible
+func (s *Solver) Seed(seed int64) {
+	s.rand = rand.New(rand.NewSource(seed))
+}
+
+func (s *Solver) Solve() (mm.Code, error) {
+	if s.size.NumCodes() > MaxCodes {
+		return nil, fmt.Errorf("%v has too many codes for a person to play", s.size)
+	}
+	start := time.Now()
+	candidates := s.size.AllCodes()
+	for s.TurnsTaken < s.MaxMoves {
+		guess := s.next(candidates)
+		result, err := s.codemaker.ScoredGuess(guess)
+		if err != nil {
+			return nil, err
+		}
+		s.TurnsTaken++
+		if result.Correct == s.size.Positions {
+			s.SolveTime = time.Since(start)
+			return guess, nil
+		}
+		s.History = append(s.History, mm.Move{Guess: guess, Result: result})
+
+		kept := candidates[:0]
+		for _, c := range candidates {
+			if mm.Score(guess, c, s.size.Colors) == result {
+				kept = append(kept, c)
+			}
+		}
+		if candidates = kept; len(candidates) == 0 {
+			return nil, fmt.Errorf("no code fits the results scored")
+		}
+	}
+	return nil, fmt.Errorf("didn't find solution in %d moves", s.TurnsTaken)
+}
+
+// next is the strategy's next guess, with candidates the codes which fit
+// the results so far
+func (s *Solver) next(candidates mm.CodeSlice) mm.Code {
+	switch s.Strategy {
+	case Same:
+		if len(s.History) == 0 {
+			return s.same(byte(s.rand.Intn(int(s.size.Colors))))
+		}
+	case Elimination:
+		// a code of each color in turn, tallying the secret's pegs of
+		// each until they're all found.  The last color's pegs are what's
+		// left, so it's never guessed alone unless it's the secret.
+		found := 0
+		for _, m := range s.History {
+			found += m.Result.Correct
+		}
+		if c := byte(len(s.History)); found < s.size.Positions && int(c) < int(s.size.Colors)-1 {
+			return s.same(c)
+		}
+	}
+	return candidates[s.rand.Intn(len(candidates))]
+}
+
+// same is the code with every peg color c
+func (s *Solver) same(c byte) mm.Code {
+	code := make(mm.Code, s.size.Positions)
+	for i := range code {
+		code[i] = c
+	}
+	return code
+}
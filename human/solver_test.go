@@ -0,0 +1,68 @@
+package human
+
+import (
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestSolver(t *testing.T) {
+	size := mm.GameSize{Positions: 4, Colors: 6}
+	for _, strategy := range []Strategy{Same, Elimination, Random} {
+		counts, err := mm.CountMoves(size, func(cm mm.Codemaker) mm.Solver {
+			s := NewSolver(cm, strategy)
+			s.Seed(1)
+			return s
+		})
+		if err != nil {
+			t.Fatalf("%v: %v", strategy, err)
+		}
+		// people do worse than Knuth's 4.476 moves
+		if counts.Expected() < 4.476 {
+			t.Errorf("%v: expected worse than Knuth, got %v", strategy, counts)
+		}
+		t.Logf("%v: %v", strategy, counts)
+	}
+}
+
+func TestElimination(t *testing.T) {
+	for secret, expected := range map[string][]string{
+		// every color is tried, the last found by elimination
+		"5555": {"0000", "1111", "2222", "3333", "4444", "5555"},
+		// the colors are all found by the third guess
+		"2011": {"0000", "1111", "2222"},
+		"0000": {"0000"},
+	} {
+		code := mm.Code{secret[0] - '0', secret[1] - '0', secret[2] - '0', secret[3] - '0'}
+		game := mm.NewCustomGameWithSecret(4, 6, code)
+		game.Quiet = true
+		s := NewSolver(game, Elimination)
+		s.Seed(1)
+		if _, err := s.Solve(); err != nil {
+			t.Fatal(err)
+		}
+		guesses := append(s.History, mm.Move{Guess: code})
+		for i, g := range expected {
+			if guesses[i].Guess.String() != g {
+				t.Errorf("%s: expected guess %d to be %s, got %v", secret, i+1, g, guesses[i].Guess)
+			}
+		}
+		// once the colors are known, every guess is an arrangement of them
+		for _, m := range guesses[len(expected):] {
+			if mm.Score(m.Guess, code, 6).Correct+mm.Score(m.Guess, code, 6).HalfCorrect != 4 {
+				t.Errorf("%s: expected %v to hold the secret's colors", secret, m.Guess)
+			}
+		}
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	for _, s := range []Strategy{Same, Elimination, Random} {
+		if parsed, err := ParseStrategy(s.String()); err != nil || parsed != s {
+			t.Errorf("expected %v parsed back, got %v, %v", s, parsed, err)
+		}
+	}
+	if _, err := ParseStrategy("knuth"); err == nil {
+		t.Error("expected an unknown strategy refused")
+	}
+}
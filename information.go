@@ -0,0 +1,95 @@
+package mastermind
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// MaxAnalyzedCodes is the most codes a board may have for Analyze, which
+// keeps every code which could still be the secret
+const MaxAnalyzedCodes = 1 << 22
+
+// Step is what one move of a game told the codebreaker
+type Step struct {
+	Move
+	// Before and After are how many codes could be the secret before the
+	// move and after it
+	Before int
+	After  int
+	// Bits is the information the move's result carried, log2 of
+	// Before/After, and Expected what the guess's result carries on
+	// average over the codes Before, which is how well it was chosen
+	// whatever result it happened to score
+	Bits     float64
+	Expected float64
+	// Total is the bits of every move so far
+	Total float64
+}
+
+// Analyze walks the moves of a game of size, where the results were
+// reduced to feedback f, and works out how far each narrowed the codes
+// which could be the secret.  It fails if the moves leave no code
+// possible.
+func Analyze(size GameSize, f Feedback, h History) ([]Step, error) {
+	if size.NumCodes() > MaxAnalyzedCodes {
+		return nil, fmt.Errorf("%v has too many codes to analyze", size)
+	}
+	candidates := size.AllCodes()
+	steps := make([]Step, 0, len(h))
+	total := 0.0
+	for i, m := range h {
+		if len(m.Guess) != size.Positions {
+			return nil, fmt.Errorf("move %d guesses %v, which isn't size %v", i+1, m.Guess, size)
+		}
+		// the codes each result leaves, of which those the move scored
+		// are kept
+		buckets := map[Result]int{}
+		kept := candidates[:0:0]
+		for _, c := range candidates {
+			r, _ := f.Score(m.Guess, c, size.Colors)
+			buckets[r]++
+			if r == m.Result {
+				kept = append(kept, c)
+			}
+		}
+		if len(kept) == 0 {
+			return nil, fmt.Errorf("no code scores move %d, %v", i+1, m)
+		}
+
+		s := Step{Move: m, Before: len(candidates), After: len(kept)}
+		s.Bits = math.Log2(float64(s.Before) / float64(s.After))
+		for _, n := range buckets {
+			p := float64(n) / float64(s.Before)
+			s.Expected -= p * math.Log2(p)
+		}
+		total += s.Bits
+		s.Total = total
+		steps = append(steps, s)
+		candidates = kept
+	}
+	return steps, nil
+}
+
+// WriteStepsCSV writes a row for each step, with a header row, for
+// plotting
+func WriteStepsCSV(w io.Writer, steps []Step) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"move", "guess", "result", "before", "after", "bits", "expected_bits", "total_bits"})
+	for i, s := range steps {
+		cw.Write([]string{
+			strconv.Itoa(i + 1),
+			s.Guess.String(),
+			s.Result.String(),
+			strconv.Itoa(s.Before),
+			strconv.Itoa(s.After),
+			strconv.FormatFloat(s.Bits, 'f', 4, 64),
+			strconv.FormatFloat(s.Expected, 'f', 4, 64),
+			strconv.FormatFloat(s.Total, 'f', 4, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
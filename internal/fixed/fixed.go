@@ -0,0 +1,45 @@
+// Package fixed holds codes in arrays rather than slices of their own, so
+// hot loops can keep and copy codes without allocating each one, and key
+// maps by them without making strings of them.
+package fixed
+
+import (
+	"fmt"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// MaxPositions is the most pegs a Code holds
+const MaxPositions = 32
+
+// Code is a code of up to MaxPositions pegs.  Codes are comparable, so
+// they key maps as they are.
+type Code struct {
+	pegs [MaxPositions]byte
+	n    uint8
+}
+
+// Of copies c into a Code.  It panics if c has more than MaxPositions pegs.
+func Of(c mm.Code) Code {
+	if len(c) > MaxPositions {
+		panic(fmt.Sprintf("a code of %d pegs is too long to fix", len(c)))
+	}
+	var f Code
+	f.n = uint8(copy(f.pegs[:], c))
+	return f
+}
+
+// Code is f's pegs as an mm.Code.  It shares them with f, so it's only
+// good while f is left as it is.
+func (f *Code) Code() mm.Code {
+	return f.pegs[:f.n:f.n]
+}
+
+// Codes is fs as mm.Codes, sharing their pegs
+func Codes(fs []Code) mm.CodeSlice {
+	codes := make(mm.CodeSlice, len(fs))
+	for i := range fs {
+		codes[i] = fs[i].Code()
+	}
+	return codes
+}
@@ -0,0 +1,37 @@
+package fixed
+
+import (
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestCode(t *testing.T) {
+	a, b := Of(mm.Code{1, 2, 3}), Of(mm.Code{1, 2, 3, 0})
+	if a == b {
+		t.Error("expected 123 and 1230 to differ")
+	}
+	if a != Of(mm.Code{1, 2, 3}) {
+		t.Error("expected two 123s to be equal")
+	}
+	seen := map[Code]bool{a: true}
+	if !seen[Of(mm.Code{1, 2, 3})] || seen[b] {
+		t.Error("expected 123 and only 123 to key the map")
+	}
+
+	fs := []Code{a, b}
+	codes := Codes(fs)
+	if codes[0].String() != "123" || codes[1].String() != "1230" || cap(codes[0]) != 3 {
+		t.Errorf("expected 123 and 1230, got %v", codes)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { Of(mm.Code{4, 5, 6}) }); allocs != 0 {
+		t.Errorf("expected fixing a code to allocate nothing, got %v allocations", allocs)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a code too long to fix to panic")
+		}
+	}()
+	Of(make(mm.Code, MaxPositions+1))
+}
@@ -0,0 +1,95 @@
+// Package metaheuristic is what the solvers which search for a code
+// consistent with the results so far have in common: the game they're
+// playing, the loop guessing and scoring each move, and the random and
+// consistent codes their searches are made of.  A solver embeds a Base and
+// supplies only its search.
+package metaheuristic
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/genetic"
+)
+
+// Base is a solver's game
+type Base struct {
+	codemaker mm.Codemaker
+	// Size is the board, and Rand every random choice the solver makes
+	Size mm.GameSize
+	Rand *rand.Rand
+	// MaxMoves is how many moves to make before giving up; a move for every
+	// color of every position unless set
+	MaxMoves int
+
+	History    mm.History
+	TurnsTaken int
+	SolveTime  time.Duration
+}
+
+// NewBase is a game against cm, seeded from the clock
+func NewBase(cm mm.Codemaker) Base {
+	size := cm.GameSize()
+	return Base{
+		codemaker: cm,
+		Size:      size,
+		Rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		MaxMoves:  size.Positions * int(size.Colors),
+	}
+}
+
+// Seed makes the solver reproducible
+func (b *Base) Seed(seed int64) {
+	b.Rand = rand.New(rand.NewSource(seed))
+}
+
+// Run plays the game, guessing whatever next chooses each move given the
+// History, until a guess wins or MaxMoves are made
+func (b *Base) Run(next func() (mm.Code, error)) (mm.Code, error) {
+	start := time.Now()
+	for b.TurnsTaken < b.MaxMoves {
+		guess, err := next()
+		if err != nil {
+			return nil, err
+		}
+		result, err := b.codemaker.ScoredGuess(guess)
+		if err != nil {
+			return nil, err
+		}
+		b.TurnsTaken++
+		if result.Correct == b.Size.Positions {
+			b.SolveTime = time.Since(start)
+			return guess, nil
+		}
+		b.History = append(b.History, mm.Move{Guess: guess, Result: result})
+	}
+	return nil, fmt.Errorf("didn't find solution in %d moves", b.TurnsTaken)
+}
+
+// Search is Run for a solver which opens with a random code, then guesses
+// what search finds each move
+func (b *Base) Search(search func() mm.Code) (mm.Code, error) {
+	return b.Run(func() (mm.Code, error) {
+		if len(b.History) == 0 {
+			return b.RandomCode(), nil
+		}
+		return search(), nil
+	})
+}
+
+// Consistent is whether c would have scored every result so far
+func (b *Base) Consistent(c mm.Code) bool {
+	sumX, sumY := genetic.Differences(c, b.History, b.Size.Colors)
+	return sumX == 0 && sumY == 0
+}
+
+// RandomCode is a code of uniformly random colors
+func (b *Base) RandomCode() mm.Code {
+	code := make(mm.Code, b.Size.Positions)
+	for i := range code {
+		code[i] = byte(b.Rand.Intn(int(b.Size.Colors)))
+	}
+	return code
+}
@@ -0,0 +1,85 @@
+package metaheuristic_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/aco"
+	"github.com/ianmcmahon/mastermind/cem"
+	"github.com/ianmcmahon/mastermind/hillclimb"
+	"github.com/ianmcmahon/mastermind/internal/metaheuristic"
+	"github.com/ianmcmahon/mastermind/pso"
+	"github.com/ianmcmahon/mastermind/simanneal"
+)
+
+// solvers are the solvers built on a Base, each made with its Base
+var solvers = []struct {
+	name string
+	new  func(cm mm.Codemaker) (mm.Solver, *metaheuristic.Base)
+}{
+	{"annealing", func(cm mm.Codemaker) (mm.Solver, *metaheuristic.Base) {
+		s := simanneal.NewSolver(cm)
+		return s, &s.Base
+	}},
+	{"swarm", func(cm mm.Codemaker) (mm.Solver, *metaheuristic.Base) {
+		s := pso.NewSolver(cm)
+		return s, &s.Base
+	}},
+	{"colony", func(cm mm.Codemaker) (mm.Solver, *metaheuristic.Base) {
+		s := aco.NewSolver(cm)
+		return s, &s.Base
+	}},
+	{"cross-entropy", func(cm mm.Codemaker) (mm.Solver, *metaheuristic.Base) {
+		s := cem.NewSolver(cm)
+		return s, &s.Base
+	}},
+	{"hill climbing", func(cm mm.Codemaker) (mm.Solver, *metaheuristic.Base) {
+		s := hillclimb.NewSolver(cm)
+		return s, &s.Base
+	}},
+}
+
+// play has a solver seeded with seed play secret
+func play(t *testing.T, new func(mm.Codemaker) (mm.Solver, *metaheuristic.Base), size mm.GameSize, secret mm.Code, seed int64) (mm.Code, *metaheuristic.Base) {
+	game := mm.NewCustomGameWithSecret(size.Positions, size.Colors, secret)
+	s, base := new(game)
+	base.Seed(seed)
+	winner, err := s.Solve()
+	if err != nil {
+		t.Fatalf("%v: %v", secret, err)
+	}
+	return winner, base
+}
+
+func TestSolvers(t *testing.T) {
+	sizes := []mm.GameSize{{Positions: 4, Colors: 6}, {Positions: 5, Colors: 8}, {Positions: 8, Colors: 10}}
+	for _, sv := range solvers {
+		t.Run(sv.name, func(t *testing.T) {
+			for _, size := range sizes {
+				for seed := int64(0); seed < 3; seed++ {
+					secret := size.CodeAt(rand.New(rand.NewSource(seed)).Intn(size.NumCodes()))
+					winner, base := play(t, sv.new, size, secret, seed)
+					t.Logf("%d moves on %v in %v", base.TurnsTaken, size, base.SolveTime)
+
+					if winner.Compare(secret) != 0 {
+						t.Errorf("%v: solved as %v", secret, winner)
+					}
+					if base.TurnsTaken != len(base.History)+1 || base.TurnsTaken > base.MaxMoves {
+						t.Errorf("%v: %d moves with %d in the history, of at most %d", secret, base.TurnsTaken, len(base.History), base.MaxMoves)
+					}
+					for _, m := range base.History {
+						if r, _ := mm.CheckCode(m.Guess, secret, size.Colors); r != m.Result {
+							t.Errorf("%v: %v kept as %v, but scores %v", secret, m.Guess, m.Result, r)
+						}
+					}
+					// the same seed plays the same game
+					if _, again := play(t, sv.new, size, secret, seed); !reflect.DeepEqual(again.History, base.History) {
+						t.Errorf("%v: seed %d played %v, then %v", secret, seed, base.History, again.History)
+					}
+				}
+			}
+		})
+	}
+}
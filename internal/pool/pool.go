@@ -0,0 +1,131 @@
+// Package pool runs functions on a bounded number of goroutines.
+package pool
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Pool limits the number of functions running at once.  Functions are
+// started with Go, may serialize access to shared state with Locked, and
+// are waited on with Wait, which reports the first error returned.
+type Pool struct {
+	sem    chan struct{}
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	errOnce sync.Once
+	err     error
+}
+
+// Workers is n, or if n isn't positive, one worker per CPU
+func Workers(n int) int {
+	if n > 0 {
+		return n
+	}
+	return runtime.NumCPU()
+}
+
+// Ranges splits [0, n) into contiguous ranges, in order, and runs f on
+// each at once, returning when they're all done.  There are at most
+// workers ranges, and no more than make each grain long; one range is run
+// on the calling goroutine.
+func Ranges(n, workers, grain int, f func(i, lo, hi int)) {
+	if grain < 1 {
+		grain = 1
+	}
+	if max := (n + grain - 1) / grain; workers > max {
+		workers = max
+	}
+	if workers <= 1 {
+		if n > 0 {
+			f(0, 0, n)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		i1, lo, hi := i, i*n/workers, (i+1)*n/workers
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f(i1, lo, hi)
+		}()
+	}
+	wg.Wait()
+}
+
+// New returns a pool running at most n functions at once
+func New(n int) *Pool {
+	p, _ := WithContext(context.Background(), n)
+	return p
+}
+
+// WithContext returns a pool running at most n functions at once, and a
+// context derived from ctx which is canceled when a function returns an
+// error or when Wait returns.  Once the context is done, Go stops starting
+// new functions.
+func WithContext(ctx context.Context, n int) (*Pool, context.Context) {
+	if n < 1 {
+		n = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &Pool{
+		sem:    make(chan struct{}, n),
+		ctx:    ctx,
+		cancel: cancel,
+	}, ctx
+}
+
+// Go runs f on its own goroutine, blocking until the pool has room for it.
+// If the pool's context is done, f is not run.
+func (p *Pool) Go(f func() error) {
+	if err := p.ctx.Err(); err != nil {
+		p.fail(err)
+		return
+	}
+
+	select {
+	case p.sem <- struct{}{}:
+	case <-p.ctx.Done():
+		p.fail(p.ctx.Err())
+		return
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.sem
+			p.wg.Done()
+		}()
+		if err := f(); err != nil {
+			p.fail(err)
+		}
+	}()
+}
+
+// Locked runs f while holding the pool's mutex
+func (p *Pool) Locked(f func() error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return f()
+}
+
+// Wait blocks until every function started by Go has returned, and returns
+// the first error any of them returned
+func (p *Pool) Wait() error {
+	p.wg.Wait()
+	p.cancel()
+	return p.err
+}
+
+func (p *Pool) fail(err error) {
+	p.errOnce.Do(func() {
+		p.err = err
+		p.cancel()
+	})
+}
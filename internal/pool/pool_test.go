@@ -0,0 +1,99 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPoolLimit(t *testing.T) {
+	p := New(4)
+	var running, peak int32
+	sum := 0
+
+	for i := 1; i <= 100; i++ {
+		i1 := i
+		p.Go(func() error {
+			n := atomic.AddInt32(&running, 1)
+			p.Locked(func() error {
+				if n > peak {
+					peak = n
+				}
+				sum += i1
+				return nil
+			})
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+	}
+
+	if err := p.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 5050 {
+		t.Errorf("sum should be 5050, got %d", sum)
+	}
+	if peak > 4 {
+		t.Errorf("%d functions ran at once, limit is 4", peak)
+	}
+}
+
+func TestPoolError(t *testing.T) {
+	p, ctx := WithContext(context.Background(), 1)
+	boom := errors.New("boom")
+
+	p.Go(func() error { return boom })
+	if err := p.Wait(); err != boom {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+	if ctx.Err() == nil {
+		t.Error("context should be canceled after an error")
+	}
+
+	ran := false
+	p.Go(func() error {
+		ran = true
+		return nil
+	})
+	p.Wait()
+	if ran {
+		t.Error("functions should not start after the context is done")
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	if n := Workers(3); n != 3 {
+		t.Errorf("expected 3 workers, got %d", n)
+	}
+	if n := Workers(0); n != runtime.NumCPU() {
+		t.Errorf("expected a worker per CPU, got %d", n)
+	}
+}
+
+func TestRanges(t *testing.T) {
+	for _, c := range []struct{ n, workers, grain, ranges int }{
+		{100, 4, 1, 4}, {100, 4, 50, 2}, {100, 1, 1, 1}, {3, 8, 1, 3}, {0, 4, 1, 0},
+	} {
+		covered := make([]int32, c.n)
+		var ranges int32
+		Ranges(c.n, c.workers, c.grain, func(i, lo, hi int) {
+			atomic.AddInt32(&ranges, 1)
+			if lo != i*c.n/int(c.ranges) {
+				t.Errorf("%+v: range %d starts at %d", c, i, lo)
+			}
+			for j := lo; j < hi; j++ {
+				atomic.AddInt32(&covered[j], 1)
+			}
+		})
+		if int(ranges) != c.ranges {
+			t.Errorf("%+v: expected %d ranges, got %d", c, c.ranges, ranges)
+		}
+		for j, n := range covered {
+			if n != 1 {
+				t.Errorf("%+v: %d covered %d times", c, j, n)
+			}
+		}
+	}
+}
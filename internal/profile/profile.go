@@ -0,0 +1,70 @@
+// Package profile writes CPU and memory profiles of a command's run, for go
+// tool pprof, when its flags ask for them.
+package profile
+
+import (
+	"flag"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// Profile is where a run's profiles are written; an empty file name means
+// that profile isn't taken
+type Profile struct {
+	CPU    string
+	Memory string
+
+	cpu *os.File
+}
+
+// AddFlags adds -cpuprofile and -memprofile to fs, naming p's files
+func (p *Profile) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&p.CPU, "cpuprofile", "", "file to write a CPU profile to")
+	fs.StringVar(&p.Memory, "memprofile", "", "file to write a memory profile to, at the end of the run")
+}
+
+// Start starts profiling the CPU, if p has a file for it
+func (p *Profile) Start() error {
+	if p.CPU == "" {
+		return nil
+	}
+	f, err := os.Create(p.CPU)
+	if err != nil {
+		return err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	p.cpu = f
+	return nil
+}
+
+// Stop finishes the CPU profile and writes the memory profile, if p has
+// files for them
+func (p *Profile) Stop() error {
+	if p.cpu != nil {
+		pprof.StopCPUProfile()
+		err := p.cpu.Close()
+		p.cpu = nil
+		if err != nil {
+			return err
+		}
+	}
+	if p.Memory == "" {
+		return nil
+	}
+	f, err := os.Create(p.Memory)
+	if err != nil {
+		return err
+	}
+	// the profile is of what's been allocated and is still live as of the
+	// last collection, so collect first
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
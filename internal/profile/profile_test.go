@@ -0,0 +1,45 @@
+package profile
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var p Profile
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	p.AddFlags(fs)
+	cpu, mem := filepath.Join(dir, "cpu.pprof"), filepath.Join(dir, "mem.pprof")
+	if err := fs.Parse([]string{"-cpuprofile", cpu, "-memprofile", mem}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{cpu, mem} {
+		if info, err := os.Stat(file); err != nil || info.Size() == 0 {
+			t.Errorf("expected a profile in %s, got %v", file, err)
+		}
+	}
+
+	// without files, there's nothing to do
+	var none Profile
+	if err := none.Start(); err != nil {
+		t.Error(err)
+	}
+	if err := none.Stop(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,81 @@
+package sat
+
+// Totalizer encodes the count of true literals among lits in unary: it
+// returns outputs where outputs[k] is true exactly when at least k+1 of lits
+// are.  This is the totalizer of Bailleux and Boufkhad, with the clauses for
+// both directions, so the outputs can be constrained either way.
+func (s *Solver) Totalizer(lits []int) []int {
+	if len(lits) <= 1 {
+		return append([]int{}, lits...)
+	}
+	a := s.Totalizer(lits[:len(lits)/2])
+	b := s.Totalizer(lits[len(lits)/2:])
+
+	r := make([]int, len(a)+len(b))
+	for i := range r {
+		r[i] = s.NewVar()
+	}
+
+	// out(x, i) is "at least i of x", with i = 0 always true and i past the
+	// end always false; 0 stands in for both
+	out := func(x []int, i int) int {
+		if i == 0 || i > len(x) {
+			return 0
+		}
+		return x[i-1]
+	}
+
+	for i := 0; i <= len(a); i++ {
+		for j := 0; j <= len(b); j++ {
+			// at least i of a and j of b means at least i+j
+			if i+j > 0 {
+				clause := []int{r[i+j-1]}
+				if l := out(a, i); l != 0 {
+					clause = append(clause, -l)
+				}
+				if l := out(b, j); l != 0 {
+					clause = append(clause, -l)
+				}
+				s.AddClause(clause...)
+			}
+			// fewer than i+1 of a and j+1 of b means fewer than i+j+1
+			if i+j < len(r) {
+				clause := []int{-r[i+j]}
+				if l := out(a, i+1); l != 0 {
+					clause = append(clause, l)
+				}
+				if l := out(b, j+1); l != 0 {
+					clause = append(clause, l)
+				}
+				s.AddClause(clause...)
+			}
+		}
+	}
+	return r
+}
+
+// Exactly constrains the count behind totalizer outputs to n
+func (s *Solver) Exactly(outputs []int, n int) bool {
+	ok := true
+	if n < 0 || n > len(outputs) {
+		return s.AddClause()
+	}
+	if n > 0 {
+		ok = s.AddClause(outputs[n-1])
+	}
+	if n < len(outputs) {
+		ok = s.AddClause(-outputs[n]) && ok
+	}
+	return ok
+}
+
+// AtMostOne constrains no two of lits to be true together
+func (s *Solver) AtMostOne(lits []int) bool {
+	ok := true
+	for i := range lits {
+		for j := i + 1; j < len(lits); j++ {
+			ok = s.AddClause(-lits[i], -lits[j]) && ok
+		}
+	}
+	return ok
+}
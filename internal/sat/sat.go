@@ -0,0 +1,361 @@
+// Package sat is a small CDCL boolean satisfiability solver, with the
+// cardinality encodings needed to state mastermind feedback as clauses.
+//
+// Variables are numbered from 1, and literals are DIMACS style: v for the
+// variable v being true, -v for it being false.
+package sat
+
+import (
+	"math/rand"
+)
+
+const (
+	unassigned int8 = 0
+	isTrue     int8 = 1
+	isFalse    int8 = -1
+
+	// activity decay, and the conflicts before the first restart and the
+	// growth of the interval between restarts
+	decay         = 0.95
+	firstRestart  = 100
+	restartGrowth = 1.5
+)
+
+// Solver holds a formula in conjunctive normal form.  Clauses may be added
+// between calls to Solve, so models can be enumerated by blocking each one
+// found.
+type Solver struct {
+	clauses [][]int
+	// watches[index(l)] are the clauses watching literal l
+	watches [][]int
+
+	assigns  []int8
+	level    []int
+	reason   []int
+	activity []float64
+	inc      float64
+
+	trail    []int
+	trailLim []int
+	qhead    int
+
+	model []bool
+	unsat bool
+	rand  *rand.Rand
+}
+
+func NewSolver() *Solver {
+	return &Solver{
+		watches:  make([][]int, 2),
+		assigns:  []int8{unassigned},
+		level:    []int{0},
+		reason:   []int{-1},
+		activity: []float64{0},
+		inc:      1,
+		model:    []bool{false},
+	}
+}
+
+// SetRand randomizes the order and polarity of decisions, so repeated
+// solves with blocking clauses sample the models rather than walking them
+// in order
+func (s *Solver) SetRand(rng *rand.Rand) {
+	s.rand = rng
+}
+
+// NumVars is the number of variables made so far
+func (s *Solver) NumVars() int {
+	return len(s.assigns) - 1
+}
+
+// NewVar makes a new variable
+func (s *Solver) NewVar() int {
+	s.assigns = append(s.assigns, unassigned)
+	s.level = append(s.level, 0)
+	s.reason = append(s.reason, -1)
+	act := 0.0
+	if s.rand != nil {
+		act = s.rand.Float64() * 1e-3
+	}
+	s.activity = append(s.activity, act)
+	s.model = append(s.model, false)
+	s.watches = append(s.watches, nil, nil)
+	return s.NumVars()
+}
+
+// index of a literal into watches
+func index(l int) int {
+	if l < 0 {
+		return 2*(-l) + 1
+	}
+	return 2 * l
+}
+
+func variable(l int) int {
+	if l < 0 {
+		return -l
+	}
+	return l
+}
+
+func (s *Solver) value(l int) int8 {
+	v := s.assigns[variable(l)]
+	if l < 0 {
+		return -v
+	}
+	return v
+}
+
+func (s *Solver) decisionLevel() int {
+	return len(s.trailLim)
+}
+
+// AddClause adds the disjunction of lits to the formula.  It returns false
+// once the formula is known to be unsatisfiable.
+func (s *Solver) AddClause(lits ...int) bool {
+	if s.unsat {
+		return false
+	}
+	s.cancelUntil(0)
+
+	// drop false and duplicate literals; a true or complementary one
+	// satisfies the clause outright
+	clause := make([]int, 0, len(lits))
+	seen := map[int]bool{}
+	for _, l := range lits {
+		switch {
+		case s.value(l) == isTrue || seen[-l]:
+			return true
+		case s.value(l) == isFalse || seen[l]:
+			continue
+		}
+		seen[l] = true
+		clause = append(clause, l)
+	}
+
+	switch len(clause) {
+	case 0:
+		s.unsat = true
+		return false
+	case 1:
+		s.enqueue(clause[0], -1)
+		if s.propagate() >= 0 {
+			s.unsat = true
+			return false
+		}
+		return true
+	}
+	s.attach(clause)
+	return true
+}
+
+func (s *Solver) attach(clause []int) int {
+	ci := len(s.clauses)
+	s.clauses = append(s.clauses, clause)
+	s.watches[index(clause[0])] = append(s.watches[index(clause[0])], ci)
+	s.watches[index(clause[1])] = append(s.watches[index(clause[1])], ci)
+	return ci
+}
+
+func (s *Solver) enqueue(l int, reason int) {
+	v := variable(l)
+	if l > 0 {
+		s.assigns[v] = isTrue
+	} else {
+		s.assigns[v] = isFalse
+	}
+	s.level[v] = s.decisionLevel()
+	s.reason[v] = reason
+	s.trail = append(s.trail, l)
+}
+
+// propagate assigns every literal implied by the trail, returning the index
+// of a conflicting clause, or -1 if there's none
+func (s *Solver) propagate() int {
+	for s.qhead < len(s.trail) {
+		falseLit := -s.trail[s.qhead]
+		s.qhead++
+
+		ws := s.watches[index(falseLit)]
+		kept := ws[:0]
+		for i, ci := range ws {
+			c := s.clauses[ci]
+			if c[0] == falseLit {
+				c[0], c[1] = c[1], c[0]
+			}
+			if s.value(c[0]) == isTrue {
+				kept = append(kept, ci)
+				continue
+			}
+
+			moved := false
+			for k := 2; k < len(c); k++ {
+				if s.value(c[k]) != isFalse {
+					c[1], c[k] = c[k], c[1]
+					s.watches[index(c[1])] = append(s.watches[index(c[1])], ci)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+
+			kept = append(kept, ci)
+			if s.value(c[0]) == isFalse {
+				kept = append(kept, ws[i+1:]...)
+				s.watches[index(falseLit)] = kept
+				s.qhead = len(s.trail)
+				return ci
+			}
+			s.enqueue(c[0], ci)
+		}
+		s.watches[index(falseLit)] = kept
+	}
+	return -1
+}
+
+// analyze derives the first UIP clause of a conflict, returning it with
+// the asserting literal first, and the level to backjump to
+func (s *Solver) analyze(conflict int) ([]int, int) {
+	seen := make([]bool, len(s.assigns))
+	learnt := []int{0}
+	pending := 0
+	p := 0
+	i := len(s.trail) - 1
+
+	for {
+		for _, q := range s.clauses[conflict] {
+			if q == p {
+				continue
+			}
+			v := variable(q)
+			if seen[v] || s.level[v] == 0 {
+				continue
+			}
+			seen[v] = true
+			s.bump(v)
+			if s.level[v] == s.decisionLevel() {
+				pending++
+			} else {
+				learnt = append(learnt, q)
+			}
+		}
+
+		// the next literal of the trail involved in the conflict
+		for !seen[variable(s.trail[i])] {
+			i--
+		}
+		p = s.trail[i]
+		i--
+		conflict = s.reason[variable(p)]
+		seen[variable(p)] = false
+		pending--
+		if pending == 0 {
+			break
+		}
+	}
+	learnt[0] = -p
+
+	back := 0
+	for k := 1; k < len(learnt); k++ {
+		if l := s.level[variable(learnt[k])]; l > back {
+			back = l
+			// watch the literal of the highest level second
+			learnt[1], learnt[k] = learnt[k], learnt[1]
+		}
+	}
+	return learnt, back
+}
+
+func (s *Solver) bump(v int) {
+	s.activity[v] += s.inc
+	if s.activity[v] > 1e100 {
+		for i := range s.activity {
+			s.activity[i] *= 1e-100
+		}
+		s.inc *= 1e-100
+	}
+}
+
+func (s *Solver) cancelUntil(level int) {
+	if s.decisionLevel() <= level {
+		return
+	}
+	for i := len(s.trail) - 1; i >= s.trailLim[level]; i-- {
+		v := variable(s.trail[i])
+		s.assigns[v] = unassigned
+		s.reason[v] = -1
+	}
+	s.trail = s.trail[:s.trailLim[level]]
+	s.trailLim = s.trailLim[:level]
+	s.qhead = len(s.trail)
+}
+
+// the unassigned variable of greatest activity, or 0 if all are assigned
+func (s *Solver) pickBranch() int {
+	best := 0
+	for v := 1; v < len(s.assigns); v++ {
+		if s.assigns[v] == unassigned && (best == 0 || s.activity[v] > s.activity[best]) {
+			best = v
+		}
+	}
+	return best
+}
+
+// Solve reports whether the formula is satisfiable.  If it is, Value gives
+// the model found.
+func (s *Solver) Solve() bool {
+	if s.unsat {
+		return false
+	}
+	s.cancelUntil(0)
+
+	conflicts := 0
+	restart := float64(firstRestart)
+	for {
+		if conflict := s.propagate(); conflict >= 0 {
+			if s.decisionLevel() == 0 {
+				s.unsat = true
+				return false
+			}
+			conflicts++
+			learnt, back := s.analyze(conflict)
+			s.cancelUntil(back)
+			if len(learnt) == 1 {
+				s.enqueue(learnt[0], -1)
+			} else {
+				s.enqueue(learnt[0], s.attach(learnt))
+			}
+			s.inc /= decay
+			continue
+		}
+
+		if float64(conflicts) >= restart {
+			conflicts = 0
+			restart *= restartGrowth
+			s.cancelUntil(0)
+			continue
+		}
+
+		v := s.pickBranch()
+		if v == 0 {
+			for i := 1; i < len(s.assigns); i++ {
+				s.model[i] = s.assigns[i] == isTrue
+			}
+			s.cancelUntil(0)
+			return true
+		}
+		l := -v
+		if s.rand != nil && s.rand.Intn(2) == 0 {
+			l = v
+		}
+		s.trailLim = append(s.trailLim, len(s.trail))
+		s.enqueue(l, -1)
+	}
+}
+
+// Value is the value of variable v in the last model found
+func (s *Solver) Value(v int) bool {
+	return s.model[v]
+}
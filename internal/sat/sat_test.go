@@ -0,0 +1,97 @@
+package sat
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSolve(t *testing.T) {
+	s := NewSolver()
+	a, b, c := s.NewVar(), s.NewVar(), s.NewVar()
+	s.AddClause(a, b)
+	s.AddClause(-a, c)
+	s.AddClause(-b, c)
+	s.AddClause(-c, -a)
+	if !s.Solve() {
+		t.Fatal("satisfiable formula reported unsatisfiable")
+	}
+	if s.Value(a) || !s.Value(b) || !s.Value(c) {
+		t.Errorf("wrong model: a=%v b=%v c=%v", s.Value(a), s.Value(b), s.Value(c))
+	}
+
+	s.AddClause(-b)
+	if s.Solve() {
+		t.Error("unsatisfiable formula reported satisfiable")
+	}
+}
+
+// n+1 pigeons don't fit in n holes, which takes real search to prove
+func TestPigeonhole(t *testing.T) {
+	for _, n := range []int{3, 5, 6} {
+		s := NewSolver()
+		in := make([][]int, n+1)
+		for p := range in {
+			in[p] = make([]int, n)
+			for h := range in[p] {
+				in[p][h] = s.NewVar()
+			}
+			s.AddClause(in[p]...)
+		}
+		for h := 0; h < n; h++ {
+			hole := []int{}
+			for p := range in {
+				hole = append(hole, in[p][h])
+			}
+			s.AtMostOne(hole)
+		}
+		if s.Solve() {
+			t.Errorf("%d pigeons fit in %d holes", n+1, n)
+		}
+	}
+}
+
+func TestTotalizer(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 1; n <= 7; n++ {
+		for k := 0; k <= n; k++ {
+			s := NewSolver()
+			s.SetRand(rng)
+			lits := make([]int, n)
+			for i := range lits {
+				lits[i] = s.NewVar()
+			}
+			s.Exactly(s.Totalizer(lits), k)
+
+			// enumerate every model, blocking each one found
+			models := 0
+			for s.Solve() {
+				models++
+				count := 0
+				block := []int{}
+				for _, l := range lits {
+					if s.Value(l) {
+						count++
+						block = append(block, -l)
+					} else {
+						block = append(block, l)
+					}
+				}
+				if count != k {
+					t.Fatalf("exactly %d of %d: model has %d", k, n, count)
+				}
+				s.AddClause(block...)
+			}
+			if expected := binomial(n, k); models != expected {
+				t.Errorf("exactly %d of %d: found %d models, expected %d", k, n, models, expected)
+			}
+		}
+	}
+}
+
+func binomial(n, k int) int {
+	r := 1
+	for i := 1; i <= k; i++ {
+		r = r * (n - k + i) / i
+	}
+	return r
+}
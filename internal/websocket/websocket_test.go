@@ -0,0 +1,106 @@
+package websocket
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func echo(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(msg)
+		}
+	}))
+}
+
+func TestEcho(t *testing.T) {
+	srv := echo(t)
+	defer srv.Close()
+	conn, err := Dial("ws" + strings.TrimPrefix(srv.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// short, 16 bit and 64 bit lengths
+	for _, n := range []int{5, 300, 70000} {
+		msg := bytes.Repeat([]byte("x"), n)
+		if err := conn.WriteMessage(msg); err != nil {
+			t.Fatal(err)
+		}
+		got, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Errorf("expected %d bytes echoed, got %d", n, len(got))
+		}
+	}
+
+	var v struct{ A int }
+	conn.WriteJSON(struct{ A int }{7})
+	if err := conn.ReadJSON(&v); err != nil || v.A != 7 {
+		t.Errorf("expected JSON echoed, got %v (%v)", v, err)
+	}
+}
+
+func TestFragmentsAndPings(t *testing.T) {
+	srv := echo(t)
+	defer srv.Close()
+	conn, err := Dial("ws" + strings.TrimPrefix(srv.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a ping between two fragments is answered without interrupting them
+	frame := func(fin bool, op byte, payload string) {
+		conn.wmu.Lock()
+		b := byte(op)
+		if fin {
+			b |= 0x80
+		}
+		conn.conn.Write(append([]byte{b, 0x80 | byte(len(payload)), 0, 0, 0, 0}, payload...))
+		conn.wmu.Unlock()
+	}
+	frame(false, opText, "hello, ")
+	frame(true, opPing, "ping")
+	frame(true, opContinuation, "world")
+
+	if fin, op, payload, err := conn.readFrame(); err != nil || !fin || op != opPong || string(payload) != "ping" {
+		t.Errorf("expected a pong, got %v %d %q %v", fin, op, payload, err)
+	}
+	if msg, err := conn.ReadMessage(); err != nil || string(msg) != "hello, world" {
+		t.Errorf("expected the fragments joined, got %q %v", msg, err)
+	}
+
+	conn.writeFrame(opClose, nil)
+	if _, err := conn.ReadMessage(); err != io.EOF {
+		t.Errorf("expected EOF after closing, got %v", err)
+	}
+}
+
+func TestNotWebsocket(t *testing.T) {
+	srv := echo(t)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected a plain request to be refused, got %s", resp.Status)
+	}
+}
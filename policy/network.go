@@ -0,0 +1,226 @@
+package policy
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Network is a feed forward neural network policy, in a simple JSON format
+// that a model trained elsewhere can be exported to.  Frameworks with their
+// own formats, ONNX and the like, can be exported to it layer by layer, or
+// served behind the Policy interface directly.
+//
+// The input is Moves slots of Positions*Colors+2 values: one for each move
+// played so far, oldest first, with the rest zero.  A slot is the guess one
+// hot encoded, position by position, then the correct and half correct pegs
+// of its result, each over Positions.
+//
+// The output is either one logit per code, in the order of
+// GameSize.CodeAt, or one logit per (position, color), position by position;
+// the first is softmaxed over every code, and the second over each position's
+// colors, with the Beam most probable codes offered as choices.
+type Network struct {
+	Size   mm.GameSize
+	Moves  int
+	Beam   int
+	Layers []Layer
+}
+
+// Layer computes Activation(Weights x + Bias).  Weights has a row per output.
+type Layer struct {
+	Weights    [][]float64
+	Bias       []float64
+	Activation string
+}
+
+// Activations known to Layer; the empty string is the identity
+const (
+	Linear  = ""
+	ReLU    = "relu"
+	Tanh    = "tanh"
+	Sigmoid = "sigmoid"
+)
+
+// Load reads a network in JSON and checks its layers fit together
+func Load(r io.Reader) (*Network, error) {
+	n := &Network{}
+	if err := json.NewDecoder(r).Decode(n); err != nil {
+		return nil, err
+	}
+	if err := n.validate(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// LoadFile reads a network from a JSON file
+func LoadFile(path string) (*Network, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Save writes the network in JSON
+func (n *Network) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(n)
+}
+
+func (n *Network) inputs() int {
+	return n.Moves * (n.Size.Positions*int(n.Size.Colors) + 2)
+}
+
+func (n *Network) validate() error {
+	if n.Size.Positions < 1 || n.Size.Colors < 1 || n.Moves < 1 {
+		return fmt.Errorf("network has no board size or moves")
+	}
+	if len(n.Layers) == 0 {
+		return fmt.Errorf("network has no layers")
+	}
+	width := n.inputs()
+	for i, l := range n.Layers {
+		if len(l.Bias) != len(l.Weights) {
+			return fmt.Errorf("layer %d has %d weight rows but %d biases", i, len(l.Weights), len(l.Bias))
+		}
+		for _, row := range l.Weights {
+			if len(row) != width {
+				return fmt.Errorf("layer %d takes %d inputs, expected %d", i, len(row), width)
+			}
+		}
+		switch l.Activation {
+		case Linear, ReLU, Tanh, Sigmoid:
+		default:
+			return fmt.Errorf("layer %d has unknown activation %q", i, l.Activation)
+		}
+		width = len(l.Weights)
+	}
+	if width != n.Size.NumCodes() && width != n.Size.Positions*int(n.Size.Colors) {
+		return fmt.Errorf("network has %d outputs, expected %d codes or %d (position, color) pairs", width, n.Size.NumCodes(), n.Size.Positions*int(n.Size.Colors))
+	}
+	return nil
+}
+
+// encodes history as the network's input
+func (n *Network) features(history mm.History) []float64 {
+	slot := n.Size.Positions*int(n.Size.Colors) + 2
+	x := make([]float64, n.inputs())
+	for i, m := range history {
+		if i >= n.Moves {
+			break
+		}
+		base := i * slot
+		for p, c := range m.Guess {
+			x[base+p*int(n.Size.Colors)+int(c)] = 1
+		}
+		x[base+slot-2] = float64(m.Result.Correct) / float64(n.Size.Positions)
+		x[base+slot-1] = float64(m.Result.HalfCorrect) / float64(n.Size.Positions)
+	}
+	return x
+}
+
+func (n *Network) forward(x []float64) []float64 {
+	for _, l := range n.Layers {
+		y := make([]float64, len(l.Weights))
+		for i, row := range l.Weights {
+			sum := l.Bias[i]
+			for j, w := range row {
+				sum += w * x[j]
+			}
+			switch l.Activation {
+			case ReLU:
+				sum = math.Max(0, sum)
+			case Tanh:
+				sum = math.Tanh(sum)
+			case Sigmoid:
+				sum = 1 / (1 + math.Exp(-sum))
+			}
+			y[i] = sum
+		}
+		x = y
+	}
+	return x
+}
+
+func softmax(x []float64) []float64 {
+	max := math.Inf(-1)
+	for _, v := range x {
+		max = math.Max(max, v)
+	}
+	out := make([]float64, len(x))
+	total := 0.0
+	for i, v := range x {
+		out[i] = math.Exp(v - max)
+		total += out[i]
+	}
+	for i := range out {
+		out[i] /= total
+	}
+	return out
+}
+
+func (n *Network) Choices(size mm.GameSize, history mm.History) ([]Choice, error) {
+	if size != n.Size {
+		return nil, fmt.Errorf("network plays %v, not %v", n.Size, size)
+	}
+	logits := n.forward(n.features(history))
+
+	if len(logits) == size.NumCodes() {
+		choices := make([]Choice, len(logits))
+		for i, p := range softmax(logits) {
+			choices[i] = Choice{Guess: size.CodeAt(i), P: p}
+		}
+		return choices, nil
+	}
+
+	colors := int(size.Colors)
+	probs := make([][]float64, size.Positions)
+	for p := range probs {
+		probs[p] = softmax(logits[p*colors : (p+1)*colors])
+	}
+	beam := n.Beam
+	if beam < 1 {
+		beam = 10
+	}
+	return beamSearch(probs, beam), nil
+}
+
+// beamSearch returns the k most probable codes given independent color
+// probabilities for each position
+func beamSearch(probs [][]float64, k int) []Choice {
+	beam := []Choice{{Guess: mm.Code{}, P: 1}}
+	for _, dist := range probs {
+		next := &choiceHeap{}
+		for _, c := range beam {
+			for color, p := range dist {
+				heap.Push(next, Choice{Guess: append(append(mm.Code{}, c.Guess...), byte(color)), P: c.P * p})
+				if next.Len() > k {
+					heap.Pop(next)
+				}
+			}
+		}
+		beam = append([]Choice{}, *next...)
+	}
+	return beam
+}
+
+// a min heap of choices, so the least probable is popped first
+type choiceHeap []Choice
+
+func (h choiceHeap) Len() int            { return len(h) }
+func (h choiceHeap) Less(i, j int) bool  { return h[i].P < h[j].P }
+func (h choiceHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *choiceHeap) Push(x interface{}) { *h = append(*h, x.(Choice)) }
+func (h *choiceHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
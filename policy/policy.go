@@ -0,0 +1,94 @@
+// Package policy plays mastermind with learned policies, eg codebreakers
+// trained by reinforcement learning elsewhere, so they can be benchmarked
+// against the classical solvers with the same harness.
+package policy
+
+import (
+	"fmt"
+	"sort"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/metaheuristic"
+)
+
+// Choice is a guess and the probability a policy gives it
+type Choice struct {
+	Guess mm.Code
+	P     float64
+}
+
+// Policy maps the moves played so far to a distribution over the next
+// guess.  The choices needn't cover every code, nor sum to one.
+type Policy interface {
+	Choices(size mm.GameSize, history mm.History) ([]Choice, error)
+}
+
+// PolicyFunc lets a plain function be a Policy
+type PolicyFunc func(size mm.GameSize, history mm.History) ([]Choice, error)
+
+func (f PolicyFunc) Choices(size mm.GameSize, history mm.History) ([]Choice, error) {
+	return f(size, history)
+}
+
+// Solver plays a policy against a codemaker
+type Solver struct {
+	// Base's Seed makes sampling reproducible
+	metaheuristic.Base
+	policy Policy
+
+	// Sample draws each guess from the policy's distribution; otherwise
+	// the most probable guess is played
+	Sample bool
+}
+
+func NewSolver(cm mm.Codemaker, p Policy) *Solver {
+	return &Solver{Base: metaheuristic.NewBase(cm), policy: p}
+}
+
+func (s *Solver) Solve() (mm.Code, error) {
+	return s.Run(s.next)
+}
+
+// next picks the policy's guess, passing over codes already guessed, since
+// they've been shown not to be the secret
+func (s *Solver) next() (mm.Code, error) {
+	choices, err := s.policy.Choices(s.Size, s.History)
+	if err != nil {
+		return nil, err
+	}
+
+	guessed := map[string]bool{}
+	for _, m := range s.History {
+		guessed[m.Guess.String()] = true
+	}
+	fresh := make([]Choice, 0, len(choices))
+	total := 0.0
+	for _, c := range choices {
+		if !guessed[c.Guess.String()] && c.P > 0 {
+			fresh = append(fresh, c)
+			total += c.P
+		}
+	}
+	if len(fresh) == 0 {
+		return nil, fmt.Errorf("policy has no new guess after %v", s.History)
+	}
+
+	if s.Sample {
+		roll := s.Rand.Float64() * total
+		for _, c := range fresh {
+			if roll -= c.P; roll < 0 {
+				return c.Guess, nil
+			}
+		}
+		return fresh[len(fresh)-1].Guess, nil
+	}
+
+	// the most probable, the least code of those tied
+	sort.SliceStable(fresh, func(i, j int) bool {
+		if fresh[i].P != fresh[j].P {
+			return fresh[i].P > fresh[j].P
+		}
+		return fresh[i].Guess.String() < fresh[j].Guess.String()
+	})
+	return fresh[0].Guess, nil
+}
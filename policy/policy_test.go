@@ -0,0 +1,112 @@
+package policy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// plays a random code consistent with the game so far
+var consistent = PolicyFunc(func(size mm.GameSize, history mm.History) ([]Choice, error) {
+	choices := []Choice{}
+	for _, c := range size.AllCodes() {
+		if history.Consistent(c, size.Colors) {
+			choices = append(choices, Choice{Guess: c, P: 1})
+		}
+	}
+	return choices, nil
+})
+
+func TestSolver(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		game := mm.NewCustomGame(4, 6)
+		solver := NewSolver(game, consistent)
+		solver.Sample = true
+		solver.Seed(int64(i))
+
+		winner, err := solver.Solve()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !game.IsWinner(winner) {
+			t.Errorf("Solution incorrect! Got %s", winner)
+		}
+	}
+}
+
+// a network with no hidden layers, whose biases alone pick a code
+func biased(size mm.GameSize, outputs int, favorite func(i int) bool) *Network {
+	n := &Network{Size: size, Moves: 3}
+	l := Layer{}
+	for i := 0; i < outputs; i++ {
+		l.Weights = append(l.Weights, make([]float64, n.inputs()))
+		bias := 0.0
+		if favorite(i) {
+			bias = 5
+		}
+		l.Bias = append(l.Bias, bias)
+	}
+	n.Layers = []Layer{l}
+	return n
+}
+
+func TestNetwork(t *testing.T) {
+	size := mm.GameSize{Positions: 4, Colors: 6}
+	favorite := mm.Code{5, 4, 3, 2}
+
+	// one output per (position, color)
+	perPosition := biased(size, 24, func(i int) bool { return favorite[i/6] == byte(i%6) })
+	// one output per code
+	perCode := biased(size, size.NumCodes(), func(i int) bool { return i == favorite.Index(size.Colors) })
+
+	for _, n := range []*Network{perPosition, perCode} {
+		buf := &bytes.Buffer{}
+		if err := n.Save(buf); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := Load(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		game := mm.NewCustomGameWithSecret(4, 6, favorite)
+		solver := NewSolver(game, loaded)
+		if _, err := solver.Solve(); err != nil {
+			t.Fatal(err)
+		}
+		if solver.TurnsTaken != 1 {
+			t.Errorf("network with %d outputs should guess %v first, took %d moves", len(n.Layers[0].Bias), favorite, solver.TurnsTaken)
+		}
+	}
+
+	choices, _ := perPosition.Choices(size, nil)
+	if len(choices) != 10 {
+		t.Errorf("expected a beam of 10 choices, got %d", len(choices))
+	}
+	if _, err := perPosition.Choices(mm.GameSize{Positions: 5, Colors: 8}, nil); err == nil {
+		t.Error("expected an error playing a network on the wrong board")
+	}
+}
+
+func TestLoadValidates(t *testing.T) {
+	bad := []string{
+		`{"Size": {"Positions": 4, "Colors": 6}, "Moves": 1, "Layers": []}`,
+		`{"Size": {"Positions": 2, "Colors": 2}, "Moves": 1, "Layers": [{"Weights": [[0, 0, 0, 0, 0, 0]], "Bias": [0]}]}`,
+		`{"Size": {"Positions": 2, "Colors": 2}, "Moves": 1, "Layers": [{"Weights": [[0, 0]], "Bias": [0]}]}`,
+		`{"Size": {"Positions": 1, "Colors": 2}, "Moves": 1, "Layers": [{"Weights": [[0, 0, 0, 0], [0, 0, 0, 0]], "Bias": [0, 0], "Activation": "swish"}]}`,
+	}
+	for _, s := range bad {
+		if _, err := Load(strings.NewReader(s)); err == nil {
+			t.Errorf("loaded invalid network %s", s)
+		}
+	}
+
+	good := `{"Size": {"Positions": 1, "Colors": 2}, "Moves": 1, "Layers": [
+		{"Weights": [[1, 0, 0, 0], [0, 1, 0, 0], [0, 0, 1, 1]], "Bias": [0, 0, 0], "Activation": "relu"},
+		{"Weights": [[1, 0, 1], [0, 1, 1]], "Bias": [0, 0]}]}`
+	if _, err := Load(strings.NewReader(good)); err != nil {
+		t.Error(err)
+	}
+}
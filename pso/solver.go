@@ -0,0 +1,119 @@
+// Package pso solves mastermind by particle swarm optimization, for boards
+// too big for exhaustive search.  Each particle is a code flying through the
+// discrete code space.  Its velocity is the set of changes it makes each
+// step: every position may take the color the particle's own best code has
+// there, the color the swarm's best code has there, or a random color, and
+// two positions may swap.  Codes are rated with the genetic solver's fitness
+// function, and the first consistent code the swarm finds is guessed.
+package pso
+
+import (
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/genetic"
+	"github.com/ianmcmahon/mastermind/internal/metaheuristic"
+)
+
+type particle struct {
+	code        mm.Code
+	fitness     float64
+	best        mm.Code
+	bestFitness float64
+}
+
+type Solver struct {
+	metaheuristic.Base
+	swarm []*particle
+
+	// Fitness rates codes; lower is fitter
+	Fitness genetic.FitnessFunction
+	// Particles is the size of the swarm
+	Particles int
+	// Cognitive is the chance a position takes the color of the particle's
+	// own best code, and Social the chance it takes the swarm's best instead
+	Cognitive float64
+	Social    float64
+	// Perturbation is the chance a position is recolored at random, and the
+	// chance a particle swaps two positions, each step
+	Perturbation float64
+	// Iterations is the most steps the swarm takes looking for a consistent
+	// code each move.  If none is found, the swarm's best code is guessed.
+	Iterations int
+}
+
+func NewSolver(cm mm.Codemaker) *Solver {
+	return &Solver{
+		Base:         metaheuristic.NewBase(cm),
+		Fitness:      genetic.Berghman{A: 2, B: 2},
+		Particles:    50,
+		Cognitive:    0.2,
+		Social:       0.2,
+		Perturbation: 0.1,
+		Iterations:   2000,
+	}
+}
+
+func (s *Solver) Solve() (mm.Code, error) {
+	s.swarm = make([]*particle, s.Particles)
+	for i := range s.swarm {
+		s.swarm[i] = &particle{code: s.RandomCode()}
+	}
+	return s.Search(s.fly)
+}
+
+// fly moves the swarm until a particle lands on a consistent code, returning
+// it, or the swarm's best code if the iterations run out.  The swarm keeps
+// its positions from move to move, but every best is forgotten, since each
+// result changes every fitness.
+func (s *Solver) fly() mm.Code {
+	var best *particle
+	for _, p := range s.swarm {
+		p.fitness = s.Fitness.Evaluate(p.code, s.History, s.Size)
+		p.best = append(mm.Code{}, p.code...)
+		p.bestFitness = p.fitness
+		if best == nil || p.fitness < best.bestFitness {
+			best = p
+		}
+	}
+	// the swarm's best, copied as particles move off it
+	gbest := append(mm.Code{}, best.best...)
+	gbestFitness := best.bestFitness
+
+	for i := 0; i < s.Iterations; i++ {
+		for _, p := range s.swarm {
+			if s.Consistent(p.code) {
+				return append(mm.Code{}, p.code...)
+			}
+
+			s.move(p, gbest)
+			p.fitness = s.Fitness.Evaluate(p.code, s.History, s.Size)
+			if p.fitness < p.bestFitness {
+				copy(p.best, p.code)
+				p.bestFitness = p.fitness
+			}
+			if p.fitness < gbestFitness {
+				copy(gbest, p.code)
+				gbestFitness = p.fitness
+			}
+		}
+	}
+	return gbest
+}
+
+// move applies one step of velocity to p, pulled towards gbest
+func (s *Solver) move(p *particle, gbest mm.Code) {
+	for i := range p.code {
+		roll := s.Rand.Float64()
+		switch {
+		case roll < s.Cognitive:
+			p.code[i] = p.best[i]
+		case roll < s.Cognitive+s.Social:
+			p.code[i] = gbest[i]
+		case roll < s.Cognitive+s.Social+s.Perturbation:
+			p.code[i] = byte(s.Rand.Intn(int(s.Size.Colors)))
+		}
+	}
+	if s.Rand.Float64() < s.Perturbation {
+		i, j := s.Rand.Intn(len(p.code)), s.Rand.Intn(len(p.code))
+		p.code[i], p.code[j] = p.code[j], p.code[i]
+	}
+}
@@ -0,0 +1,26 @@
+package pso
+
+import (
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestMoveFollowsBests(t *testing.T) {
+	solver := NewSolver(mm.NewCustomGame(6, 8))
+	solver.Seed(1)
+	solver.Cognitive, solver.Social, solver.Perturbation = 0, 1, 0
+
+	p := &particle{code: mm.Code{0, 0, 0, 0, 0, 0}, best: mm.Code{1, 1, 1, 1, 1, 1}}
+	gbest := mm.Code{2, 3, 4, 5, 6, 7}
+	solver.move(p, gbest)
+	if p.code.String() != gbest.String() {
+		t.Errorf("with Social 1, the particle should land on %v, got %v", gbest, p.code)
+	}
+
+	solver.Cognitive, solver.Social = 1, 0
+	solver.move(p, gbest)
+	if p.code.String() != p.best.String() {
+		t.Errorf("with Cognitive 1, the particle should land on %v, got %v", p.best, p.code)
+	}
+}
@@ -0,0 +1,150 @@
+// Package ratings keeps Elo ratings for players and solvers, from the
+// results of their head-to-head games.
+package ratings
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ianmcmahon/mastermind/storage"
+)
+
+const (
+	// Initial is the rating of a newcomer
+	Initial = 1500.0
+	// DefaultK is how far a single game moves a rating
+	DefaultK = 32.0
+)
+
+// Expected is the score a player rated a expects against one rated b, from
+// 0 for a certain loss to 1 for a certain win
+func Expected(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// Ratings are the ratings of everyone who's played.  They're kept in Store
+// if it's set, and only in memory otherwise.
+type Ratings struct {
+	Store storage.Store
+	K     float64
+
+	mu      sync.Mutex
+	ratings map[string]*storage.Rating
+}
+
+func New(store storage.Store) *Ratings {
+	return &Ratings{Store: store, K: DefaultK, ratings: map[string]*storage.Rating{}}
+}
+
+// get is called with mu held
+func (r *Ratings) get(name string) (*storage.Rating, error) {
+	if rating, ok := r.ratings[name]; ok {
+		return rating, nil
+	}
+	rating := &storage.Rating{Name: name, Rating: Initial}
+	if r.Store != nil {
+		stored, err := r.Store.Rating(name)
+		switch {
+		case err == nil:
+			rating = stored
+		case err != storage.ErrNotFound:
+			return nil, err
+		}
+	}
+	r.ratings[name] = rating
+	return rating, nil
+}
+
+// Get is name's rating; a newcomer's is Initial
+func (r *Ratings) Get(name string) (storage.Rating, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rating, err := r.get(name)
+	if err != nil {
+		return storage.Rating{}, err
+	}
+	return *rating, nil
+}
+
+// Record rates a game between a and b in which a scored score: 1 for a win,
+// 0.5 for a draw and 0 for a loss.  It returns their new ratings.
+func (r *Ratings) Record(a, b string, score float64) (storage.Rating, storage.Rating, error) {
+	if a == b {
+		return storage.Rating{}, storage.Rating{}, fmt.Errorf("%s can't play themselves", a)
+	}
+	if score < 0 || score > 1 {
+		return storage.Rating{}, storage.Rating{}, fmt.Errorf("score %v isn't between 0 and 1", score)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ra, err := r.get(a)
+	if err != nil {
+		return storage.Rating{}, storage.Rating{}, err
+	}
+	rb, err := r.get(b)
+	if err != nil {
+		return storage.Rating{}, storage.Rating{}, err
+	}
+
+	ea := Expected(ra.Rating, rb.Rating)
+	ra.Rating += r.K * (score - ea)
+	rb.Rating += r.K * ((1 - score) - (1 - ea))
+
+	now := time.Now()
+	for _, p := range []struct {
+		rating *storage.Rating
+		score  float64
+	}{{ra, score}, {rb, 1 - score}} {
+		p.rating.Games++
+		switch {
+		case p.score > 0.5:
+			p.rating.Wins++
+		case p.score < 0.5:
+			p.rating.Losses++
+		default:
+			p.rating.Draws++
+		}
+		p.rating.Updated = now
+		if r.Store != nil {
+			if err := r.Store.SaveRating(p.rating); err != nil {
+				return storage.Rating{}, storage.Rating{}, err
+			}
+		}
+	}
+	return *ra, *rb, nil
+}
+
+// Leaderboard is the limit highest rated, best first
+func (r *Ratings) Leaderboard(limit int) ([]storage.Rating, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Store != nil {
+		stored, err := r.Store.Ratings(limit)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]storage.Rating, len(stored))
+		for i, s := range stored {
+			out[i] = *s
+		}
+		return out, nil
+	}
+
+	out := []storage.Rating{}
+	for _, rating := range r.ratings {
+		out = append(out, *rating)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Rating != out[j].Rating {
+			return out[i].Rating > out[j].Rating
+		}
+		return out[i].Name < out[j].Name
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
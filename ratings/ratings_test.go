@@ -0,0 +1,80 @@
+package ratings
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ianmcmahon/mastermind/storage"
+)
+
+func near(x, y float64) bool {
+	return math.Abs(x-y) < 0.01
+}
+
+func TestExpected(t *testing.T) {
+	if e := Expected(1500, 1500); !near(e, 0.5) {
+		t.Errorf("expected even odds, got %v", e)
+	}
+	// 400 points is ten to one
+	if e := Expected(1900, 1500); !near(e, 10.0/11) {
+		t.Errorf("expected 10:1 odds, got %v", e)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	r := New(nil)
+	a, b, err := r.Record("alice", "bob", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(a.Rating, 1516) || !near(b.Rating, 1484) || a.Wins != 1 || b.Losses != 1 {
+		t.Errorf("expected 16 points to change hands, got %+v %+v", a, b)
+	}
+	a, c, _ := r.Record("alice", "carol", 0.5)
+	if a.Rating >= 1516 || c.Rating <= 1500 || a.Draws != 1 || a.Games != 2 {
+		t.Errorf("expected the favorite to lose points drawing, got %+v %+v", a, c)
+	}
+	if _, _, err := r.Record("alice", "alice", 1); err == nil {
+		t.Errorf("expected playing oneself refused")
+	}
+	if _, _, err := r.Record("alice", "bob", 2); err == nil {
+		t.Errorf("expected a score of 2 refused")
+	}
+
+	board, _ := r.Leaderboard(2)
+	if len(board) != 2 || board[0].Name != "alice" || board[1].Name != "carol" {
+		t.Errorf("expected alice then carol, got %+v", board)
+	}
+}
+
+func TestStored(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ratings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := storage.OpenSQLite(filepath.Join(dir, "mm.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	New(store).Record("knuth", "genetic", 1)
+
+	// a fresh start picks the ratings up from the store
+	r := New(store)
+	k, err := r.Get("knuth")
+	if err != nil || !near(k.Rating, 1516) || k.Games != 1 {
+		t.Errorf("expected knuth's win kept, got %+v %v", k, err)
+	}
+	if n, _ := r.Get("newcomer"); n.Rating != Initial || n.Games != 0 {
+		t.Errorf("expected a newcomer unrated, got %+v", n)
+	}
+	board, err := r.Leaderboard(10)
+	if err != nil || len(board) != 2 || board[0].Name != "knuth" {
+		t.Errorf("expected knuth first, got %+v %v", board, err)
+	}
+}
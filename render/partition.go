@@ -0,0 +1,101 @@
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+// barWidth is the length of the biggest bucket's bar in a table
+const barWidth = 40
+
+// examples is the first n codes of a bucket, with an ellipsis for the rest
+func examples(codes mm.CodeSlice, n int) string {
+	s := []string{}
+	for i, c := range codes {
+		if i == n {
+			s = append(s, "…")
+			break
+		}
+		s = append(s, c.String())
+	}
+	return strings.Join(s, " ")
+}
+
+func share(n, total int) float64 {
+	return 100 * float64(n) / float64(total)
+}
+
+// PartitionTable writes a partition as a text table: a row for each result
+// with the codes giving it, their share and a bar, and up to n of them
+func PartitionTable(w io.Writer, p *solver.Partition, n int) error {
+	if _, err := fmt.Fprintf(w, "guess %v splits %d codes into %d, the largest %d, scoring %.4g\n\n",
+		p.Guess, p.Total, len(p.Buckets), p.Largest(), p.Score); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%-6s %6s %6s  %-*s  %s\n", "result", "codes", "share", barWidth, "", "examples")
+	largest := p.Largest()
+	for _, b := range p.Buckets {
+		bar := strings.Repeat("#", (len(b.Codes)*barWidth+largest-1)/largest)
+		if _, err := fmt.Fprintf(w, "%-6v %6d %5.1f%%  %-*s  %s\n",
+			b.Result, len(b.Codes), share(len(b.Codes), p.Total), barWidth, bar, examples(b.Codes, n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var partitionHTML = template.Must(template.New("partition").Parse(`<table class="partition">
+<caption>{{.Guess}} splits {{.Total}} codes into {{len .Buckets}}, the largest {{.Largest}}</caption>
+<thead><tr><th>Result</th><th>Codes</th><th>Share</th><th>Examples</th></tr></thead>
+<tbody>
+{{range .Buckets}}<tr><td>{{.Result}}</td><td>{{.Count}}</td><td><meter value="{{.Count}}" max="{{$.Total}}"></meter> {{printf "%.1f" .Share}}%</td><td>{{.Examples}}</td></tr>
+{{end}}</tbody>
+</table>
+`))
+
+// PartitionHTML writes a partition as an HTML table, as PartitionTable does
+func PartitionHTML(w io.Writer, p *solver.Partition, n int) error {
+	type row struct {
+		Result   mm.Result
+		Count    int
+		Share    float64
+		Examples string
+	}
+	data := struct {
+		Guess   mm.Code
+		Total   int
+		Largest int
+		Buckets []row
+	}{Guess: p.Guess, Total: p.Total, Largest: p.Largest()}
+	for _, b := range p.Buckets {
+		data.Buckets = append(data.Buckets, row{
+			Result: b.Result, Count: len(b.Codes), Share: share(len(b.Codes), p.Total), Examples: examples(b.Codes, n),
+		})
+	}
+	return partitionHTML.Execute(w, data)
+}
+
+// PartitionDOT writes a partition as a Graphviz graph: the guess, with an
+// edge for each result to the codes giving it, up to n of them
+func PartitionDOT(w io.Writer, p *solver.Partition, n int) error {
+	var b strings.Builder
+	b.WriteString("digraph partition {\n\trankdir=LR;\n\tnode [shape=box, fontname=monospace];\n")
+	fmt.Fprintf(&b, "\tguess [label=%q, style=bold];\n", fmt.Sprintf("%v\n%d codes", p.Guess, p.Total))
+	for i, bucket := range p.Buckets {
+		label := fmt.Sprintf("%d codes\n%s", len(bucket.Codes), examples(bucket.Codes, n))
+		if len(bucket.Codes) == 1 {
+			label = bucket.Codes[0].String()
+		}
+		fmt.Fprintf(&b, "\tr%d [label=%q];\n", i, label)
+		fmt.Fprintf(&b, "\tguess -> r%d [label=%q, penwidth=%.2f];\n",
+			i, bucket.Result.String(), 1+4*float64(len(bucket.Codes))/float64(p.Largest()))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
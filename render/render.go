@@ -0,0 +1,214 @@
+// Package render draws a game's board as an SVG or PNG image: a row for
+// each guess with its pegs, and beside them the black and white pins it
+// scored.
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Palette is the pegs' colors, in order: red, green, yellow, blue, magenta,
+// cyan, white, orange, pink, brown, gray and purple, as the terminal draws
+// them.  Colors past these are drawn in Unknown.
+var Palette = []color.RGBA{
+	{0xd6, 0x27, 0x28, 0xff},
+	{0x2c, 0xa0, 0x2c, 0xff},
+	{0xf2, 0xd0, 0x2b, 0xff},
+	{0x1f, 0x5f, 0xc8, 0xff},
+	{0xc2, 0x3b, 0xc2, 0xff},
+	{0x2b, 0xc4, 0xd6, 0xff},
+	{0xf4, 0xf4, 0xf4, 0xff},
+	{0xff, 0x8c, 0x1a, 0xff},
+	{0xff, 0x8f, 0xd0, 0xff},
+	{0x8c, 0x56, 0x2c, 0xff},
+	{0x8a, 0x8a, 0x8a, 0xff},
+	{0x7a, 0x3c, 0xe0, 0xff},
+}
+
+var (
+	Unknown = color.RGBA{0x40, 0x40, 0x40, 0xff}
+	board   = color.RGBA{0x6b, 0x44, 0x23, 0xff}
+	hole    = color.RGBA{0x2e, 0x1c, 0x0e, 0xff}
+	rim     = color.RGBA{0x1a, 0x1a, 0x1a, 0xff}
+	black   = color.RGBA{0x10, 0x10, 0x10, 0xff}
+	white   = color.RGBA{0xfa, 0xfa, 0xfa, 0xff}
+)
+
+// Board is what's drawn
+type Board struct {
+	Size  mm.GameSize
+	Moves mm.History
+	// Rows, if more than the moves, leaves empty rows below them, as on a
+	// physical board
+	Rows int
+	// Secret, if set, is shown in a row of its own below the guesses
+	Secret mm.Code
+}
+
+// the layout, in pixels
+const (
+	margin = 12
+	cell   = 32
+	pegR   = 12
+	holeR  = 4
+	pinGap = 12
+	pinR   = 4.5
+)
+
+// shape is a filled rectangle, or a filled circle with an outline if it's
+// given one; the images are made of nothing else
+type shape struct {
+	circle     bool
+	x, y, w, h float64 // a rectangle
+	cx, cy, r  float64 // a circle
+	fill       color.RGBA
+	stroke     color.RGBA
+}
+
+func pegColor(c byte) color.RGBA {
+	if int(c) < len(Palette) {
+		return Palette[c]
+	}
+	return Unknown
+}
+
+// layout is the board's size in pixels, and the shapes it's drawn with
+func (b Board) layout() (int, int, []shape) {
+	positions := b.Size.Positions
+	pinCols := (positions + 1) / 2
+	rows := b.Rows
+	if rows < len(b.Moves) {
+		rows = len(b.Moves)
+	}
+	if rows < 1 {
+		rows = 1
+	}
+
+	width := 2*margin + positions*cell + 8 + pinCols*pinGap
+	height := 2*margin + rows*cell
+	if b.Secret != nil {
+		height += cell + 4
+	}
+	shapes := []shape{{x: 0, y: 0, w: float64(width), h: float64(height), fill: board}}
+
+	pegs := func(y float64, code mm.Code) {
+		for i := 0; i < positions; i++ {
+			cx := float64(margin + i*cell + cell/2)
+			if code == nil {
+				shapes = append(shapes, shape{circle: true, cx: cx, cy: y, r: holeR, fill: hole})
+			} else {
+				shapes = append(shapes, shape{circle: true, cx: cx, cy: y, r: pegR, fill: pegColor(code[i]), stroke: rim})
+			}
+		}
+	}
+
+	for row := 0; row < rows; row++ {
+		y := float64(margin + row*cell + cell/2)
+		if row >= len(b.Moves) {
+			pegs(y, nil)
+			continue
+		}
+		m := b.Moves[row]
+		pegs(y, m.Guess)
+		for k := 0; k < positions; k++ {
+			cx := float64(margin+positions*cell+8+k%pinCols*pinGap) + pinGap/2
+			cy := y - pinGap/2 + float64(k/pinCols*pinGap)
+			switch {
+			case k < m.Result.Correct:
+				shapes = append(shapes, shape{circle: true, cx: cx, cy: cy, r: pinR, fill: black, stroke: rim})
+			case k < m.Result.Correct+m.Result.HalfCorrect:
+				shapes = append(shapes, shape{circle: true, cx: cx, cy: cy, r: pinR, fill: white, stroke: rim})
+			default:
+				shapes = append(shapes, shape{circle: true, cx: cx, cy: cy, r: 2, fill: hole})
+			}
+		}
+	}
+
+	if b.Secret != nil {
+		y := float64(margin + rows*cell)
+		shapes = append(shapes, shape{x: margin, y: y + 1, w: float64(width - 2*margin), h: 2, fill: hole})
+		pegs(y+4+cell/2, b.Secret)
+	}
+	return width, height, shapes
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// SVG writes the board as an SVG image
+func SVG(w io.Writer, b Board) error {
+	width, height, shapes := b.layout()
+	if _, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, height, width, height); err != nil {
+		return err
+	}
+	for _, s := range shapes {
+		var err error
+		switch {
+		case !s.circle:
+			_, err = fmt.Fprintf(w, `<rect x="%g" y="%g" width="%g" height="%g" fill="%s"/>`+"\n", s.x, s.y, s.w, s.h, hexColor(s.fill))
+		case s.stroke.A > 0:
+			_, err = fmt.Fprintf(w, `<circle cx="%g" cy="%g" r="%g" fill="%s" stroke="%s" stroke-width="1.5"/>`+"\n",
+				s.cx, s.cy, s.r, hexColor(s.fill), hexColor(s.stroke))
+		default:
+			_, err = fmt.Fprintf(w, `<circle cx="%g" cy="%g" r="%g" fill="%s"/>`+"\n", s.cx, s.cy, s.r, hexColor(s.fill))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "</svg>")
+	return err
+}
+
+// Image draws the board
+func Image(b Board) *image.RGBA {
+	width, height, shapes := b.layout()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for _, s := range shapes {
+		switch {
+		case !s.circle:
+			for y := int(s.y); y < int(s.y+s.h); y++ {
+				for x := int(s.x); x < int(s.x+s.w); x++ {
+					img.SetRGBA(x, y, s.fill)
+				}
+			}
+		case s.stroke.A > 0:
+			disc(img, s.cx, s.cy, s.r+0.75, s.stroke)
+			disc(img, s.cx, s.cy, s.r-0.75, s.fill)
+		default:
+			disc(img, s.cx, s.cy, s.r, s.fill)
+		}
+	}
+	return img
+}
+
+// disc fills a circle, blending its edge into what's under it
+func disc(img *image.RGBA, cx, cy, r float64, c color.RGBA) {
+	for y := int(cy - r - 1); y <= int(cy+r+1); y++ {
+		for x := int(cx - r - 1); x <= int(cx+r+1); x++ {
+			// how much of the pixel the circle covers, roughly
+			d := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy)
+			cover := math.Max(0, math.Min(1, r+0.5-d))
+			if cover == 0 || !(image.Point{x, y}.In(img.Rect)) {
+				continue
+			}
+			under := img.RGBAAt(x, y)
+			blend := func(a, b uint8) uint8 { return uint8(float64(a)*(1-cover) + float64(b)*cover + 0.5) }
+			img.SetRGBA(x, y, color.RGBA{blend(under.R, c.R), blend(under.G, c.G), blend(under.B, c.B), 0xff})
+		}
+	}
+}
+
+// PNG writes the board as a PNG image
+func PNG(w io.Writer, b Board) error {
+	return png.Encode(w, Image(b))
+}
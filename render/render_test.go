@@ -0,0 +1,103 @@
+package render
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+func TestLayout(t *testing.T) {
+	b := Board{
+		Size: mm.GameSize{Positions: 4, Colors: 6},
+		Moves: mm.History{
+			{Guess: mm.Code{0, 0, 1, 1}, Result: mm.Result{Correct: 1, HalfCorrect: 2}},
+		},
+		Rows: 3,
+	}
+	width, height, shapes := b.layout()
+	if width != 2*margin+4*cell+8+2*pinGap || height != 2*margin+3*cell {
+		t.Errorf("unexpected size %dx%d", width, height)
+	}
+
+	count := map[color.RGBA]int{}
+	for _, s := range shapes {
+		count[s.fill]++
+	}
+	// two pegs of each color, then the empty holes of two rows and a pin
+	if count[Palette[0]] != 2 || count[Palette[1]] != 2 {
+		t.Errorf("expected two red and two green pegs, got %v", count)
+	}
+	if count[black] != 1 || count[white] != 2 || count[hole] != 9 {
+		t.Errorf("expected 1 black pin, 2 white and 9 holes, got %v", count)
+	}
+
+	// colors past the palette are drawn all the same
+	b = Board{Size: mm.GameSize{Positions: 1, Colors: 20}, Secret: mm.Code{15}}
+	_, _, shapes = b.layout()
+	if last := shapes[len(shapes)-1]; last.fill != Unknown {
+		t.Errorf("expected an unknown color, got %v", last.fill)
+	}
+}
+
+func TestImages(t *testing.T) {
+	b := Board{
+		Size:   mm.GameSize{Positions: 4, Colors: 6},
+		Moves:  mm.History{{Guess: mm.Code{3, 2, 1, 0}, Result: mm.Result{Correct: 4}}},
+		Secret: mm.Code{3, 2, 1, 0},
+	}
+	var svg bytes.Buffer
+	if err := SVG(&svg, b); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(svg.String(), "<circle"); n != 12 {
+		t.Errorf("expected 8 pegs and 4 pins, got %d circles", n)
+	}
+
+	img := Image(b)
+	// the middle of the first peg is blue
+	if c := img.RGBAAt(margin+cell/2, margin+cell/2); c != Palette[3] {
+		t.Errorf("expected a blue peg, got %v", c)
+	}
+	if c := img.RGBAAt(1, 1); c != board {
+		t.Errorf("expected the board's color in the corner, got %v", c)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	game := &solver.Solver{Game: mm.NewCustomGame(4, 6)}
+	history := mm.History{{Guess: mm.Code{0, 0, 1, 1}, Result: mm.Result{Correct: 2, HalfCorrect: 1}}}
+	p, err := game.Partition(history, mm.Code{0, 1, 2, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var table, html, dot bytes.Buffer
+	if err := PartitionTable(&table, p, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := PartitionHTML(&html, p, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := PartitionDOT(&dot, p, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 3+len(p.Buckets) || !strings.HasPrefix(lines[0], "guess 0122 splits 32 codes") {
+		t.Errorf("unexpected table:\n%s", table.String())
+	}
+	// the largest bucket's bar is full
+	if !strings.Contains(table.String(), strings.Repeat("#", barWidth)) {
+		t.Errorf("expected a full bar:\n%s", table.String())
+	}
+	if n := strings.Count(html.String(), "<tr>"); n != 1+len(p.Buckets) {
+		t.Errorf("expected a row for each bucket, got %d:\n%s", n, html.String())
+	}
+	if n := strings.Count(dot.String(), "guess -> r"); n != len(p.Buckets) || !strings.HasPrefix(dot.String(), "digraph") {
+		t.Errorf("expected an edge for each bucket:\n%s", dot.String())
+	}
+}
@@ -0,0 +1,211 @@
+package mastermind
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Replay is a recorded game, as kept in a .mmr file.  The secret is
+// committed to when the game starts, so a replay can be shared before it's
+// revealed and checked once it is.
+//
+// The file is text, a record per line:
+//
+//	mmr 1
+//	size 4x6
+//	commit 5e8c…  the hex SHA-256 of the salt, a colon and the secret
+//	started 2026-10-17T12:00:00Z
+//	move 0011 1-1 2.5  each guess, its result and the seconds since the start
+//	salt 9f2a…     these two reveal the secret, and may be left out
+//	secret 0123
+type Replay struct {
+	Size       GameSize
+	Commitment string
+	Started    time.Time
+	Moves      []ReplayMove
+	// Salt and Secret are empty until revealed
+	Salt   string
+	Secret Code
+}
+
+// ReplayMove is a move, and when it was made
+type ReplayMove struct {
+	Move
+	At time.Duration
+}
+
+// Commit is the commitment to secret with salt
+func Commit(secret Code, salt string) string {
+	sum := sha256.Sum256([]byte(salt + ":" + secret.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewReplay starts recording a game with secret, committing to it with
+// salt, which should be random
+func NewReplay(size GameSize, secret Code, salt string, started time.Time) *Replay {
+	return &Replay{Size: size, Commitment: Commit(secret, salt), Started: started, Salt: salt, Secret: secret}
+}
+
+// Add records a move made at
+func (r *Replay) Add(m Move, at time.Time) {
+	r.Moves = append(r.Moves, ReplayMove{Move: m, At: at.Sub(r.Started)})
+}
+
+// History is the moves made
+func (r *Replay) History() History {
+	h := make(History, len(r.Moves))
+	for i, m := range r.Moves {
+		h[i] = m.Move
+	}
+	return h
+}
+
+// Solved reports whether the last move won
+func (r *Replay) Solved() bool {
+	return len(r.Moves) > 0 && r.Moves[len(r.Moves)-1].Result.Correct == r.Size.Positions
+}
+
+// Verify checks a revealed secret against the commitment, and that it
+// scores every move as recorded
+func (r *Replay) Verify() error {
+	if r.Secret == nil {
+		return fmt.Errorf("the secret isn't revealed")
+	}
+	if Commit(r.Secret, r.Salt) != r.Commitment {
+		return fmt.Errorf("secret %v doesn't match the commitment", r.Secret)
+	}
+	for i, m := range r.Moves {
+		result, err := CheckCode(m.Guess, r.Secret, r.Size.Colors)
+		if err != nil {
+			return fmt.Errorf("move %d: %v", i+1, err)
+		}
+		if result != m.Result {
+			return fmt.Errorf("move %d: %v scores %v, not %v", i+1, m.Guess, result, m.Result)
+		}
+	}
+	return nil
+}
+
+// Write writes the replay in .mmr form; with reveal, the salt and secret
+// are written too
+func (r *Replay) Write(w io.Writer, reveal bool) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "mmr 1\nsize %v\ncommit %s\nstarted %s\n", r.Size, r.Commitment, r.Started.UTC().Format(time.RFC3339Nano))
+	for _, m := range r.Moves {
+		fmt.Fprintf(b, "move %v %v %s\n", m.Guess, m.Result, strconv.FormatFloat(m.At.Seconds(), 'f', -1, 64))
+	}
+	if reveal && r.Secret != nil {
+		fmt.Fprintf(b, "salt %s\nsecret %v\n", r.Salt, r.Secret)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ReadReplay reads a replay in .mmr form
+func ReadReplay(rd io.Reader) (*Replay, error) {
+	r := &Replay{}
+	s := bufio.NewScanner(rd)
+	line := 0
+	for s.Scan() {
+		line++
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if line == 1 {
+			if len(fields) != 2 || fields[0] != "mmr" || fields[1] != "1" {
+				return nil, fmt.Errorf("not a version 1 replay")
+			}
+			continue
+		}
+		if err := r.parse(fields); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if line == 0 {
+		return nil, fmt.Errorf("not a version 1 replay")
+	}
+	if r.Size.Positions == 0 || r.Commitment == "" {
+		return nil, fmt.Errorf("replay has no size or commitment")
+	}
+	return r, nil
+}
+
+func (r *Replay) parse(fields []string) error {
+	arg := func(n int) error {
+		if len(fields) != n+1 {
+			return fmt.Errorf("%s takes %d fields", fields[0], n)
+		}
+		return nil
+	}
+	var err error
+	switch fields[0] {
+	case "size":
+		if err = arg(1); err == nil {
+			r.Size, err = ParseGameSize(fields[1])
+		}
+	case "commit":
+		if err = arg(1); err == nil {
+			r.Commitment = fields[1]
+		}
+	case "started":
+		if err = arg(1); err == nil {
+			r.Started, err = time.Parse(time.RFC3339Nano, fields[1])
+		}
+	case "salt":
+		if err = arg(1); err == nil {
+			r.Salt = fields[1]
+		}
+	case "secret":
+		if err = arg(1); err == nil {
+			r.Secret, err = r.code(fields[1])
+		}
+	case "move":
+		if err = arg(3); err != nil {
+			return err
+		}
+		var m ReplayMove
+		if m.Guess, err = r.code(fields[1]); err != nil {
+			return err
+		}
+		if n, _ := fmt.Sscanf(fields[2], "%d-%d", &m.Result.Correct, &m.Result.HalfCorrect); n != 2 {
+			return fmt.Errorf("result %q isn't of the form 1-2", fields[2])
+		}
+		seconds, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return err
+		}
+		m.At = time.Duration(seconds * float64(time.Second))
+		r.Moves = append(r.Moves, m)
+	default:
+		// unknown records are left for later versions
+	}
+	return err
+}
+
+// code reads a code written as digits
+func (r *Replay) code(s string) (Code, error) {
+	if r.Size.Positions == 0 {
+		return nil, fmt.Errorf("code before the size")
+	}
+	if len(s) != r.Size.Positions {
+		return nil, fmt.Errorf("code %q isn't %d pegs", s, r.Size.Positions)
+	}
+	c := make(Code, len(s))
+	for i, d := range s {
+		if d < '0' || int(d-'0') >= int(r.Size.Colors) {
+			return nil, fmt.Errorf("code %q has a color out of range", s)
+		}
+		c[i] = byte(d - '0')
+	}
+	return c, nil
+}
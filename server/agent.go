@@ -0,0 +1,210 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/ianmcmahon/mastermind/internal/websocket"
+)
+
+// The agent protocol lets programs in any language play matches in the
+// lobby, and so be rated alongside everyone else.  An agent connects over
+// TCP, sending and receiving one JSON object per line, or over the /agents
+// websocket, one object per message.
+//
+// The agent starts with a hello naming itself and, optionally, the board
+// size, 4x6 by default, and the API key it plays with, if the server takes
+// keys; over the websocket the key is the request's, as for the rest of
+// the API:
+//
+//	{"type": "hello", "name": "mybot", "size": "4x6", "key": "9f8e7d6c"}
+//
+// and is answered with a welcome once it has a seat in a match, with the
+// seat, 0 or 1, and the match's state.  From then on the server asks for
+// a move whenever it's the agent's turn, with the match's state:
+//
+//	{"type": "make", "match": {...}}   set this round's secret
+//	{"type": "break", "match": {...}}  guess at it; the round's moves so far are match.current.moves
+//
+// and the agent answers with {"type": "secret", "secret": "0123"} or
+// {"type": "guess", "guess": "0123"}.  A move which isn't allowed is
+// answered with {"type": "error", "message": "..."} and asked for again.
+// When the match is finished the agent is sent {"type": "over", "match":
+// {...}} and the connection is closed.
+
+// AgentMessage is a message of the agent protocol, either way
+type AgentMessage struct {
+	Type string `json:"type"`
+	// Name, Size and Key are the hello's
+	Name string `json:"name,omitempty"`
+	Size string `json:"size,omitempty"`
+	Key  string `json:"key,omitempty"`
+	// Seat is the welcome's
+	Seat    int         `json:"seat"`
+	Match   *MatchState `json:"match,omitempty"`
+	Secret  string      `json:"secret,omitempty"`
+	Guess   string      `json:"guess,omitempty"`
+	Message string      `json:"message,omitempty"`
+}
+
+// AgentConn carries the agent protocol's messages
+type AgentConn interface {
+	ReadJSON(v interface{}) error
+	WriteJSON(v interface{}) error
+}
+
+// lineConn is an AgentConn of a JSON object per line
+type lineConn struct {
+	dec *json.Decoder
+	enc *json.Encoder
+}
+
+func newLineConn(rw io.ReadWriter) *lineConn {
+	return &lineConn{dec: json.NewDecoder(rw), enc: json.NewEncoder(rw)}
+}
+
+func (c *lineConn) ReadJSON(v interface{}) error {
+	return c.dec.Decode(v)
+}
+
+func (c *lineConn) WriteJSON(v interface{}) error {
+	return c.enc.Encode(v)
+}
+
+// ServeAgents plays the matches of agents connecting to lis, until it
+// fails, checking their hellos' keys with Auth
+func (s *Server) ServeAgents(lis net.Listener) error {
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			if err := s.serveAgent(newLineConn(conn), s.Auth, conn.RemoteAddr().String()); err != nil && err != io.EOF {
+				log.Printf("agent %v: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+func (s *Server) agentSocket(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	if err := s.ServeAgent(conn); err != nil && err != io.EOF {
+		log.Printf("agent %v: %v", r.RemoteAddr, err)
+	}
+}
+
+// ServeAgent seats the agent on conn in a match, and plays it until the
+// match is finished.  The agent's key isn't checked: conn is one let in
+// already, as the /agents websocket is by the API's own check.
+func (s *Server) ServeAgent(conn AgentConn) error {
+	return s.serveAgent(conn, nil, "")
+}
+
+// serveAgent is ServeAgent, with the hello's key, from addr, checked by
+// auth
+func (s *Server) serveAgent(conn AgentConn, auth *Auth, addr string) error {
+	var hello AgentMessage
+	if err := conn.ReadJSON(&hello); err != nil {
+		return err
+	}
+	if hello.Type != "hello" || hello.Name == "" {
+		conn.WriteJSON(AgentMessage{Type: "error", Message: "say hello with a name first"})
+		return errors.New("no hello")
+	}
+	if err := auth.check(hello.Key, addr, s.Metrics); err != nil {
+		conn.WriteJSON(AgentMessage{Type: "error", Message: err.Error()})
+		return err
+	}
+	size, err := parseSize(hello.Size)
+	if err != nil {
+		conn.WriteJSON(AgentMessage{Type: "error", Message: err.Error()})
+		return err
+	}
+	m, seat, token, err := s.Lobby.Join(hello.Name, size)
+	if err != nil {
+		conn.WriteJSON(AgentMessage{Type: "error", Message: err.Error()})
+		return err
+	}
+
+	events, cancel := m.Subscribe()
+	defer cancel()
+	st := m.State()
+	if err := conn.WriteJSON(AgentMessage{Type: "welcome", Seat: seat, Match: &st}); err != nil {
+		return err
+	}
+
+	// the agent's messages are read as they come, until the match is over
+	// and the connection is closed
+	msgs := make(chan AgentMessage)
+	errs := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			var msg AgentMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case msgs <- msg:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	// asked is the last move asked for, so it's asked for once
+	asked := ""
+	for {
+		st := m.State()
+		var ask string
+		switch {
+		case st.Phase == Finished:
+			return conn.WriteJSON(AgentMessage{Type: "over", Seat: seat, Match: &st})
+		case st.Phase == Setting && st.Maker == seat:
+			ask = "make"
+		case st.Phase == Breaking && st.Maker != seat:
+			ask = "break"
+		}
+		if key := fmt.Sprint(ask, st.Round, len(st.Current.Moves)); ask != "" && key != asked {
+			asked = key
+			if err := conn.WriteJSON(AgentMessage{Type: ask, Seat: seat, Match: &st}); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-events:
+		case msg := <-msgs:
+			var err error
+			switch msg.Type {
+			case "secret":
+				err = m.SetSecret(token, msg.Secret)
+			case "guess":
+				_, err = m.Guess(token, msg.Guess)
+			default:
+				err = fmt.Errorf("unknown message %s", msg.Type)
+			}
+			if err != nil {
+				asked = ""
+				if err := conn.WriteJSON(AgentMessage{Type: "error", Seat: seat, Message: err.Error()}); err != nil {
+					return err
+				}
+			}
+		case err := <-errs:
+			return err
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// scriptedAgent plays with secret 0123, guessing it at once after one bad
+// guess, and returns the messages it was sent
+func scriptedAgent(t *testing.T, conn AgentConn, name string) []AgentMessage {
+	var got []AgentMessage
+	conn.WriteJSON(AgentMessage{Type: "hello", Name: name, Size: "4x6"})
+	bad := true
+	for {
+		var msg AgentMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Errorf("%s: %v", name, err)
+			return got
+		}
+		got = append(got, msg)
+		switch msg.Type {
+		case "make":
+			conn.WriteJSON(AgentMessage{Type: "secret", Secret: "0123"})
+		case "break":
+			if bad {
+				bad = false
+				conn.WriteJSON(AgentMessage{Type: "guess", Guess: "99"})
+			} else {
+				conn.WriteJSON(AgentMessage{Type: "guess", Guess: "0123"})
+			}
+		case "over":
+			return got
+		}
+	}
+}
+
+func TestAgents(t *testing.T) {
+	s := NewServer()
+	results := make(chan []AgentMessage, 2)
+	for _, name := range []string{"ann", "bob"} {
+		server, client := net.Pipe()
+		go func() {
+			defer server.Close()
+			if err := s.ServeAgent(newLineConn(server)); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func(name string) { results <- scriptedAgent(t, newLineConn(client), name) }(name)
+	}
+
+	for i := 0; i < 2; i++ {
+		msgs := <-results
+		if len(msgs) == 0 || msgs[0].Type != "welcome" {
+			t.Fatalf("expected a welcome first, got %+v", msgs)
+		}
+		seat := msgs[0].Seat
+		count := map[string]int{}
+		for _, m := range msgs {
+			count[m.Type]++
+		}
+		// a round making and one breaking, asked twice for the bad guess
+		if count["make"] != 1 || count["break"] != 2 || count["error"] != 1 {
+			t.Errorf("seat %d: unexpected messages %v", seat, count)
+		}
+		over := msgs[len(msgs)-1]
+		if over.Type != "over" || over.Match.Phase != Finished || over.Match.Scores != [2]int{1, 1} {
+			t.Errorf("seat %d: expected a finished draw, got %+v", seat, over.Match)
+		}
+	}
+	if r, _ := s.Lobby.Ratings.Get("ann"); r.Games != 1 || r.Draws != 1 {
+		t.Errorf("expected the match rated, got %+v", r)
+	}
+}
+
+func TestAgentHello(t *testing.T) {
+	server, client := net.Pipe()
+	errs := make(chan error)
+	go func() { errs <- NewServer().ServeAgent(newLineConn(server)) }()
+
+	conn := newLineConn(client)
+	go conn.WriteJSON(AgentMessage{Type: "guess", Guess: "0123"})
+	var msg AgentMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "error" {
+		t.Errorf("expected an error, got %+v %v", msg, err)
+	}
+	if err := <-errs; err == nil {
+		t.Error("expected the agent to be dropped")
+	}
+}
+
+func TestAgentKey(t *testing.T) {
+	s := NewServer()
+	s.Auth = NewAuth([]APIKey{{Name: "ian", Key: "abc", Limit: Limit{10, time.Minute}}}, Limit{})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	go s.ServeAgents(lis)
+
+	for key, expected := range map[string]string{"": "error", "nope": "error", "abc": "welcome"} {
+		c, err := net.Dial("tcp", lis.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn := newLineConn(c)
+		conn.WriteJSON(AgentMessage{Type: "hello", Name: "ann", Size: "4x6", Key: key})
+		var msg AgentMessage
+		if err := conn.ReadJSON(&msg); err != nil || msg.Type != expected {
+			t.Errorf("key %q: expected %s, got %+v %v", key, expected, msg, err)
+		}
+		c.Close()
+	}
+}
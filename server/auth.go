@@ -0,0 +1,221 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit is a rate of requests: N in any Per, with up to N at once.  The
+// zero Limit allows none.
+type Limit struct {
+	N   int
+	Per time.Duration
+}
+
+// ParseLimit reads a limit written as a number of requests per second,
+// minute or hour, eg 10/s, 600/m or 5000/h
+func ParseLimit(s string) (Limit, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return Limit{}, fmt.Errorf("bad limit %q: it's requests per s, m or h, eg 10/s", s)
+	}
+	n, err := strconv.Atoi(parts[0])
+	if err != nil || n < 1 {
+		return Limit{}, fmt.Errorf("bad limit %q: it's requests per s, m or h, eg 10/s", s)
+	}
+	per, ok := map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour}[parts[1]]
+	if !ok {
+		return Limit{}, fmt.Errorf("bad limit %q: it's requests per s, m or h, eg 10/s", s)
+	}
+	return Limit{N: n, Per: per}, nil
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.N, map[time.Duration]string{time.Second: "s", time.Minute: "m", time.Hour: "h"}[l.Per])
+}
+
+// APIKey is a key callers may send, in the X-API-Key header or as the
+// api_key query parameter of a websocket, and the limit on its requests
+type APIKey struct {
+	Name  string
+	Key   string
+	Limit Limit
+}
+
+// ReadKeys reads API keys, one a line as its name, the key and its limit,
+// eg "ian 9f8e7d6c 10/s".  Blank lines and lines starting with # are
+// skipped.
+func ReadKeys(r io.Reader) ([]APIKey, error) {
+	var keys []APIKey
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected a name, key and limit", n)
+		}
+		limit, err := ParseLimit(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		keys = append(keys, APIKey{Name: fields[0], Key: fields[1], Limit: limit})
+	}
+	return keys, scanner.Err()
+}
+
+var (
+	ErrNoKey      = errors.New("this server takes an API key, in the X-API-Key header")
+	ErrUnknownKey = errors.New("unknown API key")
+)
+
+// Auth checks the API keys requests are made with, and limits the rate of
+// each key's requests.  Requests without a key are limited by the address
+// they come from to Anonymous, or refused if it's zero.  gRPC calls, by
+// UnaryInterceptor, and agents connecting over TCP are checked against the
+// same keys and limits.  A nil *Auth lets every request through.
+type Auth struct {
+	Anonymous Limit
+
+	mu      sync.Mutex
+	keys    map[string]APIKey
+	buckets map[string]*bucket
+	swept   time.Time
+	now     func() time.Time
+}
+
+func NewAuth(keys []APIKey, anonymous Limit) *Auth {
+	a := &Auth{Anonymous: anonymous, keys: map[string]APIKey{}, buckets: map[string]*bucket{}, now: time.Now}
+	for _, k := range keys {
+		a.keys[k.Key] = k
+	}
+	return a
+}
+
+// bucket holds the requests a caller may still make at once, refilled
+// over time up to its limit
+type bucket struct {
+	limit  Limit
+	tokens float64
+	at     time.Time
+}
+
+// take takes a request from the bucket if there's one, or says how long
+// until there is
+func (b *bucket) take(now time.Time) (bool, time.Duration) {
+	rate := float64(b.limit.N) / b.limit.Per.Seconds()
+	b.tokens = math.Min(float64(b.limit.N), b.tokens+now.Sub(b.at).Seconds()*rate)
+	b.at = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
+
+// full is whether the bucket would have filled back up by now, so it
+// needn't be kept
+func (b *bucket) full(now time.Time) bool {
+	return now.Sub(b.at) >= b.limit.Per
+}
+
+// apiKey is the key a request is sent with, if any
+func apiKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("api_key")
+}
+
+// allow checks a request may be served, writing the error if not
+func (a *Auth) allow(w http.ResponseWriter, r *http.Request, m *Metrics) bool {
+	err := a.check(apiKey(r), r.RemoteAddr, m)
+	var limited *limitedError
+	switch {
+	case err == nil:
+		return true
+	case errors.As(err, &limited):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(limited.wait.Seconds()))))
+		writeError(w, http.StatusTooManyRequests, err)
+	default:
+		writeError(w, http.StatusUnauthorized, err)
+	}
+	return false
+}
+
+// limitedError refuses a caller over its limit, for wait
+type limitedError struct {
+	name  string
+	limit Limit
+	wait  time.Duration
+}
+
+func (e *limitedError) Error() string {
+	return fmt.Sprintf("%s is limited to %v", e.name, e.limit)
+}
+
+// check checks a request made with key, which may be empty, from addr may
+// be served, whether it came over HTTP, gRPC or the agent protocol: it's
+// ErrNoKey, ErrUnknownKey or a *limitedError if not
+func (a *Auth) check(key, addr string, m *Metrics) error {
+	if a == nil {
+		return nil
+	}
+	var name string
+	var limit Limit
+	if key != "" {
+		k, ok := a.keys[key]
+		if !ok {
+			m.refused("unknown_key")
+			return ErrUnknownKey
+		}
+		name, limit = "key "+k.Name, k.Limit
+	} else {
+		if a.Anonymous.N == 0 {
+			m.refused("no_key")
+			return ErrNoKey
+		}
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		name, limit = "address "+host, a.Anonymous
+	}
+
+	a.mu.Lock()
+	now := a.now()
+	if now.Sub(a.swept) >= time.Minute {
+		// forget the callers who've been quiet long enough to be back
+		// to their full allowance
+		for name, b := range a.buckets {
+			if b.full(now) {
+				delete(a.buckets, name)
+			}
+		}
+		a.swept = now
+	}
+	b, ok := a.buckets[name]
+	if !ok {
+		b = &bucket{limit: limit, tokens: float64(limit.N), at: now}
+		a.buckets[name] = b
+	}
+	ok, wait := b.take(now)
+	a.mu.Unlock()
+
+	if !ok {
+		m.refused("rate_limited")
+		return &limitedError{name: name, limit: limit, wait: wait}
+	}
+	return nil
+}
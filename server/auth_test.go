@@ -0,0 +1,119 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	for in, expected := range map[string]Limit{"10/s": {10, time.Second}, "600/m": {600, time.Minute}, "1/h": {1, time.Hour}} {
+		if l, err := ParseLimit(in); err != nil || l != expected || l.String() != in {
+			t.Errorf("%q parsed as %v (%v), expected %v", in, l, err, expected)
+		}
+	}
+	for _, in := range []string{"", "10", "0/s", "-1/s", "ten/s", "10/d", "10/s/s"} {
+		if _, err := ParseLimit(in); err == nil {
+			t.Errorf("parsed bad limit %q", in)
+		}
+	}
+}
+
+func TestReadKeys(t *testing.T) {
+	keys, err := ReadKeys(strings.NewReader("# keys\nian abc 10/s\n\n  bot def 5/m\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0] != (APIKey{Name: "ian", Key: "abc", Limit: Limit{10, time.Second}}) || keys[1].Name != "bot" {
+		t.Errorf("expected ian's and bot's keys, got %+v", keys)
+	}
+	for _, bad := range []string{"ian abc", "ian abc 10/s extra", "ian abc fast"} {
+		if _, err := ReadKeys(strings.NewReader(bad)); err == nil {
+			t.Errorf("read bad keys %q", bad)
+		}
+	}
+}
+
+func TestAuth(t *testing.T) {
+	s := NewServer()
+	s.Auth = NewAuth([]APIKey{{Name: "ian", Key: "abc", Limit: Limit{2, time.Minute}}}, Limit{})
+	now := time.Unix(1700000000, 0)
+	s.Auth.now = func() time.Time { return now }
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	get := func(header, query string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/ratings"+query, nil)
+		if header != "" {
+			req.Header.Set("X-API-Key", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	if resp := get("", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a request without a key refused, got %d", resp.StatusCode)
+	}
+	if resp := get("nope", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected an unknown key refused, got %d", resp.StatusCode)
+	}
+	// the metrics and API document are open to all
+	if resp, err := http.Get(srv.URL + "/openapi.json"); err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the API document without a key, got %v %v", resp, err)
+	}
+
+	// two a minute, in the header or the query
+	if resp := get("abc", ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the key taken, got %d", resp.StatusCode)
+	}
+	if resp := get("", "?api_key=abc"); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the key taken from the query, got %d", resp.StatusCode)
+	}
+	resp := get("abc", "")
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "30" {
+		t.Errorf("expected the third request limited for 30s, got %d after %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+	s.Auth.mu.Lock()
+	now = now.Add(30 * time.Second)
+	s.Auth.mu.Unlock()
+	if resp := get("abc", ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected a request allowed again after 30s, got %d", resp.StatusCode)
+	}
+}
+
+func TestAuthAnonymous(t *testing.T) {
+	s := NewServer()
+	s.Auth = NewAuth(nil, Limit{1, time.Hour})
+	now := time.Unix(1700000000, 0)
+	s.Auth.now = func() time.Time { return now }
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	for i, expected := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		resp, err := http.Get(srv.URL + "/ratings")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Errorf("request %d: expected %d, got %d", i+1, expected, resp.StatusCode)
+		}
+	}
+
+	// callers are forgotten once they're back to their full allowance
+	s.Auth.mu.Lock()
+	now = now.Add(2 * time.Hour)
+	s.Auth.mu.Unlock()
+	http.Get(srv.URL + "/ratings")
+	s.Auth.mu.Lock()
+	defer s.Auth.mu.Unlock()
+	if n := len(s.Auth.buckets); n != 1 {
+		t.Errorf("expected the one caller kept, got %d", n)
+	}
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// NewGRPCServer serves the games over gRPC, as package api describes
+func NewGRPCServer(games *GameManager, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(append(api.ServerOptions(), opts...)...)
+	api.Register(s, &grpcService{games: games})
+	return s
+}
+
+// UnaryInterceptor checks the API keys of gRPC calls, sent as x-api-key
+// metadata, and limits their rates, sharing the limits of HTTP requests.
+// Pass it to NewGRPCServer with grpc.ChainUnaryInterceptor.
+func (a *Auth) UnaryInterceptor(m *Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var key, addr string
+		md, _ := metadata.FromIncomingContext(ctx)
+		if keys := md.Get("x-api-key"); len(keys) > 0 {
+			key = keys[0]
+		}
+		if p, ok := peer.FromContext(ctx); ok {
+			addr = p.Addr.String()
+		}
+		err := a.check(key, addr, m)
+		var limited *limitedError
+		switch {
+		case err == nil:
+			return handler(ctx, req)
+		case errors.As(err, &limited):
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+}
+
+type grpcService struct {
+	games *GameManager
+}
+
+func grpcError(err error) error {
+	switch err {
+	case ErrNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case ErrSolved:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case ErrNoSession:
+		return status.Error(codes.Unauthenticated, err.Error())
+	case ErrNotOwner:
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// apiGame is the game as the gRPC api shows it
+func (g *Game) apiGame() *api.Game {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	out := &api.Game{ID: g.ID, Size: g.Size, Solved: g.solved}
+	for i, m := range g.history {
+		out.Moves = append(out.Moves, api.Move{Player: g.players[i], Guess: m.Guess, Result: m.Result})
+	}
+	if g.solved {
+		out.Secret = g.game.Secret()
+	}
+	return out
+}
+
+// session is the session a call is made in, from a bearer token in its
+// authorization metadata as over HTTP, or nil if it has none
+func (s *grpcService) session(ctx context.Context) (*Session, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, auth := range md.Get("authorization") {
+		if strings.HasPrefix(auth, "Bearer ") {
+			return s.games.Session(strings.TrimPrefix(auth, "Bearer "))
+		}
+	}
+	return nil, nil
+}
+
+// player finds the game a call is for, if its session may play it
+func (s *grpcService) player(ctx context.Context, id string) (*Game, error) {
+	sess, err := s.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	g, err := s.games.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !g.Allows(sess) {
+		return nil, ErrNotOwner
+	}
+	return g, nil
+}
+
+func (s *grpcService) NewGame(ctx context.Context, req *api.NewGameRequest) (*api.Game, error) {
+	sess, err := s.session(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	size := req.Size
+	if size == (mm.GameSize{}) {
+		size = mm.GameSize{Positions: 4, Colors: 6}
+	}
+	g, err := s.games.Create(size)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	g.own(sess)
+	return g.apiGame(), nil
+}
+
+func (s *grpcService) GetGame(ctx context.Context, req *api.GameRequest) (*api.Game, error) {
+	g, err := s.games.Get(req.ID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return g.apiGame(), nil
+}
+
+func (s *grpcService) Guess(ctx context.Context, req *api.GuessRequest) (*api.GuessResponse, error) {
+	g, err := s.player(ctx, req.ID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	m, solved, err := g.Guess(req.Player, req.Guess.String())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	move := api.Move{Player: m.Player, Guess: req.Guess, Result: mm.Result{Correct: m.Black, HalfCorrect: m.White}}
+	return &api.GuessResponse{Move: move, Solved: solved}, nil
+}
+
+func (s *grpcService) Hint(ctx context.Context, req *api.GameRequest) (*api.Hint, error) {
+	g, err := s.player(ctx, req.ID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	hint, err := g.Hint()
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &api.Hint{Guess: hint, Remaining: g.Candidates()}, nil
+}
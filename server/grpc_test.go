@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPC(t *testing.T) {
+	games := NewGameManager()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewGRPCServer(games)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	client, err := api.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	g, err := client.NewGame(ctx, mm.GameSize{Positions: 4, Colors: 6})
+	if err != nil || g.ID == "" || g.Size.Positions != 4 || g.Size.Colors != 6 {
+		t.Fatalf("expected a 4x6 game, got %+v %v", g, err)
+	}
+	game, _ := games.Get(g.ID)
+	secret := game.game.Secret()
+
+	hint, err := client.Hint(ctx, g.ID)
+	if err != nil || hint.Remaining != 1296 || len(hint.Guess) != 4 {
+		t.Errorf("expected an opening hint, got %+v %v", hint, err)
+	}
+
+	if _, err := client.Guess(ctx, g.ID, "ian", mm.Code{9, 9}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected a bad guess refused, got %v", err)
+	}
+	r, err := client.Guess(ctx, g.ID, "ian", secret)
+	if err != nil || !r.Solved || r.Move.Result.Correct != 4 {
+		t.Errorf("expected the secret to solve it, got %+v %v", r, err)
+	}
+	if _, err := client.Guess(ctx, g.ID, "ian", secret); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected guesses refused once solved, got %v", err)
+	}
+
+	g, err = client.GetGame(ctx, g.ID)
+	if err != nil || !g.Solved || g.Secret.String() != secret.String() || len(g.Moves) != 1 || g.Moves[0].Player != "ian" {
+		t.Errorf("expected the solved game, got %+v %v", g, err)
+	}
+	if _, err := client.GetGame(ctx, "nope"); status.Code(err) != codes.NotFound {
+		t.Errorf("expected no game, got %v", err)
+	}
+
+	// a game made in a session is only the session's to play
+	sess := games.NewSession()
+	owned := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+sess.Token)
+	if g, err = client.NewGame(owned, mm.GameSize{Positions: 4, Colors: 6}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Hint(ctx, g.ID); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected a hint refused outside the session, got %v", err)
+	}
+	if _, err := client.Hint(owned, g.ID); err != nil {
+		t.Errorf("expected a hint in the session, got %v", err)
+	}
+	bad := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer nope")
+	if _, err := client.Guess(bad, g.ID, "ian", secret); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected an unknown session refused, got %v", err)
+	}
+}
+
+func TestGRPCAuth(t *testing.T) {
+	games := NewGameManager()
+	auth := NewAuth([]APIKey{{Name: "ian", Key: "abc", Limit: Limit{1, time.Minute}}}, Limit{})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewGRPCServer(games, grpc.ChainUnaryInterceptor(auth.UnaryInterceptor(nil)))
+	go s.Serve(lis)
+	defer s.Stop()
+
+	client, err := api.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+	game, err := games.Create(mm.GameSize{Positions: 4, Colors: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Hint(ctx, game.ID); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected a hint without a key refused, got %v", err)
+	}
+	bad := metadata.AppendToOutgoingContext(ctx, "x-api-key", "nope")
+	if _, err := client.Hint(bad, game.ID); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected a hint with an unknown key refused, got %v", err)
+	}
+	keyed := metadata.AppendToOutgoingContext(ctx, "x-api-key", "abc")
+	if _, err := client.Hint(keyed, game.ID); err != nil {
+		t.Errorf("expected a hint with the key, got %v", err)
+	}
+	if _, err := client.Hint(keyed, game.ID); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the key's second hint a minute limited, got %v", err)
+	}
+}
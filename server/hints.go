@@ -0,0 +1,129 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+)
+
+// HintSizes are the sizes whose strategy trees are worked out the first
+// time a hint is asked for, and kept for answering the rest.  Hints for
+// other sizes are solved afresh each time, unless a tree was added for them.
+var HintSizes = []mm.GameSize{{Positions: 4, Colors: 6}, {Positions: 3, Colors: 6}, {Positions: 4, Colors: 4}}
+
+// Trees caches strategy trees by size
+type Trees struct {
+	mu    sync.Mutex
+	trees map[mm.GameSize]*treeEntry
+}
+
+type treeEntry struct {
+	once sync.Once
+	tree *solver.Tree
+	err  error
+}
+
+func NewTrees() *Trees {
+	return &Trees{trees: map[mm.GameSize]*treeEntry{}}
+}
+
+// Add caches a tree worked out before, eg one read from a file
+func (t *Trees) Add(tree *solver.Tree) error {
+	if tree.Feedback != mm.FullFeedback {
+		return fmt.Errorf("%v tree is for %v feedback", tree.Size, tree.Feedback)
+	}
+	e := &treeEntry{tree: tree}
+	e.once.Do(func() {})
+	t.mu.Lock()
+	t.trees[tree.Size] = e
+	t.mu.Unlock()
+	return nil
+}
+
+// Get returns the tree for size, working it out if it's one of HintSizes,
+// or nil if there's none
+func (t *Trees) Get(size mm.GameSize) (*solver.Tree, error) {
+	t.mu.Lock()
+	e, ok := t.trees[size]
+	if !ok {
+		for _, s := range HintSizes {
+			if s == size {
+				e = &treeEntry{}
+				t.trees[size] = e
+			}
+		}
+	}
+	t.mu.Unlock()
+	if e == nil {
+		return nil, nil
+	}
+	// other hints for the size wait while the first works it out
+	e.once.Do(func() {
+		game := &solver.Solver{Game: mm.NewCustomGame(size.Positions, size.Colors)}
+		e.tree, e.err = game.Tree()
+	})
+	return e.tree, e.err
+}
+
+// HintRequest is a game played anywhere, for a hint at its next guess;
+// the size is 4x6 if left out
+type HintRequest struct {
+	Size  string `json:"size,omitempty"`
+	Moves []Move `json:"moves"`
+}
+
+func (s *Server) statelessHint(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	var req HintRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	size, err := parseSize(req.Size)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.Games.supports(size); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	game := mm.NewCustomGame(size.Positions, size.Colors)
+	var history mm.History
+	for _, m := range req.Moves {
+		code, err := game.Code(m.Guess)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if m.Black < 0 || m.White < 0 || m.Black+m.White > size.Positions {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("%d black and %d white isn't a result of %d pegs", m.Black, m.White, size.Positions))
+			return
+		}
+		history = append(history, mm.Move{Guess: code, Result: mm.Result{Correct: m.Black, HalfCorrect: m.White}})
+	}
+
+	start := time.Now()
+	defer func() { s.Metrics.hinted(time.Since(start)) }()
+	sv := &solver.Solver{Game: game}
+	remaining := len(sv.Possible(history))
+	if remaining == 0 {
+		writeError(w, http.StatusConflict, fmt.Errorf("no code is consistent with %v", history))
+		return
+	}
+	// a game which has strayed from the tree's strategy is solved afresh
+	var hint mm.Code
+	if tree, err := s.Trees.Get(size); err == nil && tree != nil {
+		hint, _ = tree.Step(history)
+	}
+	if hint == nil {
+		if hint, err = sv.Step(history); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, HintResponse{Hint: hint.String(), Remaining: remaining})
+}
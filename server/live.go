@@ -0,0 +1,132 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ianmcmahon/mastermind/internal/websocket"
+)
+
+// a message from a live player
+type command struct {
+	// Type is guess or hint in a game, and secret or guess in a match
+	Type   string `json:"type"`
+	Guess  string `json:"guess,omitempty"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// live plays a game over a websocket, named by the player query parameter.
+// The player is sent the game's state, then an event for every guess made
+// by anyone, so opponents see each other's moves as they happen.  Players
+// send guess commands, and hint commands, which are answered first with a
+// candidates event once the remaining codes are counted, then with a hint
+// event once the solver has chosen.  Mistakes are answered with an error
+// event.  Only the session a game belongs to may send commands; anyone may
+// watch.
+func (s *Server) live(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	sess, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	g, ok := s.game(w, params)
+	if !ok {
+		return
+	}
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	player := r.URL.Query().Get("player")
+
+	events, cancel := g.Subscribe()
+	defer cancel()
+	st := g.State()
+	if err := conn.WriteJSON(Event{Type: "state", State: &st}); err != nil {
+		return
+	}
+	go func() {
+		for e := range events {
+			if conn.WriteJSON(e) != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		var cmd command
+		if err := conn.ReadJSON(&cmd); err != nil {
+			return
+		}
+		if !g.Allows(sess) {
+			conn.WriteJSON(Event{Type: "error", Message: ErrNotOwner.Error()})
+			continue
+		}
+		switch cmd.Type {
+		case "guess":
+			// the guess comes back as an event, like everyone else's
+			if _, _, err := g.Guess(player, cmd.Guess); err != nil {
+				conn.WriteJSON(Event{Type: "error", Message: err.Error()})
+			}
+		case "hint":
+			conn.WriteJSON(Event{Type: "candidates", Remaining: g.Candidates()})
+			hint, err := g.Hint()
+			if err != nil {
+				conn.WriteJSON(Event{Type: "error", Message: err.Error()})
+				continue
+			}
+			conn.WriteJSON(Event{Type: "hint", Hint: hint.String()})
+		default:
+			conn.WriteJSON(Event{Type: "error", Message: "unknown command " + cmd.Type})
+		}
+	}
+}
+
+// liveMatch follows a match over a websocket.  Everyone is sent a match
+// event with the match's state, then another after every change.  The
+// players, named by the token query parameter, may send secret and guess
+// commands; mistakes are answered with an error event.
+func (s *Server) liveMatch(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	m, ok := s.match(w, params)
+	if !ok {
+		return
+	}
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	token := r.URL.Query().Get("token")
+
+	events, cancel := m.Subscribe()
+	defer cancel()
+	st := m.State()
+	if err := conn.WriteJSON(Event{Type: "match", Match: &st}); err != nil {
+		return
+	}
+	go func() {
+		for e := range events {
+			if conn.WriteJSON(e) != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		var cmd command
+		if err := conn.ReadJSON(&cmd); err != nil {
+			return
+		}
+		switch cmd.Type {
+		case "secret":
+			err = m.SetSecret(token, cmd.Secret)
+		case "guess":
+			_, err = m.Guess(token, cmd.Guess)
+		default:
+			err = fmt.Errorf("unknown command %s", cmd.Type)
+		}
+		if err != nil {
+			conn.WriteJSON(Event{Type: "error", Message: err.Error()})
+		}
+	}
+}
@@ -0,0 +1,411 @@
+// Package server serves mastermind games over HTTP: a REST API to make
+// games, guess and ask for hints, and a websocket to play them live.
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/solver"
+	"github.com/ianmcmahon/mastermind/storage"
+)
+
+var (
+	ErrNotFound = errors.New("no such game")
+	ErrSolved   = errors.New("game is already solved")
+)
+
+// GameManager holds the games being played
+type GameManager struct {
+	// Store, if set, keeps every game, so they outlive the process.  Games
+	// not in memory are looked for there.
+	Store storage.Store
+	// Metrics, if set, counts the games and their guesses
+	Metrics *Metrics
+	// IdleTimeout is how long a game or session may go unused before
+	// Expire forgets it; DefaultIdleTimeout unless changed, and never if
+	// zero
+	IdleTimeout time.Duration
+	// MemoryLimit refuses boards whose hints solver.EstimateMemory reckons
+	// would take more bytes than this; DefaultMemoryLimit unless changed,
+	// and none if zero, though boards of more than MaxCodes codes are
+	// refused regardless
+	MemoryLimit uint64
+	// DailySalt is mixed into the daily puzzles' secrets, so they can't be
+	// worked out from the date as the CLI's can.  NewGameManager makes a
+	// random one, which changes the day's puzzle when the process restarts;
+	// servers sharing a Store, or restarting mid-day, should set the same.
+	DailySalt string
+
+	mu       sync.Mutex
+	games    map[string]*Game
+	sessions map[string]*Session
+}
+
+// DefaultMemoryLimit and DefaultIdleTimeout are the MemoryLimit and
+// IdleTimeout of a new GameManager
+const (
+	DefaultMemoryLimit = 1 << 30
+	DefaultIdleTimeout = 24 * time.Hour
+)
+
+func NewGameManager() *GameManager {
+	return &GameManager{
+		MemoryLimit: DefaultMemoryLimit,
+		IdleTimeout: DefaultIdleTimeout,
+		DailySalt:   randomSalt(),
+		games:       map[string]*Game{},
+		sessions:    map[string]*Session{},
+	}
+}
+
+// Create starts a game with a random secret
+func (m *GameManager) Create(size mm.GameSize) (*Game, error) {
+	if err := m.supports(size); err != nil {
+		return nil, err
+	}
+	game := mm.NewCustomGame(size.Positions, size.Colors)
+	game.Quiet = true
+	return m.add(game, 0), nil
+}
+
+// CreateDaily starts a game of the daily puzzle for day's date, in UTC so
+// that everyone gets the same one, salted with DailySalt.  Which puzzle a game is isn't kept in
+// the store.
+func (m *GameManager) CreateDaily(size mm.GameSize, day time.Time) (*Game, error) {
+	if err := m.supports(size); err != nil {
+		return nil, err
+	}
+	day = day.UTC()
+	game := mm.NewSaltedDailyGame(size, day, m.DailySalt)
+	game.Quiet = true
+	return m.add(game, mm.DailyNumber(day)), nil
+}
+
+// MaxCodes is the most codes a board may have to be played here, whatever
+// the MemoryLimit: hints enumerate every code, which on bigger boards takes
+// too long or more memory than there is
+const MaxCodes = 1 << 20
+
+// supports is nil if games of size may be played here, hints and all
+func (m *GameManager) supports(size mm.GameSize) error {
+	if size.Positions < 1 || size.Colors < 1 || size.Colors > 10 {
+		return fmt.Errorf("unsupported board size %v", size)
+	}
+	if tooManyCodes(size) {
+		return fmt.Errorf("board size %v has more than %d codes, too many to hint", size, MaxCodes)
+	}
+	if need := solver.EstimateMemory(size, "knuth"); m.MemoryLimit > 0 && need > m.MemoryLimit {
+		return fmt.Errorf("board size %v would take about %d MB to hint, over this server's limit of %d MB", size, need>>20, m.MemoryLimit>>20)
+	}
+	return nil
+}
+
+// tooManyCodes is whether size has more than MaxCodes codes, counted so
+// that no number of positions overflows
+func tooManyCodes(size mm.GameSize) bool {
+	n := 1
+	for i := 0; i < size.Positions; i++ {
+		if n *= int(size.Colors); n > MaxCodes {
+			return true
+		}
+	}
+	return false
+}
+
+// randomSalt is a DailySalt no one can guess
+func randomSalt() string {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+	return hex.EncodeToString(salt)
+}
+
+func (m *GameManager) add(game *mm.Game, daily int) *Game {
+	id := make([]byte, 8)
+	rand.Read(id)
+	g := &Game{
+		ID:      hex.EncodeToString(id),
+		Size:    game.GameSize(),
+		Created: time.Now(),
+		Daily:   daily,
+		active:  time.Now(),
+		game:    game,
+		store:   m.Store,
+		metrics: m.Metrics,
+	}
+	g.save()
+	m.Metrics.gameCreated()
+
+	m.mu.Lock()
+	m.games[g.ID] = g
+	m.mu.Unlock()
+	return g
+}
+
+// Get finds a game by id
+func (m *GameManager) Get(id string) (*Game, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if g, ok := m.games[id]; ok {
+		g.touch()
+		return g, nil
+	}
+	if m.Store == nil {
+		return nil, ErrNotFound
+	}
+
+	rec, err := m.Store.Game(id)
+	if err == storage.ErrNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	g := restore(rec, m.Store)
+	g.metrics = m.Metrics
+	m.games[g.ID] = g
+	return g, nil
+}
+
+// restore replays a stored game
+func restore(rec *storage.Game, store storage.Store) *Game {
+	game := mm.NewCustomGameWithSecret(rec.Size.Positions, rec.Size.Colors, rec.Secret)
+	game.Quiet = true
+	g := &Game{ID: rec.ID, Size: rec.Size, Created: rec.Started, game: game, solved: rec.Solved, store: store, owner: rec.Owner, active: time.Now()}
+	for _, m := range rec.Moves {
+		game.ScoredGuess(m.Guess)
+		g.history = append(g.history, mm.Move{Guess: m.Guess, Result: m.Result})
+		g.players = append(g.players, m.Player)
+		g.times = append(g.times, m.At)
+	}
+	return g
+}
+
+// Game is a game in play.  Anyone may guess at it, unless it belongs to a
+// session; everyone subscribed sees every guess.
+type Game struct {
+	ID      string
+	Size    mm.GameSize
+	Created time.Time
+	// Daily is the number of the daily puzzle the game is, if it is one
+	Daily int
+
+	mu      sync.Mutex
+	game    *mm.Game
+	history mm.History
+	players []string
+	times   []time.Time
+	solved  bool
+	hub     hub
+	store   storage.Store
+	metrics *Metrics
+	// owner is the hash of the token of the session the game belongs to,
+	// if any, as ownerOf makes it
+	owner string
+	// active is when the game was last used
+	active time.Time
+}
+
+// Move is a guess as the API shows it
+type Move struct {
+	Player string `json:"player,omitempty"`
+	Guess  string `json:"guess"`
+	Black  int    `json:"black"`
+	White  int    `json:"white"`
+}
+
+// State is a game as the API shows it.  The secret is only shown once it's
+// solved.
+type State struct {
+	ID     string `json:"id"`
+	Size   string `json:"size"`
+	Moves  []Move `json:"moves"`
+	Solved bool   `json:"solved"`
+	Secret string `json:"secret,omitempty"`
+	// Daily is the number of the daily puzzle, if it is one
+	Daily int `json:"daily,omitempty"`
+}
+
+// Event is a message pushed to live players
+type Event struct {
+	// Type is one of state, guess, candidates, hint, match or error
+	Type  string `json:"type"`
+	State *State `json:"state,omitempty"`
+	Move  *Move  `json:"move,omitempty"`
+	// Solved is set on the guess which solves the game
+	Solved    bool `json:"solved,omitempty"`
+	Remaining int  `json:"remaining,omitempty"`
+	// Match is the state of a match after every change to it
+	Match   *MatchState `json:"match,omitempty"`
+	Hint    string      `json:"hint,omitempty"`
+	Message string      `json:"message,omitempty"`
+}
+
+func (g *Game) move(i int) Move {
+	m := g.history[i]
+	return Move{Player: g.players[i], Guess: m.Guess.String(), Black: m.Result.Correct, White: m.Result.HalfCorrect}
+}
+
+// State is a snapshot of the game
+func (g *Game) State() State {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	st := State{ID: g.ID, Size: g.Size.String(), Moves: []Move{}, Solved: g.solved, Daily: g.Daily}
+	for i := range g.history {
+		st.Moves = append(st.Moves, g.move(i))
+	}
+	if g.solved {
+		st.Secret = g.game.Secret().String()
+	}
+	return st
+}
+
+// History is a copy of the moves made so far
+func (g *Game) History() mm.History {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append(mm.History{}, g.history...)
+}
+
+// Guess scores guess for player, and tells everyone watching
+func (g *Game) Guess(player, guess string) (Move, bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.solved {
+		return Move{}, false, ErrSolved
+	}
+	code, err := g.game.Code(guess)
+	if err != nil {
+		return Move{}, false, err
+	}
+	r, err := g.game.ScoredGuess(code)
+	if err != nil {
+		return Move{}, false, err
+	}
+
+	g.history = append(g.history, mm.Move{Guess: code, Result: r})
+	g.players = append(g.players, player)
+	g.times = append(g.times, time.Now())
+	g.active = time.Now()
+	g.solved = g.game.IsWin(r)
+	g.save()
+	g.metrics.guessed()
+	if g.solved {
+		g.metrics.gameSolved(len(g.history), time.Since(g.Created))
+	}
+
+	m := g.move(len(g.history) - 1)
+	g.hub.publish(Event{Type: "guess", Move: &m, Solved: g.solved})
+	return m, g.solved, nil
+}
+
+// save stores the game, if there's a store; called with mu held.  A game
+// which can't be saved carries on in memory.
+func (g *Game) save() {
+	if g.store == nil {
+		return
+	}
+	rec := &storage.Game{ID: g.ID, Size: g.Size, Secret: g.game.Secret(), Solved: g.solved, Started: g.Created, Owner: g.owner}
+	for i, m := range g.history {
+		rec.Moves = append(rec.Moves, storage.Move{Player: g.players[i], Guess: m.Guess, Result: m.Result, At: g.times[i]})
+	}
+	if g.solved {
+		rec.Finished = g.times[len(g.times)-1]
+	}
+	if err := g.store.SaveGame(rec); err != nil {
+		log.Printf("saving game %s: %v", g.ID, err)
+	}
+}
+
+// Secret is the game's secret, which the API only shows once it's solved
+func (g *Game) Secret() mm.Code {
+	return g.game.Secret()
+}
+
+// Share is the game written up for sharing, as mm.ShareGrid does, out of
+// ShareGuesses
+func (g *Game) Share() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	guesses := ShareGuesses
+	if len(g.history) > guesses {
+		guesses = len(g.history)
+	}
+	return mm.ShareGrid(g.Size, g.Daily, g.history, guesses)
+}
+
+// ShareGuesses is the number of guesses a shared game is scored out of.
+// Games here have no limit, but the usual board has ten rows.
+var ShareGuesses = 10
+
+// Candidates is the number of codes which could still be the secret
+func (g *Game) Candidates() int {
+	return len(g.solver().Possible(g.History()))
+}
+
+// Hint is the solver's choice of next guess
+func (g *Game) Hint() (mm.Code, error) {
+	start := time.Now()
+	defer func() { g.metrics.hinted(time.Since(start)) }()
+	g.touch()
+	return g.solver().Step(g.History())
+}
+
+// touch keeps the game from expiring
+func (g *Game) touch() {
+	g.mu.Lock()
+	g.active = time.Now()
+	g.mu.Unlock()
+}
+
+func (g *Game) solver() *solver.Solver {
+	return &solver.Solver{Game: mm.NewCustomGame(g.Size.Positions, g.Size.Colors)}
+}
+
+// Subscribe delivers the game's events until cancel is called
+func (g *Game) Subscribe() (events <-chan Event, cancel func()) {
+	return g.hub.subscribe()
+}
+
+// hub passes events on to subscribers.  A subscriber which falls too far
+// behind misses events.
+type hub struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]bool
+}
+
+func (h *hub) subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 16)
+	h.mu.Lock()
+	if h.subscribers == nil {
+		h.subscribers = map[chan Event]bool{}
+	}
+	h.subscribers[ch] = true
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.subscribers[ch] {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (h *hub) publish(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
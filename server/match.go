@@ -0,0 +1,314 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/ratings"
+)
+
+var (
+	ErrNotYourTurn = errors.New("not your turn")
+	ErrBadToken    = errors.New("no such player in this match")
+)
+
+const (
+	// the phases of a match
+	Waiting  = "waiting"
+	Setting  = "setting"
+	Breaking = "breaking"
+	Finished = "finished"
+)
+
+// Lobby pairs players up into matches of the same board size
+type Lobby struct {
+	// Rounds is the number of rounds in each match, so each player makes
+	// Rounds/2 secrets; Guesses is the guesses allowed each round
+	Rounds  int
+	Guesses int
+	// Ratings are updated with the result of every match finished
+	Ratings *ratings.Ratings
+	// Metrics, if set, counts the matches and their guesses
+	Metrics *Metrics
+
+	mu      sync.Mutex
+	matches map[string]*Match
+	// waiting are the matches still needing a second player, by size
+	waiting map[mm.GameSize][]*Match
+}
+
+func NewLobby() *Lobby {
+	return &Lobby{
+		Rounds:  2,
+		Guesses: 10,
+		Ratings: ratings.New(nil),
+		matches: map[string]*Match{},
+		waiting: map[mm.GameSize][]*Match{},
+	}
+}
+
+func newID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Join seats player in the oldest match waiting for a board of size, or
+// starts one if none is.  It returns the match, the player's seat, and the
+// token which proves it's them playing.
+func (l *Lobby) Join(player string, size mm.GameSize) (*Match, int, string, error) {
+	if size.Positions < 1 || size.Colors < 1 || size.Colors > 10 {
+		return nil, 0, "", fmt.Errorf("unsupported board size %v", size)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	token := newID()
+	if waiting := l.waiting[size]; len(waiting) > 0 {
+		m := waiting[0]
+		l.waiting[size] = waiting[1:]
+		m.join(player, token)
+		l.Metrics.match("started")
+		return m, 1, token, nil
+	}
+
+	m := &Match{
+		ID:      newID(),
+		Size:    size,
+		Rounds:  l.Rounds,
+		Guesses: l.Guesses,
+		phase:   Waiting,
+		ratings: l.Ratings,
+		metrics: l.Metrics,
+	}
+	m.players[0], m.tokens[0] = player, token
+	l.matches[m.ID] = m
+	l.waiting[size] = append(l.waiting[size], m)
+	return m, 0, token, nil
+}
+
+// Get finds a match by id
+func (l *Lobby) Get(id string) (*Match, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m, ok := l.matches[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return m, nil
+}
+
+// Match is a series of rounds between two players.  Each round one player
+// sets a secret and the other breaks it, and they swap for the next.  As in
+// the board game, the codemaker scores a point for each guess the breaker
+// makes, and an extra one if the breaker runs out of guesses.
+type Match struct {
+	ID      string
+	Size    mm.GameSize
+	Rounds  int
+	Guesses int
+
+	mu      sync.Mutex
+	players [2]string
+	tokens  [2]string
+	scores  [2]int
+	phase   string
+	// round counts from 0; seat round%2 makes the secret
+	round  int
+	game   *mm.Game
+	moves  []Move
+	played []RoundState
+	hub    hub
+
+	ratings *ratings.Ratings
+	metrics *Metrics
+}
+
+// RoundState is a round as the API shows it.  The secret is only shown
+// once the round is over.
+type RoundState struct {
+	Maker  int    `json:"maker"`
+	Moves  []Move `json:"moves"`
+	Solved bool   `json:"solved"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// MatchState is a match as the API shows it
+type MatchState struct {
+	ID      string       `json:"id"`
+	Size    string       `json:"size"`
+	Players [2]string    `json:"players"`
+	Scores  [2]int       `json:"scores"`
+	Phase   string       `json:"phase"`
+	Round   int          `json:"round"`
+	Rounds  int          `json:"rounds"`
+	Guesses int          `json:"guesses"`
+	Maker   int          `json:"maker"`
+	Current RoundState   `json:"current"`
+	Played  []RoundState `json:"played"`
+}
+
+func (m *Match) join(player, token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.players[1], m.tokens[1] = player, token
+	m.phase = Setting
+	m.publish()
+}
+
+// Seat finds which player token belongs to
+func (m *Match) Seat(token string) (int, error) {
+	for seat, t := range m.tokens {
+		if token != "" && t == token {
+			return seat, nil
+		}
+	}
+	return 0, ErrBadToken
+}
+
+func (m *Match) maker() int {
+	return m.round % 2
+}
+
+// State is a snapshot of the match
+func (m *Match) State() MatchState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state()
+}
+
+func (m *Match) state() MatchState {
+	return MatchState{
+		ID:      m.ID,
+		Size:    m.Size.String(),
+		Players: m.players,
+		Scores:  m.scores,
+		Phase:   m.phase,
+		Round:   m.round,
+		Rounds:  m.Rounds,
+		Guesses: m.Guesses,
+		Maker:   m.maker(),
+		Current: RoundState{Maker: m.maker(), Moves: append([]Move{}, m.moves...)},
+		Played:  append([]RoundState{}, m.played...),
+	}
+}
+
+// publish is called with mu held
+func (m *Match) publish() {
+	st := m.state()
+	m.hub.publish(Event{Type: "match", Match: &st})
+}
+
+// Subscribe delivers the match's state after every change, until cancel is
+// called
+func (m *Match) Subscribe() (events <-chan Event, cancel func()) {
+	return m.hub.subscribe()
+}
+
+// SetSecret is the codemaker setting this round's secret
+func (m *Match) SetSecret(token, secret string) error {
+	seat, err := m.Seat(token)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.phase != Setting || seat != m.maker() {
+		return ErrNotYourTurn
+	}
+
+	game := mm.NewCustomGame(m.Size.Positions, m.Size.Colors)
+	code, err := game.Code(secret)
+	if err != nil {
+		return err
+	}
+	m.game = mm.NewCustomGameWithSecret(m.Size.Positions, m.Size.Colors, code)
+	m.game.Quiet = true
+	m.moves = nil
+	m.phase = Breaking
+	m.publish()
+	return nil
+}
+
+// Guess is the codebreaker guessing at this round's secret
+func (m *Match) Guess(token, guess string) (Move, error) {
+	seat, err := m.Seat(token)
+	if err != nil {
+		return Move{}, err
+	}
+	// the result is rated once the lock is let go
+	finished := false
+	defer func() {
+		if finished {
+			m.rate()
+		}
+	}()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.phase != Breaking || seat == m.maker() {
+		return Move{}, ErrNotYourTurn
+	}
+
+	code, err := m.game.Code(guess)
+	if err != nil {
+		return Move{}, err
+	}
+	r, err := m.game.ScoredGuess(code)
+	if err != nil {
+		return Move{}, err
+	}
+	move := Move{Player: m.players[seat], Guess: code.String(), Black: r.Correct, White: r.HalfCorrect}
+	m.moves = append(m.moves, move)
+	m.metrics.guessed()
+
+	solved := m.game.IsWin(r)
+	if solved || len(m.moves) >= m.Guesses {
+		m.endRound(solved)
+		finished = m.phase == Finished
+	}
+	m.publish()
+	return move, nil
+}
+
+// endRound scores the round and swaps roles; called with mu held
+func (m *Match) endRound(solved bool) {
+	maker := m.maker()
+	m.scores[maker] += len(m.moves)
+	if !solved {
+		m.scores[maker]++
+	}
+	m.played = append(m.played, RoundState{Maker: maker, Moves: m.moves, Solved: solved, Secret: m.game.Secret().String()})
+
+	m.moves = nil
+	m.game = nil
+	m.round++
+	if m.round >= m.Rounds {
+		m.phase = Finished
+	} else {
+		m.phase = Setting
+	}
+}
+
+// rate records a finished match's result; the higher score wins
+func (m *Match) rate() {
+	m.metrics.match("finished")
+	if m.ratings == nil {
+		return
+	}
+	st := m.State()
+	score := 0.5
+	switch {
+	case st.Scores[0] > st.Scores[1]:
+		score = 1
+	case st.Scores[0] < st.Scores[1]:
+		score = 0
+	}
+	if _, _, err := m.ratings.Record(st.Players[0], st.Players[1], score); err != nil {
+		log.Printf("rating match %s: %v", m.ID, err)
+	}
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/websocket"
+)
+
+func TestLobby(t *testing.T) {
+	l := NewLobby()
+	size := mm.GameSize{Positions: 4, Colors: 6}
+	a, seatA, _, _ := l.Join("alice", size)
+	other, _, _, _ := l.Join("carol", mm.GameSize{Positions: 5, Colors: 8})
+	b, seatB, _, _ := l.Join("bob", size)
+	if a != b || other == a || seatA != 0 || seatB != 1 {
+		t.Errorf("expected alice and bob paired on 4x6")
+	}
+	if st := a.State(); st.Phase != Setting || st.Players != [2]string{"alice", "bob"} {
+		t.Errorf("expected the match to start, got %+v", st)
+	}
+	if st := other.State(); st.Phase != Waiting {
+		t.Errorf("expected carol to wait, got %+v", st)
+	}
+	if _, _, _, err := l.Join("dave", mm.GameSize{Positions: 4, Colors: 20}); err == nil {
+		t.Errorf("expected 20 colors refused")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	l := NewLobby()
+	size := mm.GameSize{Positions: 2, Colors: 3}
+	l.Guesses = 3
+	m, _, alice, _ := l.Join("alice", size)
+	_, _, bob, _ := l.Join("bob", size)
+
+	if _, err := m.Guess(bob, "00"); err != ErrNotYourTurn {
+		t.Errorf("expected no guessing before the secret, got %v", err)
+	}
+	if err := m.SetSecret(bob, "12"); err != ErrNotYourTurn {
+		t.Errorf("expected alice to make the first secret, got %v", err)
+	}
+	if err := m.SetSecret("nobody", "12"); err != ErrBadToken {
+		t.Errorf("expected a stranger refused, got %v", err)
+	}
+
+	// bob breaks alice's secret on his second guess: 2 points to alice
+	if err := m.SetSecret(alice, "12"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Guess(alice, "12"); err != ErrNotYourTurn {
+		t.Errorf("expected alice not to guess her own secret, got %v", err)
+	}
+	if st := m.State(); st.Current.Secret != "" {
+		t.Errorf("expected the secret hidden during the round")
+	}
+	m.Guess(bob, "21")
+	if move, err := m.Guess(bob, "12"); err != nil || move.Black != 2 {
+		t.Errorf("expected bob to win the round, got %+v %v", move, err)
+	}
+
+	// then alice runs out of guesses on bob's: 3+1 points to bob
+	if err := m.SetSecret(bob, "00"); err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range []string{"11", "22", "12"} {
+		m.Guess(alice, g)
+	}
+	st := m.State()
+	if st.Phase != Finished || st.Scores != [2]int{2, 4} || len(st.Played) != 2 {
+		t.Errorf("expected the match finished 2-4, got %+v", st)
+	}
+	if st.Played[0].Secret != "12" || !st.Played[0].Solved || st.Played[1].Solved || st.Played[1].Maker != 1 {
+		t.Errorf("expected the rounds recorded, got %+v", st.Played)
+	}
+	if err := m.SetSecret(alice, "01"); err != ErrNotYourTurn {
+		t.Errorf("expected nothing more once finished, got %v", err)
+	}
+}
+
+func TestMatchREST(t *testing.T) {
+	s := NewServer()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	type joined struct {
+		Match MatchState
+		Seat  int
+		Token string
+	}
+	var a, b joined
+	post(t, srv.URL+"/matches", map[string]string{"player": "alice", "size": "4x6"}, &a)
+	if code := post(t, srv.URL+"/matches", map[string]string{"player": "bob", "size": "4x6"}, &b); code != http.StatusCreated || b.Seat != 1 || b.Match.Phase != Setting {
+		t.Fatalf("expected bob seated, got %d %+v", code, b)
+	}
+
+	// bob follows along live
+	conn, err := websocket.Dial("ws" + strings.TrimPrefix(srv.URL, "http") + "/matches/" + b.Match.ID + "/live?token=" + b.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var e Event
+	conn.ReadJSON(&e)
+
+	url := srv.URL + "/matches/" + a.Match.ID
+	var st MatchState
+	if code := post(t, url+"/secret", map[string]string{"token": b.Token, "secret": "0123"}, &st); code != http.StatusConflict {
+		t.Errorf("expected bob refused the secret, got %d", code)
+	}
+	if code := post(t, url+"/secret", map[string]string{"token": "x", "secret": "0123"}, &st); code != http.StatusForbidden {
+		t.Errorf("expected a stranger refused, got %d", code)
+	}
+	if code := post(t, url+"/secret", map[string]string{"token": a.Token, "secret": "0123"}, &st); code != http.StatusOK || st.Phase != Breaking {
+		t.Errorf("expected the secret set, got %d %+v", code, st)
+	}
+	if err := conn.ReadJSON(&e); err != nil || e.Type != "match" || e.Match.Phase != Breaking {
+		t.Errorf("expected bob told the round began, got %+v %v", e, err)
+	}
+
+	conn.WriteJSON(command{Type: "guess", Guess: "0123"})
+	if err := conn.ReadJSON(&e); err != nil || e.Match.Round != 1 || e.Match.Scores[0] != 1 || e.Match.Played[0].Secret != "0123" {
+		t.Errorf("expected bob's win live, got %+v %v", e.Match, err)
+	}
+	if code := get(t, url, &st); code != http.StatusOK || st.Maker != 1 || st.Phase != Setting {
+		t.Errorf("expected the roles swapped, got %d %+v", code, st)
+	}
+}
+
+func TestMatchRatings(t *testing.T) {
+	s := NewServer()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	size := mm.GameSize{Positions: 2, Colors: 3}
+	m, _, alice, _ := s.Lobby.Join("alice", size)
+	_, _, bob, _ := s.Lobby.Join("bob", size)
+	// bob takes one guess on alice's, alice two on bob's
+	m.SetSecret(alice, "12")
+	m.Guess(bob, "12")
+	m.SetSecret(bob, "00")
+	m.Guess(alice, "01")
+	m.Guess(alice, "00")
+
+	var board []struct {
+		Name   string
+		Rating float64
+		Wins   int
+	}
+	if code := get(t, srv.URL+"/ratings", &board); code != http.StatusOK || len(board) != 2 || board[0].Name != "bob" || board[0].Rating <= 1500 {
+		t.Errorf("expected bob to lead, got %d %+v", code, board)
+	}
+	var rating struct {
+		Rating float64
+		Losses int
+	}
+	if code := get(t, srv.URL+"/ratings/alice", &rating); code != http.StatusOK || rating.Losses != 1 || rating.Rating >= 1500 {
+		t.Errorf("expected alice's loss, got %d %+v", code, rating)
+	}
+}
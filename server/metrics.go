@@ -0,0 +1,115 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics are the server's Prometheus metrics.  A nil *Metrics records
+// nothing.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	gamesCreated  prometheus.Counter
+	gamesSolved   prometheus.Counter
+	guesses       prometheus.Counter
+	guessesToWin  prometheus.Histogram
+	solveDuration prometheus.Histogram
+	hintLatency   prometheus.Histogram
+	matches       *prometheus.CounterVec
+	refusals      *prometheus.CounterVec
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		gamesCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mastermind_games_created_total",
+			Help: "Games started.",
+		}),
+		gamesSolved: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mastermind_games_solved_total",
+			Help: "Games whose secret was guessed.",
+		}),
+		guesses: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "mastermind_guesses_total",
+			Help: "Guesses scored, in games and matches.",
+		}),
+		guessesToWin: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "mastermind_game_guesses",
+			Help:    "Guesses taken to solve a game.",
+			Buckets: prometheus.LinearBuckets(1, 1, 12),
+		}),
+		solveDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "mastermind_game_solve_seconds",
+			Help:    "Time from starting a game to solving it.",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+		}),
+		hintLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "mastermind_hint_seconds",
+			Help:    "Time the solver took to choose a hint.",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 4, 10),
+		}),
+		matches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mastermind_matches_total",
+			Help: "Two player matches, by the phase reached: started or finished.",
+		}, []string{"phase"}),
+		refusals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mastermind_requests_refused_total",
+			Help: "Requests refused by API key checks, by reason: no_key, unknown_key or rate_limited.",
+		}, []string{"reason"}),
+	}
+	m.registry.MustRegister(
+		m.gamesCreated, m.gamesSolved, m.guesses, m.guessesToWin, m.solveDuration, m.hintLatency, m.matches, m.refusals,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler serves the metrics for scraping
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) gameCreated() {
+	if m != nil {
+		m.gamesCreated.Inc()
+	}
+}
+
+func (m *Metrics) guessed() {
+	if m != nil {
+		m.guesses.Inc()
+	}
+}
+
+func (m *Metrics) gameSolved(guesses int, took time.Duration) {
+	if m != nil {
+		m.gamesSolved.Inc()
+		m.guessesToWin.Observe(float64(guesses))
+		m.solveDuration.Observe(took.Seconds())
+	}
+}
+
+func (m *Metrics) hinted(took time.Duration) {
+	if m != nil {
+		m.hintLatency.Observe(took.Seconds())
+	}
+}
+
+func (m *Metrics) match(phase string) {
+	if m != nil {
+		m.matches.WithLabelValues(phase).Inc()
+	}
+}
+
+func (m *Metrics) refused(reason string) {
+	if m != nil {
+		m.refusals.WithLabelValues(reason).Inc()
+	}
+}
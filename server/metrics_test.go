@@ -0,0 +1,52 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestMetrics(t *testing.T) {
+	s := NewServer()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	game := mm.NewCustomGameWithSecret(4, 6, mm.Code{3, 1, 5, 2})
+	game.Quiet = true
+	g := s.Games.add(game, 0)
+	s.Games.Create(mm.GameSize{Positions: 4, Colors: 6})
+	g.Hint()
+	g.Guess("", "0011")
+	g.Guess("", "3152")
+
+	size := mm.GameSize{Positions: 2, Colors: 3}
+	m, _, alice, _ := s.Lobby.Join("alice", size)
+	_, _, bob, _ := s.Lobby.Join("bob", size)
+	m.SetSecret(alice, "12")
+	m.Guess(bob, "12")
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	for _, line := range []string{
+		"mastermind_games_created_total 2",
+		"mastermind_games_solved_total 1",
+		"mastermind_guesses_total 3",
+		`mastermind_game_guesses_bucket{le="2"} 1`,
+		"mastermind_game_solve_seconds_count 1",
+		"mastermind_hint_seconds_count 1",
+		`mastermind_matches_total{phase="started"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), line) {
+			t.Errorf("expected %q in the metrics", line)
+		}
+	}
+}
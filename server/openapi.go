@@ -0,0 +1,268 @@
+package server
+
+//go:generate go run ../cmd/openapigen -spec openapi.json -client ../client/client.go
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Spec is an OpenAPI 3.0 document, as much of one as the API needs
+type Spec struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem is a path's operations by lowercase method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	// Security lists the ways of authenticating the operation takes; an
+	// empty requirement means it may be left unauthenticated
+	Security []map[string][]string `json:"security,omitempty"`
+	// Websocket marks an operation which upgrades the connection
+	Websocket bool `json:"x-websocket,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+	// In and Name are where an apiKey scheme's key goes
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type Schema struct {
+	Ref        string     `json:"$ref,omitempty"`
+	Type       string     `json:"type,omitempty"`
+	Format     string     `json:"format,omitempty"`
+	Items      *Schema    `json:"items,omitempty"`
+	MinItems   *int       `json:"minItems,omitempty"`
+	MaxItems   *int       `json:"maxItems,omitempty"`
+	Properties Properties `json:"properties,omitempty"`
+	Required   []string   `json:"required,omitempty"`
+}
+
+// Property is a field of an object schema
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties are an object's fields, kept in the order of the Go struct's
+type Properties []Property
+
+func (ps Properties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, p := range ps {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(p.Name)
+		schema, err := json.Marshal(p.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(schema)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ComponentName is the name of a $ref'd schema
+func (s *Schema) ComponentName() string {
+	return strings.TrimPrefix(s.Ref, "#/components/schemas/")
+}
+
+// OpenAPI describes Routes, with the schemas of their bodies made from the
+// Go types
+func OpenAPI() *Spec {
+	spec := &Spec{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: "Mastermind", Version: "1.0"},
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]*SecurityScheme{
+				"session": {
+					Type: "http", Scheme: "bearer",
+					Description: "A token from POST /sessions; websockets may take it as the session query parameter instead",
+				},
+				"apiKey": {
+					Type: "apiKey", In: "header", Name: "X-API-Key",
+					Description: "A key the server's operator gave out, if it takes them, sent with every request; websockets may take it as the api_key query parameter instead",
+				},
+			},
+		},
+	}
+	schemas := spec.Components.Schemas
+	errorSchema := schemaOf(reflect.TypeOf(Error{}), schemas)
+
+	for _, rt := range Routes {
+		op := &Operation{
+			OperationID: rt.Operation,
+			Summary:     rt.Summary,
+			Responses:   map[string]*Response{},
+			Websocket:   rt.Websocket,
+		}
+		switch rt.Session {
+		case SessionOptional:
+			op.Security = []map[string][]string{{"session": {}}, {}}
+		case SessionRequired:
+			op.Security = []map[string][]string{{"session": {}}}
+		}
+		for _, part := range strings.Split(rt.Path, "/") {
+			if strings.HasPrefix(part, "{") {
+				op.Parameters = append(op.Parameters, Parameter{
+					Name: strings.Trim(part, "{}"), In: "path", Required: true,
+					Schema: &Schema{Type: "string"},
+				})
+			}
+		}
+		for _, q := range rt.Query {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name: q.Name, In: "query", Description: q.Description,
+				Schema: &Schema{Type: q.Type},
+			})
+		}
+		if rt.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  jsonContent(schemaOf(reflect.TypeOf(rt.Request), schemas)),
+			}
+		}
+		if rt.Websocket {
+			op.Responses["101"] = &Response{Description: "Switching Protocols"}
+		} else if rt.ContentType != "" {
+			op.Responses[strconv.Itoa(rt.Status)] = &Response{
+				Description: http.StatusText(rt.Status),
+				Content:     map[string]MediaType{rt.ContentType: {Schema: &Schema{Type: "string", Format: "binary"}}},
+			}
+		} else {
+			op.Responses[strconv.Itoa(rt.Status)] = &Response{
+				Description: http.StatusText(rt.Status),
+				Content:     jsonContent(schemaOf(reflect.TypeOf(rt.Response), schemas)),
+			}
+		}
+		op.Responses["default"] = &Response{Description: "Error", Content: jsonContent(errorSchema)}
+
+		item := spec.Paths[rt.Path]
+		if item == nil {
+			item = PathItem{}
+			spec.Paths[rt.Path] = item
+		}
+		item[strings.ToLower(rt.Method)] = op
+	}
+	return spec
+}
+
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+// schemaOf is the schema of t; named structs are added to schemas and
+// referred to
+func schemaOf(t reflect.Type, schemas map[string]*Schema) *Schema {
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Ptr:
+		return schemaOf(t.Elem(), schemas)
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), schemas)}
+	case reflect.Array:
+		n := t.Len()
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), schemas), MinItems: &n, MaxItems: &n}
+	case reflect.Struct:
+		ref := &Schema{Ref: "#/components/schemas/" + t.Name()}
+		if _, ok := schemas[t.Name()]; ok {
+			return ref
+		}
+		s := &Schema{Type: "object"}
+		schemas[t.Name()] = s
+		addFields(s, t, schemas)
+		return ref
+	}
+	return &Schema{}
+}
+
+// addFields adds t's JSON fields to s, flattening embedded structs as
+// encoding/json does
+func addFields(s *Schema, t reflect.Type, schemas map[string]*Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" {
+			addFields(s, f.Type, schemas)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties = append(s.Properties, Property{Name: name, Schema: schemaOf(f.Type, schemas)})
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
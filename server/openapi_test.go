@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRoutes(t *testing.T) {
+	for _, rt := range Routes {
+		path := strings.NewReplacer("{id}", "abc", "{name}", "bob").Replace(rt.Path)
+		served := 0
+		for _, other := range Routes {
+			if params, ok := other.match(path); ok && other.Method == rt.Method {
+				served++
+				if strings.Contains(rt.Path, "{") && len(params) != 1 {
+					t.Errorf("%s: expected a parameter, got %v", rt.Path, params)
+				}
+			}
+		}
+		if served != 1 {
+			t.Errorf("%s %s is served by %d routes", rt.Method, rt.Path, served)
+		}
+		if rt.Operation == "" || rt.Summary == "" {
+			t.Errorf("%s %s is undocumented", rt.Method, rt.Path)
+		}
+	}
+
+	s := NewServer()
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/games/abc", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET" {
+		t.Errorf("expected 405 allowing GET, got %d %q", w.Code, w.Header().Get("Allow"))
+	}
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/games/abc/nothing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	srv := httptest.NewServer(NewServer())
+	defer srv.Close()
+
+	var spec struct {
+		Paths      map[string]map[string]interface{}
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]interface{}
+				Required   []string
+			}
+		}
+	}
+	if code := get(t, srv.URL+"/openapi.json", &spec); code != http.StatusOK {
+		t.Fatalf("expected the document, got %d", code)
+	}
+	for _, rt := range Routes {
+		if _, ok := spec.Paths[rt.Path][strings.ToLower(rt.Method)]; !ok {
+			t.Errorf("%s %s is missing", rt.Method, rt.Path)
+		}
+	}
+
+	// embedded fields are flattened, and omitempty ones are optional
+	guess := spec.Components.Schemas["GuessResponse"]
+	for _, p := range []string{"player", "guess", "black", "white", "solved"} {
+		if _, ok := guess.Properties[p]; !ok {
+			t.Errorf("GuessResponse is missing %s", p)
+		}
+	}
+	if strings.Join(guess.Required, ",") != "guess,black,white,solved" {
+		t.Errorf("expected player to be optional, got %v", guess.Required)
+	}
+	if _, ok := spec.Components.Schemas["RoundState"]; !ok {
+		t.Error("expected nested types to be described")
+	}
+}
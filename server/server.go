@@ -0,0 +1,586 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/render"
+	"github.com/ianmcmahon/mastermind/storage"
+)
+
+// Server serves the REST API of Routes, its OpenAPI document on
+// /openapi.json, and its Prometheus metrics on /metrics
+type Server struct {
+	Games   *GameManager
+	Lobby   *Lobby
+	Metrics *Metrics
+	// Trees answers POST /hint for the sizes it has
+	Trees *Trees
+	// Auth checks the API keys of the requests to Routes, and limits
+	// their rates, if it's set
+	Auth *Auth
+}
+
+func NewServer() *Server {
+	s := &Server{Games: NewGameManager(), Lobby: NewLobby(), Metrics: NewMetrics(), Trees: NewTrees()}
+	s.Games.Metrics = s.Metrics
+	s.Lobby.Metrics = s.Metrics
+	return s
+}
+
+// Route is an endpoint of the REST API.  The server is routed by Routes
+// and the OpenAPI document is made from them, so the two can't disagree.
+type Route struct {
+	Method string
+	// Path has its parameters in braces, eg /games/{id}
+	Path string
+	// Operation names the endpoint, in the OpenAPI document and the
+	// generated client
+	Operation string
+	Summary   string
+	Query     []Param
+	// Request and Response are values of the bodies' types, or nil for no
+	// body; Status is the status of success
+	Request  interface{}
+	Response interface{}
+	Status   int
+	// ContentType is the response's, if it isn't JSON
+	ContentType string
+	// Websocket routes upgrade the connection rather than answer
+	Websocket bool
+	// Session is whether the route takes a session's token
+	Session SessionUse
+
+	handle func(s *Server, w http.ResponseWriter, r *http.Request, params map[string]string)
+}
+
+// Param is a query parameter
+type Param struct {
+	Name string
+	// Type is an OpenAPI type, string or integer
+	Type        string
+	Description string
+}
+
+var Routes = []Route{
+	{
+		Method: http.MethodPost, Path: "/games", Operation: "CreateGame",
+		Summary: "Start a game with a random secret",
+		Request: CreateGameRequest{}, Response: State{}, Status: http.StatusCreated, Session: SessionOptional,
+		handle: (*Server).createGame,
+	},
+	{
+		Method: http.MethodGet, Path: "/games", Operation: "ListGames",
+		Summary:  "The session's unsolved games",
+		Response: GameList{}, Status: http.StatusOK, Session: SessionRequired,
+		handle: (*Server).listGames,
+	},
+	{
+		Method: http.MethodPost, Path: "/sessions", Operation: "CreateSession",
+		Summary:  "Start a session, whose games only it may play",
+		Response: SessionResponse{}, Status: http.StatusCreated,
+		handle: (*Server).createSession,
+	},
+	{
+		Method: http.MethodGet, Path: "/games/{id}", Operation: "GetGame",
+		Summary:  "A game's state; the secret is shown once it's solved",
+		Response: State{}, Status: http.StatusOK,
+		handle: (*Server).getGame,
+	},
+	{
+		Method: http.MethodPost, Path: "/games/{id}/guesses", Operation: "Guess",
+		Summary: "Score a guess",
+		Request: GuessRequest{}, Response: GuessResponse{}, Status: http.StatusOK, Session: SessionOptional,
+		handle: (*Server).guess,
+	},
+	{
+		Method: http.MethodGet, Path: "/games/{id}/hint", Operation: "Hint",
+		Summary:  "The solver's choice of next guess",
+		Response: HintResponse{}, Status: http.StatusOK, Session: SessionOptional,
+		handle: (*Server).hint,
+	},
+	{
+		Method: http.MethodGet, Path: "/games/{id}/share", Operation: "Share",
+		Summary:  "The game written up for sharing, with a row of emoji pins for each move",
+		Response: ShareResponse{}, Status: http.StatusOK,
+		handle: (*Server).share,
+	},
+	{
+		Method: http.MethodGet, Path: "/games/{id}/board.svg", Operation: "BoardSVG",
+		Summary: "A picture of the board, with the secret below once it's solved",
+		Status:  http.StatusOK, ContentType: "image/svg+xml",
+		handle: (*Server).boardSVG,
+	},
+	{
+		Method: http.MethodGet, Path: "/games/{id}/board.png", Operation: "BoardPNG",
+		Summary: "A picture of the board, with the secret below once it's solved",
+		Status:  http.StatusOK, ContentType: "image/png",
+		handle: (*Server).boardPNG,
+	},
+	{
+		Method: http.MethodPost, Path: "/daily", Operation: "CreateDaily",
+		Summary: "Start a game of today's puzzle, which has the same secret for everyone",
+		Request: CreateGameRequest{}, Response: State{}, Status: http.StatusCreated, Session: SessionOptional,
+		handle: (*Server).createDaily,
+	},
+	{
+		Method: http.MethodPost, Path: "/hint", Operation: "StatelessHint",
+		Summary: "The solver's choice of next guess for a game played anywhere, given its moves so far",
+		Request: HintRequest{}, Response: HintResponse{}, Status: http.StatusOK,
+		handle: (*Server).statelessHint,
+	},
+	{
+		Method: http.MethodGet, Path: "/games/{id}/live", Operation: "LiveGame",
+		Summary:   "Play a game over a websocket, named by the player query parameter",
+		Query:     []Param{{Name: "player", Type: "string", Description: "the player's name"}},
+		Websocket: true,
+		Session:   SessionOptional,
+		handle:    (*Server).live,
+	},
+	{
+		Method: http.MethodPost, Path: "/matches", Operation: "JoinMatch",
+		Summary: "Join the lobby, starting a match or taking the second seat in one",
+		Request: JoinRequest{}, Response: JoinResponse{}, Status: http.StatusCreated,
+		handle: (*Server).joinMatch,
+	},
+	{
+		Method: http.MethodGet, Path: "/matches/{id}", Operation: "GetMatch",
+		Summary:  "A match's state",
+		Response: MatchState{}, Status: http.StatusOK,
+		handle: (*Server).getMatch,
+	},
+	{
+		Method: http.MethodPost, Path: "/matches/{id}/secret", Operation: "SetSecret",
+		Summary: "Set the secret, as this round's codemaker",
+		Request: SecretRequest{}, Response: MatchState{}, Status: http.StatusOK,
+		handle: (*Server).setSecret,
+	},
+	{
+		Method: http.MethodPost, Path: "/matches/{id}/guesses", Operation: "MatchGuess",
+		Summary: "Score a guess, as this round's codebreaker",
+		Request: MatchGuessRequest{}, Response: Move{}, Status: http.StatusOK,
+		handle: (*Server).matchGuess,
+	},
+	{
+		Method: http.MethodGet, Path: "/matches/{id}/live", Operation: "LiveMatch",
+		Summary:   "Follow and play a match over a websocket, with the player's token",
+		Query:     []Param{{Name: "token", Type: "string", Description: "the token given on joining"}},
+		Websocket: true,
+		handle:    (*Server).liveMatch,
+	},
+	{
+		Method: http.MethodGet, Path: "/agents", Operation: "Agent",
+		Summary:   "Play matches as a program, with the agent protocol over a websocket",
+		Websocket: true,
+		handle:    (*Server).agentSocket,
+	},
+	{
+		Method: http.MethodGet, Path: "/ratings", Operation: "Leaderboard",
+		Summary:  "The highest rated players, best first",
+		Query:    []Param{{Name: "limit", Type: "integer", Description: "how many; 20 if left out"}},
+		Response: []Rating{}, Status: http.StatusOK,
+		handle: (*Server).leaderboard,
+	},
+	{
+		Method: http.MethodGet, Path: "/ratings/{name}", Operation: "GetRating",
+		Summary:  "A player's rating",
+		Response: Rating{}, Status: http.StatusOK,
+		handle: (*Server).rating,
+	},
+}
+
+// match reports whether path fits the route's pattern, and the parameters
+// in it
+func (rt Route) match(path string) (map[string]string, bool) {
+	pattern := strings.Split(strings.Trim(rt.Path, "/"), "/")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != len(pattern) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, p := range pattern {
+		if strings.HasPrefix(p, "{") {
+			if parts[i] == "" {
+				return nil, false
+			}
+			params[strings.Trim(p, "{}")] = parts[i]
+		} else if p != parts[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/metrics":
+		s.Metrics.Handler().ServeHTTP(w, r)
+		return
+	case "/openapi.json":
+		writeJSON(w, http.StatusOK, OpenAPI())
+		return
+	}
+	if !s.Auth.allow(w, r, s.Metrics) {
+		return
+	}
+
+	allowed := []string{}
+	for _, rt := range Routes {
+		params, ok := rt.match(r.URL.Path)
+		if !ok {
+			continue
+		}
+		if rt.Method == r.Method {
+			rt.handle(s, w, r, params)
+			return
+		}
+		allowed = append(allowed, rt.Method)
+	}
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	writeError(w, http.StatusNotFound, errors.New("not found"))
+}
+
+// CreateGameRequest makes a game; the size is 4x6 if left out
+type CreateGameRequest struct {
+	Size string `json:"size,omitempty"`
+}
+
+type GuessRequest struct {
+	Guess  string `json:"guess"`
+	Player string `json:"player,omitempty"`
+}
+
+type GuessResponse struct {
+	Move
+	Solved bool `json:"solved"`
+}
+
+// HintResponse is the solver's next guess, and how many codes could still
+// be the secret
+type HintResponse struct {
+	Hint      string `json:"hint"`
+	Remaining int    `json:"remaining"`
+}
+
+type ShareResponse struct {
+	Text string `json:"text"`
+}
+
+// JoinRequest joins the lobby; the size is 4x6 if left out
+type JoinRequest struct {
+	Size   string `json:"size,omitempty"`
+	Player string `json:"player"`
+}
+
+// JoinResponse is the match joined, and the token which proves it's the
+// player in that seat
+type JoinResponse struct {
+	Match MatchState `json:"match"`
+	Seat  int        `json:"seat"`
+	Token string     `json:"token"`
+}
+
+type SecretRequest struct {
+	Token  string `json:"token"`
+	Secret string `json:"secret"`
+}
+
+type MatchGuessRequest struct {
+	Token string `json:"token"`
+	Guess string `json:"guess"`
+}
+
+// Rating is a rating as the API shows it
+type Rating struct {
+	Name   string  `json:"name"`
+	Rating float64 `json:"rating"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+	Losses int     `json:"losses"`
+	Draws  int     `json:"draws"`
+}
+
+// Error is the body of every failure
+type Error struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, Error{Error: err.Error()})
+}
+
+// decode reads a request body into v, leaving v alone if there's none
+func decode(r *http.Request, v interface{}) error {
+	if r.ContentLength == 0 {
+		return nil
+	}
+	return json.NewDecoder(r.Body).Decode(v)
+}
+
+func parseSize(s string) (mm.GameSize, error) {
+	if s == "" {
+		return mm.GameSize{Positions: 4, Colors: 6}, nil
+	}
+	return mm.ParseGameSize(s)
+}
+
+func (s *Server) createGame(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	s.create(w, r, s.Games.Create)
+}
+
+func (s *Server) createDaily(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	s.create(w, r, func(size mm.GameSize) (*Game, error) {
+		return s.Games.CreateDaily(size, time.Now())
+	})
+}
+
+// create starts a game of the size asked for with newGame
+func (s *Server) create(w http.ResponseWriter, r *http.Request, newGame func(mm.GameSize) (*Game, error)) {
+	sess, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	var req CreateGameRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	size, err := parseSize(req.Size)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	g, err := newGame(size)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	g.own(sess)
+	w.Header().Set("Location", "/games/"+g.ID)
+	writeJSON(w, http.StatusCreated, g.State())
+}
+
+// game finds the game a request is for, or writes the error
+func (s *Server) game(w http.ResponseWriter, params map[string]string) (*Game, bool) {
+	g, err := s.Games.Get(params["id"])
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return nil, false
+	}
+	return g, true
+}
+
+func (s *Server) getGame(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if g, ok := s.game(w, params); ok {
+		writeJSON(w, http.StatusOK, g.State())
+	}
+}
+
+func (s *Server) guess(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	g, ok := s.player(w, r, params)
+	if !ok {
+		return
+	}
+	var req GuessRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	m, solved, err := g.Guess(req.Player, req.Guess)
+	switch {
+	case err == ErrSolved:
+		writeError(w, http.StatusConflict, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, GuessResponse{Move: m, Solved: solved})
+}
+
+func (s *Server) hint(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	g, ok := s.player(w, r, params)
+	if !ok {
+		return
+	}
+	hint, err := g.Hint()
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, HintResponse{Hint: hint.String(), Remaining: g.Candidates()})
+}
+
+func (s *Server) share(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if g, ok := s.game(w, params); ok {
+		writeJSON(w, http.StatusOK, ShareResponse{Text: g.Share()})
+	}
+}
+
+func (s *Server) boardSVG(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.board(w, params, "image/svg+xml", render.SVG)
+}
+
+func (s *Server) boardPNG(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	s.board(w, params, "image/png", render.PNG)
+}
+
+// board draws a game's board with draw
+func (s *Server) board(w http.ResponseWriter, params map[string]string, contentType string, draw func(io.Writer, render.Board) error) {
+	g, ok := s.game(w, params)
+	if !ok {
+		return
+	}
+	b := render.Board{Size: g.Size, Moves: g.History()}
+	if g.State().Solved {
+		b.Secret = b.Moves[len(b.Moves)-1].Guess
+	}
+
+	var buf bytes.Buffer
+	if err := draw(&buf, b); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	buf.WriteTo(w)
+}
+
+// matchError writes err with the status it deserves
+func matchError(w http.ResponseWriter, err error) {
+	switch err {
+	case ErrNotFound:
+		writeError(w, http.StatusNotFound, err)
+	case ErrBadToken:
+		writeError(w, http.StatusForbidden, err)
+	case ErrNotYourTurn:
+		writeError(w, http.StatusConflict, err)
+	default:
+		writeError(w, http.StatusBadRequest, err)
+	}
+}
+
+func (s *Server) joinMatch(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	var req JoinRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	size, err := parseSize(req.Size)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	m, seat, token, err := s.Lobby.Join(req.Player, size)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.Header().Set("Location", "/matches/"+m.ID)
+	writeJSON(w, http.StatusCreated, JoinResponse{Match: m.State(), Seat: seat, Token: token})
+}
+
+// match finds the match a request is for, or writes the error
+func (s *Server) match(w http.ResponseWriter, params map[string]string) (*Match, bool) {
+	m, err := s.Lobby.Get(params["id"])
+	if err != nil {
+		matchError(w, err)
+		return nil, false
+	}
+	return m, true
+}
+
+func (s *Server) getMatch(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	if m, ok := s.match(w, params); ok {
+		writeJSON(w, http.StatusOK, m.State())
+	}
+}
+
+func (s *Server) setSecret(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	m, ok := s.match(w, params)
+	if !ok {
+		return
+	}
+	var req SecretRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := m.SetSecret(req.Token, req.Secret); err != nil {
+		matchError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, m.State())
+}
+
+func (s *Server) matchGuess(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	m, ok := s.match(w, params)
+	if !ok {
+		return
+	}
+	var req MatchGuessRequest
+	if err := decode(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	move, err := m.Guess(req.Token, req.Guess)
+	if err != nil {
+		matchError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, move)
+}
+
+func toRating(r storage.Rating) Rating {
+	return Rating{Name: r.Name, Rating: r.Rating, Games: r.Games, Wins: r.Wins, Losses: r.Losses, Draws: r.Draws}
+}
+
+func (s *Server) leaderboard(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	limit := 20
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("bad limit %q", l))
+			return
+		}
+		limit = n
+	}
+	board, err := s.Lobby.Ratings.Leaderboard(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	out := []Rating{}
+	for _, rating := range board {
+		out = append(out, toRating(rating))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) rating(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	rating, err := s.Lobby.Ratings.Get(params["name"])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toRating(rating))
+}
@@ -0,0 +1,321 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/websocket"
+	"github.com/ianmcmahon/mastermind/solver"
+	"github.com/ianmcmahon/mastermind/storage"
+)
+
+func post(t *testing.T, url string, body interface{}, v interface{}) int {
+	b, _ := json.Marshal(body)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	json.NewDecoder(resp.Body).Decode(v)
+	return resp.StatusCode
+}
+
+func get(t *testing.T, url string, v interface{}) int {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	json.NewDecoder(resp.Body).Decode(v)
+	return resp.StatusCode
+}
+
+func TestREST(t *testing.T) {
+	s := NewServer()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	var st State
+	if code := post(t, srv.URL+"/games", map[string]string{"size": "4x6"}, &st); code != http.StatusCreated || st.Size != "4x6" {
+		t.Fatalf("expected a 4x6 game, got %d %+v", code, st)
+	}
+	g, _ := s.Games.Get(st.ID)
+	secret := g.game.Secret()
+
+	var hint struct {
+		Hint      string
+		Remaining int
+	}
+	if code := get(t, srv.URL+"/games/"+st.ID+"/hint", &hint); code != http.StatusOK || hint.Remaining != 1296 || len(hint.Hint) != 4 {
+		t.Errorf("expected an opening hint, got %d %+v", code, hint)
+	}
+
+	var m struct {
+		Move
+		Solved bool
+	}
+	if code := post(t, srv.URL+"/games/"+st.ID+"/guesses", map[string]string{"guess": "99"}, &m); code != http.StatusBadRequest {
+		t.Errorf("expected a bad guess refused, got %d", code)
+	}
+	if code := post(t, srv.URL+"/games/"+st.ID+"/guesses", map[string]string{"guess": secret.String(), "player": "ian"}, &m); code != http.StatusOK || !m.Solved || m.Black != 4 {
+		t.Errorf("expected the secret to solve it, got %d %+v", code, m)
+	}
+	if code := post(t, srv.URL+"/games/"+st.ID+"/guesses", map[string]string{"guess": "0000"}, &m); code != http.StatusConflict {
+		t.Errorf("expected guesses refused after solving, got %d", code)
+	}
+
+	if code := get(t, srv.URL+"/games/"+st.ID, &st); code != http.StatusOK || !st.Solved || st.Secret != secret.String() || len(st.Moves) != 1 || st.Moves[0].Player != "ian" {
+		t.Errorf("expected the solved state, got %d %+v", code, st)
+	}
+	if code := get(t, srv.URL+"/games/nope", &st); code != http.StatusNotFound {
+		t.Errorf("expected no game, got %d", code)
+	}
+}
+
+func TestLive(t *testing.T) {
+	s := NewServer()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	game := mm.NewCustomGameWithSecret(4, 6, mm.Code{3, 1, 5, 2})
+	game.Quiet = true
+	g := s.Games.add(game, 0)
+
+	dial := func(player string) *websocket.Conn {
+		conn, err := websocket.Dial("ws" + strings.TrimPrefix(srv.URL, "http") + "/games/" + g.ID + "/live?player=" + player)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var e Event
+		if err := conn.ReadJSON(&e); err != nil || e.Type != "state" || e.State.ID != g.ID {
+			t.Fatalf("expected the state first, got %+v %v", e, err)
+		}
+		return conn
+	}
+	alice, bob := dial("alice"), dial("bob")
+	defer alice.Close()
+	defer bob.Close()
+
+	// bob sees alice's guess
+	alice.WriteJSON(command{Type: "guess", Guess: "0011"})
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		var e Event
+		if err := conn.ReadJSON(&e); err != nil || e.Type != "guess" || e.Move.Player != "alice" || e.Move.Guess != "0011" {
+			t.Errorf("expected alice's guess, got %+v %v", e, err)
+		}
+	}
+
+	// hints stream the candidates count then the guess, to the asker only
+	bob.WriteJSON(command{Type: "hint"})
+	var e Event
+	if err := bob.ReadJSON(&e); err != nil || e.Type != "candidates" || e.Remaining != g.Candidates() {
+		t.Errorf("expected the candidates, got %+v %v", e, err)
+	}
+	if err := bob.ReadJSON(&e); err != nil || e.Type != "hint" || len(e.Hint) != 4 {
+		t.Errorf("expected a hint, got %+v %v", e, err)
+	}
+
+	bob.WriteJSON(command{Type: "guess", Guess: "x"})
+	if err := bob.ReadJSON(&e); err != nil || e.Type != "error" {
+		t.Errorf("expected an error, got %+v %v", e, err)
+	}
+
+	bob.WriteJSON(command{Type: "guess", Guess: g.game.Secret().String()})
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		var e Event
+		if err := conn.ReadJSON(&e); err != nil || e.Type != "guess" || !e.Solved || e.Move.Player != "bob" {
+			t.Errorf("expected bob's winning guess, got %+v %v", e, err)
+		}
+	}
+}
+
+func TestStoredGames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := storage.OpenSQLite(filepath.Join(dir, "mm.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	m := NewGameManager()
+	m.Store = store
+	g, _ := m.Create(mm.GameSize{Positions: 4, Colors: 6})
+	g.Guess("ian", "0011")
+	g.Guess("ian", g.game.Secret().String())
+
+	// a new manager, as after a restart, finds the game in the store
+	m = NewGameManager()
+	m.Store = store
+	restored, err := m.Get(g.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored.State(), g.State()) {
+		t.Errorf("expected %+v, got %+v", g.State(), restored.State())
+	}
+	if _, _, err := restored.Guess("ian", "0000"); err != ErrSolved {
+		t.Errorf("expected the restored game solved, got %v", err)
+	}
+	if _, err := m.Get("nope"); err != ErrNotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestDaily(t *testing.T) {
+	s := NewServer()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	var a, b State
+	post(t, srv.URL+"/daily", map[string]string{"size": "4x6"}, &a)
+	if code := post(t, srv.URL+"/daily", nil, &b); code != http.StatusCreated || a.ID == b.ID {
+		t.Fatalf("expected two games, got %d %+v %+v", code, a, b)
+	}
+	if a.Daily != mm.DailyNumber(time.Now().UTC()) || b.Daily != a.Daily {
+		t.Errorf("expected today's puzzle, got #%d and #%d", a.Daily, b.Daily)
+	}
+
+	size := mm.GameSize{Positions: 4, Colors: 6}
+	if s.Games.DailySalt == "" {
+		t.Fatal("expected the daily puzzle to be salted")
+	}
+	secret := mm.SaltedDailySecret(size, time.Now().UTC(), s.Games.DailySalt)
+	var m GuessResponse
+	post(t, srv.URL+"/games/"+a.ID+"/guesses", map[string]string{"guess": secret.String()}, &m)
+	if !m.Solved {
+		t.Fatalf("expected the daily secret to win, got %+v", m)
+	}
+
+	var share ShareResponse
+	get(t, srv.URL+"/games/"+a.ID+"/share", &share)
+	want := fmt.Sprintf("Mastermind #%d 4x6 1/10\n🟩🟩🟩🟩\n", a.Daily)
+	if share.Text != want {
+		t.Errorf("expected %q, got %q", want, share.Text)
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	m := NewGameManager()
+	m.MemoryLimit = 64 << 20
+	if _, err := m.Create(mm.GameSize{Positions: 4, Colors: 6}); err != nil {
+		t.Errorf("expected 4x6 taken, got %v", err)
+	}
+	if _, err := m.Create(mm.GameSize{Positions: 6, Colors: 10}); err == nil {
+		t.Error("expected 6x10 refused under 64 MB")
+	}
+	if m := NewGameManager(); m.MemoryLimit != DefaultMemoryLimit {
+		t.Errorf("expected a new manager limited to %d bytes, got %d", DefaultMemoryLimit, m.MemoryLimit)
+	}
+}
+
+func TestMaxCodes(t *testing.T) {
+	s := NewServer()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	var st State
+	for _, size := range []string{"14x10", "7x8", "64x10"} {
+		if code := post(t, srv.URL+"/games", map[string]string{"size": size}, &st); code != http.StatusBadRequest {
+			t.Errorf("expected %s refused, got %d", size, code)
+		}
+		var hint HintResponse
+		if code := post(t, srv.URL+"/hint", HintRequest{Size: size}, &hint); code != http.StatusBadRequest {
+			t.Errorf("expected a hint on %s refused, got %d", size, code)
+		}
+	}
+	if _, err := s.Games.CreateDaily(mm.GameSize{Positions: 14, Colors: 10}, time.Now()); err == nil {
+		t.Error("expected a 14x10 daily refused")
+	}
+	if _, err := s.Games.Create(mm.GameSize{Positions: 6, Colors: 10}); err != nil {
+		t.Errorf("expected 6x10 taken, got %v", err)
+	}
+}
+
+func TestBoardImages(t *testing.T) {
+	s := NewServer()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	game := mm.NewCustomGameWithSecret(4, 6, mm.Code{0, 1, 2, 3})
+	game.Quiet = true
+	g := s.Games.add(game, 0)
+	g.Guess("", "0123")
+
+	for path, contentType := range map[string]string{"/board.svg": "image/svg+xml", "/board.png": "image/png"} {
+		resp, err := http.Get(srv.URL + "/games/" + g.ID + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != contentType || len(body) == 0 {
+			t.Errorf("%s: expected an image, got %d %s", path, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+	}
+}
+
+func TestStatelessHint(t *testing.T) {
+	s := NewServer()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	sv := &solver.Solver{Game: mm.NewCustomGame(4, 6)}
+
+	for _, moves := range [][]Move{
+		nil,
+		{{Guess: "0011", Black: 1, White: 1}},
+		// off the tree's strategy
+		{{Guess: "0123", Black: 0, White: 2}, {Guess: "4455", Black: 1, White: 0}},
+	} {
+		var history mm.History
+		for _, m := range moves {
+			code, _ := sv.Code(m.Guess)
+			history = append(history, mm.Move{Guess: code, Result: mm.Result{Correct: m.Black, HalfCorrect: m.White}})
+		}
+		expected, _ := sv.Step(history)
+		var hint HintResponse
+		if code := post(t, srv.URL+"/hint", HintRequest{Moves: moves}, &hint); code != http.StatusOK || hint.Hint != expected.String() || hint.Remaining != len(sv.Possible(history)) {
+			t.Errorf("%v: expected %v, got %d %+v", moves, expected, code, hint)
+		}
+	}
+
+	// an added tree is answered from
+	tree, err := (&solver.Solver{Game: mm.NewCustomGame(3, 3)}).Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree.Root.Guess = mm.Code{2, 2, 2}
+	if err := s.Trees.Add(tree); err != nil {
+		t.Fatal(err)
+	}
+	var hint HintResponse
+	if code := post(t, srv.URL+"/hint", HintRequest{Size: "3x3"}, &hint); code != http.StatusOK || hint.Hint != "222" {
+		t.Errorf("expected the tree's opener, got %d %+v", code, hint)
+	}
+
+	for _, bad := range []struct {
+		req  HintRequest
+		code int
+	}{
+		{HintRequest{Size: "4x11"}, http.StatusBadRequest},
+		{HintRequest{Moves: []Move{{Guess: "01234"}}}, http.StatusBadRequest},
+		{HintRequest{Moves: []Move{{Guess: "0123", Black: 3, White: 2}}}, http.StatusBadRequest},
+		{HintRequest{Moves: []Move{{Guess: "0123", Black: 3, White: 1}}}, http.StatusConflict},
+	} {
+		if code := post(t, srv.URL+"/hint", bad.req, &hint); code != bad.code {
+			t.Errorf("%+v: expected %d, got %d", bad.req, bad.code, code)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoSession = errors.New("no such session")
+	ErrNotOwner  = errors.New("game belongs to another session")
+)
+
+// SessionUse is whether a route takes a session's token
+type SessionUse int
+
+const (
+	NoSession SessionUse = iota
+	SessionOptional
+	SessionRequired
+)
+
+// Session is a client of the server, known by its token.  Games made in a
+// session belong to it: only it may guess at them or ask for hints, though
+// anyone may watch.  Games made without one are open to everyone.
+// Sessions are only kept in memory, but games keep a hash of their
+// session's token, and are stored with it, so a game got again from the
+// store still belongs to its session; once that's expired, or the server
+// restarted, no one may play it.
+type Session struct {
+	Token   string
+	Created time.Time
+	// seen is when the session was last used; guarded by the manager's mu
+	seen time.Time
+	// owner is the hash of the token its games keep
+	owner string
+}
+
+// ownerOf is the hash of a session's token its games keep, so the store
+// never holds a token which could be played with
+func ownerOf(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewSession starts a session
+func (m *GameManager) NewSession() *Session {
+	token := make([]byte, 16)
+	rand.Read(token)
+	now := time.Now()
+	sess := &Session{Token: hex.EncodeToString(token), Created: now, seen: now}
+	sess.owner = ownerOf(sess.Token)
+	m.mu.Lock()
+	m.sessions[sess.Token] = sess
+	m.mu.Unlock()
+	return sess
+}
+
+// Session finds a session by its token, keeping it from expiring
+func (m *GameManager) Session(token string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sess, ok := m.sessions[token]
+	if !ok {
+		return nil, ErrNoSession
+	}
+	sess.seen = time.Now()
+	return sess, nil
+}
+
+// Active lists the unsolved games belonging to sess, oldest first
+func (m *GameManager) Active(sess *Session) []*Game {
+	m.mu.Lock()
+	var games []*Game
+	for _, g := range m.games {
+		games = append(games, g)
+	}
+	m.mu.Unlock()
+
+	var active []*Game
+	for _, g := range games {
+		g.mu.Lock()
+		if g.owner == sess.owner && !g.solved {
+			active = append(active, g)
+		}
+		g.mu.Unlock()
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].Created.Before(active[j].Created) })
+	return active
+}
+
+// Expire forgets the games and sessions which have gone unused for
+// IdleTimeout, returning how many of each.  Stored games can still be got
+// again, but games only in memory are gone for good.
+func (m *GameManager) Expire(now time.Time) (games, sessions int) {
+	if m.IdleTimeout <= 0 {
+		return 0, 0
+	}
+	cutoff := now.Add(-m.IdleTimeout)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, g := range m.games {
+		g.mu.Lock()
+		idle := g.active.Before(cutoff)
+		g.mu.Unlock()
+		if idle {
+			delete(m.games, id)
+			games++
+		}
+	}
+	for token, sess := range m.sessions {
+		if sess.seen.Before(cutoff) {
+			delete(m.sessions, token)
+			sessions++
+		}
+	}
+	return games, sessions
+}
+
+// own gives the game to sess, storing it as sess's; a nil sess leaves it
+// open to everyone
+func (g *Game) own(sess *Session) {
+	if sess == nil {
+		return
+	}
+	g.mu.Lock()
+	g.owner = sess.owner
+	g.save()
+	g.mu.Unlock()
+}
+
+// Allows reports whether sess, which may be nil, may play the game
+func (g *Game) Allows(sess *Session) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.owner == "" || sess != nil && sess.owner == g.owner
+}
+
+// SessionResponse is a new session's token, to be sent as a bearer token
+// in the Authorization header, or as the session query parameter of a
+// websocket
+type SessionResponse struct {
+	Token string `json:"token"`
+}
+
+// GameList is a session's games
+type GameList struct {
+	Games []State `json:"games"`
+}
+
+// sessionToken is the token a request is sent with, if any
+func sessionToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return r.URL.Query().Get("session")
+}
+
+// session finds the session a request is made in, which is nil if it has
+// no token, or writes the error for a token which isn't a session's
+func (s *Server) session(w http.ResponseWriter, r *http.Request) (*Session, bool) {
+	token := sessionToken(r)
+	if token == "" {
+		return nil, true
+	}
+	sess, err := s.Games.Session(token)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err)
+		return nil, false
+	}
+	return sess, true
+}
+
+// player finds the game a request is for and the session making it, or
+// writes the error if the session may not play the game
+func (s *Server) player(w http.ResponseWriter, r *http.Request, params map[string]string) (*Game, bool) {
+	sess, ok := s.session(w, r)
+	if !ok {
+		return nil, false
+	}
+	g, ok := s.game(w, params)
+	if !ok {
+		return nil, false
+	}
+	if !g.Allows(sess) {
+		writeError(w, http.StatusForbidden, ErrNotOwner)
+		return nil, false
+	}
+	return g, true
+}
+
+func (s *Server) createSession(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	writeJSON(w, http.StatusCreated, SessionResponse{Token: s.Games.NewSession().Token})
+}
+
+func (s *Server) listGames(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	sess, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	if sess == nil {
+		writeError(w, http.StatusUnauthorized, errors.New("listing games takes a session"))
+		return
+	}
+	list := GameList{Games: []State{}}
+	for _, g := range s.Games.Active(sess) {
+		list.Games = append(list.Games, g.State())
+	}
+	writeJSON(w, http.StatusOK, list)
+}
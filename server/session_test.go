@@ -0,0 +1,149 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/websocket"
+	"github.com/ianmcmahon/mastermind/storage"
+)
+
+// send makes a request in the session with token, if it's set
+func send(t *testing.T, method, url, token string, body interface{}, v interface{}) int {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, url, &buf)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	json.NewDecoder(resp.Body).Decode(v)
+	return resp.StatusCode
+}
+
+func TestSessions(t *testing.T) {
+	s := NewServer()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	var a, b SessionResponse
+	post(t, srv.URL+"/sessions", nil, &a)
+	if code := post(t, srv.URL+"/sessions", nil, &b); code != http.StatusCreated || a.Token == "" || a.Token == b.Token {
+		t.Fatalf("expected two sessions, got %d %q %q", code, a.Token, b.Token)
+	}
+
+	var st, open State
+	if code := send(t, "POST", srv.URL+"/games", a.Token, CreateGameRequest{Size: "4x6"}, &st); code != http.StatusCreated {
+		t.Fatalf("expected a game, got %d", code)
+	}
+	post(t, srv.URL+"/games", nil, &open)
+	if code := send(t, "POST", srv.URL+"/games", "nope", nil, &State{}); code != http.StatusUnauthorized {
+		t.Errorf("expected an unknown session refused, got %d", code)
+	}
+
+	// only the owner may play, but anyone may look
+	var m GuessResponse
+	if code := post(t, srv.URL+"/games/"+st.ID+"/guesses", GuessRequest{Guess: "0011"}, &m); code != http.StatusForbidden {
+		t.Errorf("expected a guess without the session refused, got %d", code)
+	}
+	if code := send(t, "GET", srv.URL+"/games/"+st.ID+"/hint", b.Token, nil, &HintResponse{}); code != http.StatusForbidden {
+		t.Errorf("expected a hint in another session refused, got %d", code)
+	}
+	if code := send(t, "POST", srv.URL+"/games/"+st.ID+"/guesses", a.Token, GuessRequest{Guess: "0011"}, &m); code != http.StatusOK {
+		t.Errorf("expected the owner's guess, got %d", code)
+	}
+	if code := get(t, srv.URL+"/games/"+st.ID, &State{}); code != http.StatusOK {
+		t.Errorf("expected anyone to see the game, got %d", code)
+	}
+	if code := send(t, "POST", srv.URL+"/games/"+open.ID+"/guesses", b.Token, GuessRequest{Guess: "0011"}, &m); code != http.StatusOK {
+		t.Errorf("expected a game without a session open to all, got %d", code)
+	}
+
+	// watchers of a live game can't play it
+	conn, err := websocket.Dial("ws" + strings.TrimPrefix(srv.URL, "http") + "/games/" + st.ID + "/live?session=" + b.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var e Event
+	conn.ReadJSON(&e)
+	conn.WriteJSON(command{Type: "guess", Guess: "0011"})
+	if err := conn.ReadJSON(&e); err != nil || e.Type != "error" || e.Message != ErrNotOwner.Error() {
+		t.Errorf("expected the guess refused, got %+v %v", e, err)
+	}
+
+	var list GameList
+	if code := send(t, "GET", srv.URL+"/games", a.Token, nil, &list); code != http.StatusOK || len(list.Games) != 1 || list.Games[0].ID != st.ID {
+		t.Errorf("expected the session's game, got %d %+v", code, list)
+	}
+	if code := get(t, srv.URL+"/games", &list); code != http.StatusUnauthorized {
+		t.Errorf("expected listing without a session refused, got %d", code)
+	}
+	g, _ := s.Games.Get(st.ID)
+	send(t, "POST", srv.URL+"/games/"+st.ID+"/guesses", a.Token, GuessRequest{Guess: g.Secret().String()}, &m)
+	if send(t, "GET", srv.URL+"/games", a.Token, nil, &list); len(list.Games) != 0 {
+		t.Errorf("expected solved games left out, got %+v", list)
+	}
+}
+
+func TestExpire(t *testing.T) {
+	m := NewGameManager()
+	m.IdleTimeout = time.Hour
+	sess := m.NewSession()
+	g, _ := m.Create(mm.GameSize{Positions: 4, Colors: 6})
+	g.own(sess)
+
+	if games, sessions := m.Expire(time.Now().Add(30 * time.Minute)); games != 0 || sessions != 0 {
+		t.Errorf("expected nothing idle yet, got %d games and %d sessions", games, sessions)
+	}
+	if games, sessions := m.Expire(time.Now().Add(2 * time.Hour)); games != 1 || sessions != 1 {
+		t.Errorf("expected the game and session expired, got %d games and %d sessions", games, sessions)
+	}
+	if _, err := m.Get(g.ID); err != ErrNotFound {
+		t.Errorf("expected the game gone, got %v", err)
+	}
+	if _, err := m.Session(sess.Token); err != ErrNoSession {
+		t.Errorf("expected the session gone, got %v", err)
+	}
+}
+
+func TestStoredOwner(t *testing.T) {
+	m := NewGameManager()
+	m.Store = storage.NewMemory()
+	sess := m.NewSession()
+	g, _ := m.Create(mm.GameSize{Positions: 4, Colors: 6})
+	g.own(sess)
+
+	// the game idles out of memory, but its session is still about
+	g.mu.Lock()
+	g.active = time.Now().Add(-2 * m.IdleTimeout)
+	g.mu.Unlock()
+	if games, _ := m.Expire(time.Now()); games != 1 {
+		t.Fatalf("expected the game expired, got %d", games)
+	}
+	again, err := m.Get(g.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Allows(nil) || again.Allows(m.NewSession()) || !again.Allows(sess) {
+		t.Error("expected the game got again to be only its session's")
+	}
+	if active := m.Active(sess); len(active) != 1 || active[0].ID != g.ID {
+		t.Errorf("expected the game listed as the session's, got %v", active)
+	}
+	if rec, _ := m.Store.Game(g.ID); rec.Owner == "" || strings.Contains(rec.Owner, sess.Token) {
+		t.Errorf("expected a hash of the token stored, got %q", rec.Owner)
+	}
+}
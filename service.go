@@ -6,6 +6,9 @@ import (
 	"math"
 	"math/rand"
 	"time"
+	"unicode/utf8"
+
+	"github.com/ianmcmahon/mastermind/internal/pool"
 )
 
 const (
@@ -20,19 +23,75 @@ func init() {
 type Code []byte
 
 func (c Code) String() string {
-	buf := new(bytes.Buffer)
+	// spelled out on the stack, so the string is the only allocation.
+	// String is inlined, so m[c.String()] looks a map up without even
+	// that, as long as the code is no longer than scratch.
+	var scratch [32]byte
+	return string(c.Append(scratch[:0]))
+}
+
+// Append appends c to buf spelled as String spells it, so a map keyed by
+// strings can be looked up with a code without allocating:
+// m[string(c.Append(buf[:0]))]
+func (c Code) Append(buf []byte) []byte {
 	for _, r := range c {
-		buf.WriteRune(rune(r) + '0')
+		buf = utf8.AppendRune(buf, rune(r)+'0')
+	}
+	return buf
+}
+
+// Compare orders codes as their Strings do, without making them: -1 if c
+// comes first, 1 if d does, 0 if they're the same
+func (c Code) Compare(d Code) int {
+	return bytes.Compare(c, d)
+}
+
+// CodeKey is a code packed into an integer, to key maps by without making
+// a string of it.  The pegs take 1, 2, 4 or 8 bits each, the fewest which
+// hold the code's highest color, in the low 56 bits, and the top byte says
+// how many bits and how many pegs, so no two codes share a key.  Any code
+// of a board small enough to list every code of fits, and Key panics on
+// one which doesn't.
+type CodeKey uint64
+
+// Key packs c into its CodeKey
+func (c Code) Key() CodeKey {
+	k, ok := c.TryKey()
+	if !ok {
+		panic(fmt.Sprintf("code %v is too long to key", c))
+	}
+	return k
+}
+
+// TryKey is Key for codes which may not fit, false for those which don't:
+// more than 56 pegs of colors 0 and 1, 28 of colors up to 3, 14 of colors
+// up to 15, or 7 of any
+func (c Code) TryKey() (CodeKey, bool) {
+	var high byte
+	for _, v := range c {
+		high |= v
+	}
+	width, mode := uint(1), uint64(0)
+	for high>>width != 0 {
+		width, mode = width*2, mode+1
+	}
+	if uint(len(c))*width > 56 || len(c) > 63 {
+		return 0, false
 	}
-	return buf.String()
+	k := mode<<62 | uint64(len(c))<<56
+	for i, v := range c {
+		k |= uint64(v) << (uint(i) * width)
+	}
+	return CodeKey(k), true
 }
 
-type CodeSet map[string]Code
+// CodeSet is a set of codes, by their keys
+type CodeSet map[CodeKey]Code
 
 type CodeSlice []Code
 
 func (s CodeSlice) Less(i, j int) bool {
-	return s[i].String() < s[j].String()
+	return s[i].Compare(s[j]) < 0
 }
 
 func (s CodeSlice) Swap(i, j int) {
@@ -43,6 +102,18 @@ func (s CodeSlice) Len() int {
 	return len(s)
 }
 
+// Min is the first of the codes in order, found in one pass rather than
+// by sorting them, or nil if there are none
+func (s CodeSlice) Min() Code {
+	var min Code
+	for _, c := range s {
+		if min == nil || c.Compare(min) < 0 {
+			min = c
+		}
+	}
+	return min
+}
+
 type Result struct {
 	Correct     int
 	HalfCorrect int
@@ -52,17 +123,125 @@ func (r Result) String() string {
 	return fmt.Sprintf("%d-%d", r.Correct, r.HalfCorrect)
 }
 
+// ResultKey packs a result into a byte, the correct pegs in the high four
+// bits and the half correct in the low four, so results can index an array
+// rather than a map.  Results of boards of more than 15 pegs don't fit, and
+// Key panics on them.
+type ResultKey byte
+
+// Key packs r into its ResultKey
+func (r Result) Key() ResultKey {
+	if r.Correct < 0 || r.Correct > 15 || r.HalfCorrect < 0 || r.HalfCorrect > 15 {
+		panic(fmt.Sprintf("result %v is too big to key", r))
+	}
+	return ResultKey(r.Correct<<4 | r.HalfCorrect)
+}
+
+// Result unpacks the result k is the key of
+func (k ResultKey) Result() Result {
+	return Result{Correct: int(k >> 4), HalfCorrect: int(k & 15)}
+}
+
 type GameSize struct {
 	Positions int
 	Colors    byte
 }
 
+func (s GameSize) String() string {
+	return fmt.Sprintf("%dx%d", s.Positions, s.Colors)
+}
+
+// ParseGameSize parses a size written as positions x colors, eg 4x6
+func ParseGameSize(str string) (GameSize, error) {
+	var positions, colors int
+	var rest string
+	if n, _ := fmt.Sscanf(str, "%dx%d%s", &positions, &colors, &rest); n != 2 {
+		return GameSize{}, fmt.Errorf("size %q isn't of the form 4x6", str)
+	}
+	if positions < 1 || colors < 1 || colors > 255 {
+		return GameSize{}, fmt.Errorf("size %q is out of range", str)
+	}
+	return GameSize{Positions: positions, Colors: byte(colors)}, nil
+}
+
+// NumCodes is the number of distinct codes of this size
+func (s GameSize) NumCodes() int {
+	n := 1
+	for i := 0; i < s.Positions; i++ {
+		n *= int(s.Colors)
+	}
+	return n
+}
+
+// CodeAt returns the i'th code of this size, counting in base Colors with
+// the first position most significant
+func (s GameSize) CodeAt(i int) Code {
+	code := make(Code, s.Positions)
+	for pos := s.Positions - 1; pos >= 0; pos-- {
+		code[pos] = byte(i % int(s.Colors))
+		i /= int(s.Colors)
+	}
+	return code
+}
+
+// EnumerationGrain is the fewest codes worth a goroutine of their own when
+// every code of a board is gone through, as by AllCodes
+const EnumerationGrain = 1 << 16
+
+// AllCodes returns every code of this size, in index order.  The codes
+// share one allocation, each capped so appending to one can't change the
+// next.  Big boards are filled in on a goroutine per CPU, each counting
+// through its own range of indexes; Codes takes how many.
+func (s GameSize) AllCodes() CodeSlice {
+	return s.Codes(0, s.NumCodes(), 0)
+}
+
+// Codes returns the codes of this size with indexes from lo up to hi, as
+// AllCodes does for every code, on at most workers goroutines, or one per
+// CPU if workers is zero.  With one worker, they're all filled in on the
+// calling goroutine.
+func (s GameSize) Codes(lo, hi, workers int) CodeSlice {
+	codes := make(CodeSlice, hi-lo)
+	pegs := make([]byte, (hi-lo)*s.Positions)
+	pool.Ranges(hi-lo, pool.Workers(workers), EnumerationGrain, func(_, from, to int) {
+		next := s.CodeAt(lo + from)
+		for i := from; i < to; i++ {
+			code := pegs[i*s.Positions : (i+1)*s.Positions : (i+1)*s.Positions]
+			copy(code, next)
+			codes[i] = code
+
+			// count up an odometer, the last position turning fastest
+			for pos := s.Positions - 1; pos >= 0; pos-- {
+				next[pos]++
+				if next[pos] < s.Colors {
+					break
+				}
+				next[pos] = 0
+			}
+		}
+	})
+	return codes
+}
+
+// Index is the inverse of GameSize.CodeAt
+func (c Code) Index(colors byte) int {
+	i := 0
+	for _, v := range c {
+		i = i*int(colors) + int(v)
+	}
+	return i
+}
+
 type Game struct {
 	TurnsTaken int
 	Size       GameSize
 	secretCode Code
 	startTime  time.Time
 	SolveTime  time.Duration
+	// Feedback is how much ScoredGuess reveals; full by default
+	Feedback Feedback
+	// Quiet stops the game announcing wins on stdout
+	Quiet bool
 }
 
 func NewGame() *Game {
@@ -112,6 +291,11 @@ func (g *Game) Reset() {
 	g.startTime = time.Now()
 }
 
+// Elapsed is the time since the game started or was last reset
+func (g *Game) Elapsed() time.Duration {
+	return time.Now().Sub(g.startTime)
+}
+
 func (g *Game) Positions() int {
 	return g.Size.Positions
 }
@@ -143,6 +327,11 @@ func (g *Game) setSecretCode(c Code) {
 	g.secretCode = c
 }
 
+// Secret reveals the secret code, eg once the game is lost
+func (g *Game) Secret() Code {
+	return append(Code{}, g.secretCode...)
+}
+
 func (g *Game) IsWin(r Result) bool {
 	return r.Correct == g.Positions() && r.HalfCorrect == 0
 }
@@ -155,23 +344,6 @@ func (g *Game) isCorrect(code Code, position int) bool {
 	return code[position] == g.secretCode[position]
 }
 
-func countColors(code Code, color byte) int {
-	count := 0
-	for _, v := range code {
-		if v == color {
-			count++
-		}
-	}
-	return count
-}
-
-func min(x, y int) int {
-	if y < x {
-		return y
-	}
-	return x
-}
-
 func (game *Game) GuessString(guess string) (Result, error) {
 	code, err := game.Code(guess)
 	if err != nil {
@@ -182,14 +354,16 @@ func (game *Game) GuessString(guess string) (Result, error) {
 
 func (game *Game) ScoredGuess(code Code) (Result, error) {
 	game.TurnsTaken++
-	result, err := CheckCode(code, game.secretCode, game.Colors())
+	result, err := game.Feedback.Score(code, game.secretCode, game.Colors())
 	if err != nil {
 		return result, err
 	}
 
 	if game.IsWin(result) && game.IsWinner(code) {
 		game.SolveTime = time.Now().Sub(game.startTime)
-		fmt.Printf("%s is a winner; solved in %d moves (%v)\n", code, game.TurnsTaken, game.SolveTime)
+		if !game.Quiet {
+			fmt.Printf("%s is a winner; solved in %d moves (%v)\n", code, game.TurnsTaken, game.SolveTime)
+		}
 		return result, nil
 	}
 
@@ -201,35 +375,114 @@ func (game *Game) ScoredGuess(code Code) (Result, error) {
 	return result, err
 }
 
+// CheckCode scores guess against actual: the pegs of the right color in
+// the right place, and those of a right color in the wrong place
 func CheckCode(guess, actual Code, colors byte) (Result, error) {
 	if len(guess) != len(actual) {
 		return Result{}, fmt.Errorf("codes are not equal length")
 	}
+	return Score(guess, actual, colors), nil
+}
 
-	// for each possible color, how many exist in the guess? how many in the secret?
-	// the minimum of these two numbers is the sum of the correct and half-correct
-	// counts for that color.
-
-	// correct counts are easy; for each position, is it correct? sum these.
-
-	// half-correct counts are the total quasi-correct counts minus the full correct count
+// Score is CheckCode for codes known to be the same length, for hot loops
+// which would otherwise check every pair.  It counts the colors in one pass
+// over the pegs, and allocates nothing.  Colors from colors on are never half correct.
+// The default 4x6 game is scored by score4x6.
+func Score(guess, actual Code, colors byte) Result {
+	if colors == defaultColors && len(guess) == defaultPositions && len(actual) == defaultPositions {
+		return score4x6(guess, actual)
+	}
+	return scoreAny(guess, actual, colors)
+}
 
+// scoreAny is Score for codes of any size
+func scoreAny(guess, actual Code, colors byte) Result {
+	// the colors of the pegs not in the right place, in each code; a byte
+	// holds the count of any code of fewer than 256 pegs
+	var inGuess, inActual [256]byte
 	correct := 0
-	halfCorrect := 0
-
-	for i, _ := range guess {
-		if guess[i] == actual[i] {
+	for i, g := range guess {
+		a := actual[i]
+		if g == a {
 			correct++
+			continue
 		}
+		inGuess[g]++
+		inActual[a]++
 	}
 
-	for i := byte(0); i < colors; i++ {
-		x := countColors(guess, i)
-		y := countColors(actual, i)
-		halfCorrect += min(x, y)
+	// a color in the wrong place is half correct as many times as it's in
+	// both codes
+	halfCorrect := 0
+	for c := 0; c < int(colors); c++ {
+		n := inGuess[c]
+		if inActual[c] < n {
+			n = inActual[c]
+		}
+		halfCorrect += int(n)
+	}
+	return Result{correct, halfCorrect}
+}
+
+// CheckCodes scores guess against each of secrets, as CheckCode would, in
+// one loop.  The guess's colors are counted once rather than for every
+// secret: the pegs two codes have in common, less those in the right place,
+// are the half correct ones.
+func CheckCodes(guess Code, secrets []Code, colors byte) ([]Result, error) {
+	for _, s := range secrets {
+		if len(s) != len(guess) {
+			return nil, fmt.Errorf("codes are not equal length")
+		}
+	}
+	if colors == defaultColors && len(guess) == defaultPositions {
+		return checkCodes4x6(guess, secrets), nil
 	}
 
-	halfCorrect -= correct
+	// the colors of the guess which can be half correct, and how many of
+	// each it has
+	var inGuess, inSecret [256]byte
+	var scratch [32]byte
+	distinct := scratch[:0]
+	for _, g := range guess {
+		if g >= colors {
+			continue
+		}
+		if inGuess[g] == 0 {
+			distinct = append(distinct, g)
+		}
+		inGuess[g]++
+	}
+	// whether a peg in the right place counts to the common ones, as a
+	// number to save a branch
+	var countable [256]int
+	for c := 0; c < int(colors); c++ {
+		countable[c] = 1
+	}
 
-	return Result{correct, halfCorrect}, nil
+	results := make([]Result, len(secrets))
+	for i, secret := range secrets {
+		g := guess[:len(secret)]
+		correct, counted := 0, 0
+		for j, a := range secret {
+			if g[j] == a {
+				correct++
+				counted += countable[a]
+			}
+			inSecret[a]++
+		}
+		common := 0
+		for _, c := range distinct {
+			n := inGuess[c]
+			if m := inSecret[c]; m < n {
+				n = m
+			}
+			common += int(n)
+		}
+		// only the counts of the secret's own pegs need clearing
+		for _, a := range secret {
+			inSecret[a] = 0
+		}
+		results[i] = Result{correct, common - counted}
+	}
+	return results, nil
 }
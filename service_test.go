@@ -1,6 +1,13 @@
 package mastermind
 
-import "testing"
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestGuessLogic(t *testing.T) {
 	game := NewGame()
@@ -26,3 +33,619 @@ func TestGuessLogic(t *testing.T) {
 		}
 	}
 }
+
+func TestHistoryConsistent(t *testing.T) {
+	history := History{
+		{Code{1, 2, 3, 4}, Result{1, 2}},
+		{Code{5, 4, 3, 1}, Result{3, 0}},
+	}
+
+	if !history.Consistent(Code{5, 4, 3, 2}, defaultColors) {
+		t.Errorf("5432 should be consistent with %v", history)
+	}
+	if history.Consistent(Code{5, 4, 3, 1}, defaultColors) {
+		t.Errorf("5431 should not be consistent with %v", history)
+	}
+	if !(History{}).Consistent(Code{0, 0, 0, 0}, defaultColors) {
+		t.Error("every code is consistent with an empty history")
+	}
+}
+
+func TestCodeIndex(t *testing.T) {
+	size := GameSize{4, 6}
+	codes := size.AllCodes()
+	if len(codes) != 1296 {
+		t.Fatalf("expected 1296 codes, got %d", len(codes))
+	}
+	for i, c := range codes {
+		if c.Index(size.Colors) != i {
+			t.Errorf("code %s at %d has index %d", c, i, c.Index(size.Colors))
+		}
+	}
+	if codes[0].String() != "0000" || codes[1295].String() != "5555" || codes[7].String() != "0011" {
+		t.Errorf("codes out of order: %s, %s, %s", codes[0], codes[7], codes[1295])
+	}
+	// 6x7 is big enough to be split between CPUs, if there are several,
+	// unless there's to be one worker
+	for _, size := range []GameSize{{3, 7}, {5, 2}, {1, 9}, {6, 7}} {
+		serial := size.Codes(0, size.NumCodes(), 1)
+		for i, c := range size.AllCodes() {
+			if c.Compare(size.CodeAt(i)) != 0 || serial[i].Compare(c) != 0 {
+				t.Errorf("%v code %d is %s, and %s serially, expected %s", size, i, c, serial[i], size.CodeAt(i))
+			}
+		}
+	}
+
+	if fmt.Sprint(size.Codes(7, 12, 1)) != fmt.Sprint(codes[7:12]) {
+		t.Errorf("expected codes 7 to 12 to be %v, got %v", codes[7:12], size.Codes(7, 12, 1))
+	}
+
+	// appending to a code leaves the next alone
+	_ = append(codes[0], 5)
+	if codes[1].String() != "0001" {
+		t.Errorf("appending to 0000 changed the next code to %s", codes[1])
+	}
+}
+
+func BenchmarkAllCodes(b *testing.B) {
+	size := GameSize{Positions: 5, Colors: 8}
+	for i := 0; i < b.N; i++ {
+		size.AllCodes()
+	}
+}
+
+// guesses the first code consistent with every result so far
+type firstConsistent struct {
+	cm Codemaker
+}
+
+func (s firstConsistent) Solve() (Code, error) {
+	history := History{}
+	for _, c := range s.cm.GameSize().AllCodes() {
+		if !history.Consistent(c, s.cm.GameSize().Colors) {
+			continue
+		}
+		r, err := s.cm.ScoredGuess(c)
+		if err != nil {
+			return nil, err
+		}
+		if r.Correct == len(c) {
+			return c, nil
+		}
+		history = append(history, Move{c, r})
+	}
+	return nil, fmt.Errorf("no consistent code left")
+}
+
+func TestEvilCodemaker(t *testing.T) {
+	size := GameSize{4, 6}
+	evil := NewEvilCodemaker(size)
+	winner, err := firstConsistent{evil}.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evil.Candidates()) != 1 || evil.Candidates()[0].String() != winner.String() {
+		t.Errorf("evil codemaker should be down to %s, has %v", winner, evil.Candidates())
+	}
+	if evil.Eliminated(winner) != 0 {
+		t.Errorf("%s was eliminated on turn %d", winner, evil.Eliminated(winner))
+	}
+
+	// the same secret, fixed in advance, takes as long
+	game := NewCustomGameWithSecret(4, 6, winner)
+	firstConsistent{game}.Solve()
+	if game.TurnsTaken != evil.TurnsTaken {
+		t.Errorf("%s took %d moves, evil codemaker took %d", winner, game.TurnsTaken, evil.TurnsTaken)
+	}
+}
+
+func TestCounterexample(t *testing.T) {
+	newSolver := func(cm Codemaker) Solver { return firstConsistent{cm} }
+	size := GameSize{3, 4}
+
+	worst, moves, err := Counterexample(size, newSolver, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range size.AllCodes() {
+		game := NewCustomGameWithSecret(size.Positions, size.Colors, c)
+		newSolver(game).Solve()
+		if game.TurnsTaken > moves {
+			t.Errorf("%s takes %d moves, worse than counterexample %s in %d", c, game.TurnsTaken, worst, moves)
+		}
+	}
+
+	// with a limit the search stops at the first secret reaching it
+	_, limited, err := Counterexample(size, newSolver, moves-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limited < moves-1 {
+		t.Errorf("limited search returned %d moves, expected at least %d", limited, moves-1)
+	}
+}
+
+func TestCountMoves(t *testing.T) {
+	size := GameSize{3, 4}
+	counts, err := CountMoves(size, func(cm Codemaker) Solver { return firstConsistent{cm} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	moves, distribution := 0, map[int]int{}
+	for _, c := range size.AllCodes() {
+		game := NewCustomGameWithSecret(size.Positions, size.Colors, c)
+		game.Quiet = true
+		firstConsistent{game}.Solve()
+		moves += game.TurnsTaken
+		distribution[game.TurnsTaken]++
+	}
+	if counts.Secrets != 64 || counts.Moves != moves || counts.Expected() != float64(moves)/64 || len(counts.Distribution) != len(distribution) {
+		t.Fatalf("expected %d moves over 64 secrets as %v, got %v", moves, distribution, counts)
+	}
+	for n, c := range distribution {
+		if counts.Distribution[n] != c || n > counts.Worst() {
+			t.Errorf("expected %d secrets in %d moves, at most %d, got %d", c, n, counts.Worst(), counts.Distribution[n])
+		}
+	}
+
+	if _, err := CountMoves(size, func(cm Codemaker) Solver { return firstConsistent{NewEvilCodemaker(size)} }); err == nil {
+		t.Error("expected a solver which doesn't break its secrets to fail")
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	size, secret := GameSize{4, 6}, Code{0, 1, 2, 3}
+	var h History
+	for _, guess := range []Code{{0, 0, 1, 1}, {0, 1, 0, 2}, {0, 1, 2, 3}} {
+		h = append(h, Move{guess, Score(guess, secret, 6)})
+	}
+	steps, err := Analyze(size, FullFeedback, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := 1296
+	for i, s := range steps {
+		after := 0
+		for _, c := range size.AllCodes() {
+			if h[:i+1].Consistent(c, 6) {
+				after++
+			}
+		}
+		if s.Before != before || s.After != after || math.Abs(s.Bits-math.Log2(float64(before)/float64(after))) > 1e-9 {
+			t.Errorf("move %d: expected %d codes narrowed to %d, got %+v", i+1, before, after, s)
+		}
+		if s.Expected <= 0 || s.Expected > math.Log2(14) {
+			t.Errorf("move %d: expected bits %v are out of range", i+1, s.Expected)
+		}
+		before = after
+	}
+	// the game is won, so every bit of the board is learned
+	if last := steps[2]; last.After != 1 || math.Abs(last.Total-math.Log2(1296)) > 1e-9 {
+		t.Errorf("expected the secret found with %v bits, got %+v", math.Log2(1296), last)
+	}
+
+	out := &bytes.Buffer{}
+	if err := WriteStepsCSV(out, steps); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 4 || !strings.HasPrefix(lines[3], "3,0123,4-0,") {
+		t.Errorf("expected a header and three rows, got\n%s", out)
+	}
+
+	h[1].Result = Result{0, 0}
+	if _, err := Analyze(size, FullFeedback, h); err == nil {
+		t.Error("expected moves no code scores refused")
+	}
+}
+
+func TestReducedFeedback(t *testing.T) {
+	game := NewGame()
+	game.setSecretCode([]byte{5, 4, 3, 2})
+
+	guesses := map[string]map[Feedback]Result{
+		"1234": {TotalFeedback: Result{0, 3}, BlackFeedback: Result{1, 0}},
+		"2345": {TotalFeedback: Result{0, 4}, BlackFeedback: Result{0, 0}},
+		"5432": {TotalFeedback: Result{4, 0}, BlackFeedback: Result{4, 0}},
+	}
+
+	for guess, expected := range guesses {
+		for f, r := range expected {
+			game.Feedback = f
+			result, err := game.GuessString(guess)
+			if err != nil {
+				t.Errorf("guess %s generated error: %v", guess, err)
+			}
+			if result != r {
+				t.Errorf("for guess %s with %v feedback, got %s, expected %s", guess, f, result, r)
+			}
+		}
+	}
+}
+
+func TestParseGameSize(t *testing.T) {
+	for _, s := range []string{"4x6", "8x12", "1x1"} {
+		size, err := ParseGameSize(s)
+		if err != nil {
+			t.Error(err)
+		}
+		if size.String() != s {
+			t.Errorf("%s parsed as %v", s, size)
+		}
+	}
+	for _, s := range []string{"", "4", "4x", "x6", "4x6x2", "0x6", "4x0", "4x300", "four by six"} {
+		if _, err := ParseGameSize(s); err == nil {
+			t.Errorf("parsed invalid size %q", s)
+		}
+	}
+}
+
+func TestDaily(t *testing.T) {
+	size := GameSize{Positions: 4, Colors: 6}
+	morning := time.Date(2026, time.March, 8, 1, 0, 0, 0, time.UTC)
+	evening := time.Date(2026, time.March, 8, 23, 0, 0, 0, time.UTC)
+	if DailySecret(size, morning).String() != DailySecret(size, evening).String() {
+		t.Error("expected the same secret all day")
+	}
+	if DailySecret(size, morning).String() == DailySecret(size, morning.AddDate(0, 0, 1)).String() &&
+		DailySecret(size, morning).String() == DailySecret(size, morning.AddDate(0, 0, 2)).String() {
+		t.Error("expected the secret to change from day to day")
+	}
+	if len(DailySecret(GameSize{Positions: 5, Colors: 8}, morning)) != 5 {
+		t.Error("expected a secret of the size asked for")
+	}
+	if SaltedDailySecret(size, morning, "").String() != DailySecret(size, morning).String() {
+		t.Error("expected no salt to be the unsalted secret")
+	}
+	if SaltedDailySecret(size, morning, "pepper").String() != SaltedDailySecret(size, evening, "pepper").String() {
+		t.Error("expected the same salted secret all day")
+	}
+	salted := 0
+	for d := 0; d < 5; d++ {
+		day := morning.AddDate(0, 0, d)
+		if SaltedDailySecret(size, day, "pepper").String() != DailySecret(size, day).String() {
+			salted++
+		}
+	}
+	if salted == 0 {
+		t.Error("expected the salt to change the secret")
+	}
+
+	if n := DailyNumber(DailyEpoch); n != 1 {
+		t.Errorf("expected the epoch to be #1, got %d", n)
+	}
+	if n := DailyNumber(time.Date(2024, time.January, 31, 23, 0, 0, 0, time.FixedZone("", -5*3600))); n != 31 {
+		t.Errorf("expected the date to be taken in its location, got #%d", n)
+	}
+
+	h := History{
+		{Guess: Code{0, 0, 1, 1}, Result: Result{Correct: 1, HalfCorrect: 1}},
+		{Guess: Code{0, 1, 2, 3}, Result: Result{Correct: 4}},
+	}
+	want := "Mastermind #7 4x6 2/10\n🟩🟨⬜⬜\n🟩🟩🟩🟩\n"
+	if got := ShareGrid(size, 7, h, 10); got != want {
+		t.Errorf("expected\n%s got\n%s", want, got)
+	}
+	if got := ShareGrid(size, 0, h[:1], 10); got != "Mastermind 4x6 X/10\n🟩🟨⬜⬜\n" {
+		t.Errorf("unexpected grid for an unsolved game\n%s", got)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	size := GameSize{Positions: 4, Colors: 6}
+	secret := Code{0, 1, 2, 3}
+	start := time.Date(2026, time.March, 8, 12, 0, 0, 0, time.UTC)
+	r := NewReplay(size, secret, "abc", start)
+	for i, guess := range []Code{{0, 0, 1, 1}, {0, 1, 2, 3}} {
+		result, _ := CheckCode(guess, secret, size.Colors)
+		r.Add(Move{Guess: guess, Result: result}, start.Add(time.Duration(i+1)*1500*time.Millisecond))
+	}
+	if err := r.Verify(); err != nil || !r.Solved() {
+		t.Fatalf("expected a solved, verified replay: %v", err)
+	}
+
+	var hidden, revealed strings.Builder
+	r.Write(&hidden, false)
+	r.Write(&revealed, true)
+	if strings.Contains(hidden.String(), "secret") || !strings.Contains(revealed.String(), "secret 0123\n") {
+		t.Errorf("unexpected replays\n%s\n%s", hidden.String(), revealed.String())
+	}
+
+	read, err := ReadReplay(strings.NewReader(revealed.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Size != size || !read.Started.Equal(start) || len(read.Moves) != 2 || read.Moves[1].At != 3*time.Second {
+		t.Errorf("unexpected replay read %+v", read)
+	}
+	if err := read.Verify(); err != nil {
+		t.Error(err)
+	}
+	read, _ = ReadReplay(strings.NewReader(hidden.String()))
+	if read.Secret != nil || read.Verify() == nil {
+		t.Error("expected the secret to be unrevealed")
+	}
+
+	// a secret which isn't the one committed to, and a result tampered with
+	tampered := strings.Replace(revealed.String(), "secret 0123", "secret 0132", 1)
+	if read, _ = ReadReplay(strings.NewReader(tampered)); read.Verify() == nil {
+		t.Error("expected a different secret to fail")
+	}
+	tampered = strings.Replace(revealed.String(), "move 0011 1-1", "move 0011 2-0", 1)
+	if read, _ = ReadReplay(strings.NewReader(tampered)); read.Verify() == nil {
+		t.Error("expected a tampered result to fail")
+	}
+
+	for _, bad := range []string{"", "mmr 2\n", "mmr 1\nsize 4x6\n", "mmr 1\nmove 0011 1-1 1\n", "mmr 1\nsize 4x6\ncommit x\nmove 0011 1 1\n"} {
+		if _, err := ReadReplay(strings.NewReader(bad)); err == nil {
+			t.Errorf("read bad replay %q", bad)
+		}
+	}
+}
+
+func TestSolveAll(t *testing.T) {
+	newSolver := func(cm Codemaker) Solver { return firstConsistent{cm} }
+	size := GameSize{3, 3}
+	results := SolveAll(size, newSolver)
+	if len(results) != 27 {
+		t.Fatalf("expected every secret played, got %d", len(results))
+	}
+	for _, r := range results {
+		last := r.Moves[len(r.Moves)-1]
+		if !r.Solved || r.Err != nil || last.Guess.String() != r.Secret.String() || last.Result != (Result{3, 0}) {
+			t.Errorf("%v: expected a win, got %+v", r.Secret, r)
+		}
+	}
+
+	var buf strings.Builder
+	if err := WriteResultsCSV(&buf, "first", "", size, results[:2]); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	header := "solver,heuristic,size,secret,moves,seconds,solved,error"
+	for i := 1; i <= len(results[1].Moves); i++ {
+		header += fmt.Sprintf(",guess_%d", i)
+	}
+	if lines[0] != header || !strings.HasPrefix(lines[1], "first,,3x3,000,1,") {
+		t.Errorf("unexpected CSV\n%s", buf.String())
+	}
+}
+
+// checkCodeByColor is CheckCode as it was, counting each color of both
+// codes in turn, to check Score against and measure it by
+func checkCodeByColor(guess, actual Code, colors byte) Result {
+	count := func(code Code, color byte) int {
+		n := 0
+		for _, v := range code {
+			if v == color {
+				n++
+			}
+		}
+		return n
+	}
+	correct, halfCorrect := 0, 0
+	for i := range guess {
+		if guess[i] == actual[i] {
+			correct++
+		}
+	}
+	for c := byte(0); c < colors; c++ {
+		x, y := count(guess, c), count(actual, c)
+		if y < x {
+			x = y
+		}
+		halfCorrect += x
+	}
+	return Result{correct, halfCorrect - correct}
+}
+
+func TestScore(t *testing.T) {
+	for _, size := range []GameSize{{4, 6}, {3, 8}, {5, 3}} {
+		codes := size.AllCodes()
+		for _, guess := range codes {
+			for _, actual := range codes {
+				expected := checkCodeByColor(guess, actual, size.Colors)
+				if r := Score(guess, actual, size.Colors); r != expected {
+					t.Fatalf("%v against %v scored %v, expected %v", guess, actual, r, expected)
+				}
+			}
+		}
+	}
+	if _, err := CheckCode(Code{0, 1, 2}, Code{0, 1, 2, 3}, 6); err == nil {
+		t.Error("expected codes of different lengths refused")
+	}
+	if n := testing.AllocsPerRun(100, func() { CheckCode(Code{0, 1, 2, 3}, Code{3, 2, 1, 0}, 6) }); n != 0 {
+		t.Errorf("expected CheckCode not to allocate, got %v allocations", n)
+	}
+}
+
+func TestScore4x6(t *testing.T) {
+	// 4x8 codes have colors 4x6 ones don't, which are never half correct
+	codes := GameSize{4, 8}.AllCodes()
+	for _, guess := range codes {
+		for _, actual := range codes {
+			if r, expected := score4x6(guess, actual), scoreAny(guess, actual, 6); r != expected {
+				t.Fatalf("%v against %v scored %v, expected %v", guess, actual, r, expected)
+			}
+		}
+	}
+}
+
+func TestCheckCodes(t *testing.T) {
+	// 4x8 codes scored as 4x6 ones have colors which are never half correct
+	for _, size := range []GameSize{{4, 6}, {3, 8}, {5, 3}, {4, 8}} {
+		codes := size.AllCodes()
+		colors := size.Colors
+		if colors == 8 && size.Positions == 4 {
+			colors = 6
+		}
+		for _, guess := range codes {
+			results, err := CheckCodes(guess, codes, colors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, actual := range codes {
+				if expected := Score(guess, actual, colors); results[i] != expected {
+					t.Fatalf("%v against %v scored %v, expected %v", guess, actual, results[i], expected)
+				}
+			}
+		}
+	}
+	if _, err := CheckCodes(Code{0, 1, 2}, []Code{{0, 1, 2}, {0, 1, 2, 3}}, 6); err == nil {
+		t.Error("expected codes of different lengths refused")
+	}
+}
+
+// the codes scored by the benchmarks, in pairs of the first 1024 4x6 codes
+var benchCodes = GameSize{Positions: 4, Colors: 6}.AllCodes()[:1024]
+
+func BenchmarkCheckCode(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		CheckCode(benchCodes[i&1023], benchCodes[i>>10&1023], 6)
+	}
+}
+
+func BenchmarkScore(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Score(benchCodes[i&1023], benchCodes[i>>10&1023], 6)
+	}
+}
+
+// one guess against all 1024 codes, for comparison with 1024 Scores
+func BenchmarkCheckCodes(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		CheckCodes(benchCodes[i&1023], benchCodes, 6)
+	}
+}
+
+func BenchmarkCheckCodeByColor(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		checkCodeByColor(benchCodes[i&1023], benchCodes[i>>10&1023], 6)
+	}
+}
+
+func TestCodeKey(t *testing.T) {
+	seen := map[CodeKey]Code{}
+	for _, size := range []GameSize{{4, 6}, {3, 6}, {2, 200}, {14, 2}, {4, 16}, {1, 3}} {
+		for _, c := range size.AllCodes() {
+			if other, ok := seen[c.Key()]; ok && other.String() != c.String() {
+				t.Fatalf("%v and %v share the key %x", c, other, c.Key())
+			}
+			seen[c.Key()] = c
+			if k, ok := c.TryKey(); !ok || k != c.Key() {
+				t.Fatalf("expected TryKey to key %v as Key does, got %x %v", c, k, ok)
+			}
+		}
+	}
+	for _, c := range []Code{make(Code, 29), {16, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, make(Code, 8, 8)} {
+		c[len(c)-1] = 200
+		if _, ok := c.TryKey(); ok {
+			t.Errorf("expected TryKey not to key %v", c)
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected %v to be too long to key", c)
+				}
+			}()
+			c.Key()
+		}()
+	}
+}
+
+func TestCodeStringAllocs(t *testing.T) {
+	code := Code{1, 2, 3, 4, 5, 6}
+	if allocs := testing.AllocsPerRun(100, func() { _ = code.String() }); allocs > 1 {
+		t.Errorf("expected String to allocate at most its string, got %v allocations", allocs)
+	}
+}
+
+func TestCodeSliceMin(t *testing.T) {
+	codes := CodeSlice{{2, 1, 0}, {0, 3, 1}, {1, 0, 0}, {0, 3, 0}, {0, 3, 1}}
+	if min := codes.Min(); min.String() != "030" {
+		t.Errorf("expected 030, got %v", min)
+	}
+	if min := (CodeSlice{}).Min(); min != nil {
+		t.Errorf("expected no codes to have no min, got %v", min)
+	}
+}
+
+func TestResultKey(t *testing.T) {
+	seen := map[ResultKey]bool{}
+	for correct := 0; correct <= 15; correct++ {
+		for half := 0; correct+half <= 15; half++ {
+			r := Result{correct, half}
+			if seen[r.Key()] || r.Key().Result() != r {
+				t.Fatalf("%v keyed as %x, which unpacks to %v", r, r.Key(), r.Key().Result())
+			}
+			seen[r.Key()] = true
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected 16 pegs to be too many to key")
+		}
+	}()
+	Result{16, 0}.Key()
+}
+
+func BenchmarkCodeKey(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		benchCodes[i&1023].Key()
+	}
+}
+
+func BenchmarkCodeString(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = benchCodes[i&1023].String()
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	size, secret := GameSize{4, 6}, Code{5, 4, 3, 2}
+	var h History
+	for _, guess := range []Code{{0, 0, 1, 1}, {0, 0, 0, 0}, {1, 1, 2, 2}, {5, 4, 3, 2}} {
+		h = append(h, Move{guess, Score(guess, secret, 6)})
+	}
+	notes, err := Annotate(size, FullFeedback, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 0000 only repeats what 0011 said; 1122 is worse than 2234, which
+	// leaves 46 codes at worst, but better than nothing; 5432 wins, but
+	// leaves 14 codes at worst where 0323 leaves 8
+	expected := []Quality{Optimal, Blunder, Blunder, Inaccuracy}
+	for i, a := range notes {
+		if a.Quality != expected[i] {
+			t.Errorf("move %d: expected %v, got %v", i+1, expected[i], a)
+		}
+		if a.Worst < a.BestWorst || a.Expected < a.BestExpected {
+			t.Errorf("move %d: expected the best no worse than the guess, got %v", i+1, a)
+		}
+	}
+	if notes[0].Worst != 256 || notes[1].Worst != 256 || notes[1].Best.String() != "2234" || notes[1].BestWorst != 46 {
+		t.Errorf("expected 0000 to leave all 256 codes of 0011, where 2234 leaves 46, got %v and %v", notes[0], notes[1])
+	}
+
+	// with one code left, guessing it is all there is, and guessing
+	// anything else a blunder
+	for _, c := range []struct {
+		guess   Code
+		quality Quality
+	}{{Code{1, 0}, Optimal}, {Code{0, 0}, Blunder}} {
+		last, err := Annotate(GameSize{2, 2}, FullFeedback, History{{Code{0, 1}, Result{0, 2}}, {c.guess, Score(c.guess, Code{1, 0}, 2)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a := last[1]; a.Quality != c.quality || a.BestWorst != 0 || a.Best.String() != "10" {
+			t.Errorf("expected %v %v with one code left, got %v", c.guess, c.quality, a)
+		}
+	}
+
+	h[1].Result = Result{1, 0}
+	if _, err := Annotate(size, FullFeedback, h); err == nil {
+		t.Error("expected moves no code scores refused")
+	}
+	if _, err := Annotate(GameSize{8, 8}, FullFeedback, h); err == nil {
+		t.Error("expected a board too big refused")
+	}
+}
@@ -0,0 +1,94 @@
+// Package simanneal solves mastermind by simulated annealing.  Each move it
+// walks the space of codes, recoloring one position at a time, towards a
+// code consistent with every result so far, rating codes with the same
+// fitness function as the genetic solver.  A worse code is accepted with
+// probability exp(-delta/T), and the temperature T cools geometrically, so
+// the walk roams freely at first and settles as it goes.  It has far fewer
+// knobs than the genetic solver, which makes it a useful point of comparison.
+package simanneal
+
+import (
+	"math"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/genetic"
+	"github.com/ianmcmahon/mastermind/internal/metaheuristic"
+)
+
+type Solver struct {
+	metaheuristic.Base
+
+	// Fitness rates codes; lower is fitter
+	Fitness genetic.FitnessFunction
+	// Temperature starts each move at InitialTemp and is multiplied by
+	// Cooling every step
+	InitialTemp float64
+	Cooling     float64
+	// Steps is the most steps taken looking for a consistent code each move.
+	// If none is found, the fittest code seen is guessed instead.
+	Steps int
+}
+
+func NewSolver(cm mm.Codemaker) *Solver {
+	s := &Solver{
+		Base:    metaheuristic.NewBase(cm),
+		Fitness: genetic.Berghman{A: 2, B: 2},
+		Cooling: 0.999,
+		Steps:   50000,
+	}
+	s.InitialTemp = float64(s.Size.Positions)
+	return s
+}
+
+func (s *Solver) Solve() (mm.Code, error) {
+	// the last guess is as good a place to start as any
+	return s.Search(func() mm.Code { return s.anneal(s.History[len(s.History)-1].Guess) })
+}
+
+// anneal walks from code towards one consistent with the history, returning
+// the first it finds, or the fittest seen if the steps run out
+func (s *Solver) anneal(code mm.Code) mm.Code {
+	current := make(mm.Code, len(code))
+	copy(current, code)
+	fitness := s.Fitness.Evaluate(current, s.History, s.Size)
+
+	best := make(mm.Code, len(code))
+	copy(best, current)
+	bestFitness := fitness
+
+	temp := s.InitialTemp
+	for step := 0; step < s.Steps; step++ {
+		if s.Consistent(current) {
+			return current
+		}
+
+		pos := s.Rand.Intn(s.Size.Positions)
+		old := current[pos]
+		current[pos] = s.otherColor(old)
+
+		f := s.Fitness.Evaluate(current, s.History, s.Size)
+		if delta := f - fitness; delta <= 0 || s.Rand.Float64() < math.Exp(-delta/temp) {
+			fitness = f
+			if f < bestFitness {
+				copy(best, current)
+				bestFitness = f
+			}
+		} else {
+			current[pos] = old
+		}
+		temp *= s.Cooling
+	}
+	return best
+}
+
+// a random color other than c, unless there's only the one
+func (s *Solver) otherColor(c byte) byte {
+	if s.Size.Colors < 2 {
+		return c
+	}
+	other := byte(s.Rand.Intn(int(s.Size.Colors) - 1))
+	if other >= c {
+		other++
+	}
+	return other
+}
@@ -0,0 +1,24 @@
+package simanneal
+
+import (
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestAnnealFindsConsistentCodes(t *testing.T) {
+	game := mm.NewCustomGameWithSecret(5, 8, mm.Code{1, 4, 0, 5, 3})
+	solver := NewSolver(game)
+	solver.Seed(1)
+	for _, guess := range []mm.Code{{0, 0, 1, 1, 2}, {3, 4, 5, 6, 7}, {1, 2, 3, 4, 5}} {
+		r, _ := game.ScoredGuess(guess)
+		solver.History = append(solver.History, mm.Move{Guess: guess, Result: r})
+	}
+
+	for i := 0; i < 10; i++ {
+		c := solver.anneal(solver.RandomCode())
+		if !solver.History.Consistent(c, 8) {
+			t.Errorf("%v isn't consistent with %v", c, solver.History)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package mastermind
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Recorder is a codemaker which keeps the moves made against it
+type Recorder struct {
+	Codemaker
+	Moves History
+}
+
+func (r *Recorder) ScoredGuess(code Code) (Result, error) {
+	result, err := r.Codemaker.ScoredGuess(code)
+	if err == nil {
+		r.Moves = append(r.Moves, Move{Guess: append(Code{}, code...), Result: result})
+	}
+	return result, err
+}
+
+// SecretResult is how a solver did against one secret
+type SecretResult struct {
+	Secret   Code
+	Moves    History
+	Duration time.Duration
+	Solved   bool
+	// Err is why the secret wasn't solved, if it wasn't
+	Err error
+}
+
+// Play has a solver made by newSolver break secret
+func Play(size GameSize, secret Code, newSolver SolverFunc) SecretResult {
+	game := NewCustomGameWithSecret(size.Positions, size.Colors, secret)
+	game.Quiet = true
+	rec := &Recorder{Codemaker: game}
+
+	start := time.Now()
+	winner, err := newSolver(rec).Solve()
+	res := SecretResult{Secret: secret, Moves: rec.Moves, Duration: time.Since(start), Err: err}
+	if err == nil {
+		if winner.String() == secret.String() {
+			res.Solved = true
+		} else {
+			res.Err = fmt.Errorf("answered %s", winner)
+		}
+	}
+	return res
+}
+
+// SolveAll has the solvers made by newSolver break every secret of size,
+// in order
+func SolveAll(size GameSize, newSolver SolverFunc) []SecretResult {
+	var results []SecretResult
+	for _, secret := range size.AllCodes() {
+		results = append(results, Play(size, secret, newSolver))
+	}
+	return results
+}
+
+// WriteResultsCSV writes a row for each result, with a header row, for
+// analysis in eg pandas or R.  The guesses made are in a column each, as
+// many as the longest game took, left empty past the end of shorter games.
+func WriteResultsCSV(w io.Writer, solver, heuristic string, size GameSize, results []SecretResult) error {
+	longest := 0
+	for _, r := range results {
+		if len(r.Moves) > longest {
+			longest = len(r.Moves)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	header := []string{"solver", "heuristic", "size", "secret", "moves", "seconds", "solved", "error"}
+	for i := 1; i <= longest; i++ {
+		header = append(header, fmt.Sprintf("guess_%d", i))
+	}
+	cw.Write(header)
+	for _, r := range results {
+		errText := ""
+		if r.Err != nil {
+			errText = r.Err.Error()
+		}
+		row := []string{
+			solver,
+			heuristic,
+			size.String(),
+			r.Secret.String(),
+			strconv.Itoa(len(r.Moves)),
+			strconv.FormatFloat(r.Duration.Seconds(), 'f', 6, 64),
+			strconv.FormatBool(r.Solved),
+			errText,
+		}
+		for i := 0; i < longest; i++ {
+			guess := ""
+			if i < len(r.Moves) {
+				guess = r.Moves[i].Guess.String()
+			}
+			row = append(row, guess)
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// MoveCounts is how many moves a strategy takes to win every secret of a
+// size, with no timing in it, for comparing strategies exactly
+type MoveCounts struct {
+	Size GameSize
+	// Secrets is how many secrets were played, and Moves the moves taken
+	// to win them all, so the expected moves are exactly Moves/Secrets
+	Secrets int
+	Moves   int
+	// Distribution counts the secrets by the moves they took
+	Distribution map[int]int
+}
+
+// Add counts a secret won in moves
+func (c *MoveCounts) Add(moves int) {
+	if c.Distribution == nil {
+		c.Distribution = map[int]int{}
+	}
+	c.Secrets++
+	c.Moves += moves
+	c.Distribution[moves]++
+}
+
+// Expected is the mean moves taken over every secret
+func (c *MoveCounts) Expected() float64 {
+	if c.Secrets == 0 {
+		return 0
+	}
+	return float64(c.Moves) / float64(c.Secrets)
+}
+
+// Worst is the most moves any secret took
+func (c *MoveCounts) Worst() int {
+	worst := 0
+	for moves := range c.Distribution {
+		if moves > worst {
+			worst = moves
+		}
+	}
+	return worst
+}
+
+func (c *MoveCounts) String() string {
+	return fmt.Sprintf("%v: %d/%d = %.4f moves expected, %d worst", c.Size, c.Moves, c.Secrets, c.Expected(), c.Worst())
+}
+
+// CountMoves has the solvers made by newSolver break every secret of size,
+// and counts the moves each took.  It fails on the first secret one
+// doesn't break.  A solver which plays a secret differently from one game
+// to the next, by chance, gives counts of only the games it played.
+func CountMoves(size GameSize, newSolver SolverFunc) (*MoveCounts, error) {
+	counts := &MoveCounts{Size: size}
+	for _, secret := range size.AllCodes() {
+		res := Play(size, secret, newSolver)
+		if !res.Solved {
+			return nil, fmt.Errorf("secret %v wasn't solved: %v", secret, res.Err)
+		}
+		counts.Add(len(res.Moves))
+	}
+	return counts, nil
+}
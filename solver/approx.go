@@ -0,0 +1,308 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/fixed"
+)
+
+// ApproxSolver plays boards too big to score exactly, like 8x10 and up.
+// Neither S nor P is ever enumerated: each move, a random sample of the
+// codes consistent with the game so far stands in for S, and each candidate
+// guess is rated by the partition it makes of the sample.  With a sample of
+// n codes, the estimated fraction of S left by a partition is within
+// z*sqrt(p(1-p)/n) of the truth at the configured Confidence, and guesses
+// are rated by the upper end of that interval (a Wilson score interval) for
+// their largest partition.  Once S is no bigger than the sample, it is
+// enumerated outright and the estimates become exact.
+type ApproxSolver struct {
+	codemaker mm.Codemaker
+	size      mm.GameSize
+	rand      *rand.Rand
+
+	// SampleSize is the number of consistent codes sampled each move
+	SampleSize int
+	// Candidates is the number of random codes rated as guesses each move,
+	// in addition to the sampled consistent codes
+	Candidates int
+	// Confidence is the confidence level for partition size estimates
+	Confidence float64
+	// MaxMoves is how many moves to make before giving up
+	MaxMoves int
+	// Feedback is how much the codemaker reveals about each guess
+	Feedback mm.Feedback
+	// Engine, if set, finds the consistent codes sampled each move in place
+	// of the solver's own depth first search, eg a SATEngine
+	Engine ConsistencyEngine
+
+	History    mm.History
+	TurnsTaken int
+	SolveTime  time.Duration
+}
+
+// DefaultSampleSize and DefaultCandidates are the SampleSize and Candidates
+// of a new ApproxSolver
+const (
+	DefaultSampleSize = 1000
+	DefaultCandidates = 200
+)
+
+func NewApproxSolver(cm mm.Codemaker) *ApproxSolver {
+	size := cm.GameSize()
+	return &ApproxSolver{
+		codemaker:  cm,
+		size:       size,
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		SampleSize: DefaultSampleSize,
+		Candidates: DefaultCandidates,
+		Confidence: 0.95,
+		MaxMoves:   size.Positions * int(size.Colors),
+	}
+}
+
+// Seed makes the solver's sampling reproducible
+func (a *ApproxSolver) Seed(seed int64) {
+	a.rand = rand.New(rand.NewSource(seed))
+}
+
+func (a *ApproxSolver) Solve() (mm.Code, error) {
+	start := time.Now()
+	for a.TurnsTaken < a.MaxMoves {
+		sample, exact, err := a.sampleConsistent()
+		if err != nil {
+			return nil, err
+		}
+		if len(sample) == 0 {
+			return nil, fmt.Errorf("no code is consistent with %v", a.History)
+		}
+
+		guess := a.bestGuess(sample, exact)
+		result, err := a.codemaker.ScoredGuess(guess)
+		if err != nil {
+			return nil, err
+		}
+		a.TurnsTaken++
+		if result.Correct == a.size.Positions {
+			a.SolveTime = time.Since(start)
+			return guess, nil
+		}
+		a.History = append(a.History, mm.Move{Guess: guess, Result: result})
+	}
+	return nil, fmt.Errorf("didn't find solution in %d moves", a.TurnsTaken)
+}
+
+// z score for a two sided interval at confidence c
+func zScore(c float64) float64 {
+	lo, hi := 0.0, 10.0
+	for i := 0; i < 50; i++ {
+		mid := (lo + hi) / 2
+		if math.Erf(mid/math.Sqrt2) < c {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// upper end of the Wilson score interval for a proportion p observed in n
+// samples, with z score z
+func wilsonUpper(p, n, z float64) float64 {
+	z2 := z * z
+	return (p + z2/(2*n) + z*math.Sqrt(p*(1-p)/n+z2/(4*n*n))) / (1 + z2/n)
+}
+
+// picks the candidate whose largest partition of the sample is smallest,
+// at the upper end of its confidence interval unless the sample is all of
+// S.  Sampled codes are rated first, so on a tie a guess which might win is
+// preferred.
+func (a *ApproxSolver) bestGuess(sample mm.CodeSlice, exact bool) mm.Code {
+	if len(sample) == 1 {
+		return sample[0]
+	}
+
+	candidates := append(mm.CodeSlice{}, sample...)
+	random := make([]fixed.Code, a.Candidates)
+	for i := range random {
+		random[i] = a.randomCode()
+	}
+	candidates = append(candidates, fixed.Codes(random)...)
+
+	z := zScore(a.Confidence)
+	n := float64(len(sample))
+	var best mm.Code
+	bestScore := math.Inf(1)
+	// hits counts the sample by result, as a hitmap does, but indexed by
+	// Correct*(Positions+1) + HalfCorrect: boards here may have too many
+	// pegs for a ResultKey
+	width := a.size.Positions + 1
+	hits := make([]int, width*width)
+	for _, c := range candidates {
+		for i := range hits {
+			hits[i] = 0
+		}
+		max := 0
+		for _, s := range sample {
+			r, _ := a.Feedback.Score(c, s, a.size.Colors)
+			k := r.Correct*width + r.HalfCorrect
+			hits[k]++
+			if hits[k] > max {
+				max = hits[k]
+			}
+		}
+		score := float64(max) / n
+		if !exact {
+			score = wilsonUpper(score, n, z)
+		}
+		if score < bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+func (a *ApproxSolver) randomCode() fixed.Code {
+	var pegs [fixed.MaxPositions]byte
+	code := pegs[:a.size.Positions]
+	for i := range code {
+		code[i] = byte(a.rand.Intn(int(a.size.Colors)))
+	}
+	return fixed.Of(code)
+}
+
+// returns up to SampleSize distinct codes consistent with the history, and
+// whether they are all of them.  If there are no more than SampleSize, they
+// are all found; otherwise they are found by randomized search, which
+// samples S roughly, not perfectly, uniformly.
+func (a *ApproxSolver) sampleConsistent() (mm.CodeSlice, bool, error) {
+	if a.Engine != nil {
+		return a.Engine.Consistent(a.size, a.Feedback, a.History, a.SampleSize, a.rand)
+	}
+	if a.size.Positions > fixed.MaxPositions {
+		return nil, false, fmt.Errorf("the approx solver's search takes boards of up to %d positions, not %v", fixed.MaxPositions, a.size)
+	}
+	c := a.newConstraints()
+	partial := make(mm.Code, 0, a.size.Positions)
+
+	// small sets are cheaper to enumerate than to sample.  The codes found
+	// are kept, and told apart, as arrays, so none takes an allocation or a
+	// string of its own.
+	var all []fixed.Code
+	if c.search(partial, nil, func(code mm.Code) bool {
+		all = append(all, fixed.Of(code))
+		return len(all) <= a.SampleSize
+	}) {
+		return fixed.Codes(all), true, nil
+	}
+
+	seen := map[fixed.Code]bool{}
+	sample := make([]fixed.Code, 0, a.SampleSize)
+	for tries := 0; len(sample) < a.SampleSize && tries < 2*a.SampleSize; tries++ {
+		c.search(partial, a.rand, func(code mm.Code) bool {
+			if f := fixed.Of(code); !seen[f] {
+				seen[f] = true
+				sample = append(sample, f)
+			}
+			return false
+		})
+	}
+	return fixed.Codes(sample), false, nil
+}
+
+// prunes partial codes which can't be completed consistently with history
+type constraints struct {
+	size     mm.GameSize
+	history  mm.History
+	feedback mm.Feedback
+	// the number of each color in each guess
+	guessColors [][]int
+}
+
+func (a *ApproxSolver) newConstraints() *constraints {
+	c := &constraints{size: a.size, history: a.History, feedback: a.Feedback}
+	for _, m := range a.History {
+		counts := make([]int, a.size.Colors)
+		for _, v := range m.Guess {
+			counts[v]++
+		}
+		c.guessColors = append(c.guessColors, counts)
+	}
+	return c
+}
+
+// could partial, whose color counts are given, be extended into a consistent code?
+func (c *constraints) feasible(partial mm.Code, colors []int) bool {
+	left := c.size.Positions - len(partial)
+	for i, m := range c.history {
+		correct := 0
+		for pos, v := range partial {
+			if m.Guess[pos] == v {
+				correct++
+			}
+		}
+		if c.feedback != mm.TotalFeedback && (correct > m.Result.Correct || correct+left < m.Result.Correct) {
+			return false
+		}
+		if c.feedback == mm.BlackFeedback {
+			continue
+		}
+
+		pegs := 0
+		for color, n := range colors {
+			if g := c.guessColors[i][color]; g < n {
+				pegs += g
+			} else {
+				pegs += n
+			}
+		}
+		total := m.Result.Correct + m.Result.HalfCorrect
+		if pegs > total || pegs+left < total {
+			return false
+		}
+	}
+	return true
+}
+
+// depth first search for consistent codes extending partial, calling found
+// for each until it returns false.  Colors are tried in order, or in random
+// order if rng is set.  Returns whether the search was exhausted.
+func (c *constraints) search(partial mm.Code, rng *rand.Rand, found func(mm.Code) bool) bool {
+	var counts [256]int
+	colors := counts[:c.size.Colors]
+	for _, v := range partial {
+		colors[v]++
+	}
+	return c.extend(partial, colors, rng, found)
+}
+
+func (c *constraints) extend(partial mm.Code, colors []int, rng *rand.Rand, found func(mm.Code) bool) bool {
+	if len(partial) == c.size.Positions {
+		return found(partial)
+	}
+
+	// the colors to try, on the stack rather than allocated at every step
+	// of the search
+	var colorOrder [256]byte
+	order := colorOrder[:c.size.Colors]
+	for i := range order {
+		order[i] = byte(i)
+	}
+	if rng != nil {
+		rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	}
+
+	for _, v := range order {
+		next := append(partial, v)
+		colors[v]++
+		if c.feasible(next, colors) && !c.extend(next, colors, rng, found) {
+			colors[v]--
+			return false
+		}
+		colors[v]--
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+package solver
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/pool"
+)
+
+// Backend counts, for each guess, how many codes in S produce each result
+// when that guess is played, indexed by the result's ResultKey.  The counts
+// are returned in the same order as guesses.
+type Backend interface {
+	CountHits(size mm.GameSize, S, guesses mm.CodeSlice) ([][256]int, error)
+}
+
+// LocalBackend counts hits on a pool of goroutines in this process, Workers
+// of them or one per CPU
+type LocalBackend struct {
+	Workers int
+}
+
+func (b LocalBackend) CountHits(size mm.GameSize, S, guesses mm.CodeSlice) ([][256]int, error) {
+	workers := pool.Workers(b.Workers)
+	// the codes are checked once here, so they can be scored without
+	// checking every pair
+	for _, codes := range []mm.CodeSlice{S, guesses} {
+		for _, c := range codes {
+			if len(c) != size.Positions {
+				return nil, fmt.Errorf("code %v isn't %d pegs", c, size.Positions)
+			}
+		}
+	}
+	limiter := pool.New(workers)
+	hits := make([][256]int, len(guesses))
+
+	for i, guess := range guesses {
+		i1, guess1 := i, guess
+		limiter.Go(func() error {
+			h := &hits[i1]
+			for _, s := range S {
+				h[mm.Score(guess1, s, size.Colors).Key()]++
+			}
+			return nil
+		})
+	}
+
+	return hits, limiter.Wait()
+}
+
+// CountArgs are the arguments to Worker.CountHits
+type CountArgs struct {
+	Size    mm.GameSize
+	S       mm.CodeSlice
+	Guesses mm.CodeSlice
+}
+
+// Worker serves a LocalBackend over net/rpc so other processes can shard
+// their scoring onto this one
+type Worker struct {
+	Backend LocalBackend
+}
+
+func (w *Worker) CountHits(args *CountArgs, reply *[][256]int) error {
+	hits, err := w.Backend.CountHits(args.Size, args.S, args.Guesses)
+	*reply = hits
+	return err
+}
+
+// ServeWorker answers scoring requests from RPCBackends on l, returning the
+// error which stops it accepting connections, eg when l is closed
+func ServeWorker(l net.Listener, workers int) error {
+	server := rpc.NewServer()
+	if err := server.Register(&Worker{LocalBackend{workers}}); err != nil {
+		return err
+	}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go server.ServeConn(conn)
+	}
+}
+
+// RPCBackend shards the guesses evenly across a set of workers started with
+// ServeWorker and merges their counts
+type RPCBackend struct {
+	clients []*rpc.Client
+}
+
+// DialBackend connects to the workers listening on each of addrs
+func DialBackend(addrs ...string) (*RPCBackend, error) {
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no worker addresses")
+	}
+	b := &RPCBackend{}
+	for _, addr := range addrs {
+		c, err := rpc.Dial("tcp", addr)
+		if err != nil {
+			b.Close()
+			return nil, fmt.Errorf("dialing worker %s: %v", addr, err)
+		}
+		b.clients = append(b.clients, c)
+	}
+	return b, nil
+}
+
+func (b *RPCBackend) CountHits(size mm.GameSize, S, guesses mm.CodeSlice) ([][256]int, error) {
+	hits := make([][256]int, len(guesses))
+	shard := (len(guesses) + len(b.clients) - 1) / len(b.clients)
+	limiter := pool.New(len(b.clients))
+
+	for i, c := range b.clients {
+		lo, hi := i*shard, (i+1)*shard
+		if lo >= len(guesses) {
+			break
+		}
+		if hi > len(guesses) {
+			hi = len(guesses)
+		}
+		c1 := c
+		limiter.Go(func() error {
+			var reply [][256]int
+			args := &CountArgs{Size: size, S: S, Guesses: guesses[lo:hi]}
+			if err := c1.Call("Worker.CountHits", args, &reply); err != nil {
+				return err
+			}
+			if len(reply) != hi-lo {
+				return fmt.Errorf("worker returned %d counts for %d guesses", len(reply), hi-lo)
+			}
+			copy(hits[lo:hi], reply)
+			return nil
+		})
+	}
+
+	return hits, limiter.Wait()
+}
+
+func (b *RPCBackend) Close() error {
+	var err error
+	for _, c := range b.clients {
+		if e := c.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// scores the guesses in P by the hits backend counts for them
+func (g *Solver) scoreWith(backend Backend, S codeSet, P mm.CodeSlice) (map[float64]mm.CodeSlice, error) {
+	codes := S.codes
+
+	hits, err := backend.CountHits(g.GameSize(), codes, P)
+	if err != nil {
+		return nil, err
+	}
+	g.counted(len(codes)*len(P), len(P))
+
+	guesses := map[float64]mm.CodeSlice{}
+	for i, h := range hits {
+		// backends count full results; merge those the game's feedback can't tell apart
+		var reduced hitmap
+		for key, n := range h {
+			if n > 0 {
+				reduced[g.Feedback.Reduce(mm.ResultKey(key).Result(), g.Positions()).Key()] += n
+			}
+		}
+		score := g.rateHits(&reduced, len(codes))
+		guesses[score] = append(guesses[score], P[i])
+	}
+	return guesses, nil
+}
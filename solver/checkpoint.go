@@ -0,0 +1,106 @@
+package solver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// CheckpointDir, if set, is where progress computing the initial move for
+// a new game size is saved, so a killed process can resume the computation
+// rather than start over.  Finished computations are kept there too.
+var CheckpointDir string
+
+// CheckpointInterval is how often progress is saved to CheckpointDir
+var CheckpointInterval = time.Minute
+
+type openerCheckpoint struct {
+	Size mm.GameSize
+	// Scored is how many codes of P, in order, have been scored
+	Scored int
+	// MinMax is the smallest largest partition found so far, and Best
+	// are the codes producing it
+	MinMax int
+	Best   mm.CodeSlice
+}
+
+func checkpointPath(size mm.GameSize) string {
+	return filepath.Join(CheckpointDir, fmt.Sprintf("opener-%dx%d.json", size.Positions, size.Colors))
+}
+
+func loadCheckpoint(size mm.GameSize) (*openerCheckpoint, error) {
+	buf, err := ioutil.ReadFile(checkpointPath(size))
+	if os.IsNotExist(err) {
+		return &openerCheckpoint{Size: size, MinMax: -1}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cp := &openerCheckpoint{}
+	if err := json.Unmarshal(buf, cp); err != nil {
+		return nil, fmt.Errorf("reading %s: %v", checkpointPath(size), err)
+	}
+	if cp.Size != size {
+		return nil, fmt.Errorf("%s is a checkpoint for %v", checkpointPath(size), cp.Size)
+	}
+	return cp, nil
+}
+
+// writes to a temporary file first, so a kill mid-write can't lose the last checkpoint
+func (cp *openerCheckpoint) save() error {
+	buf, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	path := checkpointPath(cp.Size)
+	if err := ioutil.WriteFile(path+".tmp", buf, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// returns the initial move, as bestGuessOfSet would, saving progress as it goes
+func (g *Solver) checkpointedOpener(S codeSet, P mm.CodeSlice) (mm.Code, error) {
+	cp, err := loadCheckpoint(g.GameSize())
+	if err != nil {
+		return nil, err
+	}
+	if cp.Scored > 0 {
+		fmt.Printf("resuming initial move for size %v at %d/%d\n", cp.Size, cp.Scored, len(P))
+	}
+
+	last := time.Now()
+	for ; cp.Scored < len(P); cp.Scored++ {
+		p := P[cp.Scored]
+		var hits hitmap
+		g.countHits(S, p, &hits)
+		_, max := hits.maxHits()
+		if cp.MinMax < 0 || max < cp.MinMax {
+			cp.MinMax = max
+			cp.Best = mm.CodeSlice{}
+		}
+		if max == cp.MinMax {
+			cp.Best = append(cp.Best, p)
+		}
+
+		if time.Since(last) >= CheckpointInterval {
+			if err := cp.save(); err != nil {
+				return nil, err
+			}
+			last = time.Now()
+		}
+	}
+
+	if err := cp.save(); err != nil {
+		return nil, err
+	}
+	if len(cp.Best) == 0 {
+		return nil, fmt.Errorf("no initial move found for size %v", cp.Size)
+	}
+	return cp.Best.Min(), nil
+}
@@ -0,0 +1,99 @@
+package solver
+
+import (
+	"sort"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// boards of more codes than this aren't indexed, and a set of their codes
+// finds its members by binary search instead of a bitset
+const maxIndexed = 1 << 24
+
+// codeSet is a set of one board's codes, in order.  The codes share one
+// flat slice of pegs, so the scoring loops run straight through memory
+// rather than hopping between a map's buckets, and which codes the set holds
+// is a bitset by their index on the board.
+type codeSet struct {
+	codes   mm.CodeSlice
+	colors  byte
+	members []uint64
+}
+
+// setOf makes a set of codes, which must be in order without repeats,
+// copying their pegs
+func (g *Solver) setOf(codes mm.CodeSlice) codeSet {
+	pegs := make([]byte, 0, len(codes)*g.Positions())
+	for _, c := range codes {
+		pegs = append(pegs, c...)
+	}
+	return g.setOfPegs(pegs)
+}
+
+// setOfPegs makes a set of the codes spelled one after another by pegs,
+// which must be in order without repeats, keeping pegs as they are
+func (g *Solver) setOfPegs(pegs []byte) codeSet {
+	n := g.Positions()
+	s := codeSet{codes: make(mm.CodeSlice, len(pegs)/n), colors: g.Colors()}
+	for i := range s.codes {
+		s.codes[i] = pegs[i*n : (i+1)*n : (i+1)*n]
+	}
+	if indexed(g.GameSize()) {
+		s.members = make([]uint64, (g.GameSize().NumCodes()+63)/64)
+		for _, c := range s.codes {
+			i := c.Index(s.colors)
+			s.members[i/64] |= 1 << uint(i%64)
+		}
+	}
+	return s
+}
+
+// setAmong is setOf codes in any order, some perhaps repeated
+func (g *Solver) setAmong(codes mm.CodeSlice) codeSet {
+	sorted := append(mm.CodeSlice{}, codes...)
+	sort.Sort(sorted)
+	unique := sorted[:0]
+	for _, c := range sorted {
+		if len(unique) == 0 || c.Compare(unique[len(unique)-1]) != 0 {
+			unique = append(unique, c)
+		}
+	}
+	return g.setOf(unique)
+}
+
+// indexed is whether the codes of a board are few enough for sets of them
+// to keep a bitset
+func indexed(size mm.GameSize) bool {
+	n := 1
+	for i := 0; i < size.Positions; i++ {
+		if n *= int(size.Colors); n > maxIndexed {
+			return false
+		}
+	}
+	return true
+}
+
+func (s codeSet) len() int {
+	return len(s.codes)
+}
+
+// has is whether c is in the set
+func (s codeSet) has(c mm.Code) bool {
+	if s.members != nil {
+		i := c.Index(s.colors)
+		return i >= 0 && i/64 < len(s.members) && s.members[i/64]&(1<<uint(i%64)) != 0
+	}
+	i := sort.Search(len(s.codes), func(i int) bool { return s.codes[i].Compare(c) >= 0 })
+	return i < len(s.codes) && s.codes[i].Compare(c) == 0
+}
+
+// filter is the set of the codes keep keeps, in order
+func (g *Solver) filter(s codeSet, keep func(mm.Code) bool) codeSet {
+	kept := mm.CodeSlice{}
+	for _, c := range s.codes {
+		if keep(c) {
+			kept = append(kept, c)
+		}
+	}
+	return g.setOf(kept)
+}
@@ -0,0 +1,111 @@
+package solver
+
+import (
+	"sync"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Level is how hard a secret is, as a puzzle
+type Level int
+
+const (
+	// Easy secrets take fewer moves than the strategy does on average,
+	// rounded down
+	Easy Level = iota
+	// Medium secrets take from that up to one short of the worst case
+	Medium
+	// Hard secrets take as many as the strategy's worst
+	Hard
+)
+
+func (l Level) String() string {
+	switch l {
+	case Easy:
+		return "easy"
+	case Medium:
+		return "medium"
+	case Hard:
+		return "hard"
+	}
+	return "unknown"
+}
+
+// difficulties is the strategy of each size Difficulty has been asked
+// about, with the moves it takes over every secret
+var difficulties = struct {
+	sync.Mutex
+	sizes map[mm.GameSize]*difficultyEntry
+}{sizes: map[mm.GameSize]*difficultyEntry{}}
+
+type difficultyEntry struct {
+	once   sync.Once
+	tree   *Tree
+	counts *mm.MoveCounts
+}
+
+// strategy is the tree of size and its counts, worked out the first time
+// they're needed, or nil if they can't be
+func strategy(size mm.GameSize) (*Tree, *mm.MoveCounts) {
+	difficulties.Lock()
+	e, ok := difficulties.sizes[size]
+	if !ok {
+		e = &difficultyEntry{}
+		difficulties.sizes[size] = e
+	}
+	difficulties.Unlock()
+	// others asking about the size wait while the first works it out
+	e.once.Do(func() {
+		game := &Solver{Game: mm.NewCustomGame(size.Positions, size.Colors)}
+		tree, err := game.Tree()
+		if err != nil {
+			return
+		}
+		if e.counts, err = tree.MoveCounts(); err == nil {
+			e.tree = tree
+		}
+	})
+	return e.tree, e.counts
+}
+
+// Difficulty is how many moves the solver's strategy takes to break
+// secret, the best play it knows, or 0 if secret isn't a code of size.
+// The strategy of a size is worked out the first time it's asked about,
+// which takes as long as Tree does.
+func Difficulty(secret mm.Code, size mm.GameSize) int {
+	if len(secret) != size.Positions {
+		return 0
+	}
+	for _, peg := range secret {
+		if peg >= size.Colors {
+			return 0
+		}
+	}
+	tree, _ := strategy(size)
+	if tree == nil {
+		return 0
+	}
+	moves, err := tree.Play(secret)
+	if err != nil {
+		return 0
+	}
+	return moves
+}
+
+// DifficultyLevel is how hard secret is as a puzzle, by the moves it takes
+// against those the strategy takes on other secrets of size, and whether
+// Difficulty could rate it at all
+func DifficultyLevel(secret mm.Code, size mm.GameSize) (Level, bool) {
+	moves := Difficulty(secret, size)
+	if moves == 0 {
+		return Hard, false
+	}
+	_, counts := strategy(size)
+	switch {
+	case moves >= counts.Worst():
+		return Hard, true
+	case moves < int(counts.Expected()):
+		return Easy, true
+	}
+	return Medium, true
+}
@@ -0,0 +1,118 @@
+package solver
+
+import (
+	"math"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Heuristic determines how a guess is rated by the partition it makes of S.
+// Every heuristic produces a score where lower is better.
+type Heuristic int
+
+const (
+	// MinMax rates a guess by the size of its largest partition (Knuth).
+	MinMax Heuristic = iota
+	// ExpectedSize rates a guess by the expected number of codes remaining
+	// after it is played, weighting each partition by its probability.
+	ExpectedSize
+	// Entropy rates a guess by the information its result is expected to
+	// carry; the score is the negated entropy, in bits.
+	Entropy
+)
+
+func (h Heuristic) String() string {
+	switch h {
+	case MinMax:
+		return "minmax"
+	case ExpectedSize:
+		return "expected"
+	case Entropy:
+		return "entropy"
+	}
+	return "unknown"
+}
+
+// the prior weight of code
+func (g *Solver) weight(code mm.Code) float64 {
+	if g.Priors == nil {
+		return 1.0
+	}
+	if w, ok := g.Priors[code.String()]; ok {
+		return w
+	}
+	return 1.0
+}
+
+// rates guess by how it partitions S, according to g.Heuristic
+func (g *Solver) rate(S codeSet, guess mm.Code) float64 {
+	if g.Heuristic == MinMax || g.Priors == nil {
+		var hits hitmap
+		g.countHits(S, guess, &hits)
+		return g.rateHits(&hits, S.len())
+	}
+
+	// for each result, the number of codes in S producing it and their combined weight
+	g.counted(S.len(), 1)
+	var hits hitmap
+	var weights [256]float64
+	total := 0.0
+	for _, s := range S.codes {
+		result, err := g.check(guess, s)
+		if err != nil {
+			panic(err)
+		}
+		w := g.weight(s)
+		hits[result.Key()]++
+		weights[result.Key()] += w
+		total += w
+	}
+	if total <= 0 {
+		return 0
+	}
+
+	score := 0.0
+	for r, w := range &weights {
+		if w == 0 {
+			continue
+		}
+		p := w / total
+		switch g.Heuristic {
+		case ExpectedSize:
+			score += p * float64(hits[r])
+		case Entropy:
+			if p > 0 {
+				score += p * math.Log2(p)
+			}
+		}
+	}
+	return score
+}
+
+// rates a partition of total codes with every code weighing the same
+func (g *Solver) rateHits(hits *hitmap, total int) float64 {
+	if g.Heuristic == MinMax {
+		_, score := hits.maxHits()
+		return float64(score)
+	}
+	if total == 0 {
+		return 0
+	}
+
+	score := 0.0
+	for _, n := range hits {
+		if n == 0 {
+			continue
+		}
+		p := float64(n) / float64(total)
+		switch g.Heuristic {
+		case ExpectedSize:
+			score += p * float64(n)
+		case Entropy:
+			if p > 0 {
+				score += p * math.Log2(p)
+			}
+		}
+	}
+	return score
+}
@@ -0,0 +1,78 @@
+package solver
+
+import (
+	"math"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+const (
+	// sliceHeader is what a code held on its own costs on top of its pegs
+	sliceHeader = 24
+	// mapEntry is roughly what a map keyed by string costs an entry,
+	// besides the bytes of the key
+	mapEntry = 48
+	// heapFloor is how big the runtime lets the heap grow before it first
+	// collects, which is most of what small boards take
+	heapFloor = 4 << 20
+)
+
+// EstimateMemory is roughly the most memory, in bytes, a solve of a game of
+// size takes with the named solver: knuth, the exact Solver and the server's
+// hints; or approx, the ApproxSolver as NewApproxSolver makes it.  The
+// genetic package's solver has its own, by its configuration, in
+// GeneticMemory.  It's meant for refusing boards which would run a process
+// out of memory, not for accounting.  Unknown solvers are taken to be knuth,
+// the hungriest, and boards too big to count come to math.MaxUint64.
+func EstimateMemory(size mm.GameSize, solver string) uint64 {
+	n := math.Pow(float64(size.Colors), float64(size.Positions))
+	code := float64(size.Positions + sliceHeader)
+
+	bytes := float64(heapFloor)
+	switch solver {
+	case "approx":
+		// the sample, with the keys it's drawn without repeats by, and the
+		// candidates rated against it
+		bytes += DefaultSampleSize*(2*code+mapEntry) + DefaultCandidates*code
+	default:
+		bytes += exactMemory(n, code)
+		if size == (mm.GameSize{4, 6}) {
+			// Solve plays the default game from its table
+			bytes += n*n + n*(code+mapEntry)
+		}
+	}
+	return saturate(bytes)
+}
+
+// GeneticMemory is EstimateMemory for the genetic package's solver, which
+// can't be named here, given its configuration: on each of islands, a
+// generation of population, the next and up to eligible codes, and a
+// fitness cache shared by them of up to every citizen of generations.
+// genetic.Config's EstimateMemory fills them in.
+func GeneticMemory(size mm.GameSize, population, generations, eligible, islands int) uint64 {
+	if islands < 1 {
+		islands = 1
+	}
+	code := float64(size.Positions + sliceHeader)
+	citizens := float64(islands * population)
+	bytes := float64(heapFloor) +
+		(2*citizens+float64(islands*eligible))*(2*code+mapEntry) +
+		float64(generations)*citizens*(code+mapEntry)
+	return saturate(bytes)
+}
+
+// saturate is bytes, or math.MaxUint64 if it's too many to count
+func saturate(bytes float64) uint64 {
+	if bytes >= math.MaxUint64 {
+		return math.MaxUint64
+	}
+	return uint64(bytes)
+}
+
+// exactMemory is what the exact solver holds at most on a board of n codes,
+// each code bytes: P; S and what's left of it after a guess, each with its
+// bitset; a score for every code of P; and the results of the best guess so
+// far and of the one being rated
+func exactMemory(n, code float64) float64 {
+	return n*code + 2*(n*code+n/8) + n*code + 2*n
+}
@@ -0,0 +1,31 @@
+package solver
+
+import (
+	"math"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestEstimateMemory(t *testing.T) {
+	// the exact solver grows with the board, and the others barely
+	small, big := EstimateMemory(mm.GameSize{5, 8}, "knuth"), EstimateMemory(mm.GameSize{7, 8}, "knuth")
+	if small < heapFloor || big < 10*small {
+		t.Errorf("expected 7x8 to take many times 5x8's %d bytes, got %d", small, big)
+	}
+	if approx := EstimateMemory(mm.GameSize{8, 10}, "approx"); approx > 2*heapFloor {
+		t.Errorf("expected 8x10 to take a few MB with approx, got %d bytes", approx)
+	}
+	if ga, exact := GeneticMemory(mm.GameSize{8, 10}, 150, 100, 60, 1), EstimateMemory(mm.GameSize{8, 10}, "knuth"); ga*100 > exact {
+		t.Errorf("expected 8x10 to take far less with genetic than knuth, got %d and %d bytes", ga, exact)
+	}
+	if one, four := GeneticMemory(mm.GameSize{8, 10}, 150, 100, 60, 1), GeneticMemory(mm.GameSize{8, 10}, 150, 100, 60, 4); four <= one {
+		t.Errorf("expected four islands to take more than one, got %d and %d bytes", four, one)
+	}
+	if n := EstimateMemory(mm.GameSize{20, 20}, "knuth"); n != math.MaxUint64 {
+		t.Errorf("expected 20x20 to saturate, got %d", n)
+	}
+	if EstimateMemory(mm.GameSize{6, 9}, "quantum") != EstimateMemory(mm.GameSize{6, 9}, "knuth") {
+		t.Error("expected an unknown solver estimated as knuth")
+	}
+}
@@ -0,0 +1,110 @@
+package solver
+
+import (
+	"fmt"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/pool"
+)
+
+// MultiSolver plays several boards of the same size at once with a single
+// shared stream of guesses: every guess is scored against every board that
+// hasn't been solved yet.
+type MultiSolver struct {
+	boards []*Solver
+	// Turns is the number of shared guesses made
+	Turns int
+	// Workers is how many goroutines score guesses at once; zero means one
+	// per CPU
+	Workers int
+}
+
+func NewMultiSolver(games ...*mm.Game) (*MultiSolver, error) {
+	m := &MultiSolver{}
+	for i, g := range games {
+		if g.GameSize() != games[0].GameSize() {
+			return nil, fmt.Errorf("board %d is %v, expected %v", i, g.GameSize(), games[0].GameSize())
+		}
+		board := NewSolver(g)
+		// boards are independent, so their information adds up
+		board.Heuristic = Entropy
+		m.boards = append(m.boards, board)
+	}
+	return m, nil
+}
+
+// Solve returns the secret of each board, in the order the games were given
+func (m *MultiSolver) Solve() ([]mm.Code, error) {
+	if len(m.boards) == 0 {
+		return nil, nil
+	}
+
+	S := make([]codeSet, len(m.boards))
+	for i, b := range m.boards {
+		S[i], _ = b.allPossibleCodes()
+	}
+	_, P := m.boards[0].allPossibleCodes()
+
+	solved := make([]mm.Code, len(m.boards))
+	guess := m.boards[0].initialMove
+
+	for {
+		m.Turns++
+		remaining := 0
+		for i, b := range m.boards {
+			if solved[i] != nil {
+				continue
+			}
+			result, err := b.ScoredGuess(guess)
+			if err != nil {
+				return solved, err
+			}
+			if b.IsWin(result) {
+				solved[i] = guess
+				continue
+			}
+			S[i] = b.selectMovesWithResult(S[i], guess, result)
+			if S[i].len() == 0 {
+				return solved, fmt.Errorf("no codes on board %d are consistent with its results", i)
+			}
+			remaining++
+		}
+
+		if remaining == 0 {
+			return solved, nil
+		}
+
+		guess = m.nextGuess(S, solved, P)
+	}
+}
+
+// picks the code maximizing the combined information over every unsolved
+// board; a board that's down to one code is finished off first, since that
+// guess costs the other boards nothing they wouldn't lose anyway
+func (m *MultiSolver) nextGuess(S []codeSet, solved []mm.Code, P mm.CodeSlice) mm.Code {
+	for i := range m.boards {
+		if solved[i] == nil && S[i].len() == 1 {
+			return S[i].codes[0]
+		}
+	}
+
+	scores := scoreShards(P, pool.Workers(m.Workers), func(p mm.Code) float64 {
+		score := 0.0
+		for i, b := range m.boards {
+			if solved[i] == nil {
+				score += b.rate(S[i], p)
+			}
+		}
+		return score
+	})
+
+	// among equally informative guesses prefer one that could win a board
+	best := bestScore(scores)
+	for i := range m.boards {
+		if solved[i] == nil {
+			best = selectGuesses(S[i], best)
+			break
+		}
+	}
+	return best.Min()
+}
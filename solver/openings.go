@@ -0,0 +1,173 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// MaxExactOpenings is the most codes a board may have for Openings to play
+// out the strategy after every opening, which takes a Tree each
+const MaxExactOpenings = 1 << 12
+
+// Opening is a first guess, rated as the solver would rate it
+type Opening struct {
+	Guess mm.Code
+	// Codes is how many first guesses are Guess but for the order of their
+	// pegs and which colors they are, all of which rate the same
+	Codes int
+	// Score is the guess's rating by the solver's heuristic, lower better,
+	// Largest the most codes it could leave possible, and Results how many
+	// results it could score
+	Score   float64
+	Largest int
+	Results int
+	// Counts are the moves the solver's strategy takes over every secret
+	// after opening with Guess, if Openings played them out
+	Counts *mm.MoveCounts
+}
+
+func (o Opening) String() string {
+	s := fmt.Sprintf("%v (%d codes): score %.4f, largest %d, %d results", o.Guess, o.Codes, o.Score, o.Largest, o.Results)
+	if o.Counts != nil {
+		s += fmt.Sprintf(", %.4f moves expected, %d worst", o.Counts.Expected(), o.Counts.Worst())
+	}
+	return s
+}
+
+// Openings rates every first guess on the solver's board, best first.  A
+// guess rates the same as any made from it by reordering its pegs or
+// swapping its colors, so only the least of each such family is listed,
+// eg 0012 for 4x6 but not 1020 or 3455.  By default they're ranked by the
+// solver's heuristic, then their largest partition.  With exact, the
+// strategy after each opening is played out over every secret too, and
+// they're ranked by the moves it takes on average, then at worst, then by
+// the heuristic; that needs a board of no more than MaxExactOpenings codes.
+// Priors would break the families apart, so aren't allowed.
+func (g *Solver) Openings(exact bool) ([]Opening, error) {
+	size := g.GameSize()
+	if g.Priors != nil {
+		return nil, fmt.Errorf("openings can't be rated with priors")
+	}
+	if size.NumCodes() > mm.MaxAnalyzedCodes {
+		return nil, fmt.Errorf("%v has too many codes to rate every opening", size)
+	}
+	if exact && size.NumCodes() > MaxExactOpenings {
+		return nil, fmt.Errorf("%v has too many codes to play out every opening", size)
+	}
+
+	S, _ := g.allPossibleCodes()
+	var openings []Opening
+	for _, parts := range colorCounts(size.Positions, int(size.Colors)) {
+		o := Opening{Guess: familyOf(parts), Codes: familySize(parts, int(size.Colors))}
+		o.Score = g.rate(S, o.Guess)
+		var hits hitmap
+		g.countHits(S, o.Guess, &hits)
+		for _, n := range hits {
+			if n > 0 {
+				o.Results++
+			}
+			if n > o.Largest {
+				o.Largest = n
+			}
+		}
+		if exact {
+			tree, err := g.TreeFrom(o.Guess)
+			if err != nil {
+				return nil, err
+			}
+			if o.Counts, err = tree.MoveCounts(); err != nil {
+				return nil, err
+			}
+		}
+		openings = append(openings, o)
+	}
+
+	sort.SliceStable(openings, func(i, j int) bool {
+		a, b := openings[i], openings[j]
+		if exact && a.Counts.Moves != b.Counts.Moves {
+			return a.Counts.Moves < b.Counts.Moves
+		}
+		if exact && a.Counts.Worst() != b.Counts.Worst() {
+			return a.Counts.Worst() < b.Counts.Worst()
+		}
+		if a.Score != b.Score {
+			return a.Score < b.Score
+		}
+		if a.Largest != b.Largest {
+			return a.Largest < b.Largest
+		}
+		return a.Guess.Compare(b.Guess) < 0
+	})
+	return openings, nil
+}
+
+// colorCounts is every way of making a code of positions pegs from no more
+// than colors colors, as how many pegs of each color it has, most first:
+// the partitions of positions into at most colors parts
+func colorCounts(positions, colors int) [][]int {
+	var all [][]int
+	var fill func(left, max int, parts []int)
+	fill = func(left, max int, parts []int) {
+		if left == 0 {
+			all = append(all, append([]int{}, parts...))
+			return
+		}
+		if len(parts) == colors {
+			return
+		}
+		for n := max; n >= 1; n-- {
+			if n <= left {
+				fill(left-n, n, append(parts, n))
+			}
+		}
+	}
+	fill(positions, positions, nil)
+	return all
+}
+
+// familyOf is the least code with parts[i] pegs of each color i
+func familyOf(parts []int) mm.Code {
+	var code mm.Code
+	for color, n := range parts {
+		for i := 0; i < n; i++ {
+			code = append(code, byte(color))
+		}
+	}
+	return code
+}
+
+// familySize is how many codes have pegs of colors distinct colors in the
+// counts parts: the orders of their pegs, times the ways of picking the
+// colors, those with as many pegs as each other being interchangeable
+func familySize(parts []int, colors int) int {
+	positions := 0
+	for _, n := range parts {
+		positions += n
+	}
+	size := factorial(positions)
+	for _, n := range parts {
+		size /= factorial(n)
+	}
+	for i := 0; i < len(parts); i++ {
+		size *= colors - i
+	}
+	for i := 0; i < len(parts); {
+		j := i
+		for j < len(parts) && parts[j] == parts[i] {
+			j++
+		}
+		size /= factorial(j - i)
+		i = j
+	}
+	return size
+}
+
+func factorial(n int) int {
+	f := 1
+	for i := 2; i <= n; i++ {
+		f *= i
+	}
+	return f
+}
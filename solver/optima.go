@@ -0,0 +1,83 @@
+package solver
+
+import (
+	"fmt"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Optimal is the Strategy of a Known result which is the best any
+// strategy can do
+const Optimal = "optimal"
+
+// Known is a published count of the moves taken over every secret of a
+// full feedback game, to check strategies worked out here against
+type Known struct {
+	Size mm.GameSize
+	// Strategy is the heuristic whose strategy was counted, or Optimal
+	// for the fewest moves any strategy takes: the fewest over every
+	// secret and the fewest in the worst case, which no one strategy
+	// need manage both of
+	Strategy string
+	Moves    int
+	Worst    int
+	Source   string
+}
+
+// KnownResults are the published results
+var KnownResults = []Known{
+	{mm.GameSize{4, 6}, Optimal, 5625, 5, "Koyama and Lai 1993; Knuth 1977"},
+	{mm.GameSize{4, 6}, MinMax.String(), 5801, 5, "Knuth 1977"},
+}
+
+// Deviation is how many more moves a strategy takes than a known result,
+// over every secret and in the worst case
+type Deviation struct {
+	Known Known
+	Moves int
+	Worst int
+}
+
+func (d Deviation) String() string {
+	secrets := d.Known.Size.NumCodes()
+	return fmt.Sprintf("%+d moves (%+.4f expected), %+d worst, against %s %d/%d, %d worst (%s)",
+		d.Moves, float64(d.Moves)/float64(secrets), d.Worst,
+		d.Known.Strategy, d.Known.Moves, secrets, d.Known.Worst, d.Known.Source)
+}
+
+// Compare measures counts of a strategy's moves against k.  No strategy
+// beats an optimum, and a strategy whose moves were published takes
+// exactly as many here, so counts which don't are an error: either the
+// strategy or its counting has gone wrong.
+func (k Known) Compare(counts *mm.MoveCounts) (Deviation, error) {
+	d := Deviation{Known: k, Moves: counts.Moves - k.Moves, Worst: counts.Worst() - k.Worst}
+	if counts.Size != k.Size || counts.Secrets != k.Size.NumCodes() {
+		return d, fmt.Errorf("%d secrets of %v counted, against all %d of %v", counts.Secrets, counts.Size, k.Size.NumCodes(), k.Size)
+	}
+	if k.Strategy == Optimal && (d.Moves < 0 || d.Worst < 0) {
+		return d, fmt.Errorf("%v beats the optimum: %v", counts, d)
+	}
+	if k.Strategy != Optimal && (d.Moves != 0 || d.Worst != 0) {
+		return d, fmt.Errorf("%v isn't %s's published result: %v", counts, k.Strategy, d)
+	}
+	return d, nil
+}
+
+// Deviations compares counts of a full feedback strategy's moves against
+// every known result for its size which applies to it: the optimum, and
+// the strategy's own if it's named and was published.  It fails at the
+// first result the counts can't be right against.
+func Deviations(strategy string, counts *mm.MoveCounts) ([]Deviation, error) {
+	var deviations []Deviation
+	for _, k := range KnownResults {
+		if k.Size != counts.Size || k.Strategy != Optimal && k.Strategy != strategy {
+			continue
+		}
+		d, err := k.Compare(counts)
+		if err != nil {
+			return nil, err
+		}
+		deviations = append(deviations, d)
+	}
+	return deviations, nil
+}
@@ -0,0 +1,71 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Bucket is the codes which would score a guess the same
+type Bucket struct {
+	Result mm.Result
+	Codes  mm.CodeSlice
+}
+
+// Partition is how a guess splits the codes which could still be the secret
+type Partition struct {
+	Guess mm.Code
+	// Total is the number of codes which could be the secret
+	Total int
+	// Buckets are the results the guess could score, with the codes giving
+	// them in order, from most black pins to fewest.  Results no code gives
+	// are left out.
+	Buckets []Bucket
+	// Score is the guess's rating by the solver's heuristic, lower better
+	Score float64
+}
+
+// Largest is the size of the biggest bucket, the most codes which could be
+// left after the guess
+func (p *Partition) Largest() int {
+	n := 0
+	for _, b := range p.Buckets {
+		if len(b.Codes) > n {
+			n = len(b.Codes)
+		}
+	}
+	return n
+}
+
+// Partition is the split guess makes of the codes which could be the secret
+// after history, as the solver sees it when choosing a guess
+func (game *Solver) Partition(history mm.History, guess mm.Code) (*Partition, error) {
+	if len(guess) != game.Positions() {
+		return nil, fmt.Errorf("guess %v isn't %d pegs", guess, game.Positions())
+	}
+	for _, c := range guess {
+		if c >= game.Colors() {
+			return nil, fmt.Errorf("guess %v has colors past %d", guess, game.Colors()-1)
+		}
+	}
+	S := game.consistentSet(history)
+	if S.len() == 0 {
+		return nil, fmt.Errorf("no code is consistent with %v", history)
+	}
+
+	p := &Partition{Guess: guess, Total: S.len(), Score: game.rate(S, guess)}
+	for r, T := range game.partition(S, guess) {
+		b := Bucket{Result: r}
+		b.Codes = T.codes
+		p.Buckets = append(p.Buckets, b)
+	}
+	sort.Slice(p.Buckets, func(i, j int) bool {
+		a, b := p.Buckets[i].Result, p.Buckets[j].Result
+		if a.Correct != b.Correct {
+			return a.Correct > b.Correct
+		}
+		return a.HalfCorrect > b.HalfCorrect
+	})
+	return p, nil
+}
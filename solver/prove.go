@@ -0,0 +1,97 @@
+package solver
+
+import (
+	"sync"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Counterexample is a secret the solver can't win within a bound
+type Counterexample struct {
+	Secret mm.Code
+	// Moves are the solver's moves against Secret, up to the last one the
+	// bound allows, none of which win
+	Moves mm.History
+}
+
+// Prove checks that playing Step wins every game within bound moves.  The
+// games are searched together, each guess splitting the secrets it could
+// be playing against, on up to workers goroutines at a time.  It returns a
+// counterexample if there's one, or nil if the bound holds.
+func (g *Solver) Prove(bound, workers int) (*Counterexample, error) {
+	if bound < 1 {
+		return &Counterexample{Secret: make(mm.Code, g.Positions())}, nil
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	S, P := g.allPossibleCodes()
+	p := &prover{g: g, P: P, bound: bound, sem: make(chan struct{}, workers-1)}
+	p.visit(nil, S)
+	p.wg.Wait()
+	return p.found, p.err
+}
+
+type prover struct {
+	g     *Solver
+	P     mm.CodeSlice
+	bound int
+	// sem holds a slot for each goroutine searching besides the first
+	sem chan struct{}
+	wg  sync.WaitGroup
+
+	mu    sync.Mutex
+	found *Counterexample
+	err   error
+}
+
+func (p *prover) stopped() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.found != nil || p.err != nil
+}
+
+func (p *prover) stop(found *Counterexample, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.found == nil && p.err == nil {
+		p.found, p.err = found, err
+	}
+}
+
+// visit searches the games after history, against the secrets S
+func (p *prover) visit(history mm.History, S codeSet) {
+	if p.stopped() {
+		return
+	}
+	m, err := p.g.nextMove(S, p.P)
+	if err != nil {
+		p.stop(nil, err)
+		return
+	}
+	guess := m.guess
+	for r, T := range m.partition(p.g) {
+		if p.g.IsWin(r) {
+			continue
+		}
+		next := append(append(mm.History{}, history...), mm.Move{Guess: guess, Result: r})
+		if len(next) >= p.bound {
+			p.stop(&Counterexample{Secret: T.codes[0], Moves: next}, nil)
+			return
+		}
+		// search alongside if there's a free goroutine, or carry on here
+		select {
+		case p.sem <- struct{}{}:
+			p.wg.Add(1)
+			go func(T codeSet) {
+				defer func() {
+					<-p.sem
+					p.wg.Done()
+				}()
+				p.visit(next, T)
+			}(T)
+		default:
+			p.visit(next, T)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package solver
+
+import (
+	"math/rand"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/sat"
+)
+
+// ConsistencyEngine finds codes consistent with the moves played so far.
+// Consistent returns up to n distinct consistent codes, and whether they are
+// all there are; no codes and true proves the history impossible.  rng, if
+// set, randomizes which codes are found.
+type ConsistencyEngine interface {
+	Consistent(size mm.GameSize, f mm.Feedback, history mm.History, n int, rng *rand.Rand) (mm.CodeSlice, bool, error)
+}
+
+// SATEngine finds consistent codes with a SAT solver.  The history is
+// encoded as a boolean formula over one variable per (position, color),
+// with the peg counts of each result stated through totalizers, and each code
+// found is blocked so the next solve finds another.  It pays off over depth
+// first search on very large alphabets, where the search can't prune partial
+// codes nearly as well as the solver learns from conflicts.
+type SATEngine struct{}
+
+func (SATEngine) Consistent(size mm.GameSize, f mm.Feedback, history mm.History, n int, rng *rand.Rand) (mm.CodeSlice, bool, error) {
+	s := sat.NewSolver()
+	s.SetRand(rng)
+
+	// x[p][c] is true when position p is color c
+	x := make([][]int, size.Positions)
+	for p := range x {
+		x[p] = make([]int, size.Colors)
+		for c := range x[p] {
+			x[p][c] = s.NewVar()
+		}
+		s.AddClause(x[p]...)
+		s.AtMostOne(x[p])
+	}
+
+	// counts[c][k] is true when the code has at least k+1 pegs of color c;
+	// they're only made for the colors feedback totals need
+	counts := make([][]int, size.Colors)
+	count := func(c byte) []int {
+		if counts[c] == nil {
+			lits := make([]int, size.Positions)
+			for p := range lits {
+				lits[p] = x[p][c]
+			}
+			counts[c] = s.Totalizer(lits)
+		}
+		return counts[c]
+	}
+
+	for _, m := range history {
+		if f != mm.TotalFeedback {
+			black := make([]int, size.Positions)
+			for p, c := range m.Guess {
+				black[p] = x[p][c]
+			}
+			s.Exactly(s.Totalizer(black), m.Result.Correct)
+		}
+		if f == mm.BlackFeedback {
+			continue
+		}
+
+		// pegs of color c score min(code's count, guess's count), ie one
+		// for each k up to the guess's count where the code has at least k
+		guessColors := make([]int, size.Colors)
+		for _, c := range m.Guess {
+			guessColors[c]++
+		}
+		pegs := []int{}
+		for c, g := range guessColors {
+			if g > 0 {
+				pegs = append(pegs, count(byte(c))[:g]...)
+			}
+		}
+		s.Exactly(s.Totalizer(pegs), m.Result.Correct+m.Result.HalfCorrect)
+	}
+
+	found := mm.CodeSlice{}
+	for len(found) < n {
+		if !s.Solve() {
+			return found, true, nil
+		}
+		code := make(mm.Code, size.Positions)
+		block := make([]int, size.Positions)
+		for p := range x {
+			for c, v := range x[p] {
+				if s.Value(v) {
+					code[p] = byte(c)
+					block[p] = -v
+				}
+			}
+		}
+		found = append(found, code)
+		s.AddClause(block...)
+	}
+	// there may be no more, but only another solve would tell
+	return found, !s.Solve(), nil
+}
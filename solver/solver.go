@@ -2,12 +2,11 @@ package solver
 
 import (
 	"fmt"
-	"math"
-	"rn/parallel"
-	"sort"
+	"math/rand"
 	"sync"
 
 	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/pool"
 )
 
 var initialMoves map[mm.GameSize]mm.Code
@@ -29,17 +28,79 @@ func init() {
 type Solver struct {
 	*mm.Game
 	initialMove mm.Code
+	codemaker   mm.Codemaker
+
+	// MemoryBudget caps the bytes spent holding scored guesses each move.
+	// Zero means unlimited.  When scoring all of P would exceed the budget,
+	// only a systematic sample of P is scored.  The sample always contains a
+	// code from S so every move still makes progress, but the chosen guess
+	// may be worse than the true minimax guess, so the worst case move count
+	// is no longer guaranteed.
+	MemoryBudget uint64
+
+	// Heuristic selects how candidate guesses are rated; see heuristic.go.
+	Heuristic Heuristic
+
+	// Priors weights the likelihood of each secret, keyed by Code.String().
+	// Codes without an entry weigh 1.  Only the ExpectedSize and Entropy
+	// heuristics take priors into account.
+	Priors map[string]float64
+
+	// Backend, if set, counts hits for the scoring pass each move, eg across
+	// worker processes with an RPCBackend.  By default the counting is done
+	// on a local goroutine pool.  Backends don't see Priors, so scoring with
+	// priors is always local.
+	Backend Backend
+
+	// Workers is how many goroutines score guesses at once; zero means one
+	// per CPU
+	Workers int
+
+	// ChunkSize, if set, has Solve and Step enumerate P this many codes at
+	// a time each move, scoring each chunk and letting it go, so neither P
+	// nor a score for every code of it is ever held.  Only the best guesses
+	// so far are kept.  Peak memory is then S and a chunk, for the time
+	// taken to enumerate P again every move.  MemoryBudget's sampling
+	// doesn't apply.
+	ChunkSize int
+
+	// the work of the move being chosen, and of the moves Solve has made
+	effort effort
+	stats  Stats
+
+	// rand, if Seed has set it, draws the samples of MemoryBudget in place
+	// of the global source
+	rand *rand.Rand
 }
 
 func NewSolver(g *mm.Game) *Solver {
+	return NewCodemakerSolver(g)
+}
+
+// NewCodemakerSolver returns a solver which plays against any codemaker.
+// Unless cm is itself a *mm.Game, the embedded game only keeps score.
+func NewCodemakerSolver(cm mm.Codemaker) *Solver {
+	g, ok := cm.(*mm.Game)
+	if !ok {
+		g = mm.NewCustomGame(cm.GameSize().Positions, cm.GameSize().Colors)
+	}
 	size := mm.GameSize{g.Positions(), g.Colors()}
 	initialMutex.Lock()
 	if _, ok := initialMoves[size]; !ok {
 		fmt.Printf("calculating initial move for size %v\n", size)
-		game := &Solver{mm.NewCustomGame(g.Positions(), g.Colors()), mm.Code{}}
+		game := &Solver{Game: mm.NewCustomGame(g.Positions(), g.Colors())}
 		S, P := game.allPossibleCodes()
 
-		guess := game.bestGuessOfSet(S, P)
+		var guess mm.Code
+		if CheckpointDir == "" {
+			guess = game.bestGuessOfSet(S, P)
+		} else {
+			var err error
+			if guess, err = game.checkpointedOpener(S, P); err != nil {
+				fmt.Printf("checkpointing initial move failed, starting over: %v\n", err)
+				guess = game.bestGuessOfSet(S, P)
+			}
+		}
 
 		fmt.Printf("game of size %v, initial move: %s\n", size, guess)
 		initialMoves[size] = guess
@@ -47,11 +108,31 @@ func NewSolver(g *mm.Game) *Solver {
 	initialMutex.Unlock()
 	g.Reset()
 	return &Solver{
-		g,
-		initialMoves[size],
+		Game:        g,
+		initialMove: initialMoves[size],
+		codemaker:   cm,
 	}
 }
 
+// Seed makes the solver reproducible: the only random choice it makes is
+// the sample of P it scores under a MemoryBudget
+func (g *Solver) Seed(seed int64) {
+	g.rand = rand.New(rand.NewSource(seed))
+}
+
+// ScoredGuess scores code with the solver's codemaker
+func (g *Solver) ScoredGuess(code mm.Code) (mm.Result, error) {
+	if g.codemaker == nil || g.codemaker == mm.Codemaker(g.Game) {
+		return g.Game.ScoredGuess(code)
+	}
+	g.TurnsTaken++
+	result, err := g.codemaker.ScoredGuess(code)
+	if err == nil && g.IsWin(result) {
+		g.SolveTime = g.Elapsed()
+	}
+	return result, err
+}
+
 func (g *Solver) MustScoredGuess(code mm.Code) mm.Result {
 	r, err := g.ScoredGuess(code)
 	if err != nil {
@@ -60,25 +141,10 @@ func (g *Solver) MustScoredGuess(code mm.Code) mm.Result {
 	return r
 }
 
-func (g *Solver) allPossibleCodes() (mm.CodeSet, mm.CodeSlice) {
-	numPossibleCodes := int(math.Pow(float64(g.Colors()), float64(g.Positions())))
-	set := make(mm.CodeSet, numPossibleCodes)
-	slice := make(mm.CodeSlice, numPossibleCodes)
-
-	for i := 0; i < numPossibleCodes; i++ {
-		remainder := i
-		code := g.EmptyCode()
-		for pos := 0; pos < g.Positions(); pos++ {
-			power := int(math.Pow(float64(g.Colors()), float64(g.Positions()-pos-1)))
-			posVal := int(remainder / power)
-			remainder -= posVal * power
-			code[pos] = byte(posVal)
-		}
-		set[code.String()] = code
-		slice[i] = code
-	}
-
-	return set, slice
+func (g *Solver) allPossibleCodes() (codeSet, mm.CodeSlice) {
+	size := g.GameSize()
+	slice := size.Codes(0, size.NumCodes(), g.Workers)
+	return g.setOf(slice), slice
 }
 
 func (g *Solver) possibleResults() []mm.Result {
@@ -91,68 +157,117 @@ func (g *Solver) possibleResults() []mm.Result {
 	return out
 }
 
-type hitmap map[mm.Result]int
+// hitmap counts codes by the result they score, indexed by its ResultKey
+type hitmap [256]int
 
-func (h hitmap) maxHits() (mm.Result, int) {
+func (h *hitmap) maxHits() (mm.Result, int) {
 	bestScore := 0
 	var bestResult mm.Result
-	for result, count := range h {
-		score := count
-		if score > bestScore {
-			bestScore = score
-			bestResult = result
+	for key, count := range h {
+		if count > bestScore {
+			bestScore = count
+			bestResult = mm.ResultKey(key).Result()
 		}
 	}
 	return bestResult, bestScore
 }
 
-func (g *Solver) emptyHitMap() hitmap {
-	results := g.possibleResults()
-	hm := make(hitmap, len(results))
-	for _, r := range results {
-		hm[r] = 0
-	}
-	return hm
-}
-
-func (g *Solver) selectMovesWithResult(S mm.CodeSet, guess mm.Code, result mm.Result) mm.CodeSet {
-	T := mm.CodeSet{}
-	hitcounts := g.emptyHitMap()
-	for k, s := range S {
-		res2, err := mm.CheckCode(s, guess, g.Colors())
+func (g *Solver) selectMovesWithResult(S codeSet, guess mm.Code, result mm.Result) codeSet {
+	g.counted(S.len(), 0)
+	return g.filter(S, func(s mm.Code) bool {
+		res2, err := g.check(s, guess)
 		if err != nil {
 			panic(err)
 		}
+		return res2 == result
+	})
+}
 
-		hitcounts[res2]++
+// scores guess against code, revealing only what the game's Feedback does.
+// The solver plays reduced feedback games just as it does full ones, though
+// its opening move is always the one chosen for full feedback.
+func (g *Solver) check(guess, code mm.Code) (mm.Result, error) {
+	return g.Feedback.Score(guess, code, g.Colors())
+}
 
-		if res2 == result {
-			T[k] = s
+// returns the codes of all possible codes which are consistent with history,
+// enumerated ChunkSize at a time if it's set, and all at once if not
+func (g *Solver) consistentSet(history mm.History) codeSet {
+	size := g.GameSize()
+	n, chunk := size.NumCodes(), size.NumCodes()
+	if g.ChunkSize > 0 {
+		chunk = g.ChunkSize
+	}
+	var pegs []byte
+	for lo := 0; lo < n; lo += chunk {
+		hi := lo + chunk
+		if hi > n {
+			hi = n
+		}
+		// the codes kept are copied out, so the chunk can go
+		for _, c := range g.consistentAmong(history, size.Codes(lo, hi, g.Workers)) {
+			pegs = append(pegs, c...)
 		}
 	}
-	return T
+	return g.setOfPegs(pegs)
 }
 
-func (g *Solver) countHits(S mm.CodeSet, code mm.Code) hitmap {
-	hitCounts := g.emptyHitMap()
-	for _, s := range S {
-		result, err := mm.CheckCode(code, s, g.Colors())
+// the codes which are consistent with history, each worker checking its own
+// range of them
+func (g *Solver) consistentAmong(history mm.History, codes mm.CodeSlice) mm.CodeSlice {
+	workers := pool.Workers(g.Workers)
+	shards := make([]mm.CodeSlice, workers)
+	pool.Ranges(len(codes), workers, mm.EnumerationGrain, func(i, lo, hi int) {
+		checks := 0
+		for _, s := range codes[lo:hi] {
+			// history.ConsistentWith, counting the checks it makes
+			consistent := true
+			for _, m := range history {
+				checks++
+				if r, err := g.Feedback.Score(m.Guess, s, g.Colors()); err != nil || r != m.Result {
+					consistent = false
+					break
+				}
+			}
+			if consistent {
+				shards[i] = append(shards[i], s)
+			}
+		}
+		g.counted(checks, 0)
+	})
+
+	consistent := shards[0]
+	for _, shard := range shards[1:] {
+		consistent = append(consistent, shard...)
+	}
+	return consistent
+}
+
+// splits S by the result each code would produce for guess
+func (g *Solver) partition(S codeSet, guess mm.Code) map[mm.Result]codeSet {
+	return g.moveOf(guess, S).partition(g)
+}
+
+// counts the codes of S into hits by the result code scores against them
+func (g *Solver) countHits(S codeSet, code mm.Code, hits *hitmap) {
+	g.counted(S.len(), 1)
+	for _, s := range S.codes {
+		result, err := g.check(code, s)
 		if err != nil {
 			panic(err)
 		}
 
-		hitCounts[result]++
+		hits[result.Key()]++
 	}
-	return hitCounts
 }
 
 // returns intersection of S and codes, unless that set has length 0
 // in which case, returns S
-func selectGuesses(S mm.CodeSet, codes mm.CodeSlice) mm.CodeSlice {
+func selectGuesses(S codeSet, codes mm.CodeSlice) mm.CodeSlice {
 	inS := mm.CodeSlice{}
 	notInS := mm.CodeSlice{}
 	for _, g := range codes {
-		if _, ok := S[g.String()]; ok {
+		if S.has(g) {
 			inS = append(inS, g)
 		} else {
 			notInS = append(notInS, g)
@@ -166,34 +281,37 @@ func selectGuesses(S mm.CodeSet, codes mm.CodeSlice) mm.CodeSlice {
 
 // checks every p in P (a complete set of possible codes)
 // against each s in S, scoring p by the maximum codes represented by one unique Result.
-// Returns a map, keyed on score, where score is the total number of codes remaining in S if p is the next guess
-// and the value is the set of codes in P which produce that score across all combinations
-func (g *Solver) score(S mm.CodeSet, P mm.CodeSlice) map[int]mm.CodeSlice {
-	limiter := parallel.NewLimiter(100)
-	guesses := map[int]mm.CodeSlice{}
+// Returns a map, keyed on score, where score is rated by the solver's Heuristic (by default, the total
+// number of codes remaining in S if p is the next guess) and the value is the set of codes in P which
+// produce that score across all combinations
+func (g *Solver) score(S codeSet, P mm.CodeSlice) (map[float64]mm.CodeSlice, error) {
+	if g.Backend != nil && g.Priors == nil {
+		return g.scoreWith(g.Backend, S, P)
+	}
 
-	for _, p := range P {
-		p1 := p
-		limiter.Go(func() error {
-			// count the number of distinct results each possible guess would produce for the remaining set S
-			hitsForResult := g.countHits(S, p1)
+	// score each p by how it partitions the remaining set S; lower is better
+	return scoreShards(P, pool.Workers(g.Workers), func(p mm.Code) float64 { return g.rate(S, p) }), nil
+}
 
-			// score these as the number of possibilities remaining in S after guessing p1
-			_, score := hitsForResult.maxHits()
+// scoreShards rates every code of P, split between workers which each keep
+// the scores of their share to themselves, merged once they're all done
+func scoreShards(P mm.CodeSlice, workers int, rate func(mm.Code) float64) map[float64]mm.CodeSlice {
+	shards := make([]map[float64]mm.CodeSlice, workers)
+	pool.Ranges(len(P), workers, 1, func(i, lo, hi int) {
+		scores := map[float64]mm.CodeSlice{}
+		for _, p := range P[lo:hi] {
+			score := rate(p)
+			scores[score] = append(scores[score], p)
+		}
+		shards[i] = scores
+	})
 
-			limiter.Locked(func() error {
-				if _, ok := guesses[score]; !ok {
-					guesses[score] = mm.CodeSlice{}
-				}
-				guesses[score] = append(guesses[score], p1)
-				return nil
-			})
-			return nil
-		})
+	guesses := map[float64]mm.CodeSlice{}
+	for _, scores := range shards {
+		for score, codes := range scores {
+			guesses[score] = append(guesses[score], codes...)
+		}
 	}
-
-	limiter.Wait()
-
 	return guesses
 }
 
@@ -206,94 +324,315 @@ func (g *Solver) score(S mm.CodeSet, P mm.CodeSlice) map[int]mm.CodeSlice {
 // maximum of these counts (the largest possible set after move p)
 // and then select all codes where this maximum is as small as possible
 // (to ensure the smallest set on the next pass)
-// we then sort this optimal set and return the smallest code.
-func (g *Solver) bestGuessOfSet(S mm.CodeSet, P mm.CodeSlice) mm.Code {
+// of which we return the smallest code, kept track of as we go.
+func (g *Solver) bestGuessOfSet(S codeSet, P mm.CodeSlice) mm.Code {
 	// let's see if we can find a code that minimizes the set of possible next moves
 	minMax := -1
-	codesForMax := map[int]mm.CodeSlice{}
+	var best mm.Code
 	for _, p := range P {
-		hitcount := g.emptyHitMap()
-		for _, s := range S {
-			res, _ := mm.CheckCode(p, s, g.Colors())
-			hitcount[res]++
-		}
-		sum := 0
-		max := 0
-		for _, r := range g.possibleResults() {
-			sum += hitcount[r]
-			if hitcount[r] > max {
-				max = hitcount[r]
-			}
+		var hits hitmap
+		g.countHits(S, p, &hits)
+		_, max := hits.maxHits()
+		if minMax < 0 || max < minMax || max == minMax && p.Compare(best) < 0 {
+			minMax, best = max, p
 		}
-		if _, ok := codesForMax[max]; !ok {
-			codesForMax[max] = mm.CodeSlice{}
+	}
+	return best
+}
+
+// move is a guess with the codes it was chosen against, and the key of the
+// result each of them scores against it, so the codes are split by the
+// guess's result without being scored again
+type move struct {
+	guess   mm.Code
+	S       codeSet
+	results []mm.ResultKey
+}
+
+// moveOf scores S against guess, for a guess not chosen by nextMove
+func (g *Solver) moveOf(guess mm.Code, S codeSet) move {
+	return move{guess: guess, S: S, results: g.resultKeys(guess, S.codes, nil)}
+}
+
+// resultKeys fills keys with the key of the result each of codes scores
+// against guess, as the game's feedback reveals it
+func (g *Solver) resultKeys(guess mm.Code, codes mm.CodeSlice, keys []mm.ResultKey) []mm.ResultKey {
+	results, err := mm.CheckCodes(guess, codes, g.Colors())
+	if err != nil {
+		panic(err)
+	}
+	g.counted(len(codes), 1)
+	keys = keys[:0]
+	for _, r := range results {
+		keys = append(keys, g.Feedback.Reduce(r, g.Positions()).Key())
+	}
+	return keys
+}
+
+// after is the codes which score result against the move's guess
+func (m move) after(g *Solver, result mm.Result) codeSet {
+	key := result.Key()
+	var T mm.CodeSlice
+	for i, k := range m.results {
+		if k == key {
+			T = append(T, m.S.codes[i])
 		}
-		codesForMax[max] = append(codesForMax[max], p)
+	}
+	return g.setOf(T)
+}
+
+// partition splits the codes by the result each scores against the guess
+func (m move) partition(g *Solver) map[mm.Result]codeSet {
+	buckets := map[mm.ResultKey]mm.CodeSlice{}
+	for i, k := range m.results {
+		buckets[k] = append(buckets[k], m.S.codes[i])
+	}
+	partitions := make(map[mm.Result]codeSet, len(buckets))
+	for k, T := range buckets {
+		partitions[k.Result()] = g.setOf(T)
+	}
+	return partitions
+}
 
-		if minMax < 0 || max < minMax {
-			minMax = max
+// bestMove is bestGuessOfSet, keeping the results of S against the guess
+// it picks.  The lesser of codes tied for the smallest largest partition is
+// the one sorting them would put first.
+func (g *Solver) bestMove(S codeSet, P mm.CodeSlice) move {
+	m := move{S: S}
+	var keys []mm.ResultKey
+	minMax := -1
+	for _, p := range P {
+		keys = g.resultKeys(p, S.codes, keys)
+		var hits hitmap
+		for _, k := range keys {
+			hits[k]++
+		}
+		_, max := hits.maxHits()
+		if minMax < 0 || max < minMax || max == minMax && p.Compare(m.guess) < 0 {
+			minMax, m.guess = max, p
+			// the best so far keeps its results, and its old ones are
+			// written over next
+			m.results, keys = keys, m.results
 		}
 	}
+	return m
+}
 
-	sort.Sort(codesForMax[minMax])
+// approximate bytes needed to hold one scored guess: the code itself plus
+// its slice header in the score map
+func (g *Solver) scoredCodeSize() uint64 {
+	return uint64(g.Positions() + 24)
+}
 
-	return codesForMax[minMax][0]
+// returns the codes of P to score this move.  If scoring all of P fits in
+// MemoryBudget, that's P itself; otherwise it's every k-th code of P from a
+// random offset, plus one code of S to guarantee progress.
+func (g *Solver) candidates(S codeSet, P mm.CodeSlice) mm.CodeSlice {
+	if g.MemoryBudget == 0 || uint64(len(P))*g.scoredCodeSize() <= g.MemoryBudget {
+		return P
+	}
+
+	n := int(g.MemoryBudget / g.scoredCodeSize())
+	if n < 2 {
+		n = 2
+	}
+	stride := (len(P) + n - 2) / (n - 1)
+
+	sample := make(mm.CodeSlice, 0, n)
+	hasS := false
+	intn := rand.Intn
+	if g.rand != nil {
+		intn = g.rand.Intn
+	}
+	for i := intn(stride); i < len(P); i += stride {
+		if S.has(P[i]) {
+			hasS = true
+		}
+		sample = append(sample, P[i])
+	}
+	if !hasS {
+		sample = append(sample, S.codes[0])
+	}
+	return sample
 }
 
-func bestScore(scores map[int]mm.CodeSlice) mm.CodeSlice {
-	best := -1
+// bestChunked is bestScore of scoring every code against S, the codes
+// enumerated ChunkSize at a time.  The best of each chunk are copied out of
+// it, and only the best so far are kept.
+func (g *Solver) bestChunked(S codeSet) (mm.CodeSlice, error) {
+	size := g.GameSize()
+	var best mm.CodeSlice
+	var min float64
+	for lo, n := 0, size.NumCodes(); lo < n; lo += g.ChunkSize {
+		hi := lo + g.ChunkSize
+		if hi > n {
+			hi = n
+		}
+		scores, err := g.score(S, size.Codes(lo, hi, g.Workers))
+		if err != nil {
+			return nil, err
+		}
+		for score, codes := range scores {
+			if best != nil && score > min {
+				continue
+			}
+			if best == nil || score < min {
+				best, min = mm.CodeSlice{}, score
+			}
+			for _, c := range codes {
+				best = append(best, append(mm.Code{}, c...))
+			}
+		}
+	}
+	return best, nil
+}
+
+func bestScore(scores map[float64]mm.CodeSlice) mm.CodeSlice {
+	best := -1.0
+	first := true
 	// we want the minimum score, ie the smallest possible S after this move
 	for score, _ := range scores {
-		if best < 0 || score < best {
+		if first || score < best {
 			best = score
+			first = false
 		}
 	}
 	return scores[best]
 }
 
 func (game *Solver) Solve() (mm.Code, error) {
+	// the default game is looked up rather than scored
+	if game.tabled() {
+		return game.solveTabled(defaultTable())
+	}
+
+	game.stats = Stats{}
+
 	// create set S of possible codes
-	S, P := game.allPossibleCodes()
+	var S codeSet
+	var P mm.CodeSlice
+	if game.ChunkSize > 0 {
+		// P is enumerated a chunk at a time each move instead
+		S = game.consistentSet(nil)
+	} else {
+		S, P = game.allPossibleCodes()
+	}
 
-	guess := game.initialMove
+	game.beginMove(S.len())
+	m := game.moveOf(game.initialMove, S)
 
 	for {
-		result := game.MustScoredGuess(guess)
+		result := game.MustScoredGuess(m.guess)
 
 		if game.IsWin(result) {
-			return guess, nil
+			game.endMove(1)
+			return m.guess, nil
 		}
 
-		//  remove from S any code that has a different result than our guess
-		S = game.selectMovesWithResult(S, guess, result)
+		//  keep the codes of S which had the same result as our guess,
+		//  picked out by the results of choosing it
+		S = m.after(game, result)
+		game.endMove(S.len())
+		game.beginMove(S.len())
 
-		// if we're down to two possibilities, shortcut to either of them
-		if len(S) <= 2 {
-			for _, s := range S {
-				guess = s
-			}
-			continue
+		var err error
+		if m, err = game.nextMove(S, P); err != nil {
+			return nil, err
 		}
+	}
 
-		// rank every code in complete set P by how many codes it would remove from S next pass
-		scores := game.score(S, P)
+	return nil, nil
+}
+
+// picks the next guess given S, the codes still possible, and P, the codes
+// to choose from
+func (game *Solver) nextGuess(S codeSet, P mm.CodeSlice) (mm.Code, error) {
+	m, err := game.nextMove(S, P)
+	return m.guess, err
+}
+
+// nextMove is nextGuess, with the results of S against the guess kept from
+// choosing it
+func (game *Solver) nextMove(S codeSet, P mm.CodeSlice) (move, error) {
+	// if we're down to two possibilities, shortcut to either of them; the
+	// lesser, so the same game always gets the same guess
+	if S.len() <= 2 {
+		var guess mm.Code
+		if S.len() > 0 {
+			guess = S.codes[0]
+		}
+		return game.moveOf(guess, S), nil
+	}
 
-		// choose the set of codes with the optimal (minimum) score.  Minimum score means
-		// the fewest codes remaining in S after choosing any of these codes
-		bestGuesses := bestScore(scores)
+	// rank every code in complete set P by how many codes it would remove from S next pass
+	// (or a sample of P, if scoring all of it would blow the memory budget),
+	// and choose the set of codes with the optimal (minimum) score.  Minimum score means
+	// the fewest codes remaining in S after choosing any of these codes
+	var bestGuesses mm.CodeSlice
+	if P == nil && game.ChunkSize > 0 {
+		chunk := game.GameSize().NumCodes()
+		if game.ChunkSize < chunk {
+			chunk = game.ChunkSize
+		}
+		game.holding(S.len() + chunk)
+		var err error
+		if bestGuesses, err = game.bestChunked(S); err != nil {
+			return move{}, err
+		}
+	} else {
+		candidates := game.candidates(S, P)
+		game.holding(S.len() + len(candidates))
+		scores, err := game.score(S, candidates)
+		if err != nil {
+			return move{}, err
+		}
+		bestGuesses = bestScore(scores)
+	}
 
-		// bestGuesses now contains all guesses which minimize S on the next move.
-		// bestGuesses can be split into two sets, those contained in S, and those not.
-		// if the set of guesses contained in S is empty, choose a best guess from the remainder.
-		potentialGuesses := selectGuesses(S, bestGuesses)
+	// bestGuesses now contains all guesses which minimize S on the next move.
+	// bestGuesses can be split into two sets, those contained in S, and those not.
+	// if the set of guesses contained in S is empty, choose a best guess from the remainder.
+	potentialGuesses := selectGuesses(S, bestGuesses)
+
+	// even though every code in potentialGuesses will produce the same size S' next pass,
+	// the distribution of codes in S' wrt Results on the next pass varies depending on which
+	// of these codes we choose as our next guess.
+	// Optimal solution involves choosing a code such that the maximum set of codes producing the same Result
+	// is minimized.
+	return game.bestMove(S, potentialGuesses), nil
+}
 
-		// even though every code in potentialGuesses will produce the same size S' next pass,
-		// the distribution of codes in S' wrt Results on the next pass varies depending on which
-		// of these codes we choose as our next guess.
-		// Optimal solution involves choosing a code such that the maximum set of codes producing the same Result
-		// is minimized.
-		guess = game.bestGuessOfSet(S, potentialGuesses)
+// Step returns the guess Solve would make next in the game played so far.
+// It doesn't need the solver's opener, so a bare &Solver{Game: g} will do.
+func (game *Solver) Step(history mm.History) (mm.Code, error) {
+	S := game.consistentSet(history)
+	if S.len() == 0 {
+		return nil, fmt.Errorf("no code is consistent with %v", history)
+	}
+	var P mm.CodeSlice
+	if size := game.GameSize(); game.ChunkSize == 0 {
+		P = size.Codes(0, size.NumCodes(), game.Workers)
 	}
+	return game.nextGuess(S, P)
+}
 
-	return nil, nil
+// Possible returns the codes which could still be the secret after history,
+// in order
+func (game *Solver) Possible(history mm.History) mm.CodeSlice {
+	return game.consistentSet(history).codes
+}
+
+// Opener is the solver's first guess
+func (game *Solver) Opener() mm.Code {
+	return game.initialMove
+}
+
+// StepAmong is Step for boards too big to enumerate: S is taken to be
+// candidates, and the guess is one of them, eg from the eligible set of a
+// genetic search.
+func (game *Solver) StepAmong(candidates mm.CodeSlice) (mm.Code, error) {
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no candidates to choose from")
+	}
+	return game.nextGuess(game.setAmong(candidates), candidates)
 }
@@ -1,8 +1,15 @@
 package solver
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"math"
+	"math/rand"
+	"net"
+	"os"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -26,21 +33,24 @@ func TestAllPossibleCodes(t *testing.T) {
 
 	codes, _ := game.allPossibleCodes()
 
-	for k, v := range codes {
+	for i, v := range codes.codes {
 		// assure valid
-		if k != v.String() {
-			t.Error("map entry %s contains code %s", k, v)
-		}
 		if !game.validCode(v) {
 			t.Error("Invalid code: %s", v)
 		}
-		// code is a map, assures no duplicates
+		// assure in order without duplicates
+		if i > 0 && codes.codes[i-1].Compare(v) >= 0 {
+			t.Error("code %s follows %s", v, codes.codes[i-1])
+		}
+		if !codes.has(v) {
+			t.Error("set doesn't have its code %s", v)
+		}
 	}
 	// assure correct number
 	expected := int(math.Pow(float64(game.Colors()), float64(game.Positions())))
-	if len(codes) != expected {
+	if codes.len() != expected {
 		t.Error("Should be %d (%d^%d) possible codes, only %d codes returned",
-			expected, game.Colors(), game.Positions(), len(codes))
+			expected, game.Colors(), game.Positions(), codes.len())
 	}
 }
 
@@ -85,8 +95,8 @@ func TestAllPossible(t *testing.T) {
 	var worstCaseCode mm.Code
 
 	codes, _ := NewSolver(mm.NewGame()).allPossibleCodes()
-	numGames := len(codes)
-	for _, code := range codes {
+	numGames := codes.len()
+	for _, code := range codes.codes {
 		solver := NewSolver(mm.NewCustomGameWithSecret(4, 6, code))
 
 		winner, err := solver.Solve()
@@ -118,3 +128,1028 @@ func BenchmarkSolution(b *testing.B) {
 		solver.Solve()
 	}
 }
+
+func TestMemoryBudget(t *testing.T) {
+	_, codes := NewSolver(mm.NewGame()).allPossibleCodes()
+	for _, code := range codes[:50] {
+		solver := NewSolver(mm.NewCustomGameWithSecret(4, 6, code))
+		// room for roughly 100 scored guesses per move
+		solver.MemoryBudget = 100 * solver.scoredCodeSize()
+
+		winner, err := solver.Solve()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !solver.IsWinner(winner) {
+			t.Errorf("Solution for %s incorrect with memory budget! Got %s", code, winner)
+		}
+	}
+}
+
+func TestHeuristics(t *testing.T) {
+	_, codes := NewSolver(mm.NewGame()).allPossibleCodes()
+	for _, h := range []Heuristic{ExpectedSize, Entropy} {
+		for _, code := range codes[:20] {
+			solver := NewSolver(mm.NewCustomGameWithSecret(4, 6, code))
+			solver.Heuristic = h
+
+			winner, err := solver.Solve()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !solver.IsWinner(winner) {
+				t.Errorf("%v: solution for %s incorrect! Got %s", h, code, winner)
+			}
+		}
+	}
+}
+
+func TestPriors(t *testing.T) {
+	solver := NewSolver(mm.NewGame())
+	solver.Heuristic = ExpectedSize
+	S, _ := solver.allPossibleCodes()
+
+	// with all the weight on one code, guessing it leaves almost nothing
+	solver.Priors = map[string]float64{}
+	for _, c := range S.codes {
+		solver.Priors[c.String()] = 0
+	}
+	solver.Priors["1234"] = 1
+
+	favored := solver.rate(S, mm.Code{1, 2, 3, 4})
+	other := solver.rate(S, mm.Code{5, 5, 0, 0})
+	if favored != 1 {
+		t.Errorf("expected size of guessing the only weighted code should be 1, got %.2f", favored)
+	}
+	if other <= favored {
+		t.Errorf("unweighted guess scored %.2f, should be worse than %.2f", other, favored)
+	}
+}
+
+func TestWeightAllocs(t *testing.T) {
+	solver := NewSolver(mm.NewGame())
+	solver.Priors = map[string]float64{"1234": 2}
+	code := mm.Code{1, 2, 3, 4}
+	if allocs := testing.AllocsPerRun(100, func() { solver.weight(code) }); allocs != 0 {
+		t.Errorf("expected looking up a prior to allocate nothing, got %v allocations", allocs)
+	}
+}
+
+func TestMultiSolver(t *testing.T) {
+	games := make([]*mm.Game, 3)
+	for i := range games {
+		games[i] = mm.NewGame()
+	}
+	solver, err := NewMultiSolver(games...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	winners, err := solver.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, g := range games {
+		if !g.IsWinner(winners[i]) {
+			t.Errorf("Solution for board %d incorrect! Got %s", i, winners[i])
+		}
+	}
+	fmt.Printf("Solved %d boards in %d shared moves\n", len(games), solver.Turns)
+
+	if _, err := NewMultiSolver(mm.NewGame(), mm.NewCustomGame(5, 6)); err == nil {
+		t.Error("boards of different sizes should not be accepted")
+	}
+}
+
+func TestStaticGuesses(t *testing.T) {
+	solver := NewSolver(mm.NewGame())
+	guesses, decode := solver.StaticGuesses()
+	fmt.Printf("Static guess set for %v: %v\n", solver.GameSize(), guesses)
+
+	_, codes := solver.allPossibleCodes()
+	for _, code := range codes {
+		results := make([]mm.Result, len(guesses))
+		for i, guess := range guesses {
+			results[i], _ = mm.CheckCode(guess, code, solver.Colors())
+		}
+		decoded, err := decode(results)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.String() != code.String() {
+			t.Errorf("results for %s decoded to %s", code, decoded)
+		}
+	}
+
+	if _, err := decode(nil); err == nil {
+		t.Error("decoding the wrong number of results should fail")
+	}
+}
+
+func TestWinnableWithin(t *testing.T) {
+	solver := NewSolver(mm.NewGame())
+
+	// no opener leaves few enough codes to finish in 3 more
+	if solver.WinnableWithin(nil, 4) {
+		t.Error("4x6 should not be winnable within 4 moves")
+	}
+
+	history := mm.History{{Guess: mm.Code{0, 0, 1, 1}, Result: mm.Result{Correct: 1, HalfCorrect: 1}}}
+	if !solver.WinnableWithin(history, 4) {
+		t.Errorf("after %v the game should be winnable within 4 moves", history)
+	}
+	if solver.WinnableWithin(history, 1) {
+		t.Errorf("after %v the game should not be winnable within 1 move", history)
+	}
+
+	history = append(history, mm.Move{Guess: mm.Code{0, 0, 1, 1}, Result: mm.Result{Correct: 4}})
+	if solver.WinnableWithin(history, 5) {
+		t.Errorf("no code is consistent with %v", history)
+	}
+}
+
+func TestCounterexample(t *testing.T) {
+	newSolver := func(cm mm.Codemaker) mm.Solver { return NewCodemakerSolver(cm) }
+	worst, moves, err := mm.Counterexample(mm.GameSize{4, 6}, newSolver, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("Worst case %s in %d moves\n", worst, moves)
+	if moves != 5 {
+		t.Errorf("search should stop at the first secret taking 5 moves, got %s in %d", worst, moves)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	solver := NewSolver(mm.NewGame())
+	secret := mm.Code{3, 1, 5, 2}
+
+	history := mm.History{}
+	for _, guess := range []mm.Code{{0, 0, 1, 1}, {1, 2, 3, 4}} {
+		r, _ := mm.CheckCode(guess, secret, solver.Colors())
+		history = append(history, mm.Move{Guess: guess, Result: r})
+	}
+
+	c, err := solver.Complete(history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Remaining != solver.consistentSet(history).len() {
+		t.Errorf("completion reports %d codes remaining, expected %d", c.Remaining, solver.consistentSet(history).len())
+	}
+	if !solver.WinnableWithin(history, c.Bound) || solver.WinnableWithin(history, c.Bound-1) {
+		t.Errorf("bound %d after %v is not tight", c.Bound, history)
+	}
+
+	// following the completion wins within the bound
+	for moves := 1; ; moves++ {
+		r, _ := mm.CheckCode(c.Guess, secret, solver.Colors())
+		if solver.IsWin(r) {
+			break
+		}
+		if moves >= c.Bound {
+			t.Fatalf("not solved within bound of %d", c.Bound)
+		}
+		history = append(history, mm.Move{Guess: c.Guess, Result: r})
+		next, err := solver.Complete(history)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next.Bound > c.Bound-1 {
+			t.Errorf("bound went from %d to %d after a move", c.Bound, next.Bound)
+		}
+		c = next
+	}
+
+	history = append(history, mm.Move{Guess: secret, Result: mm.Result{}})
+	if _, err := solver.Complete(history); err == nil {
+		t.Error("completing an inconsistent history should fail")
+	}
+}
+
+func TestRPCBackend(t *testing.T) {
+	addrs := []string{}
+	for i := 0; i < 2; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Skip("can't listen on loopback:", err)
+		}
+		defer l.Close()
+		go ServeWorker(l, 4)
+		addrs = append(addrs, l.Addr().String())
+	}
+
+	backend, err := DialBackend(addrs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+
+	_, codes := NewSolver(mm.NewGame()).allPossibleCodes()
+	for _, code := range codes[:10] {
+		solver := NewSolver(mm.NewCustomGameWithSecret(4, 6, code))
+		solver.Backend = backend
+
+		winner, err := solver.Solve()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !solver.IsWinner(winner) {
+			t.Errorf("Solution for %s incorrect with RPC backend! Got %s", code, winner)
+		}
+	}
+}
+
+func TestCheckpointedOpener(t *testing.T) {
+	dir, err := ioutil.TempDir("", "opener")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	CheckpointDir = dir
+	defer func() { CheckpointDir = "" }()
+
+	game := &Solver{Game: mm.NewCustomGame(3, 4)}
+	S, P := game.allPossibleCodes()
+
+	guess, err := game.checkpointedOpener(S, P)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := game.bestGuessOfSet(S, P); guess.String() != expected.String() {
+		t.Errorf("checkpointed opener %s differs from %s", guess, expected)
+	}
+
+	// a finished checkpoint is used as is
+	cp := &openerCheckpoint{Size: game.GameSize(), Scored: len(P), MinMax: 1, Best: mm.CodeSlice{{3, 2, 1}}}
+	if err := cp.save(); err != nil {
+		t.Fatal(err)
+	}
+	if guess, err = game.checkpointedOpener(S, P); err != nil || guess.String() != "321" {
+		t.Errorf("expected to resume with 321, got %s (%v)", guess, err)
+	}
+
+	// a partial one picks up where it left off
+	cp = &openerCheckpoint{Size: game.GameSize(), Scored: len(P) / 2, MinMax: 0, Best: mm.CodeSlice{{3, 2, 1}}}
+	if err := cp.save(); err != nil {
+		t.Fatal(err)
+	}
+	if guess, err = game.checkpointedOpener(S, P); err != nil || guess.String() != "321" {
+		t.Errorf("expected to resume with 321, got %s (%v)", guess, err)
+	}
+}
+
+func TestApproxSolver(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		game := mm.NewCustomGame(5, 8)
+		solver := NewApproxSolver(game)
+		solver.Seed(int64(i))
+		solver.SampleSize = 200
+		solver.Candidates = 50
+
+		winner, err := solver.Solve()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !game.IsWinner(winner) {
+			t.Errorf("Solution incorrect! Got %s", winner)
+		}
+		fmt.Printf("Approximate solver took %d moves on 5x8\n", solver.TurnsTaken)
+	}
+}
+
+func TestReducedFeedback(t *testing.T) {
+	_, codes := NewSolver(mm.NewGame()).allPossibleCodes()
+	for _, f := range []mm.Feedback{mm.BlackFeedback, mm.TotalFeedback} {
+		worstCaseMoves := 0
+		for _, code := range codes[:30] {
+			solver := NewSolver(mm.NewCustomGameWithSecret(4, 6, code))
+			solver.Feedback = f
+
+			winner, err := solver.Solve()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !solver.IsWinner(winner) {
+				t.Errorf("%v feedback: solution for %s incorrect! Got %s", f, code, winner)
+			}
+			if solver.TurnsTaken > worstCaseMoves {
+				worstCaseMoves = solver.TurnsTaken
+			}
+		}
+		fmt.Printf("Worst case with %v feedback: %d moves\n", f, worstCaseMoves)
+	}
+
+	// total feedback on big boards can only tell permutations apart by
+	// guessing them one at a time, so only black feedback is tried here
+	game := mm.NewCustomGame(5, 8)
+	game.Feedback = mm.BlackFeedback
+	approx := NewApproxSolver(game)
+	approx.Feedback = mm.BlackFeedback
+	approx.SampleSize = 200
+	approx.Candidates = 50
+	winner, err := approx.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !game.IsWinner(winner) {
+		t.Errorf("black feedback: approximate solution incorrect! Got %s", winner)
+	}
+}
+
+func TestStep(t *testing.T) {
+	secret := mm.Code{3, 1, 5, 2}
+	solver := &Solver{Game: mm.NewGame()}
+
+	// stepping through a game never takes more than Knuth's five moves
+	history := mm.History{}
+	guess := mm.Code{0, 0, 1, 1}
+	for moves := 1; ; moves++ {
+		r, _ := mm.CheckCode(guess, secret, solver.Colors())
+		if solver.IsWin(r) {
+			break
+		}
+		if moves >= 5 {
+			t.Fatalf("not solved in 5 moves: %v", history)
+		}
+		history = append(history, mm.Move{Guess: guess, Result: r})
+
+		var err error
+		if guess, err = solver.Step(history); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := solver.Step(mm.History{{Guess: secret, Result: mm.Result{0, 0}}, {Guess: secret, Result: mm.Result{4, 0}}}); err == nil {
+		t.Error("expected an error stepping through an impossible history")
+	}
+
+	candidates := mm.CodeSlice{{0, 1, 2, 3}, {1, 0, 2, 3}, {0, 1, 3, 2}}
+	guess, err := solver.StepAmong(candidates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, c := range candidates {
+		found = found || c.String() == guess.String()
+	}
+	if !found {
+		t.Errorf("%v isn't one of the candidates %v", guess, candidates)
+	}
+}
+
+func TestSATEngine(t *testing.T) {
+	solver := NewSolver(mm.NewGame())
+	secret := mm.Code{3, 1, 5, 1}
+	for _, f := range []mm.Feedback{mm.FullFeedback, mm.TotalFeedback, mm.BlackFeedback} {
+		solver.Feedback = f
+		history := mm.History{}
+		for _, guess := range []mm.Code{{0, 0, 1, 1}, {1, 2, 3, 4}} {
+			r, _ := f.Score(guess, secret, solver.Colors())
+			history = append(history, mm.Move{Guess: guess, Result: r})
+		}
+
+		S := solver.consistentSet(history)
+		found, all, err := SATEngine{}.Consistent(solver.Size, f, history, S.len()+1, rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !all || len(found) != S.len() {
+			t.Errorf("%v feedback: found %d codes (all: %v), expected all %d", f, len(found), all, S.len())
+		}
+		for _, c := range found {
+			if !S.has(c) {
+				t.Errorf("%v feedback: %v isn't consistent with %v", f, c, history)
+			}
+		}
+
+		if found, all, _ := (SATEngine{}).Consistent(solver.Size, f, history, 3, nil); len(found) != 3 || all != (S.len() == 3) {
+			t.Errorf("%v feedback: asked for 3 codes, got %d (all: %v)", f, len(found), all)
+		}
+	}
+
+	impossible := mm.History{{Guess: secret, Result: mm.Result{0, 0}}, {Guess: secret, Result: mm.Result{2, 0}}}
+	if found, all, _ := (SATEngine{}).Consistent(solver.Size, mm.FullFeedback, impossible, 10, nil); len(found) != 0 || !all {
+		t.Errorf("found %v consistent with an impossible history", found)
+	}
+
+	// a big alphabet
+	game := mm.NewCustomGame(5, 20)
+	approx := NewApproxSolver(game)
+	approx.Seed(1)
+	approx.Engine = SATEngine{}
+	approx.SampleSize = 50
+	approx.Candidates = 20
+	winner, err := approx.Solve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !game.IsWinner(winner) {
+		t.Errorf("Solution incorrect! Got %s", winner)
+	}
+	fmt.Printf("SAT engine took %d moves on 5x20 in %v\n", approx.TurnsTaken, approx.SolveTime)
+}
+
+func TestPartition(t *testing.T) {
+	game := &Solver{Game: mm.NewCustomGame(4, 6)}
+	p, err := game.Partition(nil, mm.Code{0, 0, 1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Knuth's table for 1122
+	expected := map[mm.Result]int{
+		{Correct: 4}: 1, {Correct: 3}: 20, {Correct: 2, HalfCorrect: 2}: 4, {Correct: 2, HalfCorrect: 1}: 32,
+		{Correct: 2}: 114, {Correct: 1, HalfCorrect: 2}: 36, {Correct: 1, HalfCorrect: 1}: 208, {Correct: 1}: 256,
+		{HalfCorrect: 4}: 1, {HalfCorrect: 3}: 16, {HalfCorrect: 2}: 96, {HalfCorrect: 1}: 256, {}: 256,
+	}
+	if p.Total != 1296 || len(p.Buckets) != len(expected) || p.Largest() != 256 || p.Score != 256 {
+		t.Fatalf("unexpected partition %d codes, %d buckets, largest %d, score %v", p.Total, len(p.Buckets), p.Largest(), p.Score)
+	}
+	for i, b := range p.Buckets {
+		if len(b.Codes) != expected[b.Result] {
+			t.Errorf("%v: expected %d codes, got %d", b.Result, expected[b.Result], len(b.Codes))
+		}
+		if i > 0 && b.Result.Correct > p.Buckets[i-1].Result.Correct {
+			t.Errorf("buckets out of order at %v", b.Result)
+		}
+	}
+
+	history := mm.History{{Guess: mm.Code{0, 0, 1, 1}, Result: mm.Result{Correct: 2, HalfCorrect: 2}}}
+	if p, err = game.Partition(history, mm.Code{0, 1, 0, 1}); err != nil || p.Total != 4 {
+		t.Errorf("expected 4 codes left, got %+v %v", p, err)
+	}
+	if _, err = game.Partition(nil, mm.Code{0, 6, 1, 1}); err == nil {
+		t.Error("expected a bad guess to fail")
+	}
+}
+
+func TestTree(t *testing.T) {
+	game := &Solver{Game: mm.NewCustomGame(3, 4)}
+	tree, err := game.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := tree.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+	read, err := ReadTree(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Size != tree.Size || read.Nodes() != tree.Nodes() {
+		t.Fatalf("read back %v with %d nodes, wrote %v with %d", read.Size, read.Nodes(), tree.Size, tree.Nodes())
+	}
+
+	// the tree plays every game as Step does
+	for _, secret := range read.Size.AllCodes() {
+		var history mm.History
+		for {
+			expected, err := game.Step(history)
+			if err != nil {
+				t.Fatal(err)
+			}
+			guess, err := read.Step(history)
+			if err != nil {
+				t.Fatalf("%v: %v", secret, err)
+			}
+			if guess.String() != expected.String() {
+				t.Fatalf("%v after %v: tree guesses %v, Step %v", secret, history, guess, expected)
+			}
+			if guess.String() == secret.String() {
+				break
+			}
+			r, _ := mm.FullFeedback.Score(guess, secret, 4)
+			history = append(history, mm.Move{Guess: guess, Result: r})
+		}
+		if moves, err := read.Play(secret); err != nil || moves != len(history)+1 {
+			t.Errorf("%v: played in %d moves, %v; expected %d", secret, moves, err, len(history)+1)
+		}
+	}
+
+	// and takes the moves a solver does
+	counts, err := read.MoveCounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	played, err := mm.CountMoves(read.Size, func(cm mm.Codemaker) mm.Solver { return NewCodemakerSolver(cm) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts.String() != played.String() || counts.Distribution[counts.Worst()] != played.Distribution[played.Worst()] {
+		t.Errorf("expected the tree to take the moves a solver does, %v, got %v", played, counts)
+	}
+
+	newer := append([]byte{}, encoded...)
+	newer[len(treeMagic)+1] = TreeVersion + 1
+	for _, bad := range [][]byte{nil, []byte("MMSX\x00\x01"), newer, encoded[:len(encoded)/2]} {
+		if _, err := ReadTree(bytes.NewReader(bad)); err == nil {
+			t.Errorf("read bad tree %q", bad)
+		}
+	}
+}
+
+func TestProve(t *testing.T) {
+	game := &Solver{Game: mm.NewCustomGame(3, 4)}
+	tree, err := game.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	worst := 0
+	for _, secret := range tree.Size.AllCodes() {
+		if moves, _ := tree.Play(secret); moves > worst {
+			worst = moves
+		}
+	}
+
+	for _, workers := range []int{1, 4} {
+		if c, err := game.Prove(worst, workers); c != nil || err != nil {
+			t.Errorf("%d workers: expected %d moves to hold, got %+v %v", workers, worst, c, err)
+		}
+		c, err := game.Prove(worst-1, workers)
+		if err != nil || c == nil {
+			t.Fatalf("%d workers: expected a counterexample to %d moves, got %v", workers, worst-1, err)
+		}
+		if moves, _ := tree.Play(c.Secret); moves < worst || len(c.Moves) != worst-1 {
+			t.Errorf("%d workers: %v takes %d moves, with %d shown", workers, c.Secret, moves, len(c.Moves))
+		}
+		for _, m := range c.Moves {
+			if r, _ := mm.FullFeedback.Score(m.Guess, c.Secret, 4); r != m.Result {
+				t.Errorf("%d workers: %v doesn't score %v against %v", workers, m.Guess, m.Result, c.Secret)
+			}
+		}
+	}
+}
+
+func TestSuspects(t *testing.T) {
+	game := &Solver{Game: mm.NewCustomGame(4, 6)}
+	secret := mm.Code{3, 1, 5, 2}
+	var history mm.History
+	for _, guess := range []mm.Code{{0, 0, 1, 1}, {1, 2, 2, 3}, {3, 4, 1, 5}, {3, 1, 2, 5}} {
+		r, _ := mm.CheckCode(guess, secret, 6)
+		history = append(history, mm.Move{Guess: guess, Result: r})
+	}
+	if s := game.Suspects(history); s != nil {
+		t.Errorf("expected no suspects in a consistent history, got %v", s)
+	}
+
+	truth := history[1].Result
+	history[1].Result = mm.Result{Correct: 3}
+	suspects := game.Suspects(history)
+	found := false
+	for _, s := range suspects {
+		if s.Move != 1 {
+			continue
+		}
+		for _, r := range s.Results {
+			found = found || r == truth
+		}
+	}
+	if !found {
+		t.Errorf("expected move 1 to be suspected of being %v, got %+v", truth, suspects)
+	}
+
+	// no one wrong answer explains three codes all being right
+	three := mm.History{
+		{Guess: mm.Code{0, 0, 0, 0}, Result: mm.Result{Correct: 4}},
+		{Guess: mm.Code{1, 1, 1, 1}, Result: mm.Result{Correct: 4}},
+		{Guess: mm.Code{2, 2, 2, 2}, Result: mm.Result{Correct: 4}},
+	}
+	if s := game.Suspects(three); s == nil || len(s) != 0 {
+		t.Errorf("expected an inconsistent history with no suspects, got %v", s)
+	}
+}
+
+// recorder is a codemaker which notes the guesses it scores
+type recorder struct {
+	*mm.Game
+	guesses mm.CodeSlice
+}
+
+func (r *recorder) ScoredGuess(code mm.Code) (mm.Result, error) {
+	r.guesses = append(r.guesses, code)
+	return r.Game.ScoredGuess(code)
+}
+
+func TestTable(t *testing.T) {
+	table := defaultTable()
+	if !sort.IsSorted(table.codes) {
+		t.Error("expected the table's codes in order")
+	}
+
+	// the table makes the guesses scoring would, for a sample of secrets
+	stepper := &Solver{Game: mm.NewGame()}
+	for i := 0; i < len(table.codes); i += 31 {
+		secret := table.codes[i]
+		r := &recorder{Game: mm.NewCustomGameWithSecret(4, 6, secret)}
+		solver := NewCodemakerSolver(r)
+		if !solver.tabled() {
+			t.Fatal("expected the default game tabled")
+		}
+		if winner, err := solver.Solve(); err != nil || winner.Compare(secret) != 0 {
+			t.Fatalf("expected %v solved, got %v (%v)", secret, winner, err)
+		}
+
+		expected := mm.CodeSlice{solver.Opener()}
+		history := mm.History{}
+		for guess := expected[0]; guess.Compare(secret) != 0; expected = append(expected, guess) {
+			history = append(history, mm.Move{Guess: guess, Result: mm.Score(guess, secret, 6)})
+			var err error
+			if guess, err = stepper.Step(history); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if fmt.Sprint(r.guesses) != fmt.Sprint(expected) {
+			t.Errorf("%v: expected guesses %v, got %v", secret, expected, r.guesses)
+		}
+	}
+
+	for r, guess := range secondMoves4x6 {
+		step, err := stepper.Step(mm.History{{Guess: mm.Code{0, 0, 1, 1}, Result: r}})
+		if err != nil || step.Compare(guess) != 0 {
+			t.Errorf("after 0011 scores %v, expected %v, got %v (%v)", r, step, guess, err)
+		}
+	}
+
+	// other heuristics are scored as ever
+	solver := NewSolver(mm.NewGame())
+	solver.Heuristic = Entropy
+	if solver.tabled() {
+		t.Error("expected an entropy solver not to be tabled")
+	}
+}
+
+func BenchmarkScore(b *testing.B) {
+	game := &Solver{Game: mm.NewGame()}
+	S := game.consistentSet(mm.History{{Guess: mm.Code{0, 0, 1, 1}, Result: mm.Result{1, 0}}})
+	_, P := game.allPossibleCodes()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		game.score(S, P)
+	}
+}
+
+func BenchmarkPartition(b *testing.B) {
+	game := &Solver{Game: mm.NewGame()}
+	S, _ := game.allPossibleCodes()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		game.partition(S, mm.Code{0, 0, 1, 1})
+	}
+}
+
+func TestNextMove(t *testing.T) {
+	for _, feedback := range []mm.Feedback{mm.FullFeedback, mm.BlackFeedback} {
+		game := &Solver{Game: mm.NewGame()}
+		game.Feedback = feedback
+		S := game.consistentSet(mm.History{{Guess: mm.Code{0, 0, 1, 1}, Result: game.Feedback.Reduce(mm.Result{1, 1}, 4)}})
+		_, P := game.allPossibleCodes()
+
+		// the move keeps the results which picked its guess, and splits S
+		// by them just as scoring it again would
+		m, err := game.nextMove(S, P)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := game.bestGuessOfSet(S, selectGuesses(S, bestScore(must(game.score(S, P))))); m.guess.Compare(expected) != 0 {
+			t.Errorf("%v: expected %v, got %v", feedback, expected, m.guess)
+		}
+		partitions := m.partition(game)
+		for r, T := range game.partition(S, m.guess) {
+			if fmt.Sprint(T.codes) != fmt.Sprint(partitions[r].codes) || fmt.Sprint(T.codes) != fmt.Sprint(m.after(game, r).codes) {
+				t.Errorf("%v: expected %v after %v scores %v, got %v and %v", feedback, T.codes, m.guess, r, partitions[r].codes, m.after(game, r).codes)
+			}
+		}
+		if len(partitions) != len(game.partition(S, m.guess)) {
+			t.Errorf("%v: expected %d partitions, got %d", feedback, len(game.partition(S, m.guess)), len(partitions))
+		}
+	}
+}
+
+func must(scores map[float64]mm.CodeSlice, err error) map[float64]mm.CodeSlice {
+	if err != nil {
+		panic(err)
+	}
+	return scores
+}
+
+func TestScoreShards(t *testing.T) {
+	_, P := NewSolver(mm.NewCustomGame(3, 4)).allPossibleCodes()
+	for _, workers := range []int{1, 7, 100} {
+		scores := scoreShards(P, workers, func(p mm.Code) float64 { return float64(p[0]) })
+		n := 0
+		for score, codes := range scores {
+			for _, c := range codes {
+				if float64(c[0]) != score {
+					t.Errorf("%d workers: %v scored %v", workers, c, score)
+				}
+			}
+			n += len(codes)
+		}
+		if n != len(P) || len(scores) != 4 {
+			t.Errorf("%d workers: expected %d codes in 4 scores, got %d in %d", workers, len(P), n, len(scores))
+		}
+	}
+}
+
+func TestConsistentSetWorkers(t *testing.T) {
+	// 6x7 is two ranges' worth of codes, so the workers split them
+	history := mm.History{{Guess: mm.Code{0, 0, 1, 1, 2, 2}, Result: mm.Result{1, 2}}}
+	var sets []codeSet
+	for _, workers := range []int{1, 4} {
+		game := &Solver{Game: mm.NewCustomGame(6, 7), Workers: workers}
+		sets = append(sets, game.consistentSet(history))
+	}
+	if sets[0].len() == 0 || fmt.Sprint(sets[0].codes) != fmt.Sprint(sets[1].codes) {
+		t.Errorf("expected 1 and 4 workers to find the same %d codes, got %d", sets[0].len(), sets[1].len())
+	}
+}
+
+func TestChunked(t *testing.T) {
+	// scoring P a chunk at a time picks the same guesses as scoring it whole
+	histories := []mm.History{
+		nil,
+		{{Guess: mm.Code{0, 0, 1, 1}, Result: mm.Result{1, 1}}},
+		{{Guess: mm.Code{0, 0, 1, 1}, Result: mm.Result{0, 1}}, {Guess: mm.Code{1, 2, 2, 3}, Result: mm.Result{1, 0}}},
+	}
+	for _, h := range histories {
+		whole := &Solver{Game: mm.NewGame()}
+		chunked := &Solver{Game: mm.NewGame(), ChunkSize: 97}
+		expected, err := whole.Step(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if guess, err := chunked.Step(h); err != nil || guess.Compare(expected) != 0 {
+			t.Errorf("after %v: expected %v, got %v (%v)", h, expected, guess, err)
+		}
+	}
+
+	for _, secret := range []mm.Code{{0, 1, 2}, {4, 4, 0}, {3, 2, 1}} {
+		moves := map[int]int{}
+		for _, chunk := range []int{0, 10} {
+			solver := NewSolver(mm.NewCustomGameWithSecret(3, 5, secret))
+			solver.Quiet = true
+			solver.ChunkSize = chunk
+			if _, err := solver.Solve(); err != nil {
+				t.Fatal(err)
+			}
+			moves[chunk] = solver.TurnsTaken
+		}
+		if moves[0] != moves[10] {
+			t.Errorf("%v: solved in %d moves whole, %d chunked", secret, moves[0], moves[10])
+		}
+	}
+}
+
+func BenchmarkApproxMove(b *testing.B) {
+	secret := mm.Code{7, 3, 0, 9, 1, 4, 4, 2}
+	history := mm.History{}
+	for _, guess := range []mm.Code{{0, 0, 1, 1, 2, 2, 3, 3}, {4, 4, 5, 5, 6, 6, 7, 7}} {
+		history = append(history, mm.Move{Guess: guess, Result: mm.Score(guess, secret, 10)})
+	}
+	approx := NewApproxSolver(mm.NewCustomGameWithSecret(8, 10, secret))
+	approx.Seed(1)
+	approx.History = history
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sample, exact, _ := approx.sampleConsistent()
+		approx.bestGuess(sample, exact)
+	}
+}
+
+func TestKnownResults(t *testing.T) {
+	// the moves each strategy takes over the optimum, over every secret
+	// and in the worst case, which mustn't grow unnoticed
+	limits := map[Heuristic]int{MinMax: 176, ExpectedSize: 69, Entropy: 97}
+	worstLimits := map[Heuristic]int{MinMax: 0, ExpectedSize: 1, Entropy: 1}
+	for _, h := range []Heuristic{MinMax, ExpectedSize, Entropy} {
+		game := &Solver{Game: mm.NewGame()}
+		game.Heuristic = h
+		tree, err := game.Tree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts, err := tree.MoveCounts()
+		if err != nil {
+			t.Fatal(err)
+		}
+		deviations, err := Deviations(h.String(), counts)
+		if err != nil {
+			t.Fatalf("%v: %v", h, err)
+		}
+		for _, d := range deviations {
+			t.Logf("%v: %v", h, d)
+			if d.Known.Strategy == Optimal && d.Moves > limits[h] {
+				t.Errorf("%v takes %d moves more than the optimum, up from %d", h, d.Moves, limits[h])
+			}
+			if d.Known.Strategy == Optimal && d.Worst > worstLimits[h] {
+				t.Errorf("%v takes %d moves more than the optimum in the worst case, up from %d", h, d.Worst, worstLimits[h])
+			}
+		}
+		if h == MinMax && len(deviations) != 2 {
+			t.Errorf("expected minmax checked against the optimum and Knuth, got %v", deviations)
+		}
+	}
+
+	optimum := KnownResults[0]
+	better := &mm.MoveCounts{Size: optimum.Size}
+	for i := 0; i < 1296; i++ {
+		better.Add(4)
+	}
+	if _, err := optimum.Compare(better); err == nil {
+		t.Error("expected 4 moves a secret to beat the optimum, and be refused")
+	}
+	better.Secrets--
+	if _, err := optimum.Compare(better); err == nil {
+		t.Error("expected counts of fewer than every secret refused")
+	}
+}
+
+func TestDifficulty(t *testing.T) {
+	size := mm.GameSize{4, 6}
+	levels := map[Level]int{}
+	for _, secret := range size.AllCodes() {
+		moves := Difficulty(secret, size)
+		game := NewSolver(mm.NewCustomGameWithSecret(4, 6, secret))
+		game.Quiet = true
+		game.Solve()
+		if moves != game.TurnsTaken {
+			t.Fatalf("%v: expected the %d moves the solver takes, got %d", secret, game.TurnsTaken, moves)
+		}
+		level, ok := DifficultyLevel(secret, size)
+		if !ok {
+			t.Fatalf("%v: expected a level", secret)
+		}
+		levels[level]++
+	}
+	// 4x6 secrets take 4.476 moves on average, and 5 at worst
+	if levels[Easy] != 1+6+62 || levels[Medium] != 533 || levels[Hard] != 694 {
+		t.Errorf("expected 69 easy, 533 medium and 694 hard secrets, got %v", levels)
+	}
+
+	for _, secret := range []mm.Code{{0, 1, 2}, {0, 1, 2, 6}} {
+		if moves := Difficulty(secret, size); moves != 0 {
+			t.Errorf("expected %v not rated, got %d moves", secret, moves)
+		}
+		if _, ok := DifficultyLevel(secret, size); ok {
+			t.Errorf("expected %v not given a level", secret)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	secret := mm.Code{3, 5, 1, 0}
+	for _, h := range []Heuristic{MinMax, Entropy} {
+		game := NewSolver(mm.NewCustomGameWithSecret(4, 6, secret))
+		game.Quiet = true
+		// entropy isn't looked up from the table, so every result is scored
+		game.Heuristic = h
+		if _, err := game.Solve(); err != nil {
+			t.Fatal(err)
+		}
+		stats := game.Stats()
+		if len(stats.Moves) != game.TurnsTaken {
+			t.Fatalf("%v: expected stats for each of %d moves, got %d", h, game.TurnsTaken, len(stats.Moves))
+		}
+		possible := 1296
+		for i, m := range stats.Moves {
+			if m.Possible != possible || m.Peak < m.Possible {
+				t.Errorf("%v: move %d: expected %d codes possible, got %v", h, i+1, possible, m)
+			}
+			possible -= m.Pruned
+		}
+		if possible != 1 {
+			t.Errorf("%v: expected every code but the secret pruned, %d left", h, possible)
+		}
+		total := stats.Total()
+		if total.Partitions == 0 || total.Peak < 1296 {
+			t.Errorf("%v: expected the partitions of every move counted, got %v", h, total)
+		}
+		if h == MinMax && total.Checks != 0 || h == Entropy && total.Checks < 1296 {
+			t.Errorf("%v: expected the checks scored counted, got %v", h, total)
+		}
+	}
+}
+
+func TestOpenings(t *testing.T) {
+	game := &Solver{Game: mm.NewCustomGame(4, 6)}
+	openings, err := game.Openings(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var guesses []string
+	codes := 0
+	for _, o := range openings {
+		guesses = append(guesses, o.Guess.String())
+		codes += o.Codes
+	}
+	if codes != 1296 {
+		t.Errorf("expected the openings to cover all 1296 codes, got %d", codes)
+	}
+	// Knuth's 1122 opening takes 5801 moves, and 0012 and 0123 two more,
+	// and one more in the worst case
+	if got := strings.Join(guesses, ","); got != "0011,0012,0123,0001,0000" {
+		t.Errorf("expected 0011,0012,0123,0001,0000, got %s", got)
+	}
+	if o := openings[0]; o.Codes != 90 || o.Largest != 256 || o.Results != 13 || o.Counts.Moves != 5801 || o.Counts.Worst() != 5 {
+		t.Errorf("expected 0011 to take 5801 moves, got %v", o)
+	}
+	if o := openings[1]; o.Codes != 720 || o.Counts.Moves != 5803 || o.Counts.Worst() != 6 {
+		t.Errorf("expected 0012 to take 5803 moves, got %v", o)
+	}
+
+	// without playing them out, they're ranked by the heuristic alone
+	game.Heuristic = Entropy
+	rated, err := game.Openings(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, o := range rated {
+		if o.Counts != nil || i > 0 && o.Score < rated[i-1].Score {
+			t.Errorf("expected openings ranked by entropy, got %v", rated)
+		}
+	}
+	if _, err := (&Solver{Game: mm.NewCustomGame(5, 8)}).Openings(true); err == nil {
+		t.Error("expected a board too big to play out refused")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	size := mm.GameSize{4, 4}
+	build := func() *Tree {
+		tree, err := (&Solver{Game: mm.NewCustomGame(size.Positions, size.Colors)}).Tree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tree
+	}
+	tree := build()
+	if err := tree.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the branch after the opener scores 1-0, and the first branch after
+	// that, most black pins first
+	opener := tree.Root.Guess
+	r1 := mm.Result{1, 0}
+	second := tree.Root.Next[r1]
+	var r2 mm.Result
+	for r := range second.Next {
+		if r.Correct > r2.Correct || r.Correct == r2.Correct && r.HalfCorrect > r2.HalfCorrect {
+			r2 = r
+		}
+	}
+	var reaching mm.CodeSlice
+	for _, c := range size.AllCodes() {
+		if mm.Score(opener, c, 4) == r1 && mm.Score(second.Guess, c, 4) == r2 {
+			reaching = append(reaching, c)
+		}
+	}
+	delete(second.Next, r2)
+
+	err := tree.Validate()
+	d, ok := err.(*Defect)
+	if !ok {
+		t.Fatalf("expected a defect, got %v", err)
+	}
+	if len(d.History) != 1 || d.History[0].Guess.Compare(opener) != 0 || d.History[0].Result != r1 ||
+		d.Guess.Compare(second.Guess) != 0 || len(d.Codes) != len(reaching) || !strings.Contains(d.Problem, "no branch for "+r2.String()) {
+		t.Errorf("expected no branch for %v after %v scoring %v, reached by %d codes, got %v", r2, opener, r1, len(reaching), err)
+	}
+
+	for name, breakTree := range map[string]func(*Tree){
+		"a branch for 3-1, which no code scores": func(tree *Tree) {
+			// three pegs in place leave the fourth nowhere else to go
+			tree.Root.Next[mm.Result{3, 1}] = &Node{Guess: mm.Code{0, 0, 0, 0}}
+		},
+		"a leaf which doesn't guess the one code left": func(tree *Tree) {
+			var leaf func(n *Node) *Node
+			leaf = func(n *Node) *Node {
+				for _, next := range n.Next {
+					if len(next.Next) == 0 {
+						return next
+					}
+					return leaf(next)
+				}
+				return nil
+			}
+			l := leaf(tree.Root)
+			if l.Guess.String() == "3333" {
+				l.Guess = mm.Code{2, 2, 2, 2}
+			} else {
+				l.Guess = mm.Code{3, 3, 3, 3}
+			}
+		},
+		"a guess with colors past 3": func(tree *Tree) {
+			tree.Root.Guess = mm.Code{0, 0, 1, 4}
+		},
+	} {
+		tree := build()
+		breakTree(tree)
+		if err := tree.Validate(); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("expected %q, got %v", name, err)
+		}
+	}
+}
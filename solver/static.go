@@ -0,0 +1,141 @@
+package solver
+
+import (
+	"fmt"
+	"strings"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/pool"
+)
+
+// StaticGuesses finds a fixed set of guesses whose combined results identify
+// any secret (static mastermind), along with the function which decodes those
+// results into the secret.  The decoder expects one result per guess, in order.
+//
+// Finding a minimum set is intractable in general, so guesses are chosen
+// greedily, each one splitting the secrets into as many distinct classes as
+// possible, and then any guess the others make redundant is dropped.
+func (g *Solver) StaticGuesses() (mm.CodeSlice, func([]mm.Result) (mm.Code, error)) {
+	_, P := g.allPossibleCodes()
+
+	guesses := mm.CodeSlice{}
+	classes := make([]int, len(P))
+	numClasses := 1
+	for numClasses < len(P) {
+		best, refined, n := g.bestRefinement(P, classes)
+		if n == numClasses {
+			// can't happen while two secrets share a class; guessing either splits them
+			panic("static guess search stalled")
+		}
+		guesses = append(guesses, best)
+		classes, numClasses = refined, n
+	}
+
+	// greedy choices made early are often covered by later ones
+	for i := len(guesses) - 1; i >= 0; i-- {
+		without := append(append(mm.CodeSlice{}, guesses[:i]...), guesses[i+1:]...)
+		if g.identifies(P, without) {
+			guesses = without
+		}
+	}
+
+	decoder := make(map[string]mm.Code, len(P))
+	for _, p := range P {
+		decoder[g.resultKey(guesses, p)] = p
+	}
+
+	decode := func(results []mm.Result) (mm.Code, error) {
+		if len(results) != len(guesses) {
+			return nil, fmt.Errorf("expected %d results, got %d", len(guesses), len(results))
+		}
+		strs := make([]string, len(results))
+		for i, r := range results {
+			strs[i] = r.String()
+		}
+		code, ok := decoder[strings.Join(strs, ",")]
+		if !ok {
+			return nil, fmt.Errorf("no code produces results %v", results)
+		}
+		return code, nil
+	}
+
+	return guesses, decode
+}
+
+// the results of every guess against secret, as a map key
+func (g *Solver) resultKey(guesses mm.CodeSlice, secret mm.Code) string {
+	strs := make([]string, len(guesses))
+	for i, guess := range guesses {
+		r, err := g.check(guess, secret)
+		if err != nil {
+			panic(err)
+		}
+		strs[i] = r.String()
+	}
+	return strings.Join(strs, ",")
+}
+
+// do the results of guesses tell every code in P apart?
+func (g *Solver) identifies(P mm.CodeSlice, guesses mm.CodeSlice) bool {
+	seen := make(map[string]bool, len(P))
+	for _, p := range P {
+		k := g.resultKey(guesses, p)
+		if seen[k] {
+			return false
+		}
+		seen[k] = true
+	}
+	return true
+}
+
+type classResult struct {
+	class  int
+	result mm.Result
+}
+
+// splits each class of P by the result of guess, returning the new class of
+// each code and the number of classes
+func (g *Solver) refine(P mm.CodeSlice, classes []int, guess mm.Code) ([]int, int) {
+	ids := map[classResult]int{}
+	refined := make([]int, len(P))
+	for i, p := range P {
+		r, err := g.check(guess, p)
+		if err != nil {
+			panic(err)
+		}
+		k := classResult{classes[i], r}
+		id, ok := ids[k]
+		if !ok {
+			id = len(ids)
+			ids[k] = id
+		}
+		refined[i] = id
+	}
+	return refined, len(ids)
+}
+
+// finds the guess in P which splits the current classes into the most new
+// classes, preferring the earliest such code in P
+func (g *Solver) bestRefinement(P mm.CodeSlice, classes []int) (mm.Code, []int, int) {
+	limiter := pool.New(pool.Workers(g.Workers))
+	counts := make([]int, len(P))
+
+	for i, p := range P {
+		i1, p1 := i, p
+		limiter.Go(func() error {
+			_, counts[i1] = g.refine(P, classes, p1)
+			return nil
+		})
+	}
+
+	limiter.Wait()
+
+	best := 0
+	for i, c := range counts {
+		if c > counts[best] {
+			best = i
+		}
+	}
+	refined, n := g.refine(P, classes, P[best])
+	return P[best], refined, n
+}
@@ -0,0 +1,105 @@
+package solver
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// MoveStats is the work the solver did on one move, to show where the time
+// of a search goes
+type MoveStats struct {
+	// Checks is how many times a code was scored against another, both
+	// rating guesses and narrowing the codes which could be the secret
+	Checks int64
+	// Partitions is how many guesses had the codes which could be the
+	// secret split by the result each would score
+	Partitions int64
+	// Possible is how many codes could be the secret when the move was
+	// chosen, and Pruned how many of them its result ruled out
+	Possible int
+	Pruned   int
+	// Peak is the most codes in play at once choosing the move: those which
+	// could be the secret, and the guesses rated against them
+	Peak int
+}
+
+func (m MoveStats) String() string {
+	return fmt.Sprintf("%d checks, %d partitions, %d of %d codes pruned, peak %d codes",
+		m.Checks, m.Partitions, m.Pruned, m.Possible, m.Peak)
+}
+
+// Stats is the work of each move of the last game Solve played
+type Stats struct {
+	Moves []MoveStats
+}
+
+// Total is every move's work added up, with Possible and Peak the most of
+// any move
+func (s Stats) Total() MoveStats {
+	var t MoveStats
+	for _, m := range s.Moves {
+		t.Checks += m.Checks
+		t.Partitions += m.Partitions
+		t.Pruned += m.Pruned
+		if m.Possible > t.Possible {
+			t.Possible = m.Possible
+		}
+		if m.Peak > t.Peak {
+			t.Peak = m.Peak
+		}
+	}
+	return t
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%d moves: %v", len(s.Moves), s.Total())
+}
+
+// Stats is the work of each move of the last game Solve played.  Results
+// the 4x6 table looks up rather than scores aren't counted as checks.
+func (g *Solver) Stats() Stats {
+	return Stats{Moves: append([]MoveStats{}, g.stats.Moves...)}
+}
+
+// effort is the work of the move being chosen so far, counted by every
+// goroutine scoring it
+type effort struct {
+	checks, partitions int64
+	possible, peak     int
+}
+
+// counted adds checks and partitions to the move's work; it's called once
+// for a batch of checks, not for each, to keep the counting off the hot
+// loops
+func (g *Solver) counted(checks, partitions int) {
+	atomic.AddInt64(&g.effort.checks, int64(checks))
+	if partitions > 0 {
+		atomic.AddInt64(&g.effort.partitions, int64(partitions))
+	}
+}
+
+// holding notes codes in play at once choosing the move
+func (g *Solver) holding(codes int) {
+	if codes > g.effort.peak {
+		g.effort.peak = codes
+	}
+}
+
+// beginMove starts counting the work of a move chosen with possible codes
+// which could be the secret
+func (g *Solver) beginMove(possible int) {
+	g.effort = effort{possible: possible, peak: possible}
+}
+
+// endMove records the move's work, now its result has left remaining codes
+// which could be the secret
+func (g *Solver) endMove(remaining int) {
+	e := &g.effort
+	g.stats.Moves = append(g.stats.Moves, MoveStats{
+		Checks:     atomic.LoadInt64(&e.checks),
+		Partitions: atomic.LoadInt64(&e.partitions),
+		Possible:   e.possible,
+		Pruned:     e.possible - remaining,
+		Peak:       e.peak,
+	})
+}
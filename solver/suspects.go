@@ -0,0 +1,70 @@
+package solver
+
+import (
+	"sort"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Suspect is a move whose result, if it was scored wrong, would explain a
+// history no code is consistent with
+type Suspect struct {
+	// Move is the index of the move in the history
+	Move int
+	// Results are what the move could have scored instead, given the rest
+	// of the history, from most black pins to fewest
+	Results []mm.Result
+}
+
+// Suspects finds the moves in history which could be the one scored
+// wrong, for a codemaker who's made a mistake: those which every other move
+// agrees with some code on.  There are none if history is consistent, or if
+// more than one move must be wrong.
+func (game *Solver) Suspects(history mm.History) []Suspect {
+	results := map[int]map[mm.Result]bool{}
+	S, _ := game.allPossibleCodes()
+	for _, code := range S.codes {
+		wrong := -1
+		for i, m := range history {
+			r, err := game.check(m.Guess, code)
+			if err == nil && r == m.Result {
+				continue
+			}
+			if wrong >= 0 {
+				// two moves disagree with this code
+				wrong = -2
+				break
+			}
+			wrong = i
+		}
+		if wrong == -1 {
+			// code is consistent with all of history
+			return nil
+		}
+		if wrong >= 0 {
+			if results[wrong] == nil {
+				results[wrong] = map[mm.Result]bool{}
+			}
+			r, _ := game.check(history[wrong].Guess, code)
+			results[wrong][r] = true
+		}
+	}
+
+	suspects := []Suspect{}
+	for i, rs := range results {
+		s := Suspect{Move: i}
+		for r := range rs {
+			s.Results = append(s.Results, r)
+		}
+		sort.Slice(s.Results, func(i, j int) bool {
+			a, b := s.Results[i], s.Results[j]
+			if a.Correct != b.Correct {
+				return a.Correct > b.Correct
+			}
+			return a.HalfCorrect > b.HalfCorrect
+		})
+		suspects = append(suspects, s)
+	}
+	sort.Slice(suspects, func(i, j int) bool { return suspects[i].Move < suspects[j].Move })
+	return suspects
+}
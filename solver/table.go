@@ -0,0 +1,161 @@
+package solver
+
+import (
+	"fmt"
+	"sync"
+
+	mm "github.com/ianmcmahon/mastermind"
+	"github.com/ianmcmahon/mastermind/internal/pool"
+)
+
+// secondMoves4x6 is the guess Solve makes after each result of the 4x6
+// opener 0011, worked out ahead of time
+var secondMoves4x6 = map[mm.Result]mm.Code{
+	mm.Result{3, 0}: mm.Code{0, 1, 1, 2},
+	mm.Result{2, 2}: mm.Code{0, 1, 0, 2},
+	mm.Result{2, 1}: mm.Code{0, 1, 1, 2},
+	mm.Result{2, 0}: mm.Code{0, 1, 2, 3},
+	mm.Result{1, 2}: mm.Code{0, 1, 0, 2},
+	mm.Result{1, 1}: mm.Code{0, 0, 2, 3},
+	mm.Result{1, 0}: mm.Code{0, 2, 3, 3},
+	mm.Result{0, 4}: mm.Code{1, 1, 0, 0},
+	mm.Result{0, 3}: mm.Code{0, 1, 0, 2},
+	mm.Result{0, 2}: mm.Code{1, 2, 3, 3},
+	mm.Result{0, 1}: mm.Code{1, 2, 3, 3},
+	mm.Result{0, 0}: mm.Code{2, 2, 3, 4},
+}
+
+// table is a game worked out ahead of time: every code's result against
+// every other, so the codes still possible are narrowed and guesses rated by
+// looking results up rather than scoring them
+type table struct {
+	// codes are all the game's codes, in order
+	codes mm.CodeSlice
+	index map[mm.CodeKey]int
+	// matrix[g*len(codes)+s] is the key of the result codes[g] scores
+	// against codes[s]
+	matrix []mm.ResultKey
+}
+
+var (
+	table4x6     *table
+	table4x6Once sync.Once
+)
+
+// defaultTable is the table for the default 4x6 game, filled in the first
+// time it's needed
+func defaultTable() *table {
+	table4x6Once.Do(func() {
+		table4x6 = newTable(&Solver{Game: mm.NewGame()})
+	})
+	return table4x6
+}
+
+func newTable(g *Solver) *table {
+	t := &table{
+		codes: g.GameSize().AllCodes(),
+		index: map[mm.CodeKey]int{},
+	}
+	for i, c := range t.codes {
+		t.index[c.Key()] = i
+	}
+	// each worker fills in its own rows
+	n := len(t.codes)
+	t.matrix = make([]mm.ResultKey, n*n)
+	pool.Ranges(n, pool.Workers(g.Workers), 1, func(_, lo, hi int) {
+		for i := lo; i < hi; i++ {
+			for j, secret := range t.codes {
+				t.matrix[i*n+j] = mm.Score(t.codes[i], secret, g.Colors()).Key()
+			}
+		}
+	})
+	return t
+}
+
+// tabled is whether Solve can play from the default table: a full feedback
+// 4x6 game, rated by MinMax over every code, held whole, with the usual
+// opener.  Priors don't matter to MinMax.
+func (game *Solver) tabled() bool {
+	return game.GameSize() == mm.GameSize{4, 6} &&
+		game.Feedback == mm.FullFeedback &&
+		game.Heuristic == MinMax &&
+		game.ChunkSize == 0 &&
+		game.initialMove.Compare(initialMoves[mm.GameSize{4, 6}]) == 0 &&
+		(game.MemoryBudget == 0 || 1296*game.scoredCodeSize() <= game.MemoryBudget)
+}
+
+// solveTabled plays Solve's game from the table, without scoring a code
+func (game *Solver) solveTabled(t *table) (mm.Code, error) {
+	S := make([]int, len(t.codes))
+	for i := range S {
+		S[i] = i
+	}
+	game.stats = Stats{}
+	game.beginMove(len(S))
+	guess := t.index[game.initialMove.Key()]
+	for move := 1; ; move++ {
+		result := game.MustScoredGuess(t.codes[guess])
+		if game.IsWin(result) {
+			game.endMove(1)
+			return t.codes[guess], nil
+		}
+
+		S = t.narrow(S, guess, result)
+		game.endMove(len(S))
+		if len(S) == 0 {
+			return nil, fmt.Errorf("no code is consistent with the results scored")
+		}
+		game.beginMove(len(S))
+		if move == 1 {
+			guess = t.index[secondMoves4x6[result].Key()]
+		} else {
+			guess = t.next(game, S)
+		}
+	}
+}
+
+// narrow keeps the codes of S which score result against guess
+func (t *table) narrow(S []int, guess int, result mm.Result) []int {
+	row, key := t.matrix[guess*len(t.codes):], result.Key()
+	kept := S[:0]
+	for _, s := range S {
+		if row[s] == key {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// next is the guess nextGuess would make with S still possible: the first
+// code whose largest partition of S is smallest, preferring codes of S.
+// The partitions are counted in game's stats.
+func (t *table) next(game *Solver, S []int) int {
+	// S is in order, so its first code is the lesser
+	if len(S) <= 2 {
+		return S[0]
+	}
+
+	n := len(t.codes)
+	game.counted(0, n)
+	game.holding(len(S) + n)
+	inS := make([]bool, n)
+	for _, s := range S {
+		inS[s] = true
+	}
+	best, bestMax := -1, 0
+	for g := 0; g < n; g++ {
+		var hits hitmap
+		row := t.matrix[g*n:]
+		max := 0
+		for _, s := range S {
+			hits[row[s]]++
+			if hits[row[s]] > max {
+				max = hits[row[s]]
+			}
+		}
+		if best < 0 || max < bestMax || max == bestMax && inS[g] && !inS[best] {
+			best, bestMax = g, max
+		}
+	}
+	return best
+}
@@ -0,0 +1,243 @@
+package solver
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"sort"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Tree is a strategy worked out ahead of time: the guess the solver makes
+// at every point of every game of one size, so a game can be played from
+// it without any searching
+type Tree struct {
+	Size     mm.GameSize
+	Feedback mm.Feedback
+	Root     *Node
+}
+
+// Node is a guess in a Tree, with the rest of the strategy for each result
+// it could score.  A win has no entry in Next.
+type Node struct {
+	Guess mm.Code
+	Next  map[mm.Result]*Node
+}
+
+// Tree works out the solver's whole strategy, by making the choice Step
+// would at each point of the game
+func (g *Solver) Tree() (*Tree, error) {
+	S, P := g.allPossibleCodes()
+	root, err := g.treeNode(S, P)
+	if err != nil {
+		return nil, err
+	}
+	return &Tree{Size: g.GameSize(), Feedback: g.Feedback, Root: root}, nil
+}
+
+func (g *Solver) treeNode(S codeSet, P mm.CodeSlice) (*Node, error) {
+	m, err := g.nextMove(S, P)
+	if err != nil {
+		return nil, err
+	}
+	return g.treeOf(m, P)
+}
+
+// treeOf is the strategy from m on, the choice Step would make after each
+// result it could score
+func (g *Solver) treeOf(m move, P mm.CodeSlice) (*Node, error) {
+	node := &Node{Guess: m.guess, Next: map[mm.Result]*Node{}}
+	for r, T := range m.partition(g) {
+		if g.IsWin(r) {
+			continue
+		}
+		var err error
+		if node.Next[r], err = g.treeNode(T, P); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// TreeFrom is Tree, opening with guess rather than the solver's choice
+func (g *Solver) TreeFrom(guess mm.Code) (*Tree, error) {
+	if len(guess) != g.Positions() {
+		return nil, fmt.Errorf("guess %v isn't %d pegs", guess, g.Positions())
+	}
+	for _, c := range guess {
+		if c >= g.Colors() {
+			return nil, fmt.Errorf("guess %v has colors past %d", guess, g.Colors()-1)
+		}
+	}
+	S, P := g.allPossibleCodes()
+	root, err := g.treeOf(g.moveOf(guess, S), P)
+	if err != nil {
+		return nil, err
+	}
+	return &Tree{Size: g.GameSize(), Feedback: g.Feedback, Root: root}, nil
+}
+
+// Step returns the guess the strategy makes next in the game played so far
+func (t *Tree) Step(history mm.History) (mm.Code, error) {
+	node := t.Root
+	for i, move := range history {
+		if move.Guess.String() != node.Guess.String() {
+			return nil, fmt.Errorf("move %d is %v, where the strategy guesses %v", i+1, move.Guess, node.Guess)
+		}
+		if node = node.Next[move.Result]; node == nil {
+			return nil, fmt.Errorf("the strategy has nothing after %v scoring %v", move.Guess, move.Result)
+		}
+	}
+	return node.Guess, nil
+}
+
+// Play plays the strategy against secret, returning the number of moves it
+// takes to win
+func (t *Tree) Play(secret mm.Code) (int, error) {
+	node := t.Root
+	for moves := 1; ; moves++ {
+		if node.Guess.String() == secret.String() {
+			return moves, nil
+		}
+		r, err := t.Feedback.Score(node.Guess, secret, t.Size.Colors)
+		if err != nil {
+			return 0, err
+		}
+		if node = node.Next[r]; node == nil {
+			return 0, fmt.Errorf("the strategy doesn't find %v", secret)
+		}
+	}
+}
+
+// MoveCounts plays the strategy against every secret, and counts the moves
+// each took
+func (t *Tree) MoveCounts() (*mm.MoveCounts, error) {
+	counts := &mm.MoveCounts{Size: t.Size}
+	for _, secret := range t.Size.AllCodes() {
+		moves, err := t.Play(secret)
+		if err != nil {
+			return nil, err
+		}
+		counts.Add(moves)
+	}
+	return counts, nil
+}
+
+// Nodes is the number of guesses in the tree
+func (t *Tree) Nodes() int {
+	var count func(n *Node) int
+	count = func(n *Node) int {
+		c := 1
+		for _, next := range n.Next {
+			c += count(next)
+		}
+		return c
+	}
+	return count(t.Root)
+}
+
+// TreeVersion is the version of the encoding Tree.Write writes.  ReadTree
+// reads it and every version before it.
+const TreeVersion = 1
+
+// files start with the magic followed by the version, as a big endian uint16
+const treeMagic = "MMST"
+
+// the encoded tree: the nodes flattened in preorder, so every child comes
+// after its parent
+type treeFile struct {
+	Size     mm.GameSize
+	Feedback mm.Feedback
+	Nodes    []treeNode
+}
+
+type treeNode struct {
+	Guess   mm.Code
+	Results []mm.Result
+	// Next holds, for each of Results, the index of the node played next
+	Next []int
+}
+
+// Write encodes the tree in a compact binary form, read back by ReadTree
+func (t *Tree) Write(w io.Writer) error {
+	f := treeFile{Size: t.Size, Feedback: t.Feedback}
+	var flatten func(n *Node) int
+	flatten = func(n *Node) int {
+		i := len(f.Nodes)
+		f.Nodes = append(f.Nodes, treeNode{Guess: n.Guess})
+		results := make([]mm.Result, 0, len(n.Next))
+		for r := range n.Next {
+			results = append(results, r)
+		}
+		// most black pins first, as partitions are listed, so the same
+		// tree always encodes the same
+		sort.Slice(results, func(i, j int) bool {
+			if results[i].Correct != results[j].Correct {
+				return results[i].Correct > results[j].Correct
+			}
+			return results[i].HalfCorrect > results[j].HalfCorrect
+		})
+		next := make([]int, len(results))
+		for k, r := range results {
+			next[k] = flatten(n.Next[r])
+		}
+		f.Nodes[i].Results, f.Nodes[i].Next = results, next
+		return i
+	}
+	flatten(t.Root)
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(treeMagic)
+	binary.Write(bw, binary.BigEndian, uint16(TreeVersion))
+	if err := gob.NewEncoder(bw).Encode(&f); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// ReadTree decodes a tree written by Tree.Write
+func ReadTree(r io.Reader) (*Tree, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(treeMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != treeMagic {
+		return nil, fmt.Errorf("not a strategy tree")
+	}
+	var version uint16
+	if err := binary.Read(br, binary.BigEndian, &version); err != nil {
+		return nil, fmt.Errorf("reading strategy tree version: %v", err)
+	}
+	if version == 0 || version > TreeVersion {
+		return nil, fmt.Errorf("strategy tree is version %d; this reads up to version %d", version, TreeVersion)
+	}
+
+	var f treeFile
+	if err := gob.NewDecoder(br).Decode(&f); err != nil {
+		return nil, fmt.Errorf("reading strategy tree: %v", err)
+	}
+	if len(f.Nodes) == 0 {
+		return nil, fmt.Errorf("strategy tree has no nodes")
+	}
+	nodes := make([]*Node, len(f.Nodes))
+	for i, n := range f.Nodes {
+		if len(n.Guess) != f.Size.Positions {
+			return nil, fmt.Errorf("strategy tree node %d guesses %v, which isn't size %v", i, n.Guess, f.Size)
+		}
+		if len(n.Results) != len(n.Next) {
+			return nil, fmt.Errorf("strategy tree node %d has %d results for %d nodes", i, len(n.Results), len(n.Next))
+		}
+		nodes[i] = &Node{Guess: n.Guess, Next: make(map[mm.Result]*Node, len(n.Next))}
+	}
+	// children must come after their parent, which also rules out cycles
+	for i, n := range f.Nodes {
+		for k, next := range n.Next {
+			if next <= i || next >= len(nodes) {
+				return nil, fmt.Errorf("strategy tree node %d has a bad child %d", i, next)
+			}
+			nodes[i].Next[n.Results[k]] = nodes[next]
+		}
+	}
+	return &Tree{Size: f.Size, Feedback: f.Feedback, Root: nodes[0]}, nil
+}
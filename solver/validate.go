@@ -0,0 +1,102 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Defect is a branch of a Tree which doesn't win every game reaching it
+type Defect struct {
+	// History is the moves which reach the defective node, the results
+	// those its parents branched on
+	History mm.History
+	// Guess is the node's guess
+	Guess mm.Code
+	// Problem is what's wrong with it
+	Problem string
+	// Codes are the secrets which reach the node and lose there, or are
+	// lost after it; none if the branch is one no secret reaches
+	Codes mm.CodeSlice
+}
+
+func (d *Defect) Error() string {
+	s := fmt.Sprintf("strategy tree after %v guesses %v: %s", d.History, d.Guess, d.Problem)
+	if n := len(d.Codes); n > 0 {
+		s += fmt.Sprintf(", eg %v (%d codes)", d.Codes[0], n)
+	}
+	return s
+}
+
+// Validate walks every branch of the tree with the codes which reach it,
+// and checks every result they could score is branched on, every branch
+// is reached, and every leaf guesses the one code left, so the tree wins
+// every game.  The first defect found, going through the results as
+// partitions list them, is returned as a *Defect.
+func (t *Tree) Validate() error {
+	if t.Root == nil {
+		return &Defect{Problem: "no root"}
+	}
+	return t.validate(t.Root, nil, t.Size.AllCodes())
+}
+
+func (t *Tree) validate(n *Node, history mm.History, codes mm.CodeSlice) error {
+	defect := func(problem string, codes mm.CodeSlice) error {
+		return &Defect{History: append(mm.History{}, history...), Guess: n.Guess, Problem: problem, Codes: codes}
+	}
+	if len(n.Guess) != t.Size.Positions {
+		return defect(fmt.Sprintf("a guess which isn't size %v", t.Size), codes)
+	}
+	for _, c := range n.Guess {
+		if c >= t.Size.Colors {
+			return defect(fmt.Sprintf("a guess with colors past %d", t.Size.Colors-1), codes)
+		}
+	}
+	if len(n.Next) == 0 && (len(codes) != 1 || codes[0].Compare(n.Guess) != 0) {
+		return defect("a leaf which doesn't guess the one code left", codes)
+	}
+
+	buckets := map[mm.Result]mm.CodeSlice{}
+	for _, c := range codes {
+		r, err := t.Feedback.Score(n.Guess, c, t.Size.Colors)
+		if err != nil {
+			return defect(err.Error(), codes)
+		}
+		buckets[r] = append(buckets[r], c)
+	}
+	results := make([]mm.Result, 0, len(buckets)+len(n.Next))
+	for r := range buckets {
+		results = append(results, r)
+	}
+	for r := range n.Next {
+		if _, ok := buckets[r]; !ok {
+			results = append(results, r)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Correct != results[j].Correct {
+			return results[i].Correct > results[j].Correct
+		}
+		return results[i].HalfCorrect > results[j].HalfCorrect
+	})
+
+	for _, r := range results {
+		T, next := buckets[r], n.Next[r]
+		switch {
+		case r.Correct == t.Size.Positions:
+			if next != nil {
+				return defect("a branch after winning", nil)
+			}
+		case next == nil:
+			return defect(fmt.Sprintf("no branch for %v", r), T)
+		case len(T) == 0:
+			return defect(fmt.Sprintf("a branch for %v, which no code scores", r), nil)
+		default:
+			if err := t.validate(next, append(history, mm.Move{Guess: n.Guess, Result: r}), T); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
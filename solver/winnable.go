@@ -0,0 +1,138 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// WinnableWithin reports whether, from the codes consistent with history,
+// there is a strategy guaranteed to win within n more moves
+func (g *Solver) WinnableWithin(history mm.History, n int) bool {
+	S := g.consistentSet(history)
+	if S.len() == 0 {
+		return false
+	}
+	_, P := g.allPossibleCodes()
+	return g.winnable(S, P, n)
+}
+
+// Completion is the best way to finish a partially played game
+type Completion struct {
+	// Guess is the next move
+	Guess mm.Code
+	// Bound is the number of moves, counting Guess, within which the game
+	// is guaranteed to be won by playing on optimally
+	Bound int
+	// Remaining is the number of codes consistent with the game so far
+	Remaining int
+}
+
+// Complete finds the guess which guarantees finishing the game played so far
+// in the fewest moves.  Optimal here means worst case: no other guess can
+// promise a lower bound, though another might win sooner on average.
+func (g *Solver) Complete(history mm.History) (Completion, error) {
+	S := g.consistentSet(history)
+	if S.len() == 0 {
+		return Completion{}, fmt.Errorf("no code is consistent with %v", history)
+	}
+	_, P := g.allPossibleCodes()
+
+	// guessing each consistent code in turn is always good for len(S) moves
+	for n := 1; n <= S.len(); n++ {
+		if guess, ok := g.winningGuess(S, P, n); ok {
+			return Completion{Guess: guess, Bound: n, Remaining: S.len()}, nil
+		}
+	}
+	panic("no strategy finishes within len(S) moves")
+}
+
+// the most codes any strategy can tell apart in n moves: one guess wins
+// outright, and every other result leaves n-1 moves for its partition
+func (g *Solver) maxSolvable(n int) int {
+	results := len(g.possibleResults())
+	max := 1
+	for i := 1; i < n; i++ {
+		max = 1 + (results-1)*max
+		if max > 1<<40 {
+			break
+		}
+	}
+	return max
+}
+
+func (g *Solver) winnable(S codeSet, P mm.CodeSlice, n int) bool {
+	_, ok := g.winningGuess(S, P, n)
+	return ok
+}
+
+// returns a guess from which every code in S can be won within n moves,
+// counting the guess itself
+func (g *Solver) winningGuess(S codeSet, P mm.CodeSlice, n int) (mm.Code, bool) {
+	if n <= 0 {
+		return nil, false
+	}
+	if S.len() == 1 {
+		return S.codes[0], true
+	}
+	if n == 1 || S.len() > g.maxSolvable(n) {
+		return nil, false
+	}
+
+	for _, guess := range g.promisingGuesses(S, P, n) {
+		ok := true
+		for r, T := range g.partition(S, guess) {
+			if g.IsWin(r) {
+				continue
+			}
+			if !g.winnable(T, P, n-1) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return guess, true
+		}
+	}
+	return nil, false
+}
+
+type rankedGuess struct {
+	code    mm.Code
+	maxHits int
+	inS     bool
+}
+
+// returns the guesses in P whose partitions of S are all small enough to be
+// solved in n-1 moves, best first: smallest largest partition, then codes in S
+func (g *Solver) promisingGuesses(S codeSet, P mm.CodeSlice, n int) mm.CodeSlice {
+	limit := g.maxSolvable(n - 1)
+	ranked := []rankedGuess{}
+	for _, p := range P {
+		inS := S.has(p)
+		var hits hitmap
+		g.countHits(S, p, &hits)
+		if inS {
+			// the winning result doesn't need another move
+			hits[mm.Result{Correct: g.Positions()}.Key()] = 0
+		}
+		_, max := hits.maxHits()
+		if max <= limit {
+			ranked = append(ranked, rankedGuess{p, max, inS})
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].maxHits != ranked[j].maxHits {
+			return ranked[i].maxHits < ranked[j].maxHits
+		}
+		return ranked[i].inS && !ranked[j].inS
+	})
+
+	out := make(mm.CodeSlice, len(ranked))
+	for i, r := range ranked {
+		out[i] = r.code
+	}
+	return out
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Memory is a Store which keeps everything in memory, for a single process
+// which needn't outlive a restart, eg tests and demos
+type Memory struct {
+	mu      sync.Mutex
+	games   map[string]*Game
+	runs    []*Run
+	ratings map[string]*Rating
+}
+
+func NewMemory() *Memory {
+	return &Memory{games: map[string]*Game{}, ratings: map[string]*Rating{}}
+}
+
+// games are copied in and out, so callers can't change what's kept
+func copyGame(g *Game) *Game {
+	c := *g
+	c.Secret = append(mm.Code{}, g.Secret...)
+	c.Moves = make([]Move, len(g.Moves))
+	for i, m := range g.Moves {
+		c.Moves[i] = m
+		c.Moves[i].Guess = append(mm.Code{}, m.Guess...)
+	}
+	return &c
+}
+
+func (s *Memory) SaveGame(g *Game) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.games[g.ID] = copyGame(g)
+	return nil
+}
+
+func (s *Memory) Game(id string) (*Game, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g, ok := s.games[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyGame(g), nil
+}
+
+func (s *Memory) Games(limit int) ([]*Game, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	games := []*Game{}
+	for _, g := range s.games {
+		games = append(games, copyGame(g))
+	}
+	sort.Slice(games, func(i, j int) bool { return games[i].Started.After(games[j].Started) })
+	if len(games) > limit {
+		games = games[:limit]
+	}
+	return games, nil
+}
+
+func (s *Memory) SaveRun(r *Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run := *r
+	run.Secret = append(mm.Code{}, r.Secret...)
+	s.runs = append(s.runs, &run)
+	return nil
+}
+
+func (s *Memory) Stats(size mm.GameSize) (*Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var games []*Game
+	for _, g := range s.games {
+		if g.Size == size {
+			games = append(games, g)
+		}
+	}
+	var runs []*Run
+	for _, r := range s.runs {
+		if r.Size == size {
+			runs = append(runs, r)
+		}
+	}
+	return summarize(size, games, runs), nil
+}
+
+func (s *Memory) Rating(name string) (*Rating, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.ratings[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *r
+	return &c, nil
+}
+
+func (s *Memory) SaveRating(r *Rating) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *r
+	s.ratings[r.Name] = &c
+	return nil
+}
+
+func (s *Memory) Ratings(limit int) ([]*Rating, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ratings := []*Rating{}
+	for _, r := range s.ratings {
+		c := *r
+		ratings = append(ratings, &c)
+	}
+	return bestRatings(ratings, limit), nil
+}
+
+func (s *Memory) Close() error {
+	return nil
+}
+
+// summarize works out the stats SQLite's queries do, for stores without a
+// query language: games and runs are all of size's
+func summarize(size mm.GameSize, games []*Game, runs []*Run) *Stats {
+	st := &Stats{Size: size, Distribution: map[int]int{}}
+	guesses := 0
+	for _, g := range games {
+		st.Games++
+		if g.Solved {
+			st.Solved++
+			st.Distribution[len(g.Moves)]++
+			guesses += len(g.Moves)
+		}
+	}
+	if st.Solved > 0 {
+		st.MeanGuesses = float64(guesses) / float64(st.Solved)
+	}
+
+	bySolver := map[string][]*Run{}
+	for _, r := range runs {
+		bySolver[r.Solver] = append(bySolver[r.Solver], r)
+	}
+	for solver, runs := range bySolver {
+		ss := SolverStats{Solver: solver, Runs: len(runs)}
+		moves, solved := 0, 0
+		var duration time.Duration
+		for _, r := range runs {
+			duration += r.Duration
+			if !r.Solved {
+				ss.Failures++
+				continue
+			}
+			solved++
+			moves += r.Moves
+			if r.Moves > ss.MaxMoves {
+				ss.MaxMoves = r.Moves
+			}
+		}
+		if solved > 0 {
+			ss.MeanMoves = float64(moves) / float64(solved)
+		}
+		ss.MeanDuration = duration / time.Duration(len(runs))
+		st.Solvers = append(st.Solvers, ss)
+	}
+	sort.Slice(st.Solvers, func(i, j int) bool { return st.Solvers[i].Solver < st.Solvers[j].Solver })
+	return st
+}
+
+// bestRatings sorts ratings as SQLite's Ratings does, highest first then
+// by name, and keeps the first limit
+func bestRatings(ratings []*Rating, limit int) []*Rating {
+	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].Rating != ratings[j].Rating {
+			return ratings[i].Rating > ratings[j].Rating
+		}
+		return ratings[i].Name < ratings[j].Name
+	})
+	if len(ratings) > limit {
+		ratings = ratings[:limit]
+	}
+	return ratings
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// testStore checks a store keeps games, runs and ratings as SQLite does
+func testStore(t *testing.T, s Store) {
+	start := time.Unix(1700000000, 0)
+	size := mm.GameSize{Positions: 4, Colors: 6}
+	g := &Game{
+		ID:      "a",
+		Size:    size,
+		Secret:  mm.Code{0, 0, 1, 1},
+		Started: start,
+		Moves:   []Move{{Player: "ian", Guess: mm.Code{0, 0, 1, 1}, Result: mm.Result{Correct: 4}, At: start.Add(time.Second)}},
+		Owner:   "5e55",
+	}
+	g.Solved, g.Finished = true, start.Add(time.Second)
+	if err := s.SaveGame(g); err != nil {
+		t.Fatal(err)
+	}
+	guess := Move{Guess: mm.Code{0, 0, 1, 1}}
+	s.SaveGame(&Game{ID: "b", Size: size, Secret: mm.Code{0, 0, 1, 2}, Solved: true, Started: start.Add(time.Hour), Moves: []Move{guess, guess, guess}})
+	s.SaveGame(&Game{ID: "c", Size: size, Secret: mm.Code{0, 0, 1, 2}, Started: start.Add(time.Minute), Moves: []Move{guess}})
+	s.SaveGame(&Game{ID: "d", Size: mm.GameSize{Positions: 5, Colors: 8}, Secret: mm.Code{0, 0, 1, 2, 3}, Solved: true, Moves: []Move{}})
+
+	got, err := s.Game("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, g) {
+		t.Errorf("expected %+v, got %+v", g, got)
+	}
+	if _, err := s.Game("nope"); err != ErrNotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+	games, err := s.Games(2)
+	if err != nil || len(games) != 2 || games[0].ID != "b" || games[1].ID != "c" {
+		t.Errorf("expected b then c, got %v %v", games, err)
+	}
+
+	for _, r := range []Run{
+		{Solver: "knuth", Moves: 4, Duration: time.Second, Solved: true},
+		{Solver: "knuth", Moves: 5, Duration: 3 * time.Second, Solved: true},
+		{Solver: "genetic", Moves: 9, Duration: time.Second},
+	} {
+		r.Size, r.Secret = size, mm.Code{0, 1, 2, 3}
+		if err := s.SaveRun(&r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	st, err := s.Stats(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Games != 3 || st.Solved != 2 || st.MeanGuesses != 2 || !reflect.DeepEqual(st.Distribution, map[int]int{1: 1, 3: 1}) {
+		t.Errorf("expected the games summed up, got %+v", st)
+	}
+	expected := []SolverStats{
+		{Solver: "genetic", Runs: 1, Failures: 1, MeanDuration: time.Second},
+		{Solver: "knuth", Runs: 2, MeanMoves: 4.5, MaxMoves: 5, MeanDuration: 2 * time.Second},
+	}
+	if !reflect.DeepEqual(st.Solvers, expected) {
+		t.Errorf("expected %+v, got %+v", expected, st.Solvers)
+	}
+
+	for _, r := range []*Rating{
+		{Name: "knuth", Rating: 1550, Games: 2, Wins: 2, Updated: start},
+		{Name: "genetic", Rating: 1450, Games: 2, Losses: 2, Updated: start},
+		{Name: "entropy", Rating: 1550, Games: 1, Draws: 1, Updated: start},
+	} {
+		if err := s.SaveRating(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.Rating("nope"); err != ErrNotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+	r, err := s.Rating("knuth")
+	if err != nil || r.Wins != 2 || !r.Updated.Equal(start) {
+		t.Errorf("expected knuth's rating, got %+v %v", r, err)
+	}
+	ratings, err := s.Ratings(2)
+	if err != nil || len(ratings) != 2 || ratings[0].Name != "entropy" || ratings[1].Name != "knuth" {
+		t.Errorf("expected entropy then knuth, got %v %v", ratings, err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	s := NewMemory()
+	testStore(t, s)
+
+	// what's kept can't be changed by changing what was saved or read
+	g, _ := s.Game("a")
+	g.Moves[0].Guess[0] = 5
+	if again, _ := s.Game("a"); again.Moves[0].Guess[0] != 0 {
+		t.Errorf("expected a copy of the game, got the kept one")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	s, err := Open("memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.(*Memory); !ok {
+		t.Errorf("expected a memory store, got %T", s)
+	}
+	if _, err := Open("redis://127.0.0.1:1"); err == nil {
+		t.Errorf("expected no redis server to connect to")
+	}
+}
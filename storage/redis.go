@@ -0,0 +1,421 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Redis is a Store in a Redis server, which any number of processes can
+// share.  Everything is kept under keys starting with Prefix:
+//
+//	game:<id>          a game, as JSON
+//	games              the game ids, sorted by start time
+//	games:<size>       the ids of the games of a size, eg games:4x6
+//	runs:<size>        the runs on a size, as a list of JSON
+//	rating:<name>      a rating, as JSON
+//	ratings            the rated names
+//
+// Commands go one at a time over a single connection, which is redialed if
+// it breaks.
+type Redis struct {
+	Prefix string
+
+	addr     string
+	password string
+	db       int
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// DialRedis connects to the Redis server at a URL of the form
+// redis://[:password@]host[:port][/db]
+func DialRedis(rawurl string) (*Redis, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("%q isn't a redis:// URL", rawurl)
+	}
+	s := &Redis{Prefix: "mastermind:", addr: u.Host}
+	if u.Port() == "" {
+		s.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		s.password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if s.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("bad database number %q", db)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.dial(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// dial connects, logs in and selects the database; called with mu held
+func (s *Redis) dial() error {
+	conn, err := net.DialTimeout("tcp", s.addr, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	s.conn, s.r = conn, bufio.NewReader(conn)
+	if s.password != "" {
+		if _, err := s.send("AUTH", s.password); err != nil {
+			s.hangUp()
+			return err
+		}
+	}
+	if s.db != 0 {
+		if _, err := s.send("SELECT", strconv.Itoa(s.db)); err != nil {
+			s.hangUp()
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Redis) hangUp() {
+	s.conn.Close()
+	s.conn, s.r = nil, nil
+}
+
+// redisError is an error reply, which leaves the connection usable
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// do sends a command and reads its reply: a string, an int64, nil, or a
+// []interface{} of those
+func (s *Redis) do(args ...string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		if err := s.dial(); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := s.send(args...)
+	if _, ok := err.(redisError); err != nil && !ok {
+		s.hangUp()
+	}
+	return reply, err
+}
+
+// send is do on the connection; called with mu held
+func (s *Redis) send(args ...string) (interface{}, error) {
+	w := bufio.NewWriter(s.conn)
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return readReply(s.r)
+}
+
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("redis: bad reply %q", line)
+	}
+	kind, line := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return line, nil
+	case '-':
+		return nil, redisError(line)
+	case ':':
+		return strconv.ParseInt(line, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: bad reply %q", line)
+}
+
+// list is the do of a command replying with an array of strings, in
+// which nils are left empty
+func (s *Redis) list(args ...string) ([]string, error) {
+	reply, err := s.do(args...)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("redis: %s replied %v", args[0], reply)
+	}
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i], _ = item.(string)
+	}
+	return out, nil
+}
+
+func (s *Redis) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn, s.r = nil, nil
+	return err
+}
+
+// records are kept with their times as unix nanoseconds, as SQLite keeps
+// them, so they read back the same
+type redisGame struct {
+	ID       string
+	Size     mm.GameSize
+	Secret   string
+	Solved   bool
+	Started  int64
+	Finished int64
+	Moves    []redisMove
+	Owner    string `json:",omitempty"`
+}
+
+type redisMove struct {
+	Player string
+	Guess  string
+	Black  int
+	White  int
+	At     int64
+}
+
+type redisRun struct {
+	Solver   string
+	Config   string
+	Secret   string
+	Moves    int
+	Duration time.Duration
+	Solved   bool
+	At       int64
+}
+
+type redisRating struct {
+	Name    string
+	Rating  float64
+	Games   int
+	Wins    int
+	Losses  int
+	Draws   int
+	Updated int64
+}
+
+func (s *Redis) SaveGame(g *Game) error {
+	rec := redisGame{ID: g.ID, Size: g.Size, Secret: g.Secret.String(), Solved: g.Solved, Started: nanos(g.Started), Finished: nanos(g.Finished), Owner: g.Owner}
+	for _, m := range g.Moves {
+		rec.Moves = append(rec.Moves, redisMove{Player: m.Player, Guess: m.Guess.String(), Black: m.Result.Correct, White: m.Result.HalfCorrect, At: nanos(m.At)})
+	}
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := s.do("SET", s.Prefix+"game:"+g.ID, string(buf)); err != nil {
+		return err
+	}
+	if _, err := s.do("ZADD", s.Prefix+"games", strconv.FormatInt(rec.Started, 10), g.ID); err != nil {
+		return err
+	}
+	_, err = s.do("SADD", s.Prefix+"games:"+g.Size.String(), g.ID)
+	return err
+}
+
+func (s *Redis) Game(id string) (*Game, error) {
+	games, err := s.games([]string{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(games) == 0 {
+		return nil, ErrNotFound
+	}
+	return games[0], nil
+}
+
+func (s *Redis) Games(limit int) ([]*Game, error) {
+	if limit <= 0 {
+		return []*Game{}, nil
+	}
+	ids, err := s.list("ZREVRANGE", s.Prefix+"games", "0", strconv.Itoa(limit-1))
+	if err != nil {
+		return nil, err
+	}
+	return s.games(ids)
+}
+
+// games reads the games with ids, leaving out any which aren't there
+func (s *Redis) games(ids []string) ([]*Game, error) {
+	games := []*Game{}
+	if len(ids) == 0 {
+		return games, nil
+	}
+	args := []string{"MGET"}
+	for _, id := range ids {
+		args = append(args, s.Prefix+"game:"+id)
+	}
+	recs, err := s.list(args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, buf := range recs {
+		if buf == "" {
+			continue
+		}
+		var rec redisGame
+		if err := json.Unmarshal([]byte(buf), &rec); err != nil {
+			return nil, err
+		}
+		g := &Game{ID: rec.ID, Size: rec.Size, Secret: parseCode(rec.Secret), Solved: rec.Solved,
+			Started: fromNanos(rec.Started), Finished: fromNanos(rec.Finished), Moves: []Move{}, Owner: rec.Owner}
+		for _, m := range rec.Moves {
+			g.Moves = append(g.Moves, Move{Player: m.Player, Guess: parseCode(m.Guess),
+				Result: mm.Result{Correct: m.Black, HalfCorrect: m.White}, At: fromNanos(m.At)})
+		}
+		games = append(games, g)
+	}
+	return games, nil
+}
+
+func (s *Redis) SaveRun(r *Run) error {
+	buf, err := json.Marshal(redisRun{Solver: r.Solver, Config: r.Config, Secret: r.Secret.String(),
+		Moves: r.Moves, Duration: r.Duration, Solved: r.Solved, At: nanos(r.At)})
+	if err != nil {
+		return err
+	}
+	_, err = s.do("RPUSH", s.Prefix+"runs:"+r.Size.String(), string(buf))
+	return err
+}
+
+func (s *Redis) Stats(size mm.GameSize) (*Stats, error) {
+	ids, err := s.list("SMEMBERS", s.Prefix+"games:"+size.String())
+	if err != nil {
+		return nil, err
+	}
+	games, err := s.games(ids)
+	if err != nil {
+		return nil, err
+	}
+	recs, err := s.list("LRANGE", s.Prefix+"runs:"+size.String(), "0", "-1")
+	if err != nil {
+		return nil, err
+	}
+	var runs []*Run
+	for _, buf := range recs {
+		var rec redisRun
+		if err := json.Unmarshal([]byte(buf), &rec); err != nil {
+			return nil, err
+		}
+		runs = append(runs, &Run{Solver: rec.Solver, Config: rec.Config, Size: size, Secret: parseCode(rec.Secret),
+			Moves: rec.Moves, Duration: rec.Duration, Solved: rec.Solved, At: fromNanos(rec.At)})
+	}
+	return summarize(size, games, runs), nil
+}
+
+func (s *Redis) Rating(name string) (*Rating, error) {
+	ratings, err := s.ratings([]string{name})
+	if err != nil {
+		return nil, err
+	}
+	if len(ratings) == 0 {
+		return nil, ErrNotFound
+	}
+	return ratings[0], nil
+}
+
+func (s *Redis) SaveRating(r *Rating) error {
+	buf, err := json.Marshal(redisRating{Name: r.Name, Rating: r.Rating, Games: r.Games, Wins: r.Wins,
+		Losses: r.Losses, Draws: r.Draws, Updated: nanos(r.Updated)})
+	if err != nil {
+		return err
+	}
+	if _, err := s.do("SET", s.Prefix+"rating:"+r.Name, string(buf)); err != nil {
+		return err
+	}
+	_, err = s.do("SADD", s.Prefix+"ratings", r.Name)
+	return err
+}
+
+// Ratings reads every rating to sort them, which is fine for the number
+// of players and solvers one server sees
+func (s *Redis) Ratings(limit int) ([]*Rating, error) {
+	names, err := s.list("SMEMBERS", s.Prefix+"ratings")
+	if err != nil {
+		return nil, err
+	}
+	ratings, err := s.ratings(names)
+	if err != nil {
+		return nil, err
+	}
+	return bestRatings(ratings, limit), nil
+}
+
+func (s *Redis) ratings(names []string) ([]*Rating, error) {
+	ratings := []*Rating{}
+	if len(names) == 0 {
+		return ratings, nil
+	}
+	args := []string{"MGET"}
+	for _, name := range names {
+		args = append(args, s.Prefix+"rating:"+name)
+	}
+	recs, err := s.list(args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, buf := range recs {
+		if buf == "" {
+			continue
+		}
+		var rec redisRating
+		if err := json.Unmarshal([]byte(buf), &rec); err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, &Rating{Name: rec.Name, Rating: rec.Rating, Games: rec.Games, Wins: rec.Wins,
+			Losses: rec.Losses, Draws: rec.Draws, Updated: fromNanos(rec.Updated)})
+	}
+	return ratings, nil
+}
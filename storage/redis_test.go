@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRedis serves the few commands Redis uses, from memory
+type fakeRedis struct {
+	mu       sync.Mutex
+	password string
+	strs     map[string]string
+	sets     map[string]map[string]bool
+	zsets    map[string]map[string]float64
+	lists    map[string][]string
+}
+
+func startFakeRedis(t *testing.T, password string) (*fakeRedis, string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	f := &fakeRedis{password: password, strs: map[string]string{}, sets: map[string]map[string]bool{},
+		zsets: map[string]map[string]float64{}, lists: map[string][]string{}}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f, l.Addr().String()
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := f.password == ""
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, a := range reply.([]interface{}) {
+			args = append(args, a.(string))
+		}
+		if args[0] == "AUTH" {
+			authed = len(args) == 2 && args[1] == f.password
+		}
+		if !authed {
+			io.WriteString(conn, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+		io.WriteString(conn, f.do(args))
+	}
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func array(items []string) string {
+	out := fmt.Sprintf("*%d\r\n", len(items))
+	for _, s := range items {
+		out += bulk(s)
+	}
+	return out
+}
+
+func (f *fakeRedis) do(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch args[0] {
+	case "AUTH", "SELECT":
+		return "+OK\r\n"
+	case "SET":
+		f.strs[args[1]] = args[2]
+		return "+OK\r\n"
+	case "MGET":
+		out := fmt.Sprintf("*%d\r\n", len(args)-1)
+		for _, k := range args[1:] {
+			if v, ok := f.strs[k]; ok {
+				out += bulk(v)
+			} else {
+				out += "$-1\r\n"
+			}
+		}
+		return out
+	case "SADD":
+		if f.sets[args[1]] == nil {
+			f.sets[args[1]] = map[string]bool{}
+		}
+		f.sets[args[1]][args[2]] = true
+		return ":1\r\n"
+	case "SMEMBERS":
+		var members []string
+		for m := range f.sets[args[1]] {
+			members = append(members, m)
+		}
+		return array(members)
+	case "ZADD":
+		if f.zsets[args[1]] == nil {
+			f.zsets[args[1]] = map[string]float64{}
+		}
+		f.zsets[args[1]][args[3]], _ = strconv.ParseFloat(args[2], 64)
+		return ":1\r\n"
+	case "ZREVRANGE":
+		z := f.zsets[args[1]]
+		var members []string
+		for m := range z {
+			members = append(members, m)
+		}
+		sort.Slice(members, func(i, j int) bool { return z[members[i]] > z[members[j]] })
+		stop, _ := strconv.Atoi(args[3])
+		if stop+1 < len(members) {
+			members = members[:stop+1]
+		}
+		return array(members)
+	case "RPUSH":
+		f.lists[args[1]] = append(f.lists[args[1]], args[2])
+		return fmt.Sprintf(":%d\r\n", len(f.lists[args[1]]))
+	case "LRANGE":
+		return array(f.lists[args[1]])
+	}
+	return "-ERR unknown command '" + strings.ToLower(args[0]) + "'\r\n"
+}
+
+func TestRedis(t *testing.T) {
+	_, addr := startFakeRedis(t, "")
+	s, err := Open("redis://" + addr + "/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	testStore(t, s)
+
+	// error replies are returned and leave the connection usable
+	rs := s.(*Redis)
+	if _, err := rs.do("FLUSHALL"); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("expected an error reply, got %v", err)
+	}
+	if _, err := rs.Game("a"); err != nil {
+		t.Errorf("expected the game after an error reply, got %v", err)
+	}
+
+	// a broken connection is redialed
+	rs.conn.Close()
+	rs.Game("a")
+	if _, err := rs.Game("a"); err != nil {
+		t.Errorf("expected the game after redialing, got %v", err)
+	}
+}
+
+func TestRedisAuth(t *testing.T) {
+	_, addr := startFakeRedis(t, "sekrit")
+	if _, err := DialRedis("redis://:wrong@" + addr); err == nil {
+		t.Errorf("expected the wrong password refused")
+	}
+	s, err := DialRedis("redis://:sekrit@" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.Game("nope"); err != ErrNotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,305 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS games (
+	id        TEXT PRIMARY KEY,
+	positions INTEGER NOT NULL,
+	colors    INTEGER NOT NULL,
+	secret    TEXT NOT NULL,
+	solved    INTEGER NOT NULL,
+	started   INTEGER NOT NULL,
+	finished  INTEGER NOT NULL,
+	owner     TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS games_size ON games (positions, colors);
+CREATE TABLE IF NOT EXISTS moves (
+	game   TEXT NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	seq    INTEGER NOT NULL,
+	player TEXT NOT NULL,
+	guess  TEXT NOT NULL,
+	black  INTEGER NOT NULL,
+	white  INTEGER NOT NULL,
+	at     INTEGER NOT NULL,
+	PRIMARY KEY (game, seq)
+);
+CREATE TABLE IF NOT EXISTS runs (
+	id        INTEGER PRIMARY KEY AUTOINCREMENT,
+	solver    TEXT NOT NULL,
+	config    TEXT NOT NULL,
+	positions INTEGER NOT NULL,
+	colors    INTEGER NOT NULL,
+	secret    TEXT NOT NULL,
+	moves     INTEGER NOT NULL,
+	nanos     INTEGER NOT NULL,
+	solved    INTEGER NOT NULL,
+	at        INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS runs_size ON runs (positions, colors, solver);
+CREATE TABLE IF NOT EXISTS ratings (
+	name    TEXT PRIMARY KEY,
+	rating  REAL NOT NULL,
+	games   INTEGER NOT NULL,
+	wins    INTEGER NOT NULL,
+	losses  INTEGER NOT NULL,
+	draws   INTEGER NOT NULL,
+	updated INTEGER NOT NULL
+);
+`
+
+// columns are those added to tables since they were first made, which
+// databases made before then are given when they're opened
+var columns = []struct{ table, name, def string }{
+	{"games", "owner", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// SQLite is a Store in a SQLite database file
+type SQLite struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the database at path, making it if need be
+func OpenSQLite(path string) (*SQLite, error) {
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	// one writer at a time is all SQLite allows anyway
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := addColumns(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLite{db: db}, nil
+}
+
+// addColumns adds any of columns a table lacks
+func addColumns(db *sql.DB) error {
+	for _, c := range columns {
+		var n int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, c.table, c.name).Scan(&n); err != nil {
+			return err
+		}
+		if n == 0 {
+			if _, err := db.Exec(`ALTER TABLE ` + c.table + ` ADD COLUMN ` + c.name + ` ` + c.def); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+// times are kept as unix nanoseconds, with zero for none
+func nanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromNanos(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+func (s *SQLite) SaveGame(g *Game) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO games (id, positions, colors, secret, solved, started, finished, owner) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		g.ID, g.Size.Positions, g.Size.Colors, g.Secret.String(), g.Solved, nanos(g.Started), nanos(g.Finished), g.Owner); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM moves WHERE game = ?`, g.ID); err != nil {
+		return err
+	}
+	for i, m := range g.Moves {
+		if _, err := tx.Exec(`INSERT INTO moves (game, seq, player, guess, black, white, at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			g.ID, i, m.Player, m.Guess.String(), m.Result.Correct, m.Result.HalfCorrect, nanos(m.At)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLite) Game(id string) (*Game, error) {
+	games, err := s.games(`SELECT id, positions, colors, secret, solved, started, finished, owner FROM games WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(games) == 0 {
+		return nil, ErrNotFound
+	}
+	return games[0], nil
+}
+
+func (s *SQLite) Games(limit int) ([]*Game, error) {
+	return s.games(`SELECT id, positions, colors, secret, solved, started, finished, owner FROM games ORDER BY started DESC LIMIT ?`, limit)
+}
+
+// games reads the games a query selects, with their moves
+func (s *SQLite) games(query string, args ...interface{}) ([]*Game, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	games := []*Game{}
+	for rows.Next() {
+		g := &Game{}
+		var secret string
+		var started, finished int64
+		if err := rows.Scan(&g.ID, &g.Size.Positions, &g.Size.Colors, &secret, &g.Solved, &started, &finished, &g.Owner); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		g.Secret, g.Started, g.Finished = parseCode(secret), fromNanos(started), fromNanos(finished)
+		games = append(games, g)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, g := range games {
+		if g.Moves, err = s.moves(g.ID); err != nil {
+			return nil, err
+		}
+	}
+	return games, nil
+}
+
+func (s *SQLite) moves(id string) ([]Move, error) {
+	rows, err := s.db.Query(`SELECT player, guess, black, white, at FROM moves WHERE game = ? ORDER BY seq`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	moves := []Move{}
+	for rows.Next() {
+		var m Move
+		var guess string
+		var at int64
+		if err := rows.Scan(&m.Player, &guess, &m.Result.Correct, &m.Result.HalfCorrect, &at); err != nil {
+			return nil, err
+		}
+		m.Guess, m.At = parseCode(guess), fromNanos(at)
+		moves = append(moves, m)
+	}
+	return moves, rows.Err()
+}
+
+func (s *SQLite) SaveRun(r *Run) error {
+	_, err := s.db.Exec(`INSERT INTO runs (solver, config, positions, colors, secret, moves, nanos, solved, at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.Solver, r.Config, r.Size.Positions, r.Size.Colors, r.Secret.String(), r.Moves, int64(r.Duration), r.Solved, nanos(r.At))
+	return err
+}
+
+func (s *SQLite) Stats(size mm.GameSize) (*Stats, error) {
+	st := &Stats{Size: size, Distribution: map[int]int{}}
+
+	rows, err := s.db.Query(`SELECT g.solved, COUNT(m.seq) FROM games g LEFT JOIN moves m ON m.game = g.id
+		WHERE g.positions = ? AND g.colors = ? GROUP BY g.id`, size.Positions, size.Colors)
+	if err != nil {
+		return nil, err
+	}
+	guesses := 0
+	for rows.Next() {
+		var solved bool
+		var n int
+		if err := rows.Scan(&solved, &n); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		st.Games++
+		if solved {
+			st.Solved++
+			st.Distribution[n]++
+			guesses += n
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if st.Solved > 0 {
+		st.MeanGuesses = float64(guesses) / float64(st.Solved)
+	}
+
+	rows, err = s.db.Query(`SELECT solver, COUNT(*), SUM(1 - solved),
+			COALESCE(AVG(CASE WHEN solved THEN moves END), 0), COALESCE(MAX(CASE WHEN solved THEN moves END), 0), AVG(nanos)
+		FROM runs WHERE positions = ? AND colors = ? GROUP BY solver ORDER BY solver`, size.Positions, size.Colors)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ss SolverStats
+		var duration float64
+		if err := rows.Scan(&ss.Solver, &ss.Runs, &ss.Failures, &ss.MeanMoves, &ss.MaxMoves, &duration); err != nil {
+			return nil, err
+		}
+		ss.MeanDuration = time.Duration(duration)
+		st.Solvers = append(st.Solvers, ss)
+	}
+	return st, rows.Err()
+}
+
+func (s *SQLite) Rating(name string) (*Rating, error) {
+	ratings, err := s.ratings(`SELECT name, rating, games, wins, losses, draws, updated FROM ratings WHERE name = ?`, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(ratings) == 0 {
+		return nil, ErrNotFound
+	}
+	return ratings[0], nil
+}
+
+func (s *SQLite) SaveRating(r *Rating) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO ratings (name, rating, games, wins, losses, draws, updated) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		r.Name, r.Rating, r.Games, r.Wins, r.Losses, r.Draws, nanos(r.Updated))
+	return err
+}
+
+func (s *SQLite) Ratings(limit int) ([]*Rating, error) {
+	return s.ratings(`SELECT name, rating, games, wins, losses, draws, updated FROM ratings ORDER BY rating DESC, name LIMIT ?`, limit)
+}
+
+func (s *SQLite) ratings(query string, args ...interface{}) ([]*Rating, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ratings := []*Rating{}
+	for rows.Next() {
+		r := &Rating{}
+		var updated int64
+		if err := rows.Scan(&r.Name, &r.Rating, &r.Games, &r.Wins, &r.Losses, &r.Draws, &updated); err != nil {
+			return nil, err
+		}
+		r.Updated = fromNanos(updated)
+		ratings = append(ratings, r)
+	}
+	return ratings, rows.Err()
+}
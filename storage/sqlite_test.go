@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func openTemp(t *testing.T) (*SQLite, string) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "mm.db")
+	s, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, path
+}
+
+func TestGames(t *testing.T) {
+	s, path := openTemp(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	start := time.Unix(1700000000, 0)
+	g := &Game{
+		ID:      "a",
+		Size:    mm.GameSize{Positions: 4, Colors: 12},
+		Secret:  mm.Code{11, 0, 10, 3},
+		Started: start,
+		Moves:   []Move{{Player: "ian", Guess: mm.Code{0, 0, 1, 1}, Result: mm.Result{Correct: 1}, At: start.Add(time.Second)}},
+		Owner:   "5e55",
+	}
+	if err := s.SaveGame(g); err != nil {
+		t.Fatal(err)
+	}
+	g.Moves = append(g.Moves, Move{Guess: mm.Code{11, 0, 10, 3}, Result: mm.Result{Correct: 4}, At: start.Add(2 * time.Second)})
+	g.Solved, g.Finished = true, start.Add(2*time.Second)
+	if err := s.SaveGame(g); err != nil {
+		t.Fatal(err)
+	}
+	s.SaveGame(&Game{ID: "b", Size: mm.GameSize{Positions: 4, Colors: 6}, Secret: mm.Code{0, 1, 2, 3}, Started: start.Add(time.Hour), Moves: []Move{}})
+	s.Close()
+
+	// it's all still there after reopening
+	s, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	got, err := s.Game("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, g) {
+		t.Errorf("expected %+v, got %+v", g, got)
+	}
+	if _, err := s.Game("nope"); err != ErrNotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+	games, err := s.Games(10)
+	if err != nil || len(games) != 2 || games[0].ID != "b" {
+		t.Errorf("expected b then a, got %v %v", games, err)
+	}
+}
+
+func TestStats(t *testing.T) {
+	s, path := openTemp(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer s.Close()
+
+	size := mm.GameSize{Positions: 4, Colors: 6}
+	guess := Move{Guess: mm.Code{0, 0, 1, 1}}
+	s.SaveGame(&Game{ID: "a", Size: size, Secret: mm.Code{0, 0, 1, 1}, Solved: true, Moves: []Move{guess}})
+	s.SaveGame(&Game{ID: "b", Size: size, Secret: mm.Code{0, 0, 1, 2}, Solved: true, Moves: []Move{guess, guess, guess}})
+	s.SaveGame(&Game{ID: "c", Size: size, Secret: mm.Code{0, 0, 1, 2}, Moves: []Move{guess}})
+	s.SaveGame(&Game{ID: "d", Size: mm.GameSize{Positions: 5, Colors: 8}, Secret: mm.Code{0, 0, 1, 2, 3}, Solved: true})
+	for _, r := range []Run{
+		{Solver: "knuth", Moves: 4, Duration: time.Second, Solved: true},
+		{Solver: "knuth", Moves: 5, Duration: 3 * time.Second, Solved: true},
+		{Solver: "genetic", Moves: 9, Duration: time.Second},
+	} {
+		r.Size, r.Secret = size, mm.Code{0, 1, 2, 3}
+		if err := s.SaveRun(&r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	st, err := s.Stats(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Games != 3 || st.Solved != 2 || st.MeanGuesses != 2 || !reflect.DeepEqual(st.Distribution, map[int]int{1: 1, 3: 1}) {
+		t.Errorf("expected the games summed up, got %+v", st)
+	}
+	expected := []SolverStats{
+		{Solver: "genetic", Runs: 1, Failures: 1, MeanDuration: time.Second},
+		{Solver: "knuth", Runs: 2, MeanMoves: 4.5, MaxMoves: 5, MeanDuration: 2 * time.Second},
+	}
+	if !reflect.DeepEqual(st.Solvers, expected) {
+		t.Errorf("expected %+v, got %+v", expected, st.Solvers)
+	}
+}
+
+func TestSQLiteStore(t *testing.T) {
+	s, path := openTemp(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer s.Close()
+	testStore(t, s)
+}
+
+func TestOldSQLite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "mm.db")
+
+	// a database from before games had owners
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE games (id TEXT PRIMARY KEY, positions INTEGER NOT NULL, colors INTEGER NOT NULL,
+		secret TEXT NOT NULL, solved INTEGER NOT NULL, started INTEGER NOT NULL, finished INTEGER NOT NULL);
+		INSERT INTO games VALUES ('a', 4, 6, '0011', 0, 1700000000000000000, 0)`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	s, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if g, err := s.Game("a"); err != nil || g.Owner != "" {
+		t.Errorf("expected the old game without an owner, got %+v %v", g, err)
+	}
+	g := &Game{ID: "b", Size: mm.GameSize{Positions: 4, Colors: 6}, Secret: mm.Code{0, 1, 2, 3}, Moves: []Move{}, Owner: "5e55"}
+	if err := s.SaveGame(g); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Game("b"); err != nil || got.Owner != "5e55" {
+		t.Errorf("expected the new game's owner kept, got %+v %v", got, err)
+	}
+}
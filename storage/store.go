@@ -0,0 +1,126 @@
+// Package storage keeps games, their transcripts, solver runs and the
+// statistics over them, so servers and the CLI can pick up where they left
+// off and build up a history.
+package storage
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// Open opens the store a deployment is configured with: memory for one
+// which lasts as long as the process, a redis:// URL for a Redis server,
+// or otherwise the path of a SQLite database, which may be prefixed with
+// sqlite:
+func Open(dsn string) (Store, error) {
+	switch {
+	case dsn == "memory":
+		return NewMemory(), nil
+	case strings.HasPrefix(dsn, "redis://"):
+		return DialRedis(dsn)
+	}
+	return OpenSQLite(strings.TrimPrefix(dsn, "sqlite:"))
+}
+
+// Store is somewhere games and solver runs are kept
+type Store interface {
+	// SaveGame stores g, replacing any game with its ID, transcript and all
+	SaveGame(g *Game) error
+	Game(id string) (*Game, error)
+	// Games lists the most recently started games, newest first
+	Games(limit int) ([]*Game, error)
+
+	SaveRun(r *Run) error
+	// Stats sums up the games and runs on boards of size
+	Stats(size mm.GameSize) (*Stats, error)
+
+	Rating(name string) (*Rating, error)
+	SaveRating(r *Rating) error
+	// Ratings lists the highest rated, best first
+	Ratings(limit int) ([]*Rating, error)
+
+	Close() error
+}
+
+// Game is a game and its transcript
+type Game struct {
+	ID       string
+	Size     mm.GameSize
+	Secret   mm.Code
+	Solved   bool
+	Started  time.Time
+	Finished time.Time
+	Moves    []Move
+	// Owner says who the game belongs to, if anyone; the server keeps a
+	// hash of its session's token
+	Owner string
+}
+
+// Move is a guess in a game's transcript
+type Move struct {
+	Player string
+	Guess  mm.Code
+	Result mm.Result
+	At     time.Time
+}
+
+// Run is a solver playing a single secret
+type Run struct {
+	Solver string
+	// Config describes how the solver was set up, eg its options
+	Config   string
+	Size     mm.GameSize
+	Secret   mm.Code
+	Moves    int
+	Duration time.Duration
+	Solved   bool
+	At       time.Time
+}
+
+// Stats sums up a board size's history
+type Stats struct {
+	Size   mm.GameSize
+	Games  int
+	Solved int
+	// MeanGuesses and Distribution, the count of games by guesses taken,
+	// are over the games solved
+	MeanGuesses  float64
+	Distribution map[int]int
+	Solvers      []SolverStats
+}
+
+// SolverStats sums up a solver's runs
+type SolverStats struct {
+	Solver   string
+	Runs     int
+	Failures int
+	// MeanMoves and MaxMoves are over the runs solved
+	MeanMoves    float64
+	MaxMoves     int
+	MeanDuration time.Duration
+}
+
+// Rating is a player's or solver's rating, and the record it came from
+type Rating struct {
+	Name    string
+	Rating  float64
+	Games   int
+	Wins    int
+	Losses  int
+	Draws   int
+	Updated time.Time
+}
+
+// codes are stored as their String, which every color survives
+func parseCode(s string) mm.Code {
+	c := make(mm.Code, 0, len(s))
+	for _, r := range s {
+		c = append(c, byte(r-'0'))
+	}
+	return c
+}
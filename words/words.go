@@ -0,0 +1,143 @@
+// Package words loads word lists for playing with words as codes: each
+// letter of an alphabet is a color, and each word a code of its length.
+package words
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+// Load reads a word list: either plain text, with words separated by white
+// space or commas and # starting a comment, or a JSON array of strings, as
+// Wordle's lists are published.  Words are lowercased; anything with other
+// than letters in it is skipped.
+func Load(r io.Reader) ([]string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var raw []string
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("reading JSON word list: %v", err)
+		}
+	} else {
+		s := bufio.NewScanner(bytes.NewReader(data))
+		for s.Scan() {
+			line := s.Text()
+			if i := strings.IndexByte(line, '#'); i >= 0 {
+				line = line[:i]
+			}
+			raw = append(raw, strings.FieldsFunc(line, func(r rune) bool { return unicode.IsSpace(r) || r == ',' })...)
+		}
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	words := []string{}
+	for _, w := range raw {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w != "" && strings.IndexFunc(w, func(r rune) bool { return !unicode.IsLetter(r) }) < 0 {
+			words = append(words, w)
+		}
+	}
+	return words, nil
+}
+
+// LoadFile loads the word list at path, keeping the words of length
+// letters, or all of them if length is 0
+func LoadFile(path string, length int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	words, err := Load(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return Filter(words, length), nil
+}
+
+// Filter keeps the words of length letters, or all if length is 0, each
+// once, in the order they first appear
+func Filter(words []string, length int) []string {
+	seen := map[string]bool{}
+	kept := []string{}
+	for _, w := range words {
+		if seen[w] || length > 0 && utf8.RuneCountInString(w) != length {
+			continue
+		}
+		seen[w] = true
+		kept = append(kept, w)
+	}
+	return kept
+}
+
+// Alphabet is the letters words are spelt in, in the order of the colors
+// they stand for
+type Alphabet string
+
+// English is the alphabet of English word lists
+const English Alphabet = "abcdefghijklmnopqrstuvwxyz"
+
+// Size is the board words of length letters are played on
+func (a Alphabet) Size(length int) mm.GameSize {
+	return mm.GameSize{Positions: length, Colors: byte(utf8.RuneCountInString(string(a)))}
+}
+
+// Code is word as a code
+func (a Alphabet) Code(word string) (mm.Code, error) {
+	letters := []rune(string(a))
+	code := mm.Code{}
+	for _, r := range word {
+		c := -1
+		for i, l := range letters {
+			if l == r {
+				c = i
+				break
+			}
+		}
+		if c < 0 {
+			return nil, fmt.Errorf("%q isn't in the alphabet", r)
+		}
+		code = append(code, byte(c))
+	}
+	return code, nil
+}
+
+// Word is the word a code spells
+func (a Alphabet) Word(code mm.Code) string {
+	letters := []rune(string(a))
+	var b strings.Builder
+	for _, c := range code {
+		if int(c) < len(letters) {
+			b.WriteRune(letters[c])
+		} else {
+			b.WriteRune('?')
+		}
+	}
+	return b.String()
+}
+
+// Codes are words as codes, leaving out any not spelt in the alphabet
+func (a Alphabet) Codes(words []string) mm.CodeSlice {
+	codes := mm.CodeSlice{}
+	for _, w := range words {
+		if c, err := a.Code(w); err == nil {
+			codes = append(codes, c)
+		}
+	}
+	return codes
+}
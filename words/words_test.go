@@ -0,0 +1,91 @@
+package words
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	mm "github.com/ianmcmahon/mastermind"
+)
+
+func TestLoad(t *testing.T) {
+	text := "# common words\nCrane slate\nslate, trace\nit's 12345\n\nadieu # vowels\n"
+	words, err := Load(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"crane", "slate", "slate", "trace", "adieu"}; !reflect.DeepEqual(words, expected) {
+		t.Errorf("expected %v, got %v", expected, words)
+	}
+
+	words, err = Load(strings.NewReader(` ["cigar", "rebut", "SISSY", "humph", "cigar"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"cigar", "rebut", "sissy", "humph", "cigar"}; !reflect.DeepEqual(words, expected) {
+		t.Errorf("expected %v, got %v", expected, words)
+	}
+	if _, err := Load(strings.NewReader(`["cigar",`)); err == nil {
+		t.Error("expected bad JSON to fail")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	words := []string{"crane", "cat", "crane", "über", "slate", "dog"}
+	if got := Filter(words, 5); !reflect.DeepEqual(got, []string{"crane", "slate"}) {
+		t.Errorf("unexpected five letter words %v", got)
+	}
+	if got := Filter(words, 4); !reflect.DeepEqual(got, []string{"über"}) {
+		t.Errorf("expected letters to be counted, not bytes, got %v", got)
+	}
+	if got := Filter(words, 0); len(got) != 5 {
+		t.Errorf("expected every word once, got %v", got)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "words.txt")
+	ioutil.WriteFile(path, []byte("cat\ndog\ncat\nhorse\n"), 0644)
+
+	words, err := LoadFile(path, 3)
+	if err != nil || !reflect.DeepEqual(words, []string{"cat", "dog"}) {
+		t.Errorf("unexpected words %v %v", words, err)
+	}
+	if _, err := LoadFile(filepath.Join(dir, "none.txt"), 3); err == nil {
+		t.Error("expected a missing file to fail")
+	}
+}
+
+func TestAlphabet(t *testing.T) {
+	if size := English.Size(5); size != (mm.GameSize{Positions: 5, Colors: 26}) {
+		t.Errorf("unexpected size %v", size)
+	}
+	code, err := English.Code("crane")
+	if err != nil || !reflect.DeepEqual(code, mm.Code{2, 17, 0, 13, 4}) {
+		t.Errorf("unexpected code %v %v", code, err)
+	}
+	if w := English.Word(code); w != "crane" {
+		t.Errorf("expected crane back, got %q", w)
+	}
+	if _, err := English.Code("über"); err == nil {
+		t.Error("expected a letter outside the alphabet to fail")
+	}
+	if codes := English.Codes([]string{"crane", "über", "slate"}); len(codes) != 2 {
+		t.Errorf("expected two codes, got %v", codes)
+	}
+
+	// words score as their codes do
+	guess, _ := English.Code("slate")
+	r, err := mm.CheckCode(guess, code, 26)
+	if err != nil || r != (mm.Result{Correct: 2}) {
+		t.Errorf("expected slate against crane to score 2-0, got %v %v", r, err)
+	}
+}